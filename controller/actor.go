@@ -0,0 +1,16 @@
+package controller
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns a context telling who makes the changes, to record it in the device history
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns who makes the changes, empty when unknown
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/mongo"
+)
+
+// BrandController service
+type BrandController interface {
+	Create(ctx context.Context, brand *model.BrandInfo) error
+	GetBrand(ctx context.Context, name model.Brand) (*model.BrandInfo, error)
+	GetBrands(ctx context.Context) ([]dto.BrandDTO, error)
+	Update(ctx context.Context, brand *model.BrandInfo) error
+	Delete(ctx context.Context, name model.Brand) error
+}
+
+// BrandService service
+type BrandService struct {
+	brandDB  mongo.BrandDB
+	deviceDB mongo.DeviceDB
+}
+
+// NewBrandService BrandService constructor
+func NewBrandService(brandDB mongo.BrandDB, deviceDB mongo.DeviceDB) BrandController {
+	return BrandService{
+		brandDB:  brandDB,
+		deviceDB: deviceDB,
+	}
+}
+
+// Create creates a brand in the database
+func (bs BrandService) Create(ctx context.Context, brand *model.BrandInfo) error {
+	return bs.brandDB.Create(ctx, brand)
+}
+
+// GetBrand gets a brand from the database by name
+func (bs BrandService) GetBrand(ctx context.Context, name model.Brand) (*model.BrandInfo, error) {
+	return bs.brandDB.ByName(ctx, name)
+}
+
+// GetBrands gets all brands
+func (bs BrandService) GetBrands(ctx context.Context) ([]dto.BrandDTO, error) {
+	models, err := bs.brandDB.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]dto.BrandDTO, len(models))
+	for i := range models {
+		dtos[i] = *dto.ToBrandDTO(&models[i])
+	}
+	return dtos, nil
+}
+
+// Update updates the description and the attributes schema of a brand
+func (bs BrandService) Update(ctx context.Context, brand *model.BrandInfo) error {
+	return bs.brandDB.Update(ctx, brand)
+}
+
+// Delete deletes a brand from the database, refusing it while there are devices of that brand.
+// A device of the brand can be created between the count and the deletion, so the devices are counted again once
+// the brand is deleted, the brand being restored when it is still in use.
+func (bs BrandService) Delete(ctx context.Context, name model.Brand) error {
+	if err := bs.checkUnused(ctx, name); err != nil {
+		return err
+	}
+	brand, err := bs.brandDB.Delete(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := bs.checkUnused(ctx, name); err != nil {
+		if restoreErr := bs.brandDB.Restore(ctx, brand); restoreErr != nil {
+			return restoreErr
+		}
+		return err
+	}
+	return nil
+}
+
+// checkUnused fails when there are devices of a brand
+func (bs BrandService) checkUnused(ctx context.Context, name model.Brand) error {
+	count, err := bs.deviceDB.CountByBrand(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.BrandInUseError(string(name), count)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/model"
+	mongoMocks "github.com/device-ms/mongo/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BrandController(t *testing.T) {
+	errMock := fmt.Errorf("errMock")
+
+	ctx := context.Background()
+
+	brandDB := new(mongoMocks.BrandDB)
+	defer brandDB.AssertExpectations(t)
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+
+	brand := model.BrandInfo{
+		Name:        "acme",
+		Description: "acme devices",
+	}
+
+	t.Run("ok - brand created", func(t *testing.T) {
+		brandDB.On("Create", mock.Anything, &brand).Return(nil).Once()
+		brandController := NewBrandService(brandDB, deviceDB)
+		err := brandController.Create(ctx, &brand)
+		require.NoError(t, err)
+	})
+
+	t.Run("ok - get brand", func(t *testing.T) {
+		brandDB.On("ByName", mock.Anything, brand.Name).Return(&brand, nil).Once()
+		brandController := NewBrandService(brandDB, deviceDB)
+		resp, err := brandController.GetBrand(ctx, brand.Name)
+		require.NoError(t, err)
+		require.Equal(t, brand, *resp)
+	})
+
+	t.Run("ok - list brands", func(t *testing.T) {
+		brandDB.On("List", mock.Anything).Return([]model.BrandInfo{brand}, nil).Once()
+		brandController := NewBrandService(brandDB, deviceDB)
+		brands, err := brandController.GetBrands(ctx)
+		require.NoError(t, err)
+		require.Len(t, brands, 1)
+		require.Equal(t, model.Brand("acme"), brands[0].Name)
+	})
+	t.Run("failed listing brands", func(t *testing.T) {
+		brandDB.On("List", mock.Anything).Return([]model.BrandInfo(nil), errMock).Once()
+		brandController := NewBrandService(brandDB, deviceDB)
+		brands, err := brandController.GetBrands(ctx)
+		require.EqualError(t, err, errMock.Error())
+		require.Equal(t, []dto.BrandDTO(nil), brands)
+	})
+
+	t.Run("ok - update", func(t *testing.T) {
+		brandDB.On("Update", mock.Anything, &brand).Return(nil).Once()
+		brandController := NewBrandService(brandDB, deviceDB)
+		err := brandController.Update(ctx, &brand)
+		require.NoError(t, err)
+	})
+
+	t.Run("delete refused with devices", func(t *testing.T) {
+		deviceDB.On("CountByBrand", mock.Anything, brand.Name).Return(int64(2), nil).Once()
+		brandController := NewBrandService(brandDB, deviceDB)
+		err := brandController.Delete(ctx, brand.Name)
+		require.EqualError(t, err, "result: false; code: 1500009; message: the brand acme is used by 2 device(s)")
+	})
+	t.Run("delete failed counting devices", func(t *testing.T) {
+		deviceDB.On("CountByBrand", mock.Anything, brand.Name).Return(int64(0), errMock).Once()
+		brandController := NewBrandService(brandDB, deviceDB)
+		err := brandController.Delete(ctx, brand.Name)
+		require.EqualError(t, err, errMock.Error())
+	})
+	t.Run("ok - delete", func(t *testing.T) {
+		deviceDB.On("CountByBrand", mock.Anything, brand.Name).Return(int64(0), nil).Twice()
+		brandDB.On("Delete", mock.Anything, brand.Name).Return(&brand, nil).Once()
+		brandController := NewBrandService(brandDB, deviceDB)
+		err := brandController.Delete(ctx, brand.Name)
+		require.NoError(t, err)
+	})
+	t.Run("delete restored when a device is created meanwhile", func(t *testing.T) {
+		deviceDB.On("CountByBrand", mock.Anything, brand.Name).Return(int64(0), nil).Once()
+		brandDB.On("Delete", mock.Anything, brand.Name).Return(&brand, nil).Once()
+		deviceDB.On("CountByBrand", mock.Anything, brand.Name).Return(int64(1), nil).Once()
+		brandDB.On("Restore", mock.Anything, &brand).Return(nil).Once()
+		brandController := NewBrandService(brandDB, deviceDB)
+		err := brandController.Delete(ctx, brand.Name)
+		require.EqualError(t, err, "result: false; code: 1500009; message: the brand acme is used by 1 device(s)")
+	})
+}
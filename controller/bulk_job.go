@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/model"
+	"github.com/device-ms/mongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	envBulkJobPollInterval     = "BULK_JOB_POLL_INTERVAL"
+	defaultBulkJobPollInterval = 5 * time.Second
+	// bulkJobBatchSize is how many devices a bulk job reads at once
+	bulkJobBatchSize = 100
+	// bulkJobLease is how long a worker owns a running job without saving its progress
+	bulkJobLease = time.Minute
+)
+
+// BulkJobController service
+type BulkJobController interface {
+	Create(ctx context.Context, job *model.BulkJob) error
+	Count(ctx context.Context, filter model.DeviceFilter) (int64, error)
+	GetJob(ctx context.Context, id primitive.ObjectID) (*dto.BulkJobDTO, error)
+	Cancel(ctx context.Context, id primitive.ObjectID) (*dto.BulkJobDTO, error)
+	RunNext(ctx context.Context) (bool, error)
+}
+
+// BulkJobService service
+type BulkJobService struct {
+	jobDB    mongo.BulkJobDB
+	deviceDB mongo.DeviceDB
+	devices  DeviceController
+}
+
+// NewBulkJobService BulkJobService constructor, the devices are changed through devices to record their history
+func NewBulkJobService(jobDB mongo.BulkJobDB, deviceDB mongo.DeviceDB, devices DeviceController) BulkJobController {
+	return BulkJobService{
+		jobDB:    jobDB,
+		deviceDB: deviceDB,
+		devices:  devices,
+	}
+}
+
+// Create creates a pending job, made by the actor of ctx, with the number of devices it matches now
+func (js BulkJobService) Create(ctx context.Context, job *model.BulkJob) error {
+	total, err := js.Count(ctx, job.Filter)
+	if err != nil {
+		return err
+	}
+	job.Total = total
+	job.Actor = ActorFromContext(ctx)
+	return js.jobDB.Create(ctx, job)
+}
+
+// Count counts the devices matching a filter
+func (js BulkJobService) Count(ctx context.Context, filter model.DeviceFilter) (int64, error) {
+	page, err := js.deviceDB.List(ctx, model.DeviceSearch{DeviceFilter: filter, Limit: 1, WithTotal: true})
+	if err != nil {
+		return 0, err
+	}
+	return *page.Total, nil
+}
+
+// GetJob gets a job by its id
+func (js BulkJobService) GetJob(ctx context.Context, id primitive.ObjectID) (*dto.BulkJobDTO, error) {
+	job, err := js.jobDB.ByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToBulkJobDTO(job), nil
+}
+
+// Cancel cancels a pending job, or asks the worker of a running job to stop it after the current device
+func (js BulkJobService) Cancel(ctx context.Context, id primitive.ObjectID) (*dto.BulkJobDTO, error) {
+	job, err := js.jobDB.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToBulkJobDTO(job), nil
+}
+
+// RunNext runs the next job waiting for a worker until it is finished, telling if there was one
+func (js BulkJobService) RunNext(ctx context.Context) (bool, error) {
+	job, err := js.jobDB.Claim(ctx, time.Now().UTC().Add(bulkJobLease))
+	if err != nil || job == nil {
+		return false, err
+	}
+	return true, js.run(WithActor(ctx, job.Actor), job)
+}
+
+// run changes the devices of a job read a batch at a time, from the position of its cursor.
+// The progress is saved after every device: a job resumed by another worker only changes again the device its
+// previous worker was changing when it stopped, and not a whole batch, a new name being computed from the name.
+// A job that cannot read the devices fails, while the devices that cannot be changed are only counted as failed.
+func (js BulkJobService) run(ctx context.Context, job *model.BulkJob) error {
+	for !job.CancelRequested {
+		search := model.DeviceSearch{DeviceFilter: job.Filter, Limit: bulkJobBatchSize, Sort: model.SortCreatedAtAsc}
+		if job.Cursor != "" {
+			cursor, err := model.DecodeDeviceCursor(job.Cursor, search.Sort)
+			if err != nil {
+				return js.jobDB.Finish(ctx, job.ID, model.JobFailed, "invalid cursor: "+err.Error())
+			}
+			search.Cursor = cursor
+		}
+		page, err := js.deviceDB.List(ctx, search)
+		if err != nil {
+			return js.jobDB.Finish(ctx, job.ID, model.JobFailed, err.Error())
+		}
+		if len(page.Devices) == 0 {
+			return js.jobDB.Finish(ctx, job.ID, model.JobSucceeded, "")
+		}
+
+		for i := 0; i < len(page.Devices) && !job.CancelRequested; i++ {
+			device := &page.Devices[i]
+			progress := model.BulkJobProgress{
+				Cursor:     model.NewDeviceCursor(search.Sort, device).Encode(),
+				Processed:  1,
+				LeaseUntil: time.Now().UTC().Add(bulkJobLease),
+			}
+			if err := js.apply(ctx, job.Action, device); err != nil {
+				progress.Errors = []model.BulkJobError{{DeviceID: device.ID, Message: err.Error()}}
+			}
+			job, err = js.jobDB.Progress(ctx, job.ID, progress)
+			if err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" && !job.CancelRequested {
+			return js.jobDB.Finish(ctx, job.ID, model.JobSucceeded, "")
+		}
+	}
+	return js.jobDB.Finish(ctx, job.ID, model.JobCancelled, "")
+}
+
+// apply makes the action of a job to a device.
+// A new name is only saved if the device was not changed since it was read, as it is computed from the device.
+func (js BulkJobService) apply(ctx context.Context, action model.BulkAction, device *model.Device) error {
+	switch action.Type {
+	case model.BulkActionSetBrand:
+		return js.devices.UpdateBrand(ctx, device.ID, action.Brand, nil)
+	case model.BulkActionSetName:
+		return js.devices.UpdateName(ctx, device.ID, action.Name(device), model.VersionMatch{device.Version})
+	default:
+		return js.devices.Delete(ctx, device.ID, nil, model.DeleteDeny)
+	}
+}
+
+// BulkJobWorkerConfig is the configuration of the worker running the bulk jobs
+type BulkJobWorkerConfig struct {
+	// PollInterval is the time between two searches of jobs to run, the worker is disabled when it is 0
+	PollInterval time.Duration
+}
+
+// BulkJobWorkerConfigFromEnv reads the bulk job worker configuration from the environment
+func BulkJobWorkerConfigFromEnv() (BulkJobWorkerConfig, error) {
+	config := BulkJobWorkerConfig{PollInterval: defaultBulkJobPollInterval}
+	err := durationFromEnv(envBulkJobPollInterval, &config.PollInterval)
+	return config, err
+}
+
+// BulkJobWorker runs the bulk jobs in the background
+type BulkJobWorker struct {
+	jobs   BulkJobController
+	config BulkJobWorkerConfig
+}
+
+// NewBulkJobWorker BulkJobWorker constructor
+func NewBulkJobWorker(jobs BulkJobController, config BulkJobWorkerConfig) BulkJobWorker {
+	return BulkJobWorker{
+		jobs:   jobs,
+		config: config,
+	}
+}
+
+// Run runs the jobs waiting for a worker every poll interval until ctx is done, errors are logged and the
+// interrupted jobs resumed once their lease expires
+func (worker BulkJobWorker) Run(ctx context.Context) {
+	if worker.config.PollInterval == 0 {
+		return
+	}
+	ticker := time.NewTicker(worker.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil {
+			ran, err := worker.jobs.RunNext(ctx)
+			if err != nil {
+				log.Printf("could not run a bulk job: %v", err)
+			}
+			if !ran || err != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
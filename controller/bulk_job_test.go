@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	mongoMocks "github.com/device-ms/mongo/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBulkJobController(t *testing.T) {
+	ctx := WithActor(context.Background(), "alice")
+	errMock := fmt.Errorf("errMock")
+
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
+	jobDB := new(mongoMocks.BulkJobDB)
+	defer jobDB.AssertExpectations(t)
+	jobController := NewBulkJobService(jobDB, deviceDB, NewDeviceService(deviceDB, historyDB))
+
+	filter := model.DeviceFilter{Brands: []model.Brand{"brand1"}}
+	total := int64(2)
+	countSearch := model.DeviceSearch{DeviceFilter: filter, Limit: 1, WithTotal: true}
+	batchSearch := model.DeviceSearch{DeviceFilter: filter, Limit: bulkJobBatchSize, Sort: model.SortCreatedAtAsc}
+	devices := []model.Device{
+		{ID: primitive.NewObjectID(), Name: "io", Brand: "brand1", Version: 1},
+		{ID: primitive.NewObjectID(), Name: "europa", Brand: "brand1", Version: 1},
+	}
+	newJob := func() *model.BulkJob {
+		return &model.BulkJob{
+			ID:     primitive.NewObjectID(),
+			Filter: filter,
+			Action: model.BulkAction{Type: model.BulkActionSetBrand, Brand: "brand2"},
+			Status: model.JobRunning,
+			Actor:  "alice",
+		}
+	}
+
+	t.Run("create counts the devices of the job", func(t *testing.T) {
+		deviceDB.On("List", mock.Anything, countSearch).Return(&model.DevicePage{Total: &total}, nil).Once()
+		jobDB.On("Create", mock.Anything, mock.MatchedBy(func(job *model.BulkJob) bool {
+			return job.Total == total && job.Actor == "alice"
+		})).Return(nil).Once()
+
+		err := jobController.Create(ctx, &model.BulkJob{Filter: filter, Action: model.BulkAction{Type: model.BulkActionDelete}})
+		require.NoError(t, err)
+	})
+
+	t.Run("fail count", func(t *testing.T) {
+		deviceDB.On("List", mock.Anything, countSearch).Return(nil, errMock).Once()
+		_, err := jobController.Count(ctx, filter)
+		require.EqualError(t, err, errMock.Error())
+	})
+
+	t.Run("fail cancel a finished job", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		jobDB.On("Cancel", mock.Anything, id).Return(nil, errors.InvalidStateError("job", id.Hex(), "succeeded")).Once()
+		_, err := jobController.Cancel(ctx, id)
+		require.EqualError(t, err, "result: false; code: 1500019; message: the job with id "+id.Hex()+" is succeeded")
+	})
+
+	t.Run("no job to run", func(t *testing.T) {
+		jobDB.On("Claim", mock.Anything, mock.Anything).Return(nil, nil).Once()
+		ran, err := jobController.RunNext(ctx)
+		require.NoError(t, err)
+		require.False(t, ran)
+	})
+
+	t.Run("run changes the devices and counts the failures", func(t *testing.T) {
+		job := newJob()
+		jobDB.On("Claim", mock.Anything, mock.Anything).Return(job, nil).Once()
+		deviceDB.On("List", mock.Anything, batchSearch).Return(&model.DevicePage{Devices: devices}, nil).Once()
+		changed := devices[0]
+		changed.Brand = "brand2"
+		changed.Version = 2
+		deviceDB.On("Patch", mock.Anything, devices[0].ID, model.VersionMatch(nil), mock.Anything).Return(&model.DeviceChange{Before: &devices[0], After: &changed}, nil).Once()
+		deviceDB.On("Patch", mock.Anything, devices[1].ID, model.VersionMatch(nil), mock.Anything).Return(nil, errMock).Once()
+		historyDB.On("Append", mock.Anything, mock.MatchedBy(func(rev *model.DeviceRevision) bool {
+			return rev.Actor == "alice" && rev.DeviceID == devices[0].ID
+		})).Return(nil).Once()
+		jobDB.On("Progress", mock.Anything, job.ID, mock.MatchedBy(func(progress model.BulkJobProgress) bool {
+			return progress.Processed == 1 && len(progress.Errors) == 0 && progress.Cursor == model.NewDeviceCursor(model.SortCreatedAtAsc, &devices[0]).Encode()
+		})).Return(job, nil).Once()
+		jobDB.On("Progress", mock.Anything, job.ID, mock.MatchedBy(func(progress model.BulkJobProgress) bool {
+			return progress.Processed == 1 && len(progress.Errors) == 1 && progress.Errors[0].DeviceID == devices[1].ID && progress.Cursor == model.NewDeviceCursor(model.SortCreatedAtAsc, &devices[1]).Encode()
+		})).Return(job, nil).Once()
+		jobDB.On("Finish", mock.Anything, job.ID, model.JobSucceeded, "").Return(nil).Once()
+
+		ran, err := jobController.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, ran)
+	})
+
+	t.Run("run stops a cancelled job after the current device", func(t *testing.T) {
+		job := newJob()
+		job.Action = model.BulkAction{Type: model.BulkActionSetName, NamePattern: "old-{name}"}
+		cancelled := *job
+		cancelled.CancelRequested = true
+		jobDB.On("Claim", mock.Anything, mock.Anything).Return(job, nil).Once()
+		deviceDB.On("List", mock.Anything, batchSearch).Return(&model.DevicePage{Devices: devices, NextCursor: "next"}, nil).Once()
+		deviceDB.On("UpdateName", mock.Anything, devices[0].ID, "old-io", model.VersionMatch{1}).Return(nil, errMock).Once()
+		jobDB.On("Progress", mock.Anything, job.ID, mock.Anything).Return(&cancelled, nil).Once()
+		jobDB.On("Finish", mock.Anything, job.ID, model.JobCancelled, "").Return(nil).Once()
+
+		ran, err := jobController.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, ran)
+	})
+
+	t.Run("run resumes from the cursor and fails when the devices cannot be read", func(t *testing.T) {
+		job := newJob()
+		cursor := model.NewDeviceCursor(model.SortCreatedAtAsc, &devices[0])
+		job.Cursor = cursor.Encode()
+		jobDB.On("Claim", mock.Anything, mock.Anything).Return(job, nil).Once()
+		deviceDB.On("List", mock.Anything, mock.MatchedBy(func(s model.DeviceSearch) bool {
+			return s.Cursor != nil && s.Cursor.ID == devices[0].ID
+		})).Return(nil, errMock).Once()
+		jobDB.On("Finish", mock.Anything, job.ID, model.JobFailed, errMock.Error()).Return(nil).Once()
+
+		ran, err := jobController.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, ran)
+	})
+}
+
+func TestBulkJobWorker(t *testing.T) {
+	jobDB := new(mongoMocks.BulkJobDB)
+	defer jobDB.AssertExpectations(t)
+	jobs := NewBulkJobService(jobDB, nil, nil)
+
+	t.Run("config from env", func(t *testing.T) {
+		t.Setenv(envBulkJobPollInterval, "")
+		config, err := BulkJobWorkerConfigFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, BulkJobWorkerConfig{PollInterval: 5 * time.Second}, config)
+
+		t.Setenv(envBulkJobPollInterval, "soon")
+		_, err = BulkJobWorkerConfigFromEnv()
+		require.EqualError(t, err, "invalid BULK_JOB_POLL_INTERVAL [soon]: time: invalid duration \"soon\"")
+	})
+
+	t.Run("run polls until the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		jobDB.On("Claim", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("errMock")).Once()
+		jobDB.On("Claim", mock.Anything, mock.Anything).Return(nil, nil).Once().Run(func(mock.Arguments) { cancel() })
+
+		NewBulkJobWorker(jobs, BulkJobWorkerConfig{PollInterval: time.Millisecond}).Run(ctx)
+	})
+
+	t.Run("run does nothing when disabled", func(t *testing.T) {
+		NewBulkJobWorker(jobs, BulkJobWorkerConfig{}).Run(context.Background())
+	})
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/device-ms/model"
+)
+
+const (
+	envConnectivityStaleAfter   = "CONNECTIVITY_STALE_AFTER"
+	envConnectivityOfflineAfter = "CONNECTIVITY_OFFLINE_AFTER"
+)
+
+// ConnectivityThresholdsFromEnv reads from the environment how long after their last heartbeat the devices
+// become stale, then offline
+func ConnectivityThresholdsFromEnv() (model.ConnectivityThresholds, error) {
+	thresholds := model.DefaultConnectivityThresholds
+	if err := durationFromEnv(envConnectivityStaleAfter, &thresholds.StaleAfter); err != nil {
+		return thresholds, err
+	}
+	if err := durationFromEnv(envConnectivityOfflineAfter, &thresholds.OfflineAfter); err != nil {
+		return thresholds, err
+	}
+	if err := thresholds.Validate(); err != nil {
+		return thresholds, fmt.Errorf("invalid %s or %s: %w", envConnectivityStaleAfter, envConnectivityOfflineAfter, err)
+	}
+	return thresholds, nil
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectivityThresholdsFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv(envConnectivityStaleAfter, "")
+		t.Setenv(envConnectivityOfflineAfter, "")
+		thresholds, err := ConnectivityThresholdsFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, model.ConnectivityThresholds{StaleAfter: 2 * time.Minute, OfflineAfter: 10 * time.Minute}, thresholds)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		t.Setenv(envConnectivityStaleAfter, "30s")
+		t.Setenv(envConnectivityOfflineAfter, "5m")
+		thresholds, err := ConnectivityThresholdsFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, model.ConnectivityThresholds{StaleAfter: 30 * time.Second, OfflineAfter: 5 * time.Minute}, thresholds)
+	})
+
+	t.Run("offline before stale", func(t *testing.T) {
+		t.Setenv(envConnectivityStaleAfter, "15m")
+		t.Setenv(envConnectivityOfflineAfter, "")
+		_, err := ConnectivityThresholdsFromEnv()
+		require.EqualError(t, err, "invalid CONNECTIVITY_STALE_AFTER or CONNECTIVITY_OFFLINE_AFTER: offline threshold 10m0s must be greater than the stale threshold 15m0s")
+	})
+}
@@ -2,6 +2,8 @@ package controller
 
 import (
 	"context"
+	"log"
+	"time"
 
 	"github.com/device-ms/dto"
 	"github.com/device-ms/errors"
@@ -14,43 +16,213 @@ import (
 // DeviceController service
 type DeviceController interface {
 	Create(ctx context.Context, dv *model.Device) error
+	CreateMany(ctx context.Context, items []*model.BulkItem, ordered bool) error
+	Import(ctx context.Context, items []*model.BulkItem, transactional bool) error
 	GetDevice(ctx context.Context, deviceID primitive.ObjectID) (*model.Device, error)
-	GetDevices(ctx context.Context) ([]dto.DeviceDTO, error)
-	Update(ctx context.Context, dv *model.Device) error
-	UpdateName(ctx context.Context, deviceID primitive.ObjectID, name string) error
-	UpdateBrand(ctx context.Context, deviceID primitive.ObjectID, brand model.Brand) error
-	Delete(ctx context.Context, deviceID primitive.ObjectID) error
-	GetDevicesByBrand(ctx context.Context, brand model.Brand) ([]dto.DeviceDTO, error)
+	GetDeviceByExternalID(ctx context.Context, system, value string) (*model.Device, error)
+	GetDevices(ctx context.Context, search model.DeviceSearch) (*dto.DevicePageDTO, error)
+	Export(ctx context.Context, search model.DeviceSearch, fn func(dv *model.Device) error) error
+	Update(ctx context.Context, dv *model.Device, match model.VersionMatch) error
+	UpdateName(ctx context.Context, deviceID primitive.ObjectID, name string, match model.VersionMatch) error
+	UpdateBrand(ctx context.Context, deviceID primitive.ObjectID, brand model.Brand, match model.VersionMatch) error
+	UpdateLocation(ctx context.Context, deviceID primitive.ObjectID, location *model.Location, match model.VersionMatch) error
+	RotateHeartbeatToken(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch) (string, error)
+	Heartbeat(ctx context.Context, deviceID primitive.ObjectID, token string) error
+	AddTelemetry(ctx context.Context, deviceID primitive.ObjectID, token string, samples []model.TelemetrySample) error
+	GetTelemetry(ctx context.Context, query model.TelemetryQuery) (*dto.TelemetrySeriesDTO, error)
+	Patch(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch, apply func(dv *model.Device) error) (*model.Device, error)
+	Transition(ctx context.Context, deviceID primitive.ObjectID, to model.DeviceState, reason string, match model.VersionMatch) (*model.Device, error)
+	Delete(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch, policy model.DeletePolicy) error
+	Restore(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch) (*model.Device, error)
+	Checkout(ctx context.Context, deviceID primitive.ObjectID, lease model.Lease, match model.VersionMatch) (*model.Device, error)
+	Checkin(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch) (*model.Device, error)
+	ExpireLeases(ctx context.Context, at time.Time) (int, error)
+	SetLabel(ctx context.Context, deviceID primitive.ObjectID, key, value string, match model.VersionMatch) (*model.Device, error)
+	RemoveLabel(ctx context.Context, deviceID primitive.ObjectID, key string, match model.VersionMatch) (*model.Device, error)
+	SetParent(ctx context.Context, deviceID primitive.ObjectID, parentID *primitive.ObjectID, match model.VersionMatch) (*model.Device, error)
+	GetChildren(ctx context.Context, deviceID primitive.ObjectID, depth int) (*dto.DevicePageDTO, error)
+	GetAncestors(ctx context.Context, deviceID primitive.ObjectID) (*dto.DevicePageDTO, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	GetDeviceAsOf(ctx context.Context, deviceID primitive.ObjectID, asOf time.Time) (*model.Device, error)
+	GetHistory(ctx context.Context, search model.RevisionSearch) (*dto.RevisionPageDTO, error)
+	GetRevision(ctx context.Context, deviceID primitive.ObjectID, revision int64) (*model.DeviceRevision, error)
 }
 
 // DeviceService service
 type DeviceService struct {
-	deviceDB mongo.DeviceDB
+	deviceDB  mongo.DeviceDB
+	historyDB mongo.DeviceHistoryDB
 }
 
 // NewDeviceService DeviceService constructor
-func NewDeviceService(deviceDB mongo.DeviceDB) DeviceController {
+func NewDeviceService(deviceDB mongo.DeviceDB, historyDB mongo.DeviceHistoryDB) DeviceController {
 	return DeviceService{
-		deviceDB: deviceDB,
+		deviceDB:  deviceDB,
+		historyDB: historyDB,
 	}
 }
 
-// Create creates a device in the database
+// Create creates a device in the database, its parent must exist
 func (dvs DeviceService) Create(ctx context.Context, device *model.Device) error {
+	if device.ParentID != nil {
+		if err := dvs.checkParent(ctx, device.ID, *device.ParentID); err != nil {
+			return err
+		}
+	}
 	err := dvs.deviceDB.Create(ctx, device)
 	if err != nil {
 		return err
 	}
+	after := *device
+	return dvs.record(ctx, model.OperationCreate, &model.DeviceChange{After: &after})
+}
+
+// CreateMany creates the devices of the pending items, the items already failed being reported as they are.
+// An ordered creation stops at the first item that fails, the items after it are skipped.
+// The error is the one of the revisions of the created devices that could not be recorded.
+func (dvs DeviceService) CreateMany(ctx context.Context, items []*model.BulkItem, ordered bool) error {
+	dvs.checkParents(ctx, items)
+	pending := make([]*model.BulkItem, 0, len(items))
+	failed := false
+	for _, item := range items {
+		switch {
+		case item.Status == model.BulkFailed:
+			failed = true
+		case ordered && failed:
+			item.Status = model.BulkSkipped
+		default:
+			pending = append(pending, item)
+		}
+	}
+
+	dvs.deviceDB.CreateMany(ctx, pending, ordered)
+	return dvs.recordCreated(ctx, pending)
+}
+
+// Import creates the devices of the pending items of an import, the items already failed being reported as they are.
+// A transactional import creates all the devices or none: when an item fails, the other ones are skipped,
+// the devices already created being removed. It is not atomic: the created devices can be read before they are
+// removed, and they are kept if the removal fails, the error being returned.
+func (dvs DeviceService) Import(ctx context.Context, items []*model.BulkItem, transactional bool) error {
+	if !transactional {
+		return dvs.CreateMany(ctx, items, false)
+	}
+
+	dvs.checkParents(ctx, items)
+	for _, item := range items {
+		if item.Status == model.BulkFailed {
+			skipPending(items)
+			return nil
+		}
+	}
+
+	dvs.deviceDB.CreateMany(ctx, items, true)
+	var created []primitive.ObjectID
+	for _, item := range items {
+		if item.Status == model.BulkCreated {
+			created = append(created, item.Device.ID)
+		}
+	}
+	if len(created) == len(items) {
+		return dvs.recordCreated(ctx, items)
+	}
+
+	if len(created) > 0 {
+		if err := dvs.deviceDB.Remove(ctx, created); err != nil {
+			return err
+		}
+	}
+	for _, item := range items {
+		if item.Status == model.BulkCreated {
+			item.Status = model.BulkSkipped
+		}
+	}
 	return nil
 }
 
-// Delete deletes a device from the database
-func (dvs DeviceService) Delete(ctx context.Context, deviceID primitive.ObjectID) error {
-	err := dvs.deviceDB.Delete(ctx, deviceID)
+// skipPending skips the items not processed yet
+func skipPending(items []*model.BulkItem) {
+	for _, item := range items {
+		if item.Status == model.BulkPending {
+			item.Status = model.BulkSkipped
+		}
+	}
+}
+
+// recordCreated records the creation of the devices of the created items, returning the first error
+func (dvs DeviceService) recordCreated(ctx context.Context, items []*model.BulkItem) error {
+	var err error
+	for _, item := range items {
+		if item.Status == model.BulkCreated {
+			after := *item.Device
+			if recordErr := dvs.record(ctx, model.OperationCreate, &model.DeviceChange{After: &after}); err == nil {
+				err = recordErr
+			}
+		}
+	}
+	return err
+}
+
+// Delete soft deletes a device when its version matches, it stays in the trash until it is purged.
+// A device having children is not deleted with the deny policy. With the cascade policy its descendants are deleted
+// before it, the deepest first, unless one of them is in use; a failure once some of them are deleted is reported as
+// a partial delete, the device and the descendants not deleted yet staying as they are.
+func (dvs DeviceService) Delete(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch, policy model.DeletePolicy) error {
+	var deleted, descendants int
+	if policy == model.DeleteCascade {
+		var err error
+		deleted, descendants, err = dvs.deleteDescendants(ctx, deviceID, match)
+		if err != nil {
+			return err
+		}
+	}
+
+	change, err := dvs.deviceDB.Delete(ctx, deviceID, match)
 	if err != nil {
+		if deleted > 0 {
+			return errors.PartialDeleteError(deviceID.Hex(), deleted, descendants, err)
+		}
 		return err
 	}
-	return nil
+	return dvs.record(ctx, model.OperationDelete, change)
+}
+
+// deleteDescendants deletes the descendants of a device, leaves first, returning how many were deleted out of how many.
+// Nothing is deleted when the device doesn't have a matching version or when it or one of its descendants is in use.
+func (dvs DeviceService) deleteDescendants(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch) (int, int, error) {
+	descendants, err := dvs.deviceDB.Descendants(ctx, deviceID, 0)
+	if err != nil || len(descendants) == 0 {
+		return 0, 0, err
+	}
+	device, err := dvs.deviceDB.ByID(ctx, deviceID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !match.Matches(device.Version) {
+		return 0, 0, errors.VersionMismatchError("device", deviceID.Hex())
+	}
+	for _, dv := range append([]model.Device{*device}, descendants...) {
+		if dv.CurrentState() == model.StateInUse {
+			return 0, 0, errors.InvalidStateError("device", dv.ID.Hex(), string(model.StateInUse))
+		}
+	}
+
+	// descendants are sorted by level, the leaves are the last ones
+	for i := len(descendants) - 1; i >= 0; i-- {
+		deleted := len(descendants) - 1 - i
+		change, err := dvs.deviceDB.Delete(ctx, descendants[i].ID, nil)
+		if err == nil {
+			deleted++
+			err = dvs.record(ctx, model.OperationDelete, change)
+		}
+		if err != nil {
+			if deleted == 0 {
+				return 0, len(descendants), err
+			}
+			return deleted, len(descendants), errors.PartialDeleteError(deviceID.Hex(), deleted, len(descendants), err)
+		}
+	}
+	return len(descendants), len(descendants), nil
 }
 
 // Gets a device from the database by ID
@@ -66,55 +238,387 @@ func (dvs DeviceService) GetDevice(ctx context.Context, deviceID primitive.Objec
 	return dv, nil
 }
 
-// GetDevices gets all devices
-func (dvs DeviceService) GetDevices(ctx context.Context) ([]dto.DeviceDTO, error) {
-	models, err := dvs.deviceDB.List(ctx)
+// GetDeviceByExternalID gets the device having an external id of a system
+func (dvs DeviceService) GetDeviceByExternalID(ctx context.Context, system, value string) (*model.Device, error) {
+	return dvs.deviceDB.ByExternalID(ctx, system, value)
+}
+
+// GetDevices gets a page of devices matching the search
+func (dvs DeviceService) GetDevices(ctx context.Context, search model.DeviceSearch) (*dto.DevicePageDTO, error) {
+	page, err := dvs.deviceDB.List(ctx, search)
 	if err != nil {
 		return nil, err
 	}
-	dtos := make([]dto.DeviceDTO, len(models))
-	for i := range models {
-		dtos[i] = *dto.ToDeviceDTO(&models[i])
+	return dto.ToDevicePageDTO(page), nil
+}
+
+// Export calls fn with every device matching the search, streamed from the database without paging
+func (dvs DeviceService) Export(ctx context.Context, search model.DeviceSearch, fn func(dv *model.Device) error) error {
+	return dvs.deviceDB.Stream(ctx, search, fn)
+}
+
+// Update updates the information of a device when its version matches, except infra fields like CreatedAt and UpdatedAt
+func (dvs DeviceService) Update(ctx context.Context, dv *model.Device, match model.VersionMatch) error {
+	change, err := dvs.deviceDB.Update(ctx, dv, match)
+	if err != nil {
+		return err
 	}
-	return dtos, nil
+	return dvs.record(ctx, model.OperationUpdate, change)
 }
 
-// GetDevicesByBrand gets all devices of a certain brand
-func (dvs DeviceService) GetDevicesByBrand(ctx context.Context, brand model.Brand) ([]dto.DeviceDTO, error) {
-	models, err := dvs.deviceDB.ListByBrand(ctx, brand)
+// UpdateBrand updates the brand of a device when its version matches, its attributes must satisfy the attributes
+// schema of the brand
+func (dvs DeviceService) UpdateBrand(ctx context.Context, deviceID primitive.ObjectID, brand model.Brand, match model.VersionMatch) error {
+	if !brand.IsValid() {
+		return errors.InvalidParameterError("brand", "invalid value")
+	}
+	change, err := dvs.deviceDB.Patch(ctx, deviceID, match, func(dv *model.Device) error {
+		dv.Brand = brand
+		return errors.Join(dto.ValidateAttributes(brand, dv.Attributes)...)
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
-	dtos := make([]dto.DeviceDTO, len(models))
-	for i := range models {
-		dtos[i] = *dto.ToDeviceDTO(&models[i])
+	return dvs.record(ctx, model.OperationUpdateBrand, change)
+}
+
+// UpdateName updates the name of a device when its version matches
+func (dvs DeviceService) UpdateName(ctx context.Context, deviceID primitive.ObjectID, name string, match model.VersionMatch) error {
+	change, err := dvs.deviceDB.UpdateName(ctx, deviceID, name, match)
+	if err != nil {
+		return err
 	}
-	return dtos, nil
+	return dvs.record(ctx, model.OperationUpdateName, change)
 }
 
-// Update updates the information of a device, except infra fields like CreatedAt and UpdatedAt
-func (dvs DeviceService) Update(ctx context.Context, dv *model.Device) error {
-	_, err := dvs.deviceDB.Update(ctx, dv)
+// UpdateLocation replaces the location of a device when its version matches, a nil location removing it
+func (dvs DeviceService) UpdateLocation(ctx context.Context, deviceID primitive.ObjectID, location *model.Location, match model.VersionMatch) error {
+	change, err := dvs.deviceDB.UpdateLocation(ctx, deviceID, location, match)
 	if err != nil {
 		return err
 	}
-	return nil
+	return dvs.record(ctx, model.OperationUpdateLocation, change)
 }
 
-// UpdateBrand updates the brand of a device
-func (dvs DeviceService) UpdateBrand(ctx context.Context, deviceID primitive.ObjectID, brand model.Brand) error {
-	err := dvs.deviceDB.UpdateBrand(ctx, deviceID, brand)
+// RotateHeartbeatToken gives a device a new heartbeat token when its version matches, the previous one being revoked.
+// Only the hash of the token is saved, the token is returned once.
+func (dvs DeviceService) RotateHeartbeatToken(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch) (string, error) {
+	token, hash, err := model.NewHeartbeatToken()
+	if err != nil {
+		return "", errors.UnexpectedError(err)
+	}
+	change, err := dvs.deviceDB.SetHeartbeatToken(ctx, deviceID, hash, match)
 	if err != nil {
+		return "", err
+	}
+	if err := dvs.record(ctx, model.OperationRotateHeartbeatToken, change); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Heartbeat records that a device is alive now, when the token is its heartbeat token.
+// Heartbeats are not recorded in the history of the device.
+func (dvs DeviceService) Heartbeat(ctx context.Context, deviceID primitive.ObjectID, token string) error {
+	if token == "" {
+		return errors.UnauthorizedError("device", deviceID.Hex())
+	}
+	return dvs.deviceDB.Heartbeat(ctx, deviceID, model.HashHeartbeatToken(token), time.Now().UTC().Truncate(time.Second))
+}
+
+// AddTelemetry saves the telemetry samples a device posts with its heartbeat token.
+// A device posting telemetry is alive, so the post is also a heartbeat.
+func (dvs DeviceService) AddTelemetry(ctx context.Context, deviceID primitive.ObjectID, token string, samples []model.TelemetrySample) error {
+	if err := dvs.Heartbeat(ctx, deviceID, token); err != nil {
 		return err
 	}
-	return nil
+	for i := range samples {
+		samples[i].DeviceID = deviceID
+	}
+	return dvs.deviceDB.AddTelemetry(ctx, samples)
+}
+
+// GetTelemetry gets the telemetry series of a device, downsampled when the query has a bucket
+func (dvs DeviceService) GetTelemetry(ctx context.Context, query model.TelemetryQuery) (*dto.TelemetrySeriesDTO, error) {
+	if _, err := dvs.deviceDB.ByID(ctx, query.DeviceID); err != nil {
+		return nil, err
+	}
+	series, err := dvs.deviceDB.TelemetrySeries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToTelemetrySeriesDTO(series), nil
+}
+
+// Patch changes the name and brand of a device with apply, atomically, when its version matches
+func (dvs DeviceService) Patch(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch, apply func(dv *model.Device) error) (*model.Device, error) {
+	change, err := dvs.deviceDB.Patch(ctx, deviceID, match, apply)
+	if err != nil {
+		return nil, err
+	}
+	if err := dvs.record(ctx, model.OperationPatch, change); err != nil {
+		return nil, err
+	}
+	return change.After, nil
+}
+
+// Transition moves a device to another state of its lifecycle when its version matches, recording the reason.
+// The transition is checked against the state of the device when it is changed.
+func (dvs DeviceService) Transition(ctx context.Context, deviceID primitive.ObjectID, to model.DeviceState, reason string, match model.VersionMatch) (*model.Device, error) {
+	change, err := dvs.deviceDB.Patch(ctx, deviceID, match, func(dv *model.Device) error {
+		from := dv.CurrentState()
+		if !canTransition(from, to) {
+			return errors.IllegalTransitionError("device", deviceID.Hex(), string(from), string(to))
+		}
+		dv.State = to
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	revision := model.NewDeviceRevision(model.OperationTransition, ActorFromContext(ctx), *change)
+	revision.Reason = reason
+	if err := dvs.append(ctx, revision); err != nil {
+		return nil, err
+	}
+	return change.After, nil
+}
+
+// Restore restores a soft deleted device when its version matches.
+// A device whose parent is still deleted, or purged, is detached from it once restored.
+func (dvs DeviceService) Restore(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch) (*model.Device, error) {
+	change, err := dvs.deviceDB.Restore(ctx, deviceID, match)
+	if err != nil {
+		return nil, err
+	}
+	if err := dvs.record(ctx, model.OperationRestore, change); err != nil {
+		return nil, err
+	}
+
+	restored := change.After
+	if restored.ParentID == nil {
+		return restored, nil
+	}
+	_, err = dvs.deviceDB.ByID(ctx, *restored.ParentID)
+	if err == nil {
+		return restored, nil
+	}
+	if errors.KindOf(err) != errors.KindNotFound {
+		return nil, err
+	}
+	change, err = dvs.deviceDB.SetParent(ctx, deviceID, nil, model.VersionMatch{restored.Version})
+	if err != nil {
+		return nil, err
+	}
+	if err := dvs.record(ctx, model.OperationSetParent, change); err != nil {
+		return nil, err
+	}
+	return change.After, nil
+}
+
+// Checkout lends a device to an assignee until a due date when its version matches,
+// the device must not be checked out already
+func (dvs DeviceService) Checkout(ctx context.Context, deviceID primitive.ObjectID, lease model.Lease, match model.VersionMatch) (*model.Device, error) {
+	change, err := dvs.deviceDB.Checkout(ctx, deviceID, lease, match)
+	if err != nil {
+		return nil, err
+	}
+	if err := dvs.record(ctx, model.OperationCheckout, change); err != nil {
+		return nil, err
+	}
+	return change.After, nil
+}
+
+// Checkin ends the lease of a checked out device when its version matches
+func (dvs DeviceService) Checkin(ctx context.Context, deviceID primitive.ObjectID, match model.VersionMatch) (*model.Device, error) {
+	change, err := dvs.deviceDB.Checkin(ctx, deviceID, match)
+	if err != nil {
+		return nil, err
+	}
+	if err := dvs.record(ctx, model.OperationCheckin, change); err != nil {
+		return nil, err
+	}
+	return change.After, nil
+}
+
+// ExpireLeases marks the leases past their due date at a time as expired, returning how many were marked.
+// Every expired lease is recorded in the device history as a leaseExpired revision, the event of the expiry.
+func (dvs DeviceService) ExpireLeases(ctx context.Context, at time.Time) (int, error) {
+	changes, err := dvs.deviceDB.ExpireLeases(ctx, at)
+	for _, change := range changes {
+		lease := change.After.Lease
+		log.Printf("lease of device %s by %s expired, it was due at %s", change.After.ID.Hex(), lease.Assignee, lease.DueAt.Format(time.RFC3339))
+		if recordErr := dvs.record(ctx, model.OperationLeaseExpired, change); err == nil {
+			err = recordErr
+		}
+	}
+	return len(changes), err
+}
+
+// SetLabel sets the value of a label of a device when its version matches
+func (dvs DeviceService) SetLabel(ctx context.Context, deviceID primitive.ObjectID, key, value string, match model.VersionMatch) (*model.Device, error) {
+	change, err := dvs.deviceDB.SetLabel(ctx, deviceID, key, value, match)
+	if err != nil {
+		return nil, err
+	}
+	if err := dvs.record(ctx, model.OperationSetLabel, change); err != nil {
+		return nil, err
+	}
+	return change.After, nil
+}
+
+// RemoveLabel removes a label of a device when its version matches
+func (dvs DeviceService) RemoveLabel(ctx context.Context, deviceID primitive.ObjectID, key string, match model.VersionMatch) (*model.Device, error) {
+	change, err := dvs.deviceDB.RemoveLabel(ctx, deviceID, key, match)
+	if err != nil {
+		return nil, err
+	}
+	if err := dvs.record(ctx, model.OperationRemoveLabel, change); err != nil {
+		return nil, err
+	}
+	return change.After, nil
 }
 
-// UpdateName updates the name of a device
-func (dvs DeviceService) UpdateName(ctx context.Context, deviceID primitive.ObjectID, name string) error {
-	err := dvs.deviceDB.UpdateName(ctx, deviceID, name)
+// SetParent attaches a device to a parent when its version matches, a nil parent detaching it from its parent.
+// The parent must exist and must not be the device itself nor one of its descendants.
+func (dvs DeviceService) SetParent(ctx context.Context, deviceID primitive.ObjectID, parentID *primitive.ObjectID, match model.VersionMatch) (*model.Device, error) {
+	if parentID != nil {
+		if err := dvs.checkParent(ctx, deviceID, *parentID); err != nil {
+			return nil, err
+		}
+	}
+	change, err := dvs.deviceDB.SetParent(ctx, deviceID, parentID, match)
 	if err != nil {
+		return nil, err
+	}
+	if err := dvs.record(ctx, model.OperationSetParent, change); err != nil {
+		return nil, err
+	}
+	return change.After, nil
+}
+
+// checkParent checks that a device can be attached to a parent: the parent must exist, and the device must not be
+// one of the ancestors of the parent, or the parent itself, which would make a cycle.
+// A device that is not created yet has a zero id and cannot make a cycle.
+func (dvs DeviceService) checkParent(ctx context.Context, deviceID, parentID primitive.ObjectID) error {
+	if parentID == deviceID {
+		return errors.InvalidParameterError("parentId", "a device cannot be its own parent")
+	}
+	ancestors, err := dvs.deviceDB.Ancestors(ctx, parentID)
+	if err != nil {
+		if errors.KindOf(err) == errors.KindNotFound {
+			return errors.InvalidParameterError("parentId", "the device "+parentID.Hex()+" could not be found")
+		}
 		return err
 	}
+	for _, ancestor := range ancestors {
+		if ancestor.ID == deviceID {
+			return errors.InvalidParameterError("parentId", "the device "+parentID.Hex()+" is a descendant of the device")
+		}
+	}
 	return nil
 }
+
+// checkParents fails the pending items whose device cannot be attached to its parent
+func (dvs DeviceService) checkParents(ctx context.Context, items []*model.BulkItem) {
+	for _, item := range items {
+		if item.Status == model.BulkPending && item.Device.ParentID != nil {
+			if err := dvs.checkParent(ctx, item.Device.ID, *item.Device.ParentID); err != nil {
+				item.Fail(err)
+			}
+		}
+	}
+}
+
+// GetChildren gets the descendants of a device down to depth levels below it, its children first
+func (dvs DeviceService) GetChildren(ctx context.Context, deviceID primitive.ObjectID, depth int) (*dto.DevicePageDTO, error) {
+	descendants, err := dvs.deviceDB.Descendants(ctx, deviceID, depth)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToDevicePageDTO(&model.DevicePage{Devices: descendants}), nil
+}
+
+// GetAncestors gets the ancestors of a device, from its parent up to the root of its tree
+func (dvs DeviceService) GetAncestors(ctx context.Context, deviceID primitive.ObjectID) (*dto.DevicePageDTO, error) {
+	ancestors, err := dvs.deviceDB.Ancestors(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToDevicePageDTO(&model.DevicePage{Devices: ancestors}), nil
+}
+
+// Purge hard deletes the devices soft deleted before a time, returning how many were deleted
+func (dvs DeviceService) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	count, err := dvs.deviceDB.Purge(ctx, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// record appends the revision of a change to the device history
+func (dvs DeviceService) record(ctx context.Context, operation model.RevisionOperation, change *model.DeviceChange) error {
+	return dvs.append(ctx, model.NewDeviceRevision(operation, ActorFromContext(ctx), *change))
+}
+
+// append appends a revision to the device history.
+// The change is already saved, but a lost revision would make the history wrong, so the request fails: the error
+// is logged with the revision, to be appended again.
+func (dvs DeviceService) append(ctx context.Context, revision *model.DeviceRevision) error {
+	err := dvs.historyDB.Append(ctx, revision)
+	if err != nil {
+		log.Printf("could not append revision %d of device %s: %v", revision.Revision, revision.DeviceID.Hex(), err)
+	}
+	return err
+}
+
+// GetDeviceAsOf gets a device as it was at a time.
+// It is the device after its last revision at or before that time, or else the device before its first revision
+// after that time, or else the current device, as devices created before the history have no revision.
+func (dvs DeviceService) GetDeviceAsOf(ctx context.Context, deviceID primitive.ObjectID, asOf time.Time) (*model.Device, error) {
+	var dv *model.Device
+	rev, err := dvs.historyDB.LastAt(ctx, deviceID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if rev != nil {
+		dv = rev.After
+	} else {
+		rev, err = dvs.historyDB.FirstAfter(ctx, deviceID, asOf)
+		if err != nil {
+			return nil, err
+		}
+		if rev != nil {
+			dv = rev.Before
+		} else {
+			dv, err = dvs.deviceDB.ByID(ctx, deviceID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if dv == nil || dv.DeletedAt != nil || dv.CreatedAt.After(asOf) {
+		return nil, errors.CouldNotFindObject("device", deviceID.Hex())
+	}
+	return dv, nil
+}
+
+// GetHistory gets a page of the revisions of a device, the newest first
+func (dvs DeviceService) GetHistory(ctx context.Context, search model.RevisionSearch) (*dto.RevisionPageDTO, error) {
+	page, err := dvs.historyDB.List(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToRevisionPageDTO(page), nil
+}
+
+// GetRevision gets a revision of a device
+func (dvs DeviceService) GetRevision(ctx context.Context, deviceID primitive.ObjectID, revision int64) (*model.DeviceRevision, error) {
+	rev, err := dvs.historyDB.ByRevision(ctx, deviceID, revision)
+	if err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
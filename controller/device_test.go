@@ -20,12 +20,14 @@ func TestDeviceController_GetDevice(t *testing.T) {
 
 	deviceDB := new(mongoMocks.DeviceDB)
 	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
 
 	t.Run("fail with error by id", func(t *testing.T) {
 		deviceID := primitive.NewObjectID()
 		deviceDB.On("ByID", ctx, deviceID).Return(nil, errors.CouldNotFindObject("device", deviceID.Hex())).Once()
 
-		deviceController := NewDeviceService(deviceDB)
+		deviceController := NewDeviceService(deviceDB, historyDB)
 		resp, err := deviceController.GetDevice(ctx, deviceID)
 		require.Nil(t, resp)
 		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+deviceID.Hex()+" could not be found")
@@ -39,12 +41,27 @@ func TestDeviceController_GetDevice(t *testing.T) {
 		}
 
 		deviceDB.On("ByID", ctx, device.ID).Return(&device, nil).Once()
-		deviceController := NewDeviceService(deviceDB)
+		deviceController := NewDeviceService(deviceDB, historyDB)
 		resp, err := deviceController.GetDevice(ctx, device.ID)
 		require.NoError(t, err)
 		require.NotEmpty(t, resp)
 		require.Equal(t, device, *resp)
 	})
+
+	t.Run("ok - by external id", func(t *testing.T) {
+		device := model.Device{
+			ID:          primitive.NewObjectID(),
+			Name:        "Marte",
+			Brand:       "brand2",
+			ExternalIDs: []model.ExternalID{{System: "erp", Value: "E-1"}},
+		}
+
+		deviceDB.On("ByExternalID", ctx, "erp", "E-1").Return(&device, nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		resp, err := deviceController.GetDeviceByExternalID(ctx, "erp", "E-1")
+		require.NoError(t, err)
+		require.Equal(t, device, *resp)
+	})
 }
 
 func Test_DeviceController(t *testing.T) {
@@ -54,6 +71,8 @@ func Test_DeviceController(t *testing.T) {
 
 	deviceDB := new(mongoMocks.DeviceDB)
 	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
 
 	device := model.Device{
 		ID:    primitive.NewObjectID(),
@@ -64,7 +83,7 @@ func Test_DeviceController(t *testing.T) {
 	t.Run("fail on db save", func(t *testing.T) {
 		deviceDB.On("Create", mock.Anything, &device).Return(errors.CreateError("device", "unexpected error")).Once()
 
-		deviceController := NewDeviceService(deviceDB)
+		deviceController := NewDeviceService(deviceDB, historyDB)
 		err := deviceController.Create(ctx, &device)
 		require.EqualError(t, err, "result: false; code: 1500003; message: error creating device reason unexpected error")
 	})
@@ -74,96 +93,261 @@ func Test_DeviceController(t *testing.T) {
 			device := args[1].(*model.Device)
 			device.CreatedAt = time.Now().UTC().Truncate(time.Second)
 		}).Once()
+		historyDB.On("Append", mock.Anything, mock.MatchedBy(func(rev *model.DeviceRevision) bool {
+			return rev.Operation == model.OperationCreate && rev.Before == nil && rev.After.Name == "saturno"
+		})).Return(nil).Once()
 
-		deviceController := NewDeviceService(deviceDB)
+		deviceController := NewDeviceService(deviceDB, historyDB)
 		err := deviceController.Create(ctx, &device)
 		require.NoError(t, err)
 	})
 
 	t.Run("ok - update", func(t *testing.T) {
-		deviceDB.On("Update", mock.Anything, &device).Return(&device, nil).Once()
-		deviceController := NewDeviceService(deviceDB)
-		err := deviceController.Update(ctx, &device)
+		deviceDB.On("Update", mock.Anything, &device, model.VersionMatch{1}).Return(changeOf(device), nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationUpdate)).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.Update(ctx, &device, model.VersionMatch{1})
 		require.NoError(t, err)
 	})
 	t.Run("update failed", func(t *testing.T) {
-		deviceDB.On("Update", mock.Anything, &device).Return(nil, errMock).Once()
-		deviceController := NewDeviceService(deviceDB)
-		err := deviceController.Update(ctx, &device)
+		deviceDB.On("Update", mock.Anything, &device, model.VersionMatch{1}).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.Update(ctx, &device, model.VersionMatch{1})
 		require.EqualError(t, err, errMock.Error())
 	})
 
 	t.Run("ok - update name", func(t *testing.T) {
-		deviceDB.On("UpdateName", mock.Anything, device.ID, "plutao").Return(nil).Once()
-		deviceController := NewDeviceService(deviceDB)
-		err := deviceController.UpdateName(ctx, device.ID, "plutao")
+		deviceDB.On("UpdateName", mock.Anything, device.ID, "plutao", model.VersionMatch(nil)).Return(changeOf(device), nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationUpdateName)).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.UpdateName(ctx, device.ID, "plutao", nil)
 		require.NoError(t, err)
 	})
+	t.Run("update name history failed", func(t *testing.T) {
+		deviceDB.On("UpdateName", mock.Anything, device.ID, "plutao", model.VersionMatch(nil)).Return(changeOf(device), nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationUpdateName)).Return(errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.UpdateName(ctx, device.ID, "plutao", nil)
+		require.EqualError(t, err, errMock.Error())
+	})
 	t.Run("update name failed", func(t *testing.T) {
-		deviceDB.On("UpdateName", mock.Anything, device.ID, "plutao").Return(errMock).Once()
-		deviceController := NewDeviceService(deviceDB)
-		err := deviceController.UpdateName(ctx, device.ID, "plutao")
+		deviceDB.On("UpdateName", mock.Anything, device.ID, "plutao", model.VersionMatch(nil)).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.UpdateName(ctx, device.ID, "plutao", nil)
+		require.EqualError(t, err, errMock.Error())
+	})
+
+	t.Run("ok - update location", func(t *testing.T) {
+		location := &model.Location{Site: "paris", Room: "b12"}
+		deviceDB.On("UpdateLocation", mock.Anything, device.ID, location, model.VersionMatch{1}).Return(changeOf(device), nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationUpdateLocation)).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.UpdateLocation(ctx, device.ID, location, model.VersionMatch{1})
+		require.NoError(t, err)
+	})
+	t.Run("update location failed", func(t *testing.T) {
+		deviceDB.On("UpdateLocation", mock.Anything, device.ID, (*model.Location)(nil), model.VersionMatch(nil)).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.UpdateLocation(ctx, device.ID, nil, nil)
+		require.EqualError(t, err, errMock.Error())
+	})
+
+	t.Run("ok - rotate heartbeat token", func(t *testing.T) {
+		var hash string
+		deviceDB.On("SetHeartbeatToken", mock.Anything, device.ID, mock.AnythingOfType("string"), model.VersionMatch{1}).
+			Run(func(args mock.Arguments) { hash = args.String(2) }).Return(changeOf(device), nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationRotateHeartbeatToken)).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		token, err := deviceController.RotateHeartbeatToken(ctx, device.ID, model.VersionMatch{1})
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+		require.Equal(t, model.HashHeartbeatToken(token), hash)
+	})
+	t.Run("rotate heartbeat token failed", func(t *testing.T) {
+		deviceDB.On("SetHeartbeatToken", mock.Anything, device.ID, mock.AnythingOfType("string"), model.VersionMatch(nil)).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		token, err := deviceController.RotateHeartbeatToken(ctx, device.ID, nil)
+		require.EqualError(t, err, errMock.Error())
+		require.Empty(t, token)
+	})
+
+	t.Run("ok - heartbeat", func(t *testing.T) {
+		before := time.Now().UTC().Truncate(time.Second)
+		deviceDB.On("Heartbeat", mock.Anything, device.ID, model.HashHeartbeatToken("secret"), mock.MatchedBy(func(at time.Time) bool {
+			return !at.Before(before) && at.Equal(at.Truncate(time.Second))
+		})).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.Heartbeat(ctx, device.ID, "secret")
+		require.NoError(t, err)
+	})
+	t.Run("heartbeat without token", func(t *testing.T) {
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.Heartbeat(ctx, device.ID, "")
+		require.EqualError(t, err, "result: false; code: 1500024; message: the credentials are not valid for the device with id "+device.ID.Hex())
+	})
+
+	t.Run("ok - add telemetry", func(t *testing.T) {
+		samples := []model.TelemetrySample{{At: time.Now().UTC(), Metrics: map[string]float64{"temperature": 21.5}}}
+		deviceDB.On("Heartbeat", mock.Anything, device.ID, model.HashHeartbeatToken("secret"), mock.AnythingOfType("time.Time")).Return(nil).Once()
+		deviceDB.On("AddTelemetry", mock.Anything, mock.MatchedBy(func(saved []model.TelemetrySample) bool {
+			return len(saved) == 1 && saved[0].DeviceID == device.ID
+		})).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.AddTelemetry(ctx, device.ID, "secret", samples)
+		require.NoError(t, err)
+	})
+	t.Run("add telemetry with wrong token", func(t *testing.T) {
+		unauthorized := errors.UnauthorizedError("device", device.ID.Hex())
+		deviceDB.On("Heartbeat", mock.Anything, device.ID, model.HashHeartbeatToken("guess"), mock.AnythingOfType("time.Time")).Return(unauthorized).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.AddTelemetry(ctx, device.ID, "guess", []model.TelemetrySample{{}})
+		require.Equal(t, unauthorized, err)
+	})
+
+	t.Run("ok - get telemetry", func(t *testing.T) {
+		at := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+		query := model.TelemetryQuery{DeviceID: device.ID, From: at, To: at.Add(time.Hour)}
+		deviceDB.On("ByID", mock.Anything, device.ID).Return(&device, nil).Once()
+		deviceDB.On("TelemetrySeries", mock.Anything, query).Return(&model.TelemetrySeries{
+			Points: []model.TelemetryPoint{{At: at, Metrics: map[string]float64{"battery": 80}}},
+		}, nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		series, err := deviceController.GetTelemetry(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, &dto.TelemetrySeriesDTO{Items: []dto.TelemetrySampleDTO{{At: &at, Metrics: map[string]float64{"battery": 80}}}}, series)
+	})
+	t.Run("get telemetry of unknown device", func(t *testing.T) {
+		deviceDB.On("ByID", mock.Anything, device.ID).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		series, err := deviceController.GetTelemetry(ctx, model.TelemetryQuery{DeviceID: device.ID})
 		require.EqualError(t, err, errMock.Error())
+		require.Nil(t, series)
 	})
 
 	t.Run("ok - update brand", func(t *testing.T) {
-		deviceDB.On("UpdateBrand", mock.Anything, device.ID, model.Brand("brand1")).Return(nil).Once()
-		deviceController := NewDeviceService(deviceDB)
-		err := deviceController.UpdateBrand(ctx, device.ID, model.Brand("brand1"))
+		deviceDB.On("Patch", mock.Anything, device.ID, model.VersionMatch(nil), mock.Anything).Return(changeOf(device), nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationUpdateBrand)).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.UpdateBrand(ctx, device.ID, model.Brand("brand1"), nil)
 		require.NoError(t, err)
 	})
 	t.Run("update brand failed", func(t *testing.T) {
-		deviceDB.On("UpdateBrand", mock.Anything, device.ID, model.Brand("brand1")).Return(errMock).Once()
-		deviceController := NewDeviceService(deviceDB)
-		err := deviceController.UpdateBrand(ctx, device.ID, model.Brand("brand1"))
+		deviceDB.On("Patch", mock.Anything, device.ID, model.VersionMatch(nil), mock.Anything).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.UpdateBrand(ctx, device.ID, model.Brand("brand1"), nil)
+		require.EqualError(t, err, errMock.Error())
+	})
+	t.Run("update brand rejects the attributes the brand schema does not accept", func(t *testing.T) {
+		schema, err := model.ParseAttributesSchema([]byte(`{"type": "object", "properties": {"ram": {"type": "integer"}}}`))
+		require.NoError(t, err)
+		model.SetBrandRegistry(schemaRegistry{"brand2": schema})
+		defer model.SetBrandRegistry(nil)
+
+		withAttributes := device
+		withAttributes.Attributes = model.Attributes{"ram": "16GB"}
+		deviceDB.On("Patch", mock.Anything, device.ID, model.VersionMatch(nil), mock.Anything).Return(nil, errMock).Once().Run(func(args mock.Arguments) {
+			apply := args.Get(3).(func(*model.Device) error)
+			require.EqualError(t, apply(&withAttributes), "result: false; code: 1500002; message: parameter 'attributes.ram' is invalid 'expected integer'")
+			require.Equal(t, model.Brand("brand2"), withAttributes.Brand)
+		})
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err = deviceController.UpdateBrand(ctx, device.ID, model.Brand("brand2"), nil)
+		require.EqualError(t, err, errMock.Error())
+
+		err = deviceController.UpdateBrand(ctx, device.ID, model.Brand("brand3"), nil)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'brand' is invalid 'invalid value'")
+	})
+
+	t.Run("ok - patch", func(t *testing.T) {
+		patched := device
+		patched.Version = 2
+		deviceDB.On("Patch", mock.Anything, device.ID, model.VersionMatch{1}, mock.Anything).Return(&model.DeviceChange{Before: &device, After: &patched}, nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationPatch)).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		dv, err := deviceController.Patch(ctx, device.ID, model.VersionMatch{1}, func(dv *model.Device) error { return nil })
+		require.NoError(t, err)
+		require.Equal(t, &patched, dv)
+	})
+	t.Run("patch failed", func(t *testing.T) {
+		deviceDB.On("Patch", mock.Anything, device.ID, model.VersionMatch(nil), mock.Anything).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		_, err := deviceController.Patch(ctx, device.ID, nil, func(dv *model.Device) error { return nil })
 		require.EqualError(t, err, errMock.Error())
 	})
 
 	t.Run("ok - list devices", func(t *testing.T) {
-		deviceDB.On("List", mock.Anything).Return([]model.Device{{
-			ID:    device.ID,
-			Name:  "venus",
-			Brand: model.Brand("brand2"),
-		}}, nil).Once()
-		deviceController := NewDeviceService(deviceDB)
-		devices, err := deviceController.GetDevices(ctx)
-		require.NoError(t, err)
-		require.Len(t, devices, 1)
-		require.Equal(t, devices[0].Name, "venus")
-		require.Equal(t, devices[0].Brand, model.Brand("brand2"))
+		search := model.DeviceSearch{Limit: 1, Sort: model.SortCreatedAtAsc}
+		total := int64(2)
+		deviceDB.On("List", mock.Anything, search).Return(&model.DevicePage{
+			Devices: []model.Device{{
+				ID:    device.ID,
+				Name:  "venus",
+				Brand: model.Brand("brand2"),
+			}},
+			NextCursor: "next",
+			Total:      &total,
+		}, nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		page, err := deviceController.GetDevices(ctx, search)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		require.Equal(t, page.Items[0].Name, "venus")
+		require.Equal(t, page.Items[0].Brand, model.Brand("brand2"))
+		require.Equal(t, "next", page.NextCursor)
+		require.Equal(t, &total, page.Total)
 	})
 	t.Run("failed listing devices", func(t *testing.T) {
-		deviceDB.On("List", mock.Anything).Return([]model.Device(nil), errMock).Once()
-		deviceController := NewDeviceService(deviceDB)
-		devices, err := deviceController.GetDevices(ctx)
+		deviceDB.On("List", mock.Anything, model.DeviceSearch{}).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		page, err := deviceController.GetDevices(ctx, model.DeviceSearch{})
 		require.EqualError(t, err, errMock.Error())
-		require.Equal(t, []dto.DeviceDTO(nil), devices)
+		require.Equal(t, (*dto.DevicePageDTO)(nil), page)
 	})
 
 	t.Run("ok - list devices by brand", func(t *testing.T) {
-		deviceDB.On("ListByBrand", ctx, device.Brand).Return([]model.Device{{
-			ID:    device.ID,
-			Name:  "saturno",
-			Brand: model.Brand("brand1"),
-		}}, nil).Once()
-		deviceController := NewDeviceService(deviceDB)
-		devices, err := deviceController.GetDevicesByBrand(ctx, device.Brand)
+		search := model.DeviceSearch{DeviceFilter: model.DeviceFilter{Brands: []model.Brand{device.Brand}}}
+		deviceDB.On("List", ctx, search).Return(&model.DevicePage{
+			Devices: []model.Device{{
+				ID:    device.ID,
+				Name:  "saturno",
+				Brand: model.Brand("brand1"),
+			}},
+		}, nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		page, err := deviceController.GetDevices(ctx, search)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		require.Equal(t, page.Items[0].Name, "saturno")
+		require.Equal(t, page.Items[0].Brand, model.Brand("brand1"))
+		require.Empty(t, page.NextCursor)
+		require.Nil(t, page.Total)
+	})
+
+	t.Run("ok - export devices", func(t *testing.T) {
+		search := model.DeviceSearch{DeviceFilter: model.DeviceFilter{Brands: []model.Brand{device.Brand}}}
+		deviceDB.On("Stream", ctx, search, mock.Anything).Return(func(_ context.Context, _ model.DeviceSearch, fn func(dv *model.Device) error) error {
+			return fn(&device)
+		}).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		var exported []*model.Device
+		err := deviceController.Export(ctx, search, func(dv *model.Device) error {
+			exported = append(exported, dv)
+			return nil
+		})
 		require.NoError(t, err)
-		require.Len(t, devices, 1)
-		require.Equal(t, devices[0].Name, "saturno")
-		require.Equal(t, devices[0].Brand, model.Brand("brand1"))
+		require.Equal(t, []*model.Device{&device}, exported)
 	})
-	t.Run("failed listing devices by brand", func(t *testing.T) {
-		deviceDB.On("ListByBrand", ctx, device.Brand).Return([]model.Device(nil), errMock).Once()
-		deviceController := NewDeviceService(deviceDB)
-		devices, err := deviceController.GetDevicesByBrand(ctx, device.Brand)
+
+	t.Run("failed exporting devices", func(t *testing.T) {
+		deviceDB.On("Stream", ctx, model.DeviceSearch{}, mock.Anything).Return(errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.Export(ctx, model.DeviceSearch{}, func(dv *model.Device) error { return nil })
 		require.EqualError(t, err, errMock.Error())
-		require.Equal(t, []dto.DeviceDTO(nil), devices)
 	})
 
 	t.Run("ok - get device by id", func(t *testing.T) {
 		deviceDB.On("ByID", mock.Anything, device.ID).Return(&device, nil).Once()
-		deviceController := NewDeviceService(deviceDB)
+		deviceController := NewDeviceService(deviceDB, historyDB)
 		device, err := deviceController.GetDevice(ctx, device.ID)
 		require.NoError(t, err)
 		require.NotNil(t, device)
@@ -172,22 +356,587 @@ func Test_DeviceController(t *testing.T) {
 	})
 	t.Run("failed getting device by id", func(t *testing.T) {
 		deviceDB.On("ByID", mock.Anything, device.ID).Return((*model.Device)(nil), errMock).Once()
-		deviceController := NewDeviceService(deviceDB)
+		deviceController := NewDeviceService(deviceDB, historyDB)
 		device, err := deviceController.GetDevice(ctx, device.ID)
 		require.EqualError(t, err, errMock.Error())
 		require.Equal(t, (*model.Device)(nil), device)
 	})
 
 	t.Run("ok - delete", func(t *testing.T) {
-		deviceDB.On("Delete", mock.Anything, device.ID).Return(nil).Once()
-		deviceController := NewDeviceService(deviceDB)
-		err := deviceController.Delete(ctx, device.ID)
+		deviceDB.On("Delete", mock.Anything, device.ID, model.VersionMatch{2}).Return(changeOf(device), nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationDelete)).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.Delete(ctx, device.ID, model.VersionMatch{2}, model.DeleteDeny)
 		require.NoError(t, err)
 	})
 	t.Run("delete failed", func(t *testing.T) {
-		deviceDB.On("Delete", mock.Anything, device.ID).Return(errMock).Once()
-		deviceController := NewDeviceService(deviceDB)
-		err := deviceController.Delete(ctx, device.ID)
+		deviceDB.On("Delete", mock.Anything, device.ID, model.VersionMatch{2}).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		err := deviceController.Delete(ctx, device.ID, model.VersionMatch{2}, model.DeleteDeny)
 		require.EqualError(t, err, errMock.Error())
 	})
+
+	t.Run("ok - restore", func(t *testing.T) {
+		restored := device
+		restored.Version = 3
+		deviceDB.On("Restore", mock.Anything, device.ID, model.VersionMatch{2}).Return(&model.DeviceChange{Before: &device, After: &restored}, nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationRestore)).Return(nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		dv, err := deviceController.Restore(ctx, device.ID, model.VersionMatch{2})
+		require.NoError(t, err)
+		require.Equal(t, &restored, dv)
+	})
+	t.Run("restore failed", func(t *testing.T) {
+		deviceDB.On("Restore", mock.Anything, device.ID, model.VersionMatch(nil)).Return(nil, errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		_, err := deviceController.Restore(ctx, device.ID, nil)
+		require.EqualError(t, err, errMock.Error())
+	})
+
+	t.Run("ok - purge", func(t *testing.T) {
+		deletedBefore := time.Now().UTC()
+		deviceDB.On("Purge", mock.Anything, deletedBefore).Return(int64(2), nil).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		count, err := deviceController.Purge(ctx, deletedBefore)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+	})
+	t.Run("purge failed", func(t *testing.T) {
+		deletedBefore := time.Now().UTC()
+		deviceDB.On("Purge", mock.Anything, deletedBefore).Return(int64(0), errMock).Once()
+		deviceController := NewDeviceService(deviceDB, historyDB)
+		_, err := deviceController.Purge(ctx, deletedBefore)
+		require.EqualError(t, err, errMock.Error())
+	})
+}
+
+// changeOf returns the change of a device to its next version
+func changeOf(device model.Device) *model.DeviceChange {
+	after := device
+	after.Version++
+	return &model.DeviceChange{Before: &device, After: &after}
+}
+
+// revisionOf matches the revision of an operation
+func revisionOf(operation model.RevisionOperation) interface{} {
+	return mock.MatchedBy(func(rev *model.DeviceRevision) bool {
+		return rev.Operation == operation && rev.Revision == rev.After.Version
+	})
+}
+
+func TestDeviceController_History(t *testing.T) {
+	ctx := WithActor(context.Background(), "alice")
+	errMock := fmt.Errorf("errMock")
+
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
+	deviceController := NewDeviceService(deviceDB, historyDB)
+
+	createdAt := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.AddDate(0, 0, 1)
+	before := model.Device{ID: primitive.NewObjectID(), Name: "io", Brand: "brand1", CreatedAt: createdAt, Version: 1}
+	after := before
+	after.Name = "europa"
+	after.UpdatedAt = &updatedAt
+	after.Version = 2
+	revision := model.NewDeviceRevision(model.OperationUpdateName, "alice", model.DeviceChange{Before: &before, After: &after})
+
+	t.Run("revisions record the actor", func(t *testing.T) {
+		deviceDB.On("UpdateName", mock.Anything, before.ID, "europa", model.VersionMatch(nil)).Return(&model.DeviceChange{Before: &before, After: &after}, nil).Once()
+		historyDB.On("Append", mock.Anything, revision).Return(nil).Once()
+		err := deviceController.UpdateName(ctx, before.ID, "europa", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("as of a time after a revision", func(t *testing.T) {
+		asOf := updatedAt.Add(time.Hour)
+		historyDB.On("LastAt", mock.Anything, before.ID, asOf).Return(revision, nil).Once()
+		dv, err := deviceController.GetDeviceAsOf(ctx, before.ID, asOf)
+		require.NoError(t, err)
+		require.Equal(t, &after, dv)
+	})
+
+	t.Run("as of a time before the first revision", func(t *testing.T) {
+		asOf := createdAt.Add(time.Hour)
+		historyDB.On("LastAt", mock.Anything, before.ID, asOf).Return(nil, nil).Once()
+		historyDB.On("FirstAfter", mock.Anything, before.ID, asOf).Return(revision, nil).Once()
+		dv, err := deviceController.GetDeviceAsOf(ctx, before.ID, asOf)
+		require.NoError(t, err)
+		require.Equal(t, &before, dv)
+	})
+
+	t.Run("as of a time without revisions", func(t *testing.T) {
+		asOf := createdAt.Add(time.Hour)
+		historyDB.On("LastAt", mock.Anything, before.ID, asOf).Return(nil, nil).Once()
+		historyDB.On("FirstAfter", mock.Anything, before.ID, asOf).Return(nil, nil).Once()
+		deviceDB.On("ByID", mock.Anything, before.ID).Return(&before, nil).Once()
+		dv, err := deviceController.GetDeviceAsOf(ctx, before.ID, asOf)
+		require.NoError(t, err)
+		require.Equal(t, &before, dv)
+	})
+
+	t.Run("as of a time before the creation", func(t *testing.T) {
+		asOf := createdAt.Add(-time.Hour)
+		created := model.NewDeviceRevision(model.OperationCreate, "", model.DeviceChange{After: &before})
+		historyDB.On("LastAt", mock.Anything, before.ID, asOf).Return(nil, nil).Once()
+		historyDB.On("FirstAfter", mock.Anything, before.ID, asOf).Return(created, nil).Once()
+		_, err := deviceController.GetDeviceAsOf(ctx, before.ID, asOf)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+before.ID.Hex()+" could not be found")
+	})
+
+	t.Run("as of a time after the deletion", func(t *testing.T) {
+		asOf := updatedAt.Add(time.Hour)
+		deleted := after
+		deleted.DeletedAt = &updatedAt
+		historyDB.On("LastAt", mock.Anything, before.ID, asOf).Return(model.NewDeviceRevision(model.OperationDelete, "", model.DeviceChange{Before: &after, After: &deleted}), nil).Once()
+		_, err := deviceController.GetDeviceAsOf(ctx, before.ID, asOf)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+before.ID.Hex()+" could not be found")
+	})
+
+	t.Run("as of failed", func(t *testing.T) {
+		historyDB.On("LastAt", mock.Anything, before.ID, createdAt).Return(nil, errMock).Once()
+		_, err := deviceController.GetDeviceAsOf(ctx, before.ID, createdAt)
+		require.EqualError(t, err, errMock.Error())
+	})
+
+	t.Run("ok - history", func(t *testing.T) {
+		search := model.RevisionSearch{DeviceID: before.ID, Limit: 1}
+		historyDB.On("List", mock.Anything, search).Return(&model.RevisionPage{Revisions: []model.DeviceRevision{*revision}, NextCursor: "2"}, nil).Once()
+		page, err := deviceController.GetHistory(ctx, search)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		require.Equal(t, int64(2), page.Items[0].Revision)
+		require.Equal(t, "2", page.NextCursor)
+	})
+	t.Run("history failed", func(t *testing.T) {
+		historyDB.On("List", mock.Anything, model.RevisionSearch{DeviceID: before.ID}).Return(nil, errMock).Once()
+		_, err := deviceController.GetHistory(ctx, model.RevisionSearch{DeviceID: before.ID})
+		require.EqualError(t, err, errMock.Error())
+	})
+
+	t.Run("ok - revision", func(t *testing.T) {
+		historyDB.On("ByRevision", mock.Anything, before.ID, int64(2)).Return(revision, nil).Once()
+		rev, err := deviceController.GetRevision(ctx, before.ID, 2)
+		require.NoError(t, err)
+		require.Equal(t, revision, rev)
+	})
+	t.Run("revision failed", func(t *testing.T) {
+		historyDB.On("ByRevision", mock.Anything, before.ID, int64(3)).Return(nil, errMock).Once()
+		_, err := deviceController.GetRevision(ctx, before.ID, 3)
+		require.EqualError(t, err, errMock.Error())
+	})
+}
+
+func TestDeviceController_Transition(t *testing.T) {
+	ctx := WithActor(context.Background(), "alice")
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
+	deviceController := NewDeviceService(deviceDB, historyDB)
+
+	// patch applies the transition to a device in a state, as the database does
+	patch := func(state model.DeviceState) func(context.Context, primitive.ObjectID, model.VersionMatch, func(*model.Device) error) (*model.DeviceChange, error) {
+		return func(_ context.Context, id primitive.ObjectID, _ model.VersionMatch, apply func(*model.Device) error) (*model.DeviceChange, error) {
+			before := model.Device{ID: id, Name: "io", Brand: "brand1", State: state, Version: 1}
+			after := before
+			if err := apply(&after); err != nil {
+				return nil, err
+			}
+			after.Version++
+			return &model.DeviceChange{Before: &before, After: &after}, nil
+		}
+	}
+
+	t.Run("transitions", func(t *testing.T) {
+		require.True(t, canTransition(model.StateOrdered, model.StateInStock))
+		require.True(t, canTransition(model.StateInStock, model.StateInUse))
+		require.True(t, canTransition(model.StateInUse, model.StateInRepair))
+		require.True(t, canTransition(model.StateInRepair, model.StateRetired))
+		require.False(t, canTransition(model.StateInUse, model.StateRetired))
+		require.False(t, canTransition(model.StateInStock, model.StateInStock))
+		require.False(t, canTransition(model.StateRetired, model.StateInStock))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		deviceDB.On("Patch", mock.Anything, id, model.VersionMatch{1}, mock.Anything).Return(patch(model.StateInStock), nil).Once()
+		historyDB.On("Append", mock.Anything, mock.MatchedBy(func(rev *model.DeviceRevision) bool {
+			return rev.Operation == model.OperationTransition && rev.Reason == "assigned to bob" && rev.Actor == "alice" &&
+				rev.Before.State == model.StateInStock && rev.After.State == model.StateInUse
+		})).Return(nil).Once()
+
+		dv, err := deviceController.Transition(ctx, id, model.StateInUse, "assigned to bob", model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Equal(t, model.StateInUse, dv.State)
+		require.Equal(t, int64(2), dv.Version)
+	})
+
+	t.Run("illegal transition", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		deviceDB.On("Patch", mock.Anything, id, model.VersionMatch(nil), mock.Anything).Return(patch(model.StateInUse), nil).Once()
+
+		_, err := deviceController.Transition(ctx, id, model.StateRetired, "lost", nil)
+		require.EqualError(t, err, "result: false; code: 1500022; message: the device with id "+id.Hex()+" cannot move from inUse to retired")
+	})
+
+	t.Run("devices created before states are in stock", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		deviceDB.On("Patch", mock.Anything, id, model.VersionMatch(nil), mock.Anything).Return(patch(""), nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationTransition)).Return(nil).Once()
+
+		dv, err := deviceController.Transition(ctx, id, model.StateRetired, "obsolete", nil)
+		require.NoError(t, err)
+		require.Equal(t, model.StateRetired, dv.State)
+	})
+}
+
+func TestDeviceController_Labels(t *testing.T) {
+	ctx := WithActor(context.Background(), "alice")
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
+	deviceController := NewDeviceService(deviceDB, historyDB)
+
+	id := primitive.NewObjectID()
+	unlabelled := model.Device{ID: id, Name: "io", Brand: "brand1", Version: 1}
+	labelled := model.Device{ID: id, Name: "io", Brand: "brand1", Labels: map[string]string{"env": "prod"}, Version: 2}
+
+	t.Run("set label", func(t *testing.T) {
+		deviceDB.On("SetLabel", mock.Anything, id, "env", "prod", model.VersionMatch{1}).
+			Return(&model.DeviceChange{Before: &unlabelled, After: &labelled}, nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationSetLabel)).Return(nil).Once()
+
+		dv, err := deviceController.SetLabel(ctx, id, "env", "prod", model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"env": "prod"}, dv.Labels)
+	})
+
+	t.Run("remove label", func(t *testing.T) {
+		removed := unlabelled
+		removed.Version = 3
+		deviceDB.On("RemoveLabel", mock.Anything, id, "env", model.VersionMatch(nil)).
+			Return(&model.DeviceChange{Before: &labelled, After: &removed}, nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationRemoveLabel)).Return(nil).Once()
+
+		dv, err := deviceController.RemoveLabel(ctx, id, "env", nil)
+		require.NoError(t, err)
+		require.Nil(t, dv.Labels)
+	})
+
+	t.Run("fail remove label", func(t *testing.T) {
+		deviceDB.On("RemoveLabel", mock.Anything, id, "rack", model.VersionMatch(nil)).Return(nil, fmt.Errorf("errMock")).Once()
+
+		_, err := deviceController.RemoveLabel(ctx, id, "rack", nil)
+		require.EqualError(t, err, "errMock")
+	})
+}
+
+func TestDeviceController_Hierarchy(t *testing.T) {
+	ctx := context.Background()
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
+	deviceController := NewDeviceService(deviceDB, historyDB)
+
+	rack := model.Device{ID: primitive.NewObjectID(), Name: "rack", Brand: "brand1", Version: 1}
+	chassis := model.Device{ID: primitive.NewObjectID(), Name: "chassis", Brand: "brand1", ParentID: &rack.ID, Version: 1}
+	module := model.Device{ID: primitive.NewObjectID(), Name: "module", Brand: "brand1", ParentID: &chassis.ID, Version: 1}
+
+	t.Run("create with parent", func(t *testing.T) {
+		dv := &model.Device{Name: "module", Brand: "brand1", ParentID: &chassis.ID}
+		deviceDB.On("Ancestors", mock.Anything, chassis.ID).Return([]model.Device{rack}, nil).Once()
+		deviceDB.On("Create", mock.Anything, dv).Return(nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationCreate)).Return(nil).Once()
+
+		require.NoError(t, deviceController.Create(ctx, dv))
+	})
+
+	t.Run("fail create with unknown parent", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		deviceDB.On("Ancestors", mock.Anything, id).Return(nil, errors.CouldNotFindObject("device", id.Hex())).Once()
+
+		err := deviceController.Create(ctx, &model.Device{Name: "module", Brand: "brand1", ParentID: &id})
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'parentId' is invalid 'the device "+id.Hex()+" could not be found'")
+	})
+
+	t.Run("set parent", func(t *testing.T) {
+		moved := module
+		moved.ParentID = &rack.ID
+		moved.Version = 2
+		deviceDB.On("Ancestors", mock.Anything, rack.ID).Return([]model.Device{}, nil).Once()
+		deviceDB.On("SetParent", mock.Anything, module.ID, &rack.ID, model.VersionMatch{1}).
+			Return(&model.DeviceChange{Before: &module, After: &moved}, nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationSetParent)).Return(nil).Once()
+
+		dv, err := deviceController.SetParent(ctx, module.ID, &rack.ID, model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Equal(t, &rack.ID, dv.ParentID)
+	})
+
+	t.Run("fail set parent making a cycle", func(t *testing.T) {
+		_, err := deviceController.SetParent(ctx, rack.ID, &rack.ID, nil)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'parentId' is invalid 'a device cannot be its own parent'")
+
+		deviceDB.On("Ancestors", mock.Anything, module.ID).Return([]model.Device{chassis, rack}, nil).Once()
+		_, err = deviceController.SetParent(ctx, rack.ID, &module.ID, nil)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'parentId' is invalid 'the device "+module.ID.Hex()+" is a descendant of the device'")
+	})
+
+	t.Run("children and ancestors", func(t *testing.T) {
+		deviceDB.On("Descendants", mock.Anything, rack.ID, 2).Return([]model.Device{chassis, module}, nil).Once()
+		page, err := deviceController.GetChildren(ctx, rack.ID, 2)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 2)
+		require.Equal(t, rack.ID.Hex(), page.Items[0].ParentID)
+
+		deviceDB.On("Ancestors", mock.Anything, module.ID).Return([]model.Device{chassis, rack}, nil).Once()
+		page, err = deviceController.GetAncestors(ctx, module.ID)
+		require.NoError(t, err)
+		require.Equal(t, "chassis", page.Items[0].Name)
+		require.Equal(t, "rack", page.Items[1].Name)
+	})
+
+	t.Run("fail delete with children", func(t *testing.T) {
+		deviceDB.On("Delete", mock.Anything, rack.ID, model.VersionMatch(nil)).Return(nil, errors.DeviceHasChildrenError(rack.ID.Hex(), 1)).Once()
+
+		err := deviceController.Delete(ctx, rack.ID, nil, model.DeleteDeny)
+		require.EqualError(t, err, "result: false; code: 1500023; message: the device with id "+rack.ID.Hex()+" has 1 child device(s)")
+	})
+
+	t.Run("fail cascade delete with a descendant in use", func(t *testing.T) {
+		inUse := module
+		inUse.State = model.StateInUse
+		deviceDB.On("Descendants", mock.Anything, rack.ID, 0).Return([]model.Device{chassis, inUse}, nil).Once()
+		deviceDB.On("ByID", mock.Anything, rack.ID).Return(&rack, nil).Once()
+
+		err := deviceController.Delete(ctx, rack.ID, nil, model.DeleteCascade)
+		require.EqualError(t, err, "result: false; code: 1500019; message: the device with id "+module.ID.Hex()+" is inUse")
+	})
+
+	t.Run("fail cascade delete with another version", func(t *testing.T) {
+		deviceDB.On("Descendants", mock.Anything, rack.ID, 0).Return([]model.Device{chassis, module}, nil).Once()
+		deviceDB.On("ByID", mock.Anything, rack.ID).Return(&rack, nil).Once()
+
+		err := deviceController.Delete(ctx, rack.ID, model.VersionMatch{2}, model.DeleteCascade)
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+rack.ID.Hex()+" does not have the expected version")
+	})
+
+	t.Run("cascade delete", func(t *testing.T) {
+		var deleted []string
+		deleting := func(args mock.Arguments) {
+			deleted = append(deleted, args.Get(1).(primitive.ObjectID).Hex())
+		}
+		deviceDB.On("Descendants", mock.Anything, rack.ID, 0).Return([]model.Device{chassis, module}, nil).Once()
+		deviceDB.On("ByID", mock.Anything, rack.ID).Return(&rack, nil).Once()
+		deviceDB.On("Delete", mock.Anything, rack.ID, model.VersionMatch{1}).Return(changeOf(rack), nil).Once().Run(deleting)
+		deviceDB.On("Delete", mock.Anything, chassis.ID, model.VersionMatch(nil)).Return(changeOf(chassis), nil).Once().Run(deleting)
+		deviceDB.On("Delete", mock.Anything, module.ID, model.VersionMatch(nil)).Return(changeOf(module), nil).Once().Run(deleting)
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationDelete)).Return(nil).Times(3)
+
+		require.NoError(t, deviceController.Delete(ctx, rack.ID, model.VersionMatch{1}, model.DeleteCascade))
+		require.Equal(t, []string{module.ID.Hex(), chassis.ID.Hex(), rack.ID.Hex()}, deleted)
+	})
+
+	t.Run("fail cascade delete part way", func(t *testing.T) {
+		deviceDB.On("Descendants", mock.Anything, rack.ID, 0).Return([]model.Device{chassis, module}, nil).Once()
+		deviceDB.On("ByID", mock.Anything, rack.ID).Return(&rack, nil).Once()
+		deviceDB.On("Delete", mock.Anything, module.ID, model.VersionMatch(nil)).Return(changeOf(module), nil).Once()
+		deviceDB.On("Delete", mock.Anything, chassis.ID, model.VersionMatch(nil)).Return(nil, errors.DeviceHasChildrenError(chassis.ID.Hex(), 1)).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationDelete)).Return(nil).Once()
+
+		err := deviceController.Delete(ctx, rack.ID, nil, model.DeleteCascade)
+		require.EqualError(t, err, "result: false; code: 1500025; message: the device with id "+rack.ID.Hex()+" was not deleted, 1 of its 2 descendant(s) were: "+
+			"the device with id "+chassis.ID.Hex()+" has 1 child device(s)")
+
+		deviceDB.On("Descendants", mock.Anything, rack.ID, 0).Return([]model.Device{chassis, module}, nil).Once()
+		deviceDB.On("ByID", mock.Anything, rack.ID).Return(&rack, nil).Once()
+		deviceDB.On("Delete", mock.Anything, module.ID, model.VersionMatch(nil)).Return(changeOf(module), nil).Once()
+		deviceDB.On("Delete", mock.Anything, chassis.ID, model.VersionMatch(nil)).Return(changeOf(chassis), nil).Once()
+		deviceDB.On("Delete", mock.Anything, rack.ID, model.VersionMatch(nil)).Return(nil, errors.DeviceHasChildrenError(rack.ID.Hex(), 1)).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationDelete)).Return(nil).Twice()
+
+		err = deviceController.Delete(ctx, rack.ID, nil, model.DeleteCascade)
+		require.EqualError(t, err, "result: false; code: 1500025; message: the device with id "+rack.ID.Hex()+" was not deleted, 2 of its 2 descendant(s) were: "+
+			"the device with id "+rack.ID.Hex()+" has 1 child device(s)")
+	})
+
+	t.Run("restore detaches from a deleted parent", func(t *testing.T) {
+		restored := chassis
+		restored.Version = 3
+		detached := restored
+		detached.ParentID = nil
+		detached.Version = 4
+		deviceDB.On("Restore", mock.Anything, chassis.ID, model.VersionMatch(nil)).Return(&model.DeviceChange{After: &restored}, nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationRestore)).Return(nil).Once()
+		deviceDB.On("ByID", mock.Anything, rack.ID).Return(nil, errors.CouldNotFindObject("device", rack.ID.Hex())).Once()
+		deviceDB.On("SetParent", mock.Anything, chassis.ID, (*primitive.ObjectID)(nil), model.VersionMatch{3}).
+			Return(&model.DeviceChange{Before: &restored, After: &detached}, nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationSetParent)).Return(nil).Once()
+
+		dv, err := deviceController.Restore(ctx, chassis.ID, nil)
+		require.NoError(t, err)
+		require.Nil(t, dv.ParentID)
+	})
+}
+
+func TestDeviceController_CreateMany(t *testing.T) {
+	ctx := context.Background()
+	invalid := errors.RequiredParameterError("brand", "body")
+
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
+	deviceController := NewDeviceService(deviceDB, historyDB)
+
+	newItems := func() []*model.BulkItem {
+		items := []*model.BulkItem{
+			{Device: &model.Device{Name: "io", Brand: "brand1"}},
+			{Device: &model.Device{Name: "europa"}},
+			{Device: &model.Device{Name: "ganymede", Brand: "brand2"}},
+		}
+		items[1].Fail(invalid)
+		return items
+	}
+	create := func(args mock.Arguments) {
+		for _, item := range args[1].([]*model.BulkItem) {
+			item.Device.ID = primitive.NewObjectID()
+			item.Device.Version = 1
+			item.Status = model.BulkCreated
+		}
+	}
+
+	t.Run("ordered creation skips the items after a failure", func(t *testing.T) {
+		items := newItems()
+		deviceDB.On("CreateMany", mock.Anything, items[:1], true).Run(create).Once()
+		historyDB.On("Append", mock.Anything, mock.Anything).Return(nil).Once()
+
+		deviceController.CreateMany(ctx, items, true)
+		require.Equal(t, model.BulkCreated, items[0].Status)
+		require.Equal(t, model.BulkFailed, items[1].Status)
+		require.Equal(t, invalid, items[1].Err)
+		require.Equal(t, model.BulkSkipped, items[2].Status)
+	})
+
+	t.Run("unordered creation creates the valid items", func(t *testing.T) {
+		items := newItems()
+		deviceDB.On("CreateMany", mock.Anything, []*model.BulkItem{items[0], items[2]}, false).Run(create).Once()
+		historyDB.On("Append", mock.Anything, mock.Anything).Return(nil).Twice()
+
+		deviceController.CreateMany(ctx, items, false)
+		require.Equal(t, model.BulkCreated, items[0].Status)
+		require.Equal(t, model.BulkFailed, items[1].Status)
+		require.Equal(t, model.BulkCreated, items[2].Status)
+	})
+
+	t.Run("failed devices have no revision", func(t *testing.T) {
+		items := newItems()[:1]
+		deviceDB.On("CreateMany", mock.Anything, items, true).Run(func(args mock.Arguments) {
+			items[0].Fail(errors.CreateError("device", "errMock"))
+		}).Once()
+
+		deviceController.CreateMany(ctx, items, true)
+		require.Equal(t, model.BulkFailed, items[0].Status)
+	})
+}
+
+func TestDeviceController_Import(t *testing.T) {
+	ctx := context.Background()
+	invalid := errors.RequiredParameterError("brand", "body")
+	errMock := fmt.Errorf("errMock")
+
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
+	deviceController := NewDeviceService(deviceDB, historyDB)
+
+	newItems := func() []*model.BulkItem {
+		return []*model.BulkItem{
+			{Device: &model.Device{Name: "io", Brand: "brand1"}},
+			{Device: &model.Device{Name: "europa", Brand: "brand2"}},
+		}
+	}
+	create := func(args mock.Arguments) {
+		for _, item := range args[1].([]*model.BulkItem) {
+			item.Device.ID = primitive.NewObjectID()
+			item.Status = model.BulkCreated
+		}
+	}
+
+	t.Run("transactional import creates all the devices", func(t *testing.T) {
+		items := newItems()
+		deviceDB.On("CreateMany", mock.Anything, items, true).Run(create).Once()
+		historyDB.On("Append", mock.Anything, mock.Anything).Return(nil).Twice()
+
+		require.NoError(t, deviceController.Import(ctx, items, true))
+		require.Equal(t, model.BulkCreated, items[0].Status)
+		require.Equal(t, model.BulkCreated, items[1].Status)
+	})
+
+	t.Run("transactional import with an invalid row creates nothing", func(t *testing.T) {
+		items := newItems()
+		items[1].Fail(invalid)
+
+		require.NoError(t, deviceController.Import(ctx, items, true))
+		require.Equal(t, model.BulkSkipped, items[0].Status)
+		require.Equal(t, model.BulkFailed, items[1].Status)
+	})
+
+	t.Run("transactional import removes the devices created before a failure", func(t *testing.T) {
+		items := newItems()
+		deviceDB.On("CreateMany", mock.Anything, items, true).Run(func(args mock.Arguments) {
+			items[0].Device.ID = primitive.NewObjectID()
+			items[0].Status = model.BulkCreated
+			items[1].Fail(errors.CreateError("device", "errMock"))
+		}).Once()
+		deviceDB.On("Remove", mock.Anything, mock.Anything).Return(func(_ context.Context, ids []primitive.ObjectID) error {
+			require.Equal(t, []primitive.ObjectID{items[0].Device.ID}, ids)
+			return nil
+		}).Once()
+
+		require.NoError(t, deviceController.Import(ctx, items, true))
+		require.Equal(t, model.BulkSkipped, items[0].Status)
+		require.Equal(t, model.BulkFailed, items[1].Status)
+	})
+
+	t.Run("fail removing the devices created before a failure", func(t *testing.T) {
+		items := newItems()
+		deviceDB.On("CreateMany", mock.Anything, items, true).Run(func(args mock.Arguments) {
+			items[0].Device.ID = primitive.NewObjectID()
+			items[0].Status = model.BulkCreated
+			items[1].Fail(errors.CreateError("device", "errMock"))
+		}).Once()
+		deviceDB.On("Remove", mock.Anything, mock.Anything).Return(errMock).Once()
+
+		require.EqualError(t, deviceController.Import(ctx, items, true), errMock.Error())
+	})
+
+	t.Run("partial import creates the valid rows", func(t *testing.T) {
+		items := newItems()
+		items[1].Fail(invalid)
+		deviceDB.On("CreateMany", mock.Anything, items[:1], false).Run(create).Once()
+		historyDB.On("Append", mock.Anything, mock.Anything).Return(nil).Once()
+
+		require.NoError(t, deviceController.Import(ctx, items, false))
+		require.Equal(t, model.BulkCreated, items[0].Status)
+		require.Equal(t, model.BulkFailed, items[1].Status)
+	})
+}
+
+// schemaRegistry is a brand registry of the brands with their attributes schema
+type schemaRegistry map[model.Brand]*model.AttributesSchema
+
+func (r schemaRegistry) Exists(brand model.Brand) bool {
+	_, ok := r[brand]
+	return ok
+}
+
+func (r schemaRegistry) AttributesSchema(brand model.Brand) *model.AttributesSchema {
+	return r[brand]
 }
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/mongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// idempotencyLock is how long the first request with an idempotency key can take before a retry processes it again
+const idempotencyLock = time.Minute
+
+// IdempotencyController service
+type IdempotencyController interface {
+	Begin(ctx context.Context, key, requestHash string, ttl time.Duration) (*model.IdempotentRequest, error)
+	Complete(ctx context.Context, key string, status int, contentType string, body []byte, deviceID *primitive.ObjectID) error
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyService idempotency key service
+type IdempotencyService struct {
+	idempotencyDB mongo.IdempotencyDB
+}
+
+// NewIdempotencyService IdempotencyService constructor
+func NewIdempotencyService(idempotencyDB mongo.IdempotencyDB) IdempotencyController {
+	return IdempotencyService{
+		idempotencyDB: idempotencyDB,
+	}
+}
+
+// Begin reserves the key for the request, kept for ttl, and returns nil when the request must be processed.
+// When the request was already sent with the key, its completed request is returned to replay the response.
+// The key cannot be reused for another request, nor while its first request is still being processed.
+func (is IdempotencyService) Begin(ctx context.Context, key, requestHash string, ttl time.Duration) (*model.IdempotentRequest, error) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	taken, err := is.idempotencyDB.Reserve(ctx, &model.IdempotentRequest{
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		LockedUntil: now.Add(idempotencyLock),
+		ExpiresAt:   now.Add(ttl),
+	})
+	if err != nil || taken == nil {
+		return nil, err
+	}
+	if taken.RequestHash != requestHash {
+		return nil, errors.IdempotencyKeyReusedError(key)
+	}
+	if !taken.Completed {
+		return nil, errors.RequestInProgressError(key)
+	}
+	return taken, nil
+}
+
+// Complete saves the response of the request of the key, to replay it, and the device the request created if any
+// so that the key is purged with it
+func (is IdempotencyService) Complete(ctx context.Context, key string, status int, contentType string, body []byte, deviceID *primitive.ObjectID) error {
+	return is.idempotencyDB.Complete(ctx, key, status, contentType, body, deviceID)
+}
+
+// Release frees the key of a request that could not be processed, so that it can be retried
+func (is IdempotencyService) Release(ctx context.Context, key string) error {
+	return is.idempotencyDB.Release(ctx, key)
+}
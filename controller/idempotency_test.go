@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	mongoMocks "github.com/device-ms/mongo/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIdempotencyController(t *testing.T) {
+	ctx := context.Background()
+	errMock := fmt.Errorf("errMock")
+
+	idempotencyDB := new(mongoMocks.IdempotencyDB)
+	defer idempotencyDB.AssertExpectations(t)
+	idempotencyController := NewIdempotencyService(idempotencyDB)
+
+	t.Run("first request is processed", func(t *testing.T) {
+		idempotencyDB.On("Reserve", mock.Anything, mock.Anything).Return(func(_ context.Context, req *model.IdempotentRequest) (*model.IdempotentRequest, error) {
+			require.Equal(t, "key1", req.Key)
+			require.Equal(t, "hash1", req.RequestHash)
+			require.Equal(t, time.Minute, req.LockedUntil.Sub(req.CreatedAt))
+			require.Equal(t, time.Hour, req.ExpiresAt.Sub(req.CreatedAt))
+			return nil, nil
+		}).Once()
+
+		replay, err := idempotencyController.Begin(ctx, "key1", "hash1", time.Hour)
+		require.NoError(t, err)
+		require.Nil(t, replay)
+	})
+
+	t.Run("same request is replayed", func(t *testing.T) {
+		taken := &model.IdempotentRequest{Key: "key1", RequestHash: "hash1", Completed: true, Status: 201}
+		idempotencyDB.On("Reserve", mock.Anything, mock.Anything).Return(taken, nil).Once()
+
+		replay, err := idempotencyController.Begin(ctx, "key1", "hash1", time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, taken, replay)
+	})
+
+	t.Run("fail different request", func(t *testing.T) {
+		idempotencyDB.On("Reserve", mock.Anything, mock.Anything).Return(&model.IdempotentRequest{Key: "key1", RequestHash: "hash1", Completed: true}, nil).Once()
+
+		_, err := idempotencyController.Begin(ctx, "key1", "hash2", time.Hour)
+		require.EqualError(t, err, "result: false; code: 1500020; message: the idempotency key key1 was used for a different request")
+	})
+
+	t.Run("fail request in progress", func(t *testing.T) {
+		idempotencyDB.On("Reserve", mock.Anything, mock.Anything).Return(&model.IdempotentRequest{Key: "key1", RequestHash: "hash1"}, nil).Once()
+
+		_, err := idempotencyController.Begin(ctx, "key1", "hash1", time.Hour)
+		require.EqualError(t, err, "result: false; code: 1500021; message: the request with idempotency key key1 is still in progress")
+	})
+
+	t.Run("fail reserving", func(t *testing.T) {
+		idempotencyDB.On("Reserve", mock.Anything, mock.Anything).Return(nil, errMock).Once()
+
+		_, err := idempotencyController.Begin(ctx, "key1", "hash1", time.Hour)
+		require.EqualError(t, err, errMock.Error())
+	})
+
+	t.Run("complete and release", func(t *testing.T) {
+		deviceID := primitive.NewObjectID()
+		idempotencyDB.On("Complete", mock.Anything, "key1", 201, "application/json", []byte("{}"), &deviceID).Return(nil).Once()
+		require.NoError(t, idempotencyController.Complete(ctx, "key1", 201, "application/json", []byte("{}"), &deviceID))
+
+		idempotencyDB.On("Release", mock.Anything, "key2").Return(errMock).Once()
+		require.EqualError(t, idempotencyController.Release(ctx, "key2"), errMock.Error())
+	})
+}
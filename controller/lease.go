@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	envLeaseSweepInterval = "LEASE_SWEEP_INTERVAL"
+	// defaultLeaseSweepInterval checks the overdue leases every minute
+	defaultLeaseSweepInterval = time.Minute
+)
+
+// LeaseSweeperConfig is the configuration of the job marking the overdue leases as expired
+type LeaseSweeperConfig struct {
+	// Interval is the time between two sweeps, the job is disabled when it is 0
+	Interval time.Duration
+}
+
+// LeaseSweeperConfigFromEnv reads the lease sweeper configuration from the environment
+func LeaseSweeperConfigFromEnv() (LeaseSweeperConfig, error) {
+	config := LeaseSweeperConfig{
+		Interval: defaultLeaseSweepInterval,
+	}
+	if err := durationFromEnv(envLeaseSweepInterval, &config.Interval); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// LeaseSweeper marks the leases of the checked out devices past their due date as expired
+type LeaseSweeper struct {
+	devices DeviceController
+	config  LeaseSweeperConfig
+}
+
+// NewLeaseSweeper LeaseSweeper constructor
+func NewLeaseSweeper(devices DeviceController, config LeaseSweeperConfig) LeaseSweeper {
+	return LeaseSweeper{
+		devices: devices,
+		config:  config,
+	}
+}
+
+// Run sweeps the leases every interval until ctx is done, failed sweeps are logged and retried on the next one
+func (sweeper LeaseSweeper) Run(ctx context.Context) {
+	if sweeper.config.Interval == 0 {
+		return
+	}
+	ticker := time.NewTicker(sweeper.config.Interval)
+	defer ticker.Stop()
+	for {
+		_, err := sweeper.Sweep(ctx)
+		if err != nil {
+			log.Printf("could not expire the overdue leases: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep marks the leases overdue now as expired, returning how many were marked
+func (sweeper LeaseSweeper) Sweep(ctx context.Context) (int, error) {
+	return sweeper.devices.ExpireLeases(ctx, time.Now().UTC().Truncate(time.Second))
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	mongoMocks "github.com/device-ms/mongo/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLeaseSweeperConfigFromEnv(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv(envLeaseSweepInterval, "")
+		config, err := LeaseSweeperConfigFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, LeaseSweeperConfig{Interval: time.Minute}, config)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		t.Setenv(envLeaseSweepInterval, "often")
+		_, err := LeaseSweeperConfigFromEnv()
+		require.EqualError(t, err, "invalid LEASE_SWEEP_INTERVAL [often]: time: invalid duration \"often\"")
+	})
+}
+
+func TestDeviceController_Lease(t *testing.T) {
+	ctx := WithActor(context.Background(), "alice")
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
+	deviceController := NewDeviceService(deviceDB, historyDB)
+
+	id := primitive.NewObjectID()
+	now := time.Now().UTC().Truncate(time.Second)
+	lease := model.Lease{Assignee: "bob", CheckedOutAt: now, DueAt: now.Add(time.Hour)}
+	available := model.Device{ID: id, Name: "io", Brand: "brand1", Version: 1}
+	checkedOut := model.Device{ID: id, Name: "io", Brand: "brand1", Lease: &lease, Version: 2, UpdatedAt: &now}
+
+	t.Run("checkout", func(t *testing.T) {
+		deviceDB.On("Checkout", mock.Anything, id, model.Lease{Assignee: "bob", DueAt: lease.DueAt}, model.VersionMatch{1}).
+			Return(&model.DeviceChange{Before: &available, After: &checkedOut}, nil).Once()
+		historyDB.On("Append", mock.Anything, mock.MatchedBy(func(rev *model.DeviceRevision) bool {
+			return rev.Operation == model.OperationCheckout && rev.Actor == "alice" && rev.After.Lease.Assignee == "bob"
+		})).Return(nil).Once()
+
+		dv, err := deviceController.Checkout(ctx, id, model.Lease{Assignee: "bob", DueAt: lease.DueAt}, model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Equal(t, &checkedOut, dv)
+	})
+
+	t.Run("fail checkout", func(t *testing.T) {
+		deviceDB.On("Checkout", mock.Anything, id, mock.Anything, model.VersionMatch(nil)).Return(nil, fmt.Errorf("errMock")).Once()
+
+		_, err := deviceController.Checkout(ctx, id, model.Lease{Assignee: "carol", DueAt: lease.DueAt}, nil)
+		require.EqualError(t, err, "errMock")
+	})
+
+	t.Run("checkin", func(t *testing.T) {
+		checkedIn := available
+		checkedIn.Version = 3
+		deviceDB.On("Checkin", mock.Anything, id, model.VersionMatch(nil)).
+			Return(&model.DeviceChange{Before: &checkedOut, After: &checkedIn}, nil).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationCheckin)).Return(nil).Once()
+
+		dv, err := deviceController.Checkin(ctx, id, nil)
+		require.NoError(t, err)
+		require.Nil(t, dv.Lease)
+	})
+
+	t.Run("expire leases", func(t *testing.T) {
+		at := lease.DueAt.Add(time.Minute)
+		expired := lease
+		expired.ExpiredAt = &at
+		after := checkedOut
+		after.Lease = &expired
+		after.Version = 3
+		deviceDB.On("ExpireLeases", mock.Anything, at).
+			Return([]*model.DeviceChange{{Before: &checkedOut, After: &after}}, fmt.Errorf("errMock")).Once()
+		historyDB.On("Append", mock.Anything, revisionOf(model.OperationLeaseExpired)).Return(nil).Once()
+
+		count, err := deviceController.ExpireLeases(ctx, at)
+		require.EqualError(t, err, "errMock")
+		require.Equal(t, 1, count)
+	})
+}
+
+func TestLeaseSweeper(t *testing.T) {
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+
+	t.Run("sweep expires the leases overdue now", func(t *testing.T) {
+		before := time.Now().UTC().Truncate(time.Second)
+		deviceDB.On("ExpireLeases", mock.Anything, mock.MatchedBy(func(at time.Time) bool {
+			return !at.Before(before) && at.Before(before.Add(time.Minute))
+		})).Return([]*model.DeviceChange{}, nil).Once()
+
+		sweeper := NewLeaseSweeper(NewDeviceService(deviceDB, nil), LeaseSweeperConfig{Interval: time.Minute})
+		count, err := sweeper.Sweep(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+	})
+
+	t.Run("run sweeps until the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		deviceDB.On("ExpireLeases", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("errMock")).Once()
+		deviceDB.On("ExpireLeases", mock.Anything, mock.Anything).Return([]*model.DeviceChange{}, nil).Once().Run(func(mock.Arguments) { cancel() })
+
+		sweeper := NewLeaseSweeper(NewDeviceService(deviceDB, nil), LeaseSweeperConfig{Interval: time.Millisecond})
+		sweeper.Run(ctx)
+	})
+
+	t.Run("run does nothing when disabled", func(t *testing.T) {
+		sweeper := NewLeaseSweeper(NewDeviceService(deviceDB, nil), LeaseSweeperConfig{})
+		sweeper.Run(context.Background())
+	})
+}
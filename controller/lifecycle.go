@@ -0,0 +1,23 @@
+package controller
+
+import "github.com/device-ms/model"
+
+// deviceTransitions is the lifecycle of a device: the states a device can move to from each state.
+// A retired device is not used anymore, it cannot move to another state.
+var deviceTransitions = map[model.DeviceState][]model.DeviceState{
+	model.StateOrdered:  {model.StateInStock, model.StateRetired},
+	model.StateInStock:  {model.StateInUse, model.StateInRepair, model.StateRetired},
+	model.StateInUse:    {model.StateInStock, model.StateInRepair},
+	model.StateInRepair: {model.StateInStock, model.StateRetired},
+	model.StateRetired:  nil,
+}
+
+// canTransition tells if a device can move from a state to another one
+func canTransition(from, to model.DeviceState) bool {
+	for _, state := range deviceTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	envPurgeRetention = "PURGE_RETENTION"
+	envPurgeInterval  = "PURGE_INTERVAL"
+	// defaultPurgeRetention keeps the soft deleted devices for 30 days
+	defaultPurgeRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval  = time.Hour
+)
+
+// PurgeConfig is the configuration of the job hard deleting the soft deleted devices
+type PurgeConfig struct {
+	// Retention is how long a soft deleted device can be restored
+	Retention time.Duration
+	// Interval is the time between two purges, the job is disabled when it is 0
+	Interval time.Duration
+}
+
+// PurgeConfigFromEnv reads the purge job configuration from the environment
+func PurgeConfigFromEnv() (PurgeConfig, error) {
+	config := PurgeConfig{
+		Retention: defaultPurgeRetention,
+		Interval:  defaultPurgeInterval,
+	}
+	if err := durationFromEnv(envPurgeRetention, &config.Retention); err != nil {
+		return config, err
+	}
+	if err := durationFromEnv(envPurgeInterval, &config.Interval); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// durationFromEnv reads a non negative duration from the environment, keeping the default when it is not set
+func durationFromEnv(env string, duration *time.Duration) error {
+	value := os.Getenv(env)
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s [%s]: %w", env, value, err)
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid %s [%s]: must not be negative", env, value)
+	}
+	*duration = d
+	return nil
+}
+
+// PurgeJob hard deletes the devices soft deleted for longer than the retention period
+type PurgeJob struct {
+	devices DeviceController
+	config  PurgeConfig
+}
+
+// NewPurgeJob PurgeJob constructor
+func NewPurgeJob(devices DeviceController, config PurgeConfig) PurgeJob {
+	return PurgeJob{
+		devices: devices,
+		config:  config,
+	}
+}
+
+// Run purges the devices every interval until ctx is done, failed purges are logged and retried on the next one
+func (job PurgeJob) Run(ctx context.Context) {
+	if job.config.Interval == 0 {
+		return
+	}
+	ticker := time.NewTicker(job.config.Interval)
+	defer ticker.Stop()
+	for {
+		count, err := job.Purge(ctx)
+		if err != nil {
+			log.Printf("could not purge the deleted devices: %v", err)
+		} else if count > 0 {
+			log.Printf("purged %d deleted device(s)", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge hard deletes the devices soft deleted before the retention period, returning how many were deleted
+func (job PurgeJob) Purge(ctx context.Context) (int64, error) {
+	return job.devices.Purge(ctx, time.Now().UTC().Add(-job.config.Retention))
+}
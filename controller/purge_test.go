@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	mongoMocks "github.com/device-ms/mongo/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeConfigFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv(envPurgeRetention, "")
+		t.Setenv(envPurgeInterval, "")
+		config, err := PurgeConfigFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, PurgeConfig{Retention: 30 * 24 * time.Hour, Interval: time.Hour}, config)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		t.Setenv(envPurgeRetention, "168h")
+		t.Setenv(envPurgeInterval, "0")
+		config, err := PurgeConfigFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, PurgeConfig{Retention: 7 * 24 * time.Hour}, config)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		t.Setenv(envPurgeRetention, "a week")
+		t.Setenv(envPurgeInterval, "")
+		_, err := PurgeConfigFromEnv()
+		require.EqualError(t, err, "invalid PURGE_RETENTION [a week]: time: invalid duration \"a week\"")
+	})
+
+	t.Run("negative duration", func(t *testing.T) {
+		t.Setenv(envPurgeRetention, "")
+		t.Setenv(envPurgeInterval, "-1h")
+		_, err := PurgeConfigFromEnv()
+		require.EqualError(t, err, "invalid PURGE_INTERVAL [-1h]: must not be negative")
+	})
+}
+
+func TestPurgeJob(t *testing.T) {
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	retention := 24 * time.Hour
+
+	t.Run("purge deletes the devices deleted before the retention period", func(t *testing.T) {
+		before := time.Now().UTC().Add(-retention)
+		deviceDB.On("Purge", mock.Anything, mock.MatchedBy(func(deletedBefore time.Time) bool {
+			return !deletedBefore.Before(before) && deletedBefore.Before(before.Add(time.Minute))
+		})).Return(int64(3), nil).Once()
+
+		job := NewPurgeJob(NewDeviceService(deviceDB, nil), PurgeConfig{Retention: retention, Interval: time.Hour})
+		count, err := job.Purge(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int64(3), count)
+	})
+
+	t.Run("run purges until the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		deviceDB.On("Purge", mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("errMock")).Once()
+		deviceDB.On("Purge", mock.Anything, mock.Anything).Return(int64(1), nil).Once().Run(func(mock.Arguments) { cancel() })
+
+		job := NewPurgeJob(NewDeviceService(deviceDB, nil), PurgeConfig{Retention: retention, Interval: time.Millisecond})
+		job.Run(ctx)
+	})
+
+	t.Run("run does nothing when disabled", func(t *testing.T) {
+		job := NewPurgeJob(NewDeviceService(deviceDB, nil), PurgeConfig{Retention: retention})
+		job.Run(context.Background())
+	})
+}
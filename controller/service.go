@@ -9,17 +9,30 @@ import (
 // ServiceController is the service interface
 type ServiceController interface {
 	DeviceController() DeviceController
+	BrandController() BrandController
+	BulkJobController() BulkJobController
+	IdempotencyController() IdempotencyController
+	TwinController() TwinController
 }
 
 // Service represents the service with all controllers and clients inside
 type Service struct {
-	device DeviceController
+	device      DeviceController
+	brand       BrandController
+	job         BulkJobController
+	idempotency IdempotencyController
+	twin        TwinController
 }
 
 // New returns a new service
-func New(ctx context.Context, deviceDB mongo.DeviceDB) Service {
+func New(ctx context.Context, deviceDB mongo.DeviceDB, historyDB mongo.DeviceHistoryDB, brandDB mongo.BrandDB, jobDB mongo.BulkJobDB, idempotencyDB mongo.IdempotencyDB, twinDB mongo.DeviceTwinDB) Service {
+	device := NewDeviceService(deviceDB, historyDB)
 	return Service{
-		device: NewDeviceService(deviceDB),
+		device:      device,
+		brand:       NewBrandService(brandDB, deviceDB),
+		job:         NewBulkJobService(jobDB, deviceDB, device),
+		idempotency: NewIdempotencyService(idempotencyDB),
+		twin:        NewTwinService(twinDB, device),
 	}
 }
 
@@ -27,3 +40,23 @@ func New(ctx context.Context, deviceDB mongo.DeviceDB) Service {
 func (s Service) DeviceController() DeviceController {
 	return s.device
 }
+
+// BrandController returns the brand controller.
+func (s Service) BrandController() BrandController {
+	return s.brand
+}
+
+// BulkJobController returns the bulk job controller.
+func (s Service) BulkJobController() BulkJobController {
+	return s.job
+}
+
+// IdempotencyController returns the idempotency key controller.
+func (s Service) IdempotencyController() IdempotencyController {
+	return s.idempotency
+}
+
+// TwinController returns the device twin controller.
+func (s Service) TwinController() TwinController {
+	return s.twin
+}
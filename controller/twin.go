@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/device-ms/model"
+	"github.com/device-ms/mongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TwinController service
+type TwinController interface {
+	GetTwin(ctx context.Context, deviceID primitive.ObjectID) (*model.DeviceTwin, error)
+	SetDesired(ctx context.Context, deviceID primitive.ObjectID, properties model.TwinProperties, match model.VersionMatch) (*model.DeviceTwin, error)
+	SetReported(ctx context.Context, deviceID primitive.ObjectID, token string, properties model.TwinProperties) (*model.DeviceTwin, error)
+}
+
+// TwinService service
+type TwinService struct {
+	twinDB  mongo.DeviceTwinDB
+	devices DeviceController
+}
+
+// NewTwinService TwinService constructor, the devices of the twins are checked and authenticated through devices
+func NewTwinService(twinDB mongo.DeviceTwinDB, devices DeviceController) TwinController {
+	return TwinService{
+		twinDB:  twinDB,
+		devices: devices,
+	}
+}
+
+// GetTwin gets the twin of a device
+func (ts TwinService) GetTwin(ctx context.Context, deviceID primitive.ObjectID) (*model.DeviceTwin, error) {
+	if _, err := ts.devices.GetDevice(ctx, deviceID); err != nil {
+		return nil, err
+	}
+	return ts.twinDB.ByDeviceID(ctx, deviceID)
+}
+
+// SetDesired replaces the desired properties of a device when the version of the desired side matches
+func (ts TwinService) SetDesired(ctx context.Context, deviceID primitive.ObjectID, properties model.TwinProperties, match model.VersionMatch) (*model.DeviceTwin, error) {
+	if _, err := ts.devices.GetDevice(ctx, deviceID); err != nil {
+		return nil, err
+	}
+	return ts.twinDB.SetDesired(ctx, deviceID, properties, match)
+}
+
+// SetReported replaces the reported properties a device posts with its heartbeat token.
+// A device reporting its properties is alive, so the report is also a heartbeat.
+func (ts TwinService) SetReported(ctx context.Context, deviceID primitive.ObjectID, token string, properties model.TwinProperties) (*model.DeviceTwin, error) {
+	if err := ts.devices.Heartbeat(ctx, deviceID, token); err != nil {
+		return nil, err
+	}
+	return ts.twinDB.SetReported(ctx, deviceID, properties, nil)
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	mongoMocks "github.com/device-ms/mongo/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTwinController(t *testing.T) {
+	ctx := context.Background()
+
+	deviceDB := new(mongoMocks.DeviceDB)
+	defer deviceDB.AssertExpectations(t)
+	historyDB := new(mongoMocks.DeviceHistoryDB)
+	defer historyDB.AssertExpectations(t)
+	twinDB := new(mongoMocks.DeviceTwinDB)
+	defer twinDB.AssertExpectations(t)
+	twinController := NewTwinService(twinDB, NewDeviceService(deviceDB, historyDB))
+
+	device := &model.Device{ID: primitive.NewObjectID(), Name: "io", Brand: "brand1", Version: 1}
+	properties := model.TwinProperties{"interval": float64(60)}
+
+	t.Run("ok - get twin", func(t *testing.T) {
+		twin := &model.DeviceTwin{DeviceID: device.ID, Desired: model.TwinSide{Properties: properties, Version: 1}}
+		deviceDB.On("ByID", ctx, device.ID).Return(device, nil).Once()
+		twinDB.On("ByDeviceID", ctx, device.ID).Return(twin, nil).Once()
+		got, err := twinController.GetTwin(ctx, device.ID)
+		require.NoError(t, err)
+		require.Equal(t, twin, got)
+	})
+	t.Run("get twin of unknown device", func(t *testing.T) {
+		notFound := errors.CouldNotFindObject("device", device.ID.Hex())
+		deviceDB.On("ByID", ctx, device.ID).Return(nil, notFound).Once()
+		_, err := twinController.GetTwin(ctx, device.ID)
+		require.Equal(t, notFound, err)
+	})
+
+	t.Run("ok - set desired", func(t *testing.T) {
+		twin := &model.DeviceTwin{DeviceID: device.ID, Desired: model.TwinSide{Properties: properties, Version: 2}}
+		deviceDB.On("ByID", ctx, device.ID).Return(device, nil).Once()
+		twinDB.On("SetDesired", ctx, device.ID, properties, model.VersionMatch{1}).Return(twin, nil).Once()
+		got, err := twinController.SetDesired(ctx, device.ID, properties, model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Equal(t, twin, got)
+	})
+	t.Run("set desired with version mismatch", func(t *testing.T) {
+		mismatch := errors.VersionMismatchError("device twin desired side", device.ID.Hex())
+		deviceDB.On("ByID", ctx, device.ID).Return(device, nil).Once()
+		twinDB.On("SetDesired", ctx, device.ID, properties, model.VersionMatch{}).Return(nil, mismatch).Once()
+		_, err := twinController.SetDesired(ctx, device.ID, properties, model.VersionMatch{})
+		require.Equal(t, mismatch, err)
+	})
+
+	t.Run("ok - set reported", func(t *testing.T) {
+		twin := &model.DeviceTwin{DeviceID: device.ID, Reported: model.TwinSide{Properties: properties, Version: 1}}
+		deviceDB.On("Heartbeat", ctx, device.ID, model.HashHeartbeatToken("secret"), mock.AnythingOfType("time.Time")).Return(nil).Once()
+		twinDB.On("SetReported", ctx, device.ID, properties, model.VersionMatch(nil)).Return(twin, nil).Once()
+		got, err := twinController.SetReported(ctx, device.ID, "secret", properties)
+		require.NoError(t, err)
+		require.Equal(t, twin, got)
+	})
+	t.Run("set reported with wrong token", func(t *testing.T) {
+		unauthorized := errors.UnauthorizedError("device", device.ID.Hex())
+		deviceDB.On("Heartbeat", ctx, device.ID, model.HashHeartbeatToken("guess"), mock.AnythingOfType("time.Time")).Return(unauthorized).Once()
+		_, err := twinController.SetReported(ctx, device.ID, "guess", properties)
+		require.Equal(t, unauthorized, err)
+	})
+}
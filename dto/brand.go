@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/device-ms/model"
+)
+
+// BrandDTO is a brand DTO
+type BrandDTO struct {
+	Name             model.Brand     `json:"name"`
+	Description      string          `json:"description,omitempty"`
+	AttributesSchema json.RawMessage `json:"attributesSchema,omitempty"`
+	CreatedAt        *time.Time      `json:"createdAt"`
+}
+
+// ToBrandDTO maps a brand model to a brand dto response
+func ToBrandDTO(m *model.BrandInfo) *BrandDTO {
+	dto := BrandDTO{
+		Name:        m.Name,
+		Description: m.Description,
+		CreatedAt:   &m.CreatedAt,
+	}
+	if m.AttributesSchema != "" {
+		dto.AttributesSchema = json.RawMessage(m.AttributesSchema)
+	}
+	return &dto
+}
+
+// CreateBrandRequestDTO represents the body information to create a new brand
+type CreateBrandRequestDTO struct {
+	Name             model.Brand     `json:"name"`
+	Description      string          `json:"description"`
+	AttributesSchema json.RawMessage `json:"attributesSchema"`
+}
+
+// ToModel maps a brand creation dto to a brand model
+func (req CreateBrandRequestDTO) ToModel() *model.BrandInfo {
+	return &model.BrandInfo{
+		Name:             req.Name,
+		Description:      req.Description,
+		AttributesSchema: attributesSchema(req.AttributesSchema),
+	}
+}
+
+// UpdateBrandRequestDTO request when updating a brand
+type UpdateBrandRequestDTO struct {
+	Name             model.Brand
+	Description      string          `json:"description"`
+	AttributesSchema json.RawMessage `json:"attributesSchema"`
+}
+
+// ToModel maps a brand update request dto to a brand model
+func (req UpdateBrandRequestDTO) ToModel() *model.BrandInfo {
+	return &model.BrandInfo{
+		Name:             req.Name,
+		Description:      req.Description,
+		AttributesSchema: attributesSchema(req.AttributesSchema),
+	}
+}
+
+// HasAttributesSchema tells if a brand request has an attributes schema, a null schema being none
+func HasAttributesSchema(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+// attributesSchema returns the attributes schema of a brand request as compact JSON, empty when there is none
+func attributesSchema(raw json.RawMessage) string {
+	if !HasAttributesSchema(raw) {
+		return ""
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
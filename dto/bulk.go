@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+)
+
+// NDJSONMediaType is the media type of a body with a JSON document per line
+const NDJSONMediaType = "application/x-ndjson"
+
+// BulkCreateResponseDTO is the outcome of a bulk creation, with an item per device of the request in the same order
+type BulkCreateResponseDTO struct {
+	Created int                 `json:"created"`
+	Failed  int                 `json:"failed"`
+	Skipped int                 `json:"skipped"`
+	Items   []BulkCreateItemDTO `json:"items"`
+}
+
+// BulkCreateItemDTO is the outcome of the creation of a device of a bulk creation,
+// with the id of the created device or the error making it fail
+type BulkCreateItemDTO struct {
+	Index  int              `json:"index"`
+	Status model.BulkStatus `json:"status"`
+	ID     string           `json:"id,omitempty"`
+	Error  *ErrorDTO        `json:"error,omitempty"`
+}
+
+// ErrorDTO is the error of an item of a bulk operation
+type ErrorDTO struct {
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+// ToBulkCreateResponseDTO maps the items of a bulk creation to its response
+func ToBulkCreateResponseDTO(items []*model.BulkItem) BulkCreateResponseDTO {
+	res := BulkCreateResponseDTO{Items: make([]BulkCreateItemDTO, len(items))}
+	for i, item := range items {
+		res.Items[i] = BulkCreateItemDTO{Index: i, Status: item.Status}
+		switch item.Status {
+		case model.BulkCreated:
+			res.Created++
+			res.Items[i].ID = item.Device.ID.Hex()
+		case model.BulkFailed:
+			res.Failed++
+			custErr := errors.Wrap(item.Err)
+			res.Items[i].Error = &ErrorDTO{Code: custErr.Code, Message: custErr.Message}
+		case model.BulkSkipped:
+			res.Skipped++
+		}
+	}
+	return res
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestToBulkCreateResponseDTO(t *testing.T) {
+	id := primitive.NewObjectID()
+	items := []*model.BulkItem{
+		{Device: &model.Device{ID: id, Name: "io"}, Status: model.BulkCreated},
+		{Device: &model.Device{Name: "europa"}},
+		{Device: &model.Device{Name: "ganymede"}, Status: model.BulkSkipped},
+	}
+	items[1].Fail(errors.InvalidParameterError("brand", "invalid value [brand one]"))
+
+	res := ToBulkCreateResponseDTO(items)
+	require.Equal(t, BulkCreateResponseDTO{
+		Created: 1,
+		Failed:  1,
+		Skipped: 1,
+		Items: []BulkCreateItemDTO{
+			{Index: 0, Status: model.BulkCreated, ID: id.Hex()},
+			{Index: 1, Status: model.BulkFailed, Error: &ErrorDTO{Code: 1500002, Message: "parameter 'brand' is invalid 'invalid value [brand one]'"}},
+			{Index: 2, Status: model.BulkSkipped},
+		},
+	}, res)
+}
@@ -3,43 +3,108 @@ package dto
 import (
 	"time"
 
+	"github.com/device-ms/errors"
 	"github.com/device-ms/model"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // DeviceDTO is a device DTO
 type DeviceDTO struct {
-	ID        string      `json:"id"`
-	Name      string      `json:"name,omitempty"`
-	Brand     model.Brand `json:"brand"`
-	CreatedAt *time.Time  `json:"createdAt"`
+	ID           string             `json:"id"`
+	Name         string             `json:"name,omitempty"`
+	Brand        model.Brand        `json:"brand"`
+	SerialNumber string             `json:"serialNumber,omitempty"`
+	ExternalIDs  map[string]string  `json:"externalIds,omitempty"`
+	State        model.DeviceState  `json:"state"`
+	Lease        *LeaseDTO          `json:"lease,omitempty"`
+	Labels       map[string]string  `json:"labels,omitempty"`
+	Attributes   model.Attributes   `json:"attributes,omitempty"`
+	ParentID     string             `json:"parentId,omitempty"`
+	Location     *LocationDTO       `json:"location,omitempty"`
+	LastSeenAt   *time.Time         `json:"lastSeenAt,omitempty"`
+	Connectivity model.Connectivity `json:"connectivity"`
+	CreatedAt    *time.Time         `json:"createdAt"`
+	Version      int64              `json:"version"`
+	DeletedAt    *time.Time         `json:"deletedAt,omitempty"`
 }
 
 // ToDeviceDTO maps a device model to a device dto response
 func ToDeviceDTO(m *model.Device) *DeviceDTO {
 	dto := DeviceDTO{
-		ID:        m.ID.Hex(),
-		Name:      m.Name,
-		Brand:     m.Brand,
-		CreatedAt: &m.CreatedAt,
+		ID:           m.ID.Hex(),
+		Name:         m.Name,
+		Brand:        m.Brand,
+		SerialNumber: m.SerialNumber,
+		ExternalIDs:  m.ExternalIDsBySystem(),
+		State:        m.CurrentState(),
+		Lease:        ToLeaseDTO(m.Lease),
+		Labels:       m.Labels,
+		Attributes:   m.Attributes,
+		ParentID:     hex(m.ParentID),
+		Location:     ToLocationDTO(m.Location),
+		LastSeenAt:   m.LastSeenAt,
+		Connectivity: m.Connectivity(time.Now()),
+		CreatedAt:    &m.CreatedAt,
+		Version:      m.Version,
+		DeletedAt:    m.DeletedAt,
 	}
 
 	return &dto
 }
 
-// UpdateDeviceRequestDTO request when updating a device
+// LeaseDTO is the lease of a checked out device, overdue once the lease sweeper found it past its due date.
+// Overdue is derived from the saved expiry, so that it changes with the version of the device like its ETag.
+type LeaseDTO struct {
+	Assignee     string     `json:"assignee"`
+	CheckedOutAt time.Time  `json:"checkedOutAt"`
+	DueAt        time.Time  `json:"dueAt"`
+	Overdue      bool       `json:"overdue"`
+	ExpiredAt    *time.Time `json:"expiredAt,omitempty"`
+}
+
+// ToLeaseDTO maps a lease model to a lease dto response, nil when there is no lease
+func ToLeaseDTO(m *model.Lease) *LeaseDTO {
+	if m == nil {
+		return nil
+	}
+	return &LeaseDTO{
+		Assignee:     m.Assignee,
+		CheckedOutAt: m.CheckedOutAt,
+		DueAt:        m.DueAt,
+		Overdue:      m.ExpiredAt != nil,
+		ExpiredAt:    m.ExpiredAt,
+	}
+}
+
+// ValidateAttributes validates the attributes of a device against the attributes schema of its brand,
+// reporting every violation by its path
+func ValidateAttributes(brand model.Brand, attributes model.Attributes) []error {
+	var errs []error
+	for _, violation := range attributes.Violations(brand.AttributesSchema()) {
+		errs = append(errs, errors.InvalidParameterError("attributes"+violation.Path, violation.Reason))
+	}
+	return errs
+}
+
+// UpdateDeviceRequestDTO request when updating a device, replacing all its updatable fields
 type UpdateDeviceRequestDTO struct {
-	DeviceID primitive.ObjectID
-	Name     string      `json:"name"`
-	Brand    model.Brand `json:"brand"`
+	DeviceID     primitive.ObjectID
+	Name         string            `json:"name"`
+	Brand        model.Brand       `json:"brand"`
+	SerialNumber string            `json:"serialNumber"`
+	ExternalIDs  map[string]string `json:"externalIds"`
+	Attributes   model.Attributes  `json:"attributes"`
 }
 
 // ToModel maps a device update request dto to a device model
 func (req UpdateDeviceRequestDTO) ToModel() (*model.Device, error) {
 	return &model.Device{
-		ID:    req.DeviceID,
-		Name:  req.Name,
-		Brand: req.Brand,
+		ID:           req.DeviceID,
+		Name:         req.Name,
+		Brand:        req.Brand,
+		SerialNumber: req.SerialNumber,
+		ExternalIDs:  model.NewExternalIDs(req.ExternalIDs),
+		Attributes:   attributes(req.Attributes),
 	}, nil
 }
 
@@ -57,6 +122,20 @@ func (req UpdateDeviceNameRequestDTO) ToModel() (*model.Device, error) {
 	}, nil
 }
 
+// UpdateDeviceLocationRequestDTO request when updating the location of a device, an empty location removing it
+type UpdateDeviceLocationRequestDTO struct {
+	DeviceID primitive.ObjectID
+	LocationDTO
+}
+
+// ToModel maps a device update location request dto to a device model
+func (req UpdateDeviceLocationRequestDTO) ToModel() (*model.Device, error) {
+	return &model.Device{
+		ID:       req.DeviceID,
+		Location: req.LocationDTO.ToModel(),
+	}, nil
+}
+
 // UpdateDeviceBrandRequestDTO request when updating the brand of a device
 type UpdateDeviceBrandRequestDTO struct {
 	DeviceID primitive.ObjectID
@@ -73,16 +152,156 @@ func (req UpdateDeviceBrandRequestDTO) ToModel() (*model.Device, error) {
 
 // CreateDeviceRequestDTO represents the body information to create a new device
 type CreateDeviceRequestDTO struct {
-	Name  string      `json:"name"`
-	Brand model.Brand `json:"brand"`
+	Name         string            `json:"name"`
+	Brand        model.Brand       `json:"brand"`
+	SerialNumber string            `json:"serialNumber"`
+	ExternalIDs  map[string]string `json:"externalIds"`
+	// State is the initial state of the device, the default state when it is empty
+	State      model.DeviceState `json:"state"`
+	Labels     map[string]string `json:"labels"`
+	Attributes model.Attributes  `json:"attributes"`
+	// ParentID is the id of the device containing the device, none when it is empty
+	ParentID string       `json:"parentId"`
+	Location *LocationDTO `json:"location"`
 }
 
 // ToModel maps a device creation dto to a device model
 func (req CreateDeviceRequestDTO) ToModel() *model.Device {
 	return &model.Device{
-		Name:  req.Name,
-		Brand: req.Brand,
+		Name:         req.Name,
+		Brand:        req.Brand,
+		SerialNumber: req.SerialNumber,
+		ExternalIDs:  model.NewExternalIDs(req.ExternalIDs),
+		State:        req.State,
+		Labels:       labels(req.Labels),
+		Attributes:   attributes(req.Attributes),
+		ParentID:     objectID(req.ParentID),
+		Location:     req.Location.ToModel(),
+	}
+}
+
+// hex returns an optional id as an hexadecimal string, empty when there is none
+func hex(id *primitive.ObjectID) string {
+	if id == nil {
+		return ""
+	}
+	return id.Hex()
+}
+
+// objectID parses an optional id, nil when it is empty or invalid
+func objectID(hex string) *primitive.ObjectID {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// labels returns the labels of a request, nil when there are none
+func labels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
 	}
+	return labels
+}
+
+// attributes returns the attributes of a request, nil when there are none
+func attributes(attributes model.Attributes) model.Attributes {
+	if len(attributes) == 0 {
+		return nil
+	}
+	return attributes
+}
+
+// LocationDTO is where a device is, its point being a GeoJSON point
+type LocationDTO struct {
+	Site     string       `json:"site,omitempty"`
+	Building string       `json:"building,omitempty"`
+	Room     string       `json:"room,omitempty"`
+	Point    *GeoPointDTO `json:"point,omitempty"`
+}
+
+// GeoPointDTO is a GeoJSON point, its coordinates being the longitude and the latitude in that order
+type GeoPointDTO struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// ToLocationDTO maps a location model to a location dto response, nil when there is no location
+func ToLocationDTO(m *model.Location) *LocationDTO {
+	if m == nil {
+		return nil
+	}
+	dto := LocationDTO{
+		Site:     m.Site,
+		Building: m.Building,
+		Room:     m.Room,
+	}
+	if m.Point != nil {
+		dto.Point = &GeoPointDTO{Type: m.Point.Type, Coordinates: m.Point.Coordinates}
+	}
+	return &dto
+}
+
+// ToModel maps a location dto to a location model, nil when the location is missing or empty
+func (req *LocationDTO) ToModel() *model.Location {
+	if req == nil {
+		return nil
+	}
+	location := model.Location{
+		Site:     req.Site,
+		Building: req.Building,
+		Room:     req.Room,
+	}
+	if req.Point != nil {
+		location.Point = &model.GeoPoint{Type: req.Point.Type, Coordinates: req.Point.Coordinates}
+	}
+	if location.IsZero() {
+		return nil
+	}
+	return &location
+}
+
+// SetLabelRequestDTO is the request to set the value of a label of a device
+type SetLabelRequestDTO struct {
+	DeviceID primitive.ObjectID `json:"-"`
+	Key      string             `json:"-"`
+	Value    *string            `json:"value"`
+}
+
+// TransitionRequestDTO is the request to move a device to another state of its lifecycle
+type TransitionRequestDTO struct {
+	DeviceID primitive.ObjectID `json:"-"`
+	To       model.DeviceState  `json:"to"`
+	Reason   string             `json:"reason"`
+}
+
+// SetParentRequestDTO is the request to attach a device to a parent, or to detach it from its parent
+// when ParentID is nil
+type SetParentRequestDTO struct {
+	DeviceID primitive.ObjectID `json:"-"`
+	ParentID *string            `json:"parentId"`
+}
+
+// CheckoutRequestDTO is the request to lend a device to an assignee until a due date
+type CheckoutRequestDTO struct {
+	DeviceID primitive.ObjectID `json:"-"`
+	Assignee string             `json:"assignee"`
+	DueAt    *time.Time         `json:"dueAt"`
+}
+
+// ToModel maps a checkout request dto to a lease model
+func (req CheckoutRequestDTO) ToModel() model.Lease {
+	lease := model.Lease{Assignee: req.Assignee}
+	if req.DueAt != nil {
+		lease.DueAt = req.DueAt.UTC().Truncate(time.Second)
+	}
+	return lease
+}
+
+// HeartbeatTokenDTO is the token a device sends its heartbeats with, only returned when it is generated
+type HeartbeatTokenDTO struct {
+	Token string `json:"token"`
 }
 
 // CreatedDeviceResponseDTO is a device DTO
@@ -101,5 +320,48 @@ func ToCreatedDeviceResponseDTO(m *model.Device) CreatedDeviceResponseDTO {
 
 // SearchDevicesRequestDTO is the request information used to search devices
 type SearchDevicesRequestDTO struct {
-	Brand model.Brand `json:"brand"`
+	model.DeviceFilter
+	Limit  int64            `json:"limit"`
+	Cursor string           `json:"cursor"`
+	Sort   model.DeviceSort `json:"sort"`
+	Total  bool             `json:"total"`
+}
+
+// ToModel maps a device search request dto to a device search model
+func (req SearchDevicesRequestDTO) ToModel() (model.DeviceSearch, error) {
+	search := model.DeviceSearch{
+		DeviceFilter: req.DeviceFilter,
+		Limit:        req.Limit,
+		Sort:         req.Sort,
+		WithTotal:    req.Total,
+	}
+	if req.Cursor != "" {
+		cursor, err := model.DecodeDeviceCursor(req.Cursor, req.Sort)
+		if err != nil {
+			return search, errors.InvalidParameterError("cursor", err.Error())
+		}
+		search.Cursor = cursor
+	}
+	return search, nil
+}
+
+// DevicePageDTO is a page of devices
+type DevicePageDTO struct {
+	Items      []DeviceDTO `json:"items"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	Total      *int64      `json:"total,omitempty"`
+}
+
+// ToDevicePageDTO maps a device page model to a device page dto response
+func ToDevicePageDTO(m *model.DevicePage) *DevicePageDTO {
+	dto := DevicePageDTO{
+		Items:      make([]DeviceDTO, len(m.Devices)),
+		NextCursor: m.NextCursor,
+		Total:      m.Total,
+	}
+	for i := range m.Devices {
+		dto.Items[i] = *ToDeviceDTO(&m.Devices[i])
+	}
+
+	return &dto
 }
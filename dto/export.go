@@ -0,0 +1,132 @@
+package dto
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/device-ms/model"
+)
+
+// ExportFormat is the format of a device export
+type ExportFormat string
+
+// Enum values
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportNDJSON ExportFormat = "ndjson"
+	ExportJSON   ExportFormat = "json"
+)
+
+// IsValid checks if the export format is valid
+func (f ExportFormat) IsValid() bool {
+	return f == ExportCSV || f == ExportNDJSON || f == ExportJSON
+}
+
+// MediaType returns the media type of the export format
+func (f ExportFormat) MediaType() string {
+	switch f {
+	case ExportCSV:
+		return CSVMediaType
+	case ExportNDJSON:
+		return NDJSONMediaType
+	}
+	return "application/json"
+}
+
+// DeviceEncoder writes devices one at a time, Close ending the document
+type DeviceEncoder interface {
+	Encode(dv *model.Device) error
+	Close() error
+}
+
+// NewDeviceEncoder creates a device encoder writing the export format to w
+func NewDeviceEncoder(format ExportFormat, w io.Writer) DeviceEncoder {
+	switch format {
+	case ExportCSV:
+		return &csvDeviceEncoder{writer: csv.NewWriter(w)}
+	case ExportNDJSON:
+		return ndjsonDeviceEncoder{encoder: json.NewEncoder(w)}
+	}
+	return &jsonDeviceEncoder{w: w}
+}
+
+// csvDeviceEncoder writes a header line then a line per device
+type csvDeviceEncoder struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvDeviceEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.writer.Write([]string{"id", "name", "brand", "state", "version", "createdAt"})
+}
+
+func (e *csvDeviceEncoder) Encode(dv *model.Device) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.writer.Write([]string{
+		dv.ID.Hex(),
+		dv.Name,
+		string(dv.Brand),
+		string(dv.CurrentState()),
+		strconv.FormatInt(dv.Version, 10),
+		dv.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+func (e *csvDeviceEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// ndjsonDeviceEncoder writes a device dto per line
+type ndjsonDeviceEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e ndjsonDeviceEncoder) Encode(dv *model.Device) error {
+	return e.encoder.Encode(ToDeviceDTO(dv))
+}
+
+func (e ndjsonDeviceEncoder) Close() error {
+	return nil
+}
+
+// jsonDeviceEncoder writes a JSON array of device dtos
+type jsonDeviceEncoder struct {
+	w       io.Writer
+	encoded int
+}
+
+func (e *jsonDeviceEncoder) Encode(dv *model.Device) error {
+	b, err := json.Marshal(ToDeviceDTO(dv))
+	if err != nil {
+		return err
+	}
+	separator := ",\n"
+	if e.encoded == 0 {
+		separator = "[\n"
+	}
+	e.encoded++
+	_, err = io.WriteString(e.w, separator+string(b))
+	return err
+}
+
+func (e *jsonDeviceEncoder) Close() error {
+	end := "\n]\n"
+	if e.encoded == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeviceEncoder(t *testing.T) {
+	id, err := primitive.ObjectIDFromHex("676b240a7bbab556f4a6b57b")
+	require.NoError(t, err)
+	devices := []*model.Device{
+		{ID: id, Name: "io, moon", Brand: "brand1", Version: 2, CreatedAt: time.Date(2024, 12, 24, 21, 0, 0, 0, time.UTC)},
+		{ID: id, Brand: "brand2", State: model.StateInUse, Version: 1, CreatedAt: time.Date(2024, 12, 25, 21, 0, 0, 0, time.UTC)},
+	}
+	encode := func(format ExportFormat, devices []*model.Device) string {
+		buf := new(bytes.Buffer)
+		encoder := NewDeviceEncoder(format, buf)
+		for _, dv := range devices {
+			require.NoError(t, encoder.Encode(dv))
+		}
+		require.NoError(t, encoder.Close())
+		return buf.String()
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		require.Equal(t, "id,name,brand,state,version,createdAt\n"+
+			"676b240a7bbab556f4a6b57b,\"io, moon\",brand1,inStock,2,2024-12-24T21:00:00Z\n"+
+			"676b240a7bbab556f4a6b57b,,brand2,inUse,1,2024-12-25T21:00:00Z\n", encode(ExportCSV, devices))
+		require.Equal(t, "id,name,brand,state,version,createdAt\n", encode(ExportCSV, nil))
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		require.Equal(t, "{\"id\":\"676b240a7bbab556f4a6b57b\",\"name\":\"io, moon\",\"brand\":\"brand1\",\"state\":\"inStock\",\"connectivity\":\"offline\",\"createdAt\":\"2024-12-24T21:00:00Z\",\"version\":2}\n"+
+			"{\"id\":\"676b240a7bbab556f4a6b57b\",\"brand\":\"brand2\",\"state\":\"inUse\",\"connectivity\":\"offline\",\"createdAt\":\"2024-12-25T21:00:00Z\",\"version\":1}\n", encode(ExportNDJSON, devices))
+		require.Empty(t, encode(ExportNDJSON, nil))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		require.Equal(t, "[\n{\"id\":\"676b240a7bbab556f4a6b57b\",\"name\":\"io, moon\",\"brand\":\"brand1\",\"state\":\"inStock\",\"connectivity\":\"offline\",\"createdAt\":\"2024-12-24T21:00:00Z\",\"version\":2},\n"+
+			"{\"id\":\"676b240a7bbab556f4a6b57b\",\"brand\":\"brand2\",\"state\":\"inUse\",\"connectivity\":\"offline\",\"createdAt\":\"2024-12-25T21:00:00Z\",\"version\":1}\n]\n", encode(ExportJSON, devices))
+		require.Equal(t, "[]\n", encode(ExportJSON, nil))
+	})
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+)
+
+// CSVMediaType is the media type of comma separated values
+const CSVMediaType = "text/csv"
+
+// ImportMode tells how an import commits its rows
+type ImportMode string
+
+// Enum values
+const (
+	// ImportTransactional commits all the rows, or none when one of them is rejected. It is not atomic,
+	// the devices created before a failure being rolled back by compensation, removed afterwards.
+	ImportTransactional ImportMode = "transactional"
+	// ImportPartial commits the valid rows only
+	ImportPartial ImportMode = "partial"
+)
+
+// IsValid checks if the import mode is valid
+func (m ImportMode) IsValid() bool {
+	return m == ImportTransactional || m == ImportPartial
+}
+
+// ImportReportDTO is the outcome of a CSV import, with an error per rejected line
+type ImportReportDTO struct {
+	Mode     ImportMode       `json:"mode"`
+	Rows     int              `json:"rows"`
+	Created  int              `json:"created"`
+	Rejected int              `json:"rejected"`
+	Skipped  int              `json:"skipped"`
+	Errors   []ImportErrorDTO `json:"errors"`
+}
+
+// ImportErrorDTO is the error rejecting a line of a CSV import
+type ImportErrorDTO struct {
+	Line    int    `json:"line"`
+	Name    string `json:"name"`
+	Brand   string `json:"brand"`
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+// ToImportReportDTO maps the items of an import, read from the lines of the CSV body, to its report
+func ToImportReportDTO(mode ImportMode, lines []int, items []*model.BulkItem) ImportReportDTO {
+	report := ImportReportDTO{Mode: mode, Rows: len(items), Errors: []ImportErrorDTO{}}
+	for i, item := range items {
+		switch item.Status {
+		case model.BulkCreated:
+			report.Created++
+		case model.BulkFailed:
+			report.Rejected++
+			custErr := errors.Wrap(item.Err)
+			report.Errors = append(report.Errors, ImportErrorDTO{
+				Line:    lines[i],
+				Name:    item.Device.Name,
+				Brand:   string(item.Device.Brand),
+				Code:    custErr.Code,
+				Message: custErr.Message,
+			})
+		case model.BulkSkipped:
+			report.Skipped++
+		}
+	}
+	return report
+}
+
+// WriteCSV writes the rejected lines of the report as CSV
+func (report ImportReportDTO) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"line", "name", "brand", "code", "message"}); err != nil {
+		return err
+	}
+	for _, e := range report.Errors {
+		record := []string{strconv.Itoa(e.Line), e.Name, e.Brand, strconv.FormatInt(e.Code, 10), e.Message}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestToImportReportDTO(t *testing.T) {
+	items := []*model.BulkItem{
+		{Device: &model.Device{ID: primitive.NewObjectID(), Name: "io", Brand: "brand1"}, Status: model.BulkCreated},
+		{Device: &model.Device{Name: "europa", Brand: "brand one"}},
+		{Device: &model.Device{Name: "ganymede"}},
+	}
+	items[1].Fail(errors.InvalidParameterError("brand", "invalid value [brand one]"))
+	items[2].Fail(errors.RequiredParameterError("brand", "body"))
+
+	report := ToImportReportDTO(ImportPartial, []int{2, 3, 5}, items)
+	require.Equal(t, ImportReportDTO{
+		Mode:     ImportPartial,
+		Rows:     3,
+		Created:  1,
+		Rejected: 2,
+		Errors: []ImportErrorDTO{
+			{Line: 3, Name: "europa", Brand: "brand one", Code: 1500002, Message: "parameter 'brand' is invalid 'invalid value [brand one]'"},
+			{Line: 5, Name: "ganymede", Code: 1500001, Message: "parameter 'brand' in body is required"},
+		},
+	}, report)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, report.WriteCSV(buf))
+	require.Equal(t, "line,name,brand,code,message\n"+
+		"3,europa,brand one,1500002,parameter 'brand' is invalid 'invalid value [brand one]'\n"+
+		"5,ganymede,,1500001,parameter 'brand' in body is required\n", buf.String())
+}
@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/device-ms/model"
+)
+
+// BulkJobRequestDTO is the request to change all the devices matching a filter in the background.
+// A dry run only counts the devices matching the filter.
+type BulkJobRequestDTO struct {
+	Filter BulkJobFilterDTO `json:"filter"`
+	Action BulkActionDTO    `json:"action"`
+	DryRun bool             `json:"dryRun"`
+}
+
+// BulkJobFilterDTO is the criteria of the devices of a bulk job, the creation range including createdFrom
+// and excluding createdTo
+type BulkJobFilterDTO struct {
+	Brands      []model.Brand `json:"brands,omitempty"`
+	NamePrefix  string        `json:"namePrefix,omitempty"`
+	CreatedFrom *time.Time    `json:"createdFrom,omitempty"`
+	CreatedTo   *time.Time    `json:"createdTo,omitempty"`
+}
+
+// BulkActionDTO is the change of a bulk job
+type BulkActionDTO struct {
+	Type        model.BulkActionType `json:"type"`
+	Brand       model.Brand          `json:"brand,omitempty"`
+	NamePattern string               `json:"namePattern,omitempty"`
+}
+
+// ToModel maps a bulk job request dto to a bulk job model
+func (req BulkJobRequestDTO) ToModel() *model.BulkJob {
+	job := &model.BulkJob{
+		Filter: model.DeviceFilter{
+			Brands:    req.Filter.Brands,
+			CreatedAt: model.TimeRange{Gte: req.Filter.CreatedFrom, Lt: req.Filter.CreatedTo},
+		},
+		Action: model.BulkAction(req.Action),
+	}
+	if req.Filter.NamePrefix != "" {
+		job.Filter.Name = &model.NameFilter{Match: model.NameMatchPrefix, Value: req.Filter.NamePrefix}
+	}
+	return job
+}
+
+// BulkJobDTO is a bulk job with its progress
+type BulkJobDTO struct {
+	ID              string            `json:"id"`
+	Status          model.JobStatus   `json:"status"`
+	Filter          BulkJobFilterDTO  `json:"filter"`
+	Action          BulkActionDTO     `json:"action"`
+	Actor           string            `json:"actor,omitempty"`
+	Total           int64             `json:"total"`
+	Processed       int64             `json:"processed"`
+	Failed          int64             `json:"failed"`
+	Errors          []BulkJobErrorDTO `json:"errors,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	CancelRequested bool              `json:"cancelRequested,omitempty"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	StartedAt       *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt      *time.Time        `json:"finishedAt,omitempty"`
+}
+
+// BulkJobErrorDTO is the error of a device a bulk job could not change
+type BulkJobErrorDTO struct {
+	DeviceID string `json:"deviceId"`
+	Message  string `json:"message"`
+}
+
+// BulkJobDryRunDTO is the outcome of a bulk job dry run
+type BulkJobDryRunDTO struct {
+	Matched int64 `json:"matched"`
+}
+
+// ToBulkJobDTO maps a bulk job model to a bulk job dto response
+func ToBulkJobDTO(m *model.BulkJob) *BulkJobDTO {
+	dto := BulkJobDTO{
+		ID:     m.ID.Hex(),
+		Status: m.Status,
+		Filter: BulkJobFilterDTO{
+			Brands:      m.Filter.Brands,
+			CreatedFrom: m.Filter.CreatedAt.Gte,
+			CreatedTo:   m.Filter.CreatedAt.Lt,
+		},
+		Action:          BulkActionDTO(m.Action),
+		Actor:           m.Actor,
+		Total:           m.Total,
+		Processed:       m.Processed,
+		Failed:          m.Failed,
+		Error:           m.Error,
+		CancelRequested: m.CancelRequested,
+		CreatedAt:       m.CreatedAt,
+		StartedAt:       m.StartedAt,
+		FinishedAt:      m.FinishedAt,
+	}
+	if m.Filter.Name != nil {
+		dto.Filter.NamePrefix = m.Filter.Name.Value
+	}
+	for _, jobErr := range m.Errors {
+		dto.Errors = append(dto.Errors, BulkJobErrorDTO{DeviceID: jobErr.DeviceID.Hex(), Message: jobErr.Message})
+	}
+
+	return &dto
+}
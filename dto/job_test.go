@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBulkJobDTO(t *testing.T) {
+	from := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	req := BulkJobRequestDTO{
+		Filter: BulkJobFilterDTO{Brands: []model.Brand{"brand1"}, NamePrefix: "io", CreatedFrom: &from},
+		Action: BulkActionDTO{Type: model.BulkActionSetName, NamePattern: "old-{name}"},
+	}
+
+	job := req.ToModel()
+	require.Equal(t, model.DeviceFilter{
+		Brands:    []model.Brand{"brand1"},
+		Name:      &model.NameFilter{Match: model.NameMatchPrefix, Value: "io"},
+		CreatedAt: model.TimeRange{Gte: &from},
+	}, job.Filter)
+	require.Equal(t, model.BulkAction{Type: model.BulkActionSetName, NamePattern: "old-{name}"}, job.Action)
+
+	job.ID = primitive.NewObjectID()
+	job.Status = model.JobRunning
+	deviceID := primitive.NewObjectID()
+	job.Errors = []model.BulkJobError{{DeviceID: deviceID, Message: "failed"}}
+	res := ToBulkJobDTO(job)
+	require.Equal(t, job.ID.Hex(), res.ID)
+	require.Equal(t, req.Filter, res.Filter)
+	require.Equal(t, req.Action, res.Action)
+	require.Equal(t, []BulkJobErrorDTO{{DeviceID: deviceID.Hex(), Message: "failed"}}, res.Errors)
+}
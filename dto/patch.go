@@ -0,0 +1,425 @@
+package dto
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+)
+
+// Patch media types
+const (
+	MergePatchMediaType = "application/merge-patch+json"
+	JSONPatchMediaType  = "application/json-patch+json"
+)
+
+// JSON patch operations
+const (
+	opAdd     = "add"
+	opRemove  = "remove"
+	opReplace = "replace"
+	opMove    = "move"
+	opCopy    = "copy"
+	opTest    = "test"
+)
+
+// patchableDeviceFields are the device fields a patch can change
+var patchableDeviceFields = []string{"name", "brand", "serialNumber", "externalIds", "attributes"}
+
+func isPatchableDeviceField(field string) bool {
+	for _, patchable := range patchableDeviceFields {
+		if field == patchable {
+			return true
+		}
+	}
+	return false
+}
+
+// DevicePatchDTO is a patch of the updatable fields of a device
+type DevicePatchDTO interface {
+	// Validate validates the patch itself, without the device it applies to
+	Validate() error
+	// Apply returns the update request resulting of patching the device
+	Apply(device *model.Device) (UpdateDeviceRequestDTO, error)
+}
+
+// devicePatchDocument is the JSON document of the patchable fields of a device
+type devicePatchDocument map[string]interface{}
+
+func newDevicePatchDocument(device *model.Device) devicePatchDocument {
+	doc := devicePatchDocument{
+		"name":  device.Name,
+		"brand": string(device.Brand),
+	}
+	if device.SerialNumber != "" {
+		doc["serialNumber"] = device.SerialNumber
+	}
+	if ids := device.ExternalIDsBySystem(); ids != nil {
+		externalIDs := make(map[string]interface{}, len(ids))
+		for system, value := range ids {
+			externalIDs[system] = value
+		}
+		doc["externalIds"] = externalIDs
+	}
+	if len(device.Attributes) > 0 {
+		doc["attributes"] = map[string]interface{}(device.Attributes)
+	}
+	return doc
+}
+
+// updateRequest maps the patched document to an update request, a removed field being empty
+func (doc devicePatchDocument) updateRequest(device *model.Device) (UpdateDeviceRequestDTO, error) {
+	var errs []error
+	fields := make(map[string]string, len(patchableDeviceFields))
+	for _, field := range patchableDeviceFields {
+		value, ok := doc[field]
+		if !ok || field == "externalIds" || field == "attributes" {
+			continue
+		}
+		s, ok := value.(string)
+		if !ok {
+			errs = append(errs, errors.InvalidParameterError(field, "expected a string"))
+			continue
+		}
+		fields[field] = s
+	}
+	externalIDs, err := doc.externalIDs()
+	errs = append(errs, err)
+	attributes, err := doc.attributes()
+	errs = append(errs, err)
+	if err := errors.Join(errs...); err != nil {
+		return UpdateDeviceRequestDTO{}, err
+	}
+
+	return UpdateDeviceRequestDTO{
+		DeviceID:     device.ID,
+		Name:         fields["name"],
+		Brand:        model.Brand(fields["brand"]),
+		SerialNumber: fields["serialNumber"],
+		ExternalIDs:  externalIDs,
+		Attributes:   attributes,
+	}, nil
+}
+
+// externalIDs maps the patched external ids, an object of a value per system
+func (doc devicePatchDocument) externalIDs() (map[string]string, error) {
+	value, ok := doc["externalIds"]
+	if !ok {
+		return nil, nil
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.InvalidParameterError("externalIds", "expected an object")
+	}
+	var errs []error
+	systems := make([]string, 0, len(object))
+	for system := range object {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+	externalIDs := make(map[string]string, len(object))
+	for _, system := range systems {
+		s, ok := object[system].(string)
+		if !ok {
+			errs = append(errs, errors.InvalidParameterError("externalIds."+system, "expected a string"))
+			continue
+		}
+		externalIDs[system] = s
+	}
+	return externalIDs, errors.Join(errs...)
+}
+
+// attributes maps the patched attributes, an object of any values
+func (doc devicePatchDocument) attributes() (model.Attributes, error) {
+	value, ok := doc["attributes"]
+	if !ok {
+		return nil, nil
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.InvalidParameterError("attributes", "expected an object")
+	}
+	return object, nil
+}
+
+// MergePatchDTO is a JSON merge patch (RFC 7396) of a device: a null member removes the field
+type MergePatchDTO map[string]json.RawMessage
+
+// Validate validates the merge patch members
+func (p MergePatchDTO) Validate() error {
+	var errs []error
+	for _, field := range sortedKeys(p) {
+		if !isPatchableDeviceField(field) {
+			errs = append(errs, errors.InvalidParameterError(field, "unknown field"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply merges the patch into the device
+func (p MergePatchDTO) Apply(device *model.Device) (UpdateDeviceRequestDTO, error) {
+	doc := newDevicePatchDocument(device)
+	var errs []error
+	for _, field := range sortedKeys(p) {
+		var value interface{}
+		if err := json.Unmarshal(p[field], &value); err != nil {
+			errs = append(errs, errors.InvalidParameterError(field, "invalid value"))
+			continue
+		}
+		if value == nil {
+			delete(doc, field)
+			continue
+		}
+		doc[field] = mergeValue(doc[field], value)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return UpdateDeviceRequestDTO{}, err
+	}
+	return doc.updateRequest(device)
+}
+
+// mergeValue merges a patch value into a document value: objects are merged member by member, a null member
+// removing it, and any other value replaces the document one
+func mergeValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	merged := make(map[string]interface{}, len(targetObject)+len(patchObject))
+	for key, value := range targetObject {
+		merged[key] = value
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = mergeValue(merged[key], value)
+	}
+	return merged
+}
+
+// JSONPatchOperationDTO is an operation of a JSON patch
+type JSONPatchOperationDTO struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatchDTO is a JSON patch (RFC 6902) of a device, its operations are applied in order
+type JSONPatchDTO []JSONPatchOperationDTO
+
+// Validate validates the operations of the patch
+func (p JSONPatchDTO) Validate() error {
+	var errs []error
+	for i, op := range p {
+		field := "operations[" + strconv.Itoa(i) + "]"
+		switch op.Op {
+		case opAdd, opReplace, opTest:
+			if op.Value == nil {
+				errs = append(errs, errors.RequiredParameterError(field+".value", "body"))
+			}
+		case opMove, opCopy:
+			if _, ok := patchPointer(op.From); !ok {
+				errs = append(errs, errors.InvalidParameterError(field+".from", "unknown field ["+op.From+"]"))
+			}
+		case opRemove:
+		default:
+			errs = append(errs, errors.InvalidParameterError(field+".op", "unsupported operation ["+op.Op+"]"))
+		}
+		if _, ok := patchPointer(op.Path); !ok {
+			errs = append(errs, errors.InvalidParameterError(field+".path", "unknown field ["+op.Path+"]"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply applies the operations of the patch to the device
+func (p JSONPatchDTO) Apply(device *model.Device) (UpdateDeviceRequestDTO, error) {
+	doc := newDevicePatchDocument(device).normalized()
+	for i, op := range p {
+		field := "operations[" + strconv.Itoa(i) + "]"
+		path, _ := patchPointer(op.Path)
+		from, _ := patchPointer(op.From)
+
+		var value interface{}
+		if op.Value != nil {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return UpdateDeviceRequestDTO{}, errors.InvalidParameterError(field+".value", "invalid value")
+			}
+		}
+
+		switch op.Op {
+		case opAdd, opRemove, opReplace:
+			if !patchValueAt(map[string]interface{}(doc), path, op.Op, value) {
+				return UpdateDeviceRequestDTO{}, errors.InvalidParameterError(field+".path", "no value at ["+op.Path+"]")
+			}
+		case opMove, opCopy:
+			fromValue, ok := valueAt(map[string]interface{}(doc), from)
+			if !ok {
+				return UpdateDeviceRequestDTO{}, errors.InvalidParameterError(field+".from", "no value at ["+op.From+"]")
+			}
+			if op.Op == opMove {
+				if isPointerPrefix(from, path) {
+					return UpdateDeviceRequestDTO{}, errors.InvalidParameterError(field+".from", "cannot move ["+op.From+"] into itself")
+				}
+				patchValueAt(map[string]interface{}(doc), from, opRemove, nil)
+			} else {
+				fromValue = normalizedJSON(fromValue)
+			}
+			if !patchValueAt(map[string]interface{}(doc), path, opAdd, fromValue) {
+				return UpdateDeviceRequestDTO{}, errors.InvalidParameterError(field+".path", "no value at ["+op.Path+"]")
+			}
+		case opTest:
+			if current, ok := valueAt(map[string]interface{}(doc), path); !ok || !reflect.DeepEqual(current, value) {
+				return UpdateDeviceRequestDTO{}, errors.PatchTestFailedError(op.Path)
+			}
+		}
+	}
+	return doc.updateRequest(device)
+}
+
+// patchPointer returns the reference tokens of a JSON pointer (RFC 6901) to a patchable device field or, within
+// the external ids and the attributes, to one of their members
+func patchPointer(pointer string) ([]string, bool) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	if !isPatchableDeviceField(tokens[0]) {
+		return nil, false
+	}
+	return tokens, len(tokens) == 1 || tokens[0] == "externalIds" || tokens[0] == "attributes"
+}
+
+// isPointerPrefix tells whether a pointer references a proper ancestor of another
+func isPointerPrefix(prefix, tokens []string) bool {
+	return len(prefix) < len(tokens) && reflect.DeepEqual(prefix, tokens[:len(prefix)])
+}
+
+// valueAt returns the value the reference tokens of a pointer lead to within a JSON value
+func valueAt(value interface{}, tokens []string) (interface{}, bool) {
+	for _, token := range tokens {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			member, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+			value = member
+		case []interface{}:
+			i, ok := arrayIndex(token, len(v)-1)
+			if !ok {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// patchValueAt adds, removes or replaces the value the reference tokens of a pointer lead to within an object,
+// telling whether the pointer references a location the operation applies to
+func patchValueAt(object map[string]interface{}, tokens []string, op string, value interface{}) bool {
+	parent, ok := valueAt(object, tokens[:len(tokens)-1])
+	if !ok {
+		return false
+	}
+	token := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := p[token]; !ok && op != opAdd {
+			return false
+		}
+		if op == opRemove {
+			delete(p, token)
+		} else {
+			p[token] = value
+		}
+		return true
+	case []interface{}:
+		var array []interface{}
+		switch op {
+		case opAdd:
+			i, ok := len(p), token == "-"
+			if !ok {
+				i, ok = arrayIndex(token, len(p))
+			}
+			if !ok {
+				return false
+			}
+			array = append(append(append(make([]interface{}, 0, len(p)+1), p[:i]...), value), p[i:]...)
+		case opRemove:
+			i, ok := arrayIndex(token, len(p)-1)
+			if !ok {
+				return false
+			}
+			array = append(append(make([]interface{}, 0, len(p)-1), p[:i]...), p[i+1:]...)
+		default:
+			i, ok := arrayIndex(token, len(p)-1)
+			if !ok {
+				return false
+			}
+			p[i] = value
+			return true
+		}
+		// an array changing of length is replaced in its own parent
+		return patchValueAt(object, tokens[:len(tokens)-1], opReplace, array)
+	}
+	return false
+}
+
+// arrayIndex parses an array index token (RFC 6901), at most max
+func arrayIndex(token string, max int) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, false
+	}
+	i, err := strconv.Atoi(token)
+	return i, err == nil && i <= max
+}
+
+// normalized returns a copy of the document holding JSON values only, so its values compare with the patch ones
+// whatever their type in the database
+func (doc devicePatchDocument) normalized() devicePatchDocument {
+	if object, ok := normalizedJSON(map[string]interface{}(doc)).(map[string]interface{}); ok {
+		return object
+	}
+	return doc
+}
+
+// normalizedJSON returns a copy of a value as decoded from its JSON encoding
+func normalizedJSON(value interface{}) interface{} {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// sortedKeys returns the members of a merge patch in order, so errors are reported in a stable order
+func sortedKeys(p MergePatchDTO) []string {
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,160 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMergePatchDTO(t *testing.T) {
+	device := &model.Device{ID: primitive.NewObjectID(), Name: "earth", Brand: "brand1"}
+	decode := func(t *testing.T, body string) MergePatchDTO {
+		var patch MergePatchDTO
+		require.NoError(t, json.Unmarshal([]byte(body), &patch))
+		return patch
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		patch := decode(t, `{"name":"mars"}`)
+		require.NoError(t, patch.Validate())
+		upd, err := patch.Apply(device)
+		require.NoError(t, err)
+		require.Equal(t, UpdateDeviceRequestDTO{DeviceID: device.ID, Name: "mars", Brand: "brand1"}, upd)
+	})
+
+	t.Run("null removes the field", func(t *testing.T) {
+		upd, err := decode(t, `{"name":null,"brand":"brand2"}`).Apply(device)
+		require.NoError(t, err)
+		require.Equal(t, UpdateDeviceRequestDTO{DeviceID: device.ID, Brand: "brand2"}, upd)
+	})
+
+	t.Run("unknown fields", func(t *testing.T) {
+		err := decode(t, `{"id":"1","createdAt":"2024-12-01T00:00:00Z","name":"mars"}`).Validate()
+		require.EqualError(t, err, "result: false; code: 1500013; message: the request has 2 invalid parameters")
+	})
+
+	t.Run("not a string", func(t *testing.T) {
+		_, err := decode(t, `{"name":1}`).Apply(device)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'name' is invalid 'expected a string'")
+	})
+
+	t.Run("external ids are merged", func(t *testing.T) {
+		identified := *device
+		identified.SerialNumber = "SN-1"
+		identified.ExternalIDs = model.NewExternalIDs(map[string]string{"erp": "E-1", "assetTag": "A-1"})
+		upd, err := decode(t, `{"serialNumber":null,"externalIds":{"erp":"E-2","assetTag":null,"crm":"C-1"}}`).Apply(&identified)
+		require.NoError(t, err)
+		require.Equal(t, UpdateDeviceRequestDTO{
+			DeviceID:    device.ID,
+			Name:        "earth",
+			Brand:       "brand1",
+			ExternalIDs: map[string]string{"erp": "E-2", "crm": "C-1"},
+		}, upd)
+	})
+
+	t.Run("external id not a string", func(t *testing.T) {
+		_, err := decode(t, `{"externalIds":{"erp":1}}`).Apply(device)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'externalIds.erp' is invalid 'expected a string'")
+	})
+
+	t.Run("attributes are merged", func(t *testing.T) {
+		specified := *device
+		specified.Attributes = model.Attributes{"ram": 8.0, "screen": map[string]interface{}{"size": 6.1, "hdr": true}}
+		upd, err := decode(t, `{"attributes":{"ram":null,"os":"ios","screen":{"hdr":null}}}`).Apply(&specified)
+		require.NoError(t, err)
+		require.Equal(t, model.Attributes{"os": "ios", "screen": map[string]interface{}{"size": 6.1}}, upd.Attributes)
+		require.Equal(t, model.Attributes{"ram": 8.0, "screen": map[string]interface{}{"size": 6.1, "hdr": true}}, specified.Attributes)
+	})
+
+	t.Run("attributes not an object", func(t *testing.T) {
+		_, err := decode(t, `{"attributes":[1]}`).Apply(device)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'attributes' is invalid 'expected an object'")
+	})
+}
+
+func TestJSONPatchDTO(t *testing.T) {
+	device := &model.Device{ID: primitive.NewObjectID(), Name: "earth", Brand: "brand1"}
+	decode := func(t *testing.T, body string) JSONPatchDTO {
+		var patch JSONPatchDTO
+		require.NoError(t, json.Unmarshal([]byte(body), &patch))
+		return patch
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		patch := decode(t, `[
+			{"op":"test","path":"/name","value":"earth"},
+			{"op":"replace","path":"/name","value":"mars"},
+			{"op":"add","path":"/brand","value":"brand2"}
+		]`)
+		require.NoError(t, patch.Validate())
+		upd, err := patch.Apply(device)
+		require.NoError(t, err)
+		require.Equal(t, UpdateDeviceRequestDTO{DeviceID: device.ID, Name: "mars", Brand: "brand2"}, upd)
+	})
+
+	t.Run("move and copy", func(t *testing.T) {
+		upd, err := decode(t, `[{"op":"copy","from":"/brand","path":"/name"}]`).Apply(device)
+		require.NoError(t, err)
+		require.Equal(t, UpdateDeviceRequestDTO{DeviceID: device.ID, Name: "brand1", Brand: "brand1"}, upd)
+
+		upd, err = decode(t, `[{"op":"move","from":"/name","path":"/brand"}]`).Apply(device)
+		require.NoError(t, err)
+		require.Equal(t, UpdateDeviceRequestDTO{DeviceID: device.ID, Brand: "earth"}, upd)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		upd, err := decode(t, `[{"op":"remove","path":"/name"}]`).Apply(device)
+		require.NoError(t, err)
+		require.Equal(t, UpdateDeviceRequestDTO{DeviceID: device.ID, Brand: "brand1"}, upd)
+
+		_, err = decode(t, `[{"op":"remove","path":"/name"},{"op":"replace","path":"/name","value":"mars"}]`).Apply(device)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'operations[1].path' is invalid 'no value at [/name]'")
+	})
+
+	t.Run("test failed", func(t *testing.T) {
+		_, err := decode(t, `[{"op":"test","path":"/name","value":"mars"},{"op":"replace","path":"/name","value":"venus"}]`).Apply(device)
+		require.EqualError(t, err, "result: false; code: 1500018; message: the value at /name is not the one tested")
+	})
+
+	t.Run("nested attributes and external ids", func(t *testing.T) {
+		device := &model.Device{
+			ID: device.ID, Name: "earth", Brand: "brand1",
+			ExternalIDs: []model.ExternalID{{System: "erp", Value: "e1"}},
+			Attributes:  model.Attributes{"size": int32(3), "tags": []interface{}{"a", "b"}, "a/b": "c"},
+		}
+		upd, err := decode(t, `[
+			{"op":"test","path":"/attributes/size","value":3},
+			{"op":"replace","path":"/attributes/size","value":4},
+			{"op":"add","path":"/attributes/tags/1","value":"x"},
+			{"op":"add","path":"/attributes/tags/-","value":"z"},
+			{"op":"remove","path":"/attributes/a~1b"},
+			{"op":"copy","from":"/externalIds/erp","path":"/externalIds/crm"},
+			{"op":"test","path":"/attributes","value":{"size":4,"tags":["a","x","b","z"]}}
+		]`).Apply(device)
+		require.NoError(t, err)
+		require.Equal(t, model.Attributes{"size": float64(4), "tags": []interface{}{"a", "x", "b", "z"}}, upd.Attributes)
+		require.Equal(t, map[string]string{"erp": "e1", "crm": "e1"}, upd.ExternalIDs)
+		require.Equal(t, int32(3), device.Attributes["size"])
+
+		_, err = decode(t, `[{"op":"replace","path":"/attributes/color","value":"red"}]`).Apply(device)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'operations[0].path' is invalid 'no value at [/attributes/color]'")
+		_, err = decode(t, `[{"op":"add","path":"/attributes/tags/5","value":"x"}]`).Apply(device)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'operations[0].path' is invalid 'no value at [/attributes/tags/5]'")
+		_, err = decode(t, `[{"op":"move","from":"/attributes","path":"/attributes/x"}]`).Apply(device)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'operations[0].from' is invalid 'cannot move [/attributes] into itself'")
+	})
+
+	t.Run("invalid operations", func(t *testing.T) {
+		err := decode(t, `[
+			{"op":"replace","path":"/id","value":"1"},
+			{"op":"increment","path":"/name"},
+			{"op":"add","path":"/name"},
+			{"op":"move","path":"/name"},
+			{"op":"remove","path":"/name/first"}
+		]`).Validate()
+		require.EqualError(t, err, "result: false; code: 1500013; message: the request has 5 invalid parameters")
+	})
+}
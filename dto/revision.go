@@ -0,0 +1,172 @@
+package dto
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/device-ms/model"
+)
+
+// DeviceRevisionDTO is a revision of a device, with the fields it changed
+type DeviceRevisionDTO struct {
+	DeviceID  string                  `json:"deviceId"`
+	Revision  int64                   `json:"revision"`
+	Operation model.RevisionOperation `json:"operation"`
+	Actor     string                  `json:"actor,omitempty"`
+	Reason    string                  `json:"reason,omitempty"`
+	At        time.Time               `json:"at"`
+	Before    *DeviceDTO              `json:"before,omitempty"`
+	After     *DeviceDTO              `json:"after"`
+	Changes   []FieldChangeDTO        `json:"changes"`
+}
+
+// FieldChangeDTO is the change of a device field in a revision, a missing value being null
+type FieldChangeDTO struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ToDeviceRevisionDTO maps a device revision model to a device revision dto response
+func ToDeviceRevisionDTO(m *model.DeviceRevision) *DeviceRevisionDTO {
+	dto := DeviceRevisionDTO{
+		DeviceID:  m.DeviceID.Hex(),
+		Revision:  m.Revision,
+		Operation: m.Operation,
+		Actor:     m.Actor,
+		Reason:    m.Reason,
+		At:        m.At,
+		After:     ToDeviceDTO(m.After),
+		Changes:   diffDevices(m.Before, m.After),
+	}
+	if m.Before != nil {
+		dto.Before = ToDeviceDTO(m.Before)
+	}
+
+	return &dto
+}
+
+// diffDevices returns the changes of the fields of a device, before being nil for its creation
+func diffDevices(before, after *model.Device) []FieldChangeDTO {
+	var b model.Device
+	if before != nil {
+		b = *before
+	}
+	changes := make([]FieldChangeDTO, 0)
+	if before == nil || b.Name != after.Name {
+		changes = append(changes, FieldChangeDTO{Field: "name", Before: fieldValue(before, b.Name), After: after.Name})
+	}
+	if before == nil || b.Brand != after.Brand {
+		changes = append(changes, FieldChangeDTO{Field: "brand", Before: fieldValue(before, b.Brand), After: after.Brand})
+	}
+	if b.SerialNumber != after.SerialNumber {
+		changes = append(changes, FieldChangeDTO{Field: "serialNumber", Before: optionalValue(b.SerialNumber), After: optionalValue(after.SerialNumber)})
+	}
+	if before, after := b.ExternalIDsBySystem(), after.ExternalIDsBySystem(); !reflect.DeepEqual(before, after) {
+		changes = append(changes, FieldChangeDTO{Field: "externalIds", Before: externalIDsValue(before), After: externalIDsValue(after)})
+	}
+	if before != nil && b.CurrentState() != after.CurrentState() {
+		changes = append(changes, FieldChangeDTO{Field: "state", Before: b.CurrentState(), After: after.CurrentState()})
+	}
+	if !reflect.DeepEqual(b.Labels, after.Labels) {
+		changes = append(changes, FieldChangeDTO{Field: "labels", Before: labelsValue(b.Labels), After: labelsValue(after.Labels)})
+	}
+	if !reflect.DeepEqual(b.Attributes, after.Attributes) {
+		changes = append(changes, FieldChangeDTO{Field: "attributes", Before: attributesValue(b.Attributes), After: attributesValue(after.Attributes)})
+	}
+	if parentBefore, parentAfter := hex(b.ParentID), hex(after.ParentID); parentBefore != parentAfter {
+		changes = append(changes, FieldChangeDTO{Field: "parentId", Before: optionalValue(parentBefore), After: optionalValue(parentAfter)})
+	}
+	if !reflect.DeepEqual(b.Location, after.Location) {
+		changes = append(changes, FieldChangeDTO{Field: "location", Before: locationValue(b.Location), After: locationValue(after.Location)})
+	}
+	if !reflect.DeepEqual(b.Lease, after.Lease) {
+		changes = append(changes, FieldChangeDTO{Field: "lease", Before: leaseValue(b.Lease), After: leaseValue(after.Lease)})
+	}
+	if (b.DeletedAt == nil) != (after.DeletedAt == nil) {
+		changes = append(changes, FieldChangeDTO{Field: "deletedAt", Before: timeValue(b.DeletedAt), After: timeValue(after.DeletedAt)})
+	}
+	return changes
+}
+
+// fieldValue returns the value of a field of a device, nil when there is no device
+func fieldValue(device *model.Device, value interface{}) interface{} {
+	if device == nil {
+		return nil
+	}
+	return value
+}
+
+// optionalValue returns the value of an optional field, nil when it is empty
+func optionalValue(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// externalIDsValue returns the external ids of a device by system, nil when there are none
+func externalIDsValue(bySystem map[string]string) interface{} {
+	if bySystem == nil {
+		return nil
+	}
+	return bySystem
+}
+
+// labelsValue returns the labels of a device, nil when there are none
+func labelsValue(labels map[string]string) interface{} {
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
+}
+
+// attributesValue returns the attributes of a device, nil when there are none
+func attributesValue(attributes model.Attributes) interface{} {
+	if len(attributes) == 0 {
+		return nil
+	}
+	return attributes
+}
+
+// locationValue returns the location of a device, nil when it has none
+func locationValue(location *model.Location) interface{} {
+	if location == nil {
+		return nil
+	}
+	return ToLocationDTO(location)
+}
+
+// leaseValue returns the lease of a device, nil when it is not checked out
+func leaseValue(lease *model.Lease) interface{} {
+	if lease == nil {
+		return nil
+	}
+	return ToLeaseDTO(lease)
+}
+
+func timeValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// RevisionPageDTO is a page of revisions
+type RevisionPageDTO struct {
+	Items      []DeviceRevisionDTO `json:"items"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
+
+// ToRevisionPageDTO maps a revision page model to a revision page dto response
+func ToRevisionPageDTO(m *model.RevisionPage) *RevisionPageDTO {
+	dto := RevisionPageDTO{
+		Items:      make([]DeviceRevisionDTO, len(m.Revisions)),
+		NextCursor: m.NextCursor,
+	}
+	for i := range m.Revisions {
+		dto.Items[i] = *ToDeviceRevisionDTO(&m.Revisions[i])
+	}
+
+	return &dto
+}
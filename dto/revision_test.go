@@ -0,0 +1,129 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestToDeviceRevisionDTO(t *testing.T) {
+	createdAt := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	created := model.Device{ID: primitive.NewObjectID(), Name: "io", Brand: "brand1", CreatedAt: createdAt, Version: 1}
+
+	t.Run("creation", func(t *testing.T) {
+		rev := ToDeviceRevisionDTO(model.NewDeviceRevision(model.OperationCreate, "alice", model.DeviceChange{After: &created}))
+		require.Equal(t, created.ID.Hex(), rev.DeviceID)
+		require.Equal(t, int64(1), rev.Revision)
+		require.Equal(t, createdAt, rev.At)
+		require.Nil(t, rev.Before)
+		require.Equal(t, []FieldChangeDTO{
+			{Field: "name", Before: nil, After: "io"},
+			{Field: "brand", Before: nil, After: model.Brand("brand1")},
+		}, rev.Changes)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		updated := created
+		updated.Brand = "brand2"
+		updated.Version = 2
+		rev := ToDeviceRevisionDTO(model.NewDeviceRevision(model.OperationUpdateBrand, "", model.DeviceChange{Before: &created, After: &updated}))
+		require.Equal(t, "io", rev.Before.Name)
+		require.Equal(t, []FieldChangeDTO{{Field: "brand", Before: model.Brand("brand1"), After: model.Brand("brand2")}}, rev.Changes)
+	})
+
+	t.Run("identifiers", func(t *testing.T) {
+		identified := created
+		identified.SerialNumber = "SN-1"
+		identified.ExternalIDs = []model.ExternalID{{System: "erp", Value: "E-1"}}
+		identified.Version = 2
+		rev := ToDeviceRevisionDTO(model.NewDeviceRevision(model.OperationPatch, "", model.DeviceChange{Before: &created, After: &identified}))
+		require.Equal(t, []FieldChangeDTO{
+			{Field: "serialNumber", Before: nil, After: "SN-1"},
+			{Field: "externalIds", Before: nil, After: map[string]string{"erp": "E-1"}},
+		}, rev.Changes)
+		require.Equal(t, map[string]string{"erp": "E-1"}, rev.After.ExternalIDs)
+	})
+
+	t.Run("transition", func(t *testing.T) {
+		inUse := created
+		inUse.State = model.StateInUse
+		inUse.Version = 2
+		revision := model.NewDeviceRevision(model.OperationTransition, "alice", model.DeviceChange{Before: &created, After: &inUse})
+		revision.Reason = "assigned to bob"
+		rev := ToDeviceRevisionDTO(revision)
+		require.Equal(t, "assigned to bob", rev.Reason)
+		require.Equal(t, []FieldChangeDTO{
+			{Field: "state", Before: model.StateInStock, After: model.StateInUse},
+		}, rev.Changes)
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		labelled := created
+		labelled.Labels = map[string]string{"env": "prod"}
+		labelled.Version = 2
+		rev := ToDeviceRevisionDTO(model.NewDeviceRevision(model.OperationSetLabel, "", model.DeviceChange{Before: &created, After: &labelled}))
+		require.Equal(t, []FieldChangeDTO{
+			{Field: "labels", Before: nil, After: map[string]string{"env": "prod"}},
+		}, rev.Changes)
+		require.Equal(t, map[string]string{"env": "prod"}, rev.After.Labels)
+	})
+
+	t.Run("attributes", func(t *testing.T) {
+		specified := created
+		specified.Attributes = model.Attributes{"ram": 8.0}
+		specified.Version = 2
+		rev := ToDeviceRevisionDTO(model.NewDeviceRevision(model.OperationUpdate, "", model.DeviceChange{Before: &created, After: &specified}))
+		require.Equal(t, []FieldChangeDTO{
+			{Field: "attributes", Before: nil, After: model.Attributes{"ram": 8.0}},
+		}, rev.Changes)
+	})
+
+	t.Run("parent", func(t *testing.T) {
+		parentID := primitive.NewObjectID()
+		attached := created
+		attached.ParentID = &parentID
+		attached.Version = 2
+		rev := ToDeviceRevisionDTO(model.NewDeviceRevision(model.OperationSetParent, "", model.DeviceChange{Before: &created, After: &attached}))
+		require.Equal(t, []FieldChangeDTO{
+			{Field: "parentId", Before: nil, After: parentID.Hex()},
+		}, rev.Changes)
+		require.Equal(t, parentID.Hex(), rev.After.ParentID)
+	})
+
+	t.Run("location", func(t *testing.T) {
+		located := created
+		located.Location = &model.Location{Site: "paris", Point: model.NewGeoPoint(model.GeoPosition{Lat: 48.8584, Lng: 2.2945})}
+		located.Version = 2
+		rev := ToDeviceRevisionDTO(model.NewDeviceRevision(model.OperationUpdateLocation, "", model.DeviceChange{Before: &created, After: &located}))
+		location := &LocationDTO{Site: "paris", Point: &GeoPointDTO{Type: "Point", Coordinates: []float64{2.2945, 48.8584}}}
+		require.Equal(t, []FieldChangeDTO{
+			{Field: "location", Before: nil, After: location},
+		}, rev.Changes)
+		require.Equal(t, location, rev.After.Location)
+	})
+
+	t.Run("checkout", func(t *testing.T) {
+		checkedOut := created
+		checkedOut.Lease = &model.Lease{Assignee: "bob", CheckedOutAt: createdAt, DueAt: createdAt.AddDate(0, 0, 7)}
+		checkedOut.Version = 2
+		rev := ToDeviceRevisionDTO(model.NewDeviceRevision(model.OperationCheckout, "alice", model.DeviceChange{Before: &created, After: &checkedOut}))
+		require.Equal(t, []FieldChangeDTO{
+			{Field: "lease", Before: nil, After: &LeaseDTO{Assignee: "bob", CheckedOutAt: createdAt, DueAt: createdAt.AddDate(0, 0, 7)}},
+		}, rev.Changes)
+		require.Nil(t, rev.Before.Lease)
+		require.Equal(t, "bob", rev.After.Lease.Assignee)
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		deletedAt := createdAt.AddDate(0, 0, 1)
+		deleted := created
+		deleted.DeletedAt = &deletedAt
+		deleted.Version = 2
+		rev := ToDeviceRevisionDTO(model.NewDeviceRevision(model.OperationDelete, "", model.DeviceChange{Before: &created, After: &deleted}))
+		require.Equal(t, deletedAt, rev.At)
+		require.Equal(t, []FieldChangeDTO{{Field: "deletedAt", Before: nil, After: deletedAt}}, rev.Changes)
+	})
+}
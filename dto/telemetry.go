@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/device-ms/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TelemetrySampleDTO is the values of the metrics of a device at a time
+type TelemetrySampleDTO struct {
+	At      *time.Time         `json:"at,omitempty"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// AddTelemetryRequestDTO request when a device posts telemetry samples
+type AddTelemetryRequestDTO struct {
+	DeviceID primitive.ObjectID   `json:"-"`
+	Samples  []TelemetrySampleDTO `json:"samples"`
+}
+
+// ToModel maps the samples of the request to telemetry sample models, the samples without time being taken now.
+// The times are kept to the millisecond, the precision of the database.
+func (req AddTelemetryRequestDTO) ToModel(now time.Time) []model.TelemetrySample {
+	samples := make([]model.TelemetrySample, len(req.Samples))
+	for i, sample := range req.Samples {
+		at := now
+		if sample.At != nil {
+			at = *sample.At
+		}
+		samples[i] = model.TelemetrySample{
+			DeviceID: req.DeviceID,
+			At:       at.UTC().Truncate(time.Millisecond),
+			Metrics:  sample.Metrics,
+		}
+	}
+	return samples
+}
+
+// TelemetrySeriesDTO is the points of a telemetry series, the oldest first
+type TelemetrySeriesDTO struct {
+	Items     []TelemetrySampleDTO `json:"items"`
+	Truncated bool                 `json:"truncated,omitempty"`
+}
+
+// ToTelemetrySeriesDTO maps a telemetry series model to a telemetry series dto response
+func ToTelemetrySeriesDTO(m *model.TelemetrySeries) *TelemetrySeriesDTO {
+	dto := TelemetrySeriesDTO{
+		Items:     make([]TelemetrySampleDTO, len(m.Points)),
+		Truncated: m.Truncated,
+	}
+	for i := range m.Points {
+		dto.Items[i] = TelemetrySampleDTO{At: &m.Points[i].At, Metrics: m.Points[i].Metrics}
+	}
+
+	return &dto
+}
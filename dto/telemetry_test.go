@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAddTelemetryRequestDTO(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	now := time.Date(2025, 1, 15, 12, 0, 0, 999999999, time.UTC)
+	at := time.Date(2025, 1, 15, 13, 0, 0, 1500000, time.FixedZone("CET", 3600))
+
+	req := AddTelemetryRequestDTO{DeviceID: deviceID, Samples: []TelemetrySampleDTO{
+		{Metrics: map[string]float64{"temperature": 21.5}},
+		{At: &at, Metrics: map[string]float64{"battery": 80}},
+	}}
+	require.Equal(t, []model.TelemetrySample{
+		{DeviceID: deviceID, At: time.Date(2025, 1, 15, 12, 0, 0, 999000000, time.UTC), Metrics: map[string]float64{"temperature": 21.5}},
+		{DeviceID: deviceID, At: time.Date(2025, 1, 15, 12, 0, 0, 1000000, time.UTC), Metrics: map[string]float64{"battery": 80}},
+	}, req.ToModel(now))
+}
+
+func TestToTelemetrySeriesDTO(t *testing.T) {
+	at := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	series := ToTelemetrySeriesDTO(&model.TelemetrySeries{
+		Points:    []model.TelemetryPoint{{At: at, Metrics: map[string]float64{"battery": 80}}},
+		Truncated: true,
+	})
+	require.Equal(t, &TelemetrySeriesDTO{Items: []TelemetrySampleDTO{{At: &at, Metrics: map[string]float64{"battery": 80}}}, Truncated: true}, series)
+
+	series = ToTelemetrySeriesDTO(&model.TelemetrySeries{})
+	require.NotNil(t, series.Items)
+	require.Empty(t, series.Items)
+}
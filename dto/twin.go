@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/device-ms/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TwinSideDTO is a side of a device twin, its properties and their version
+type TwinSideDTO struct {
+	Properties map[string]interface{} `json:"properties"`
+	Version    int64                  `json:"version"`
+	UpdatedAt  *time.Time             `json:"updatedAt,omitempty"`
+}
+
+// DeviceTwinDTO is the desired and reported sides of the twin of a device
+type DeviceTwinDTO struct {
+	DeviceID primitive.ObjectID `json:"deviceId"`
+	Desired  TwinSideDTO        `json:"desired"`
+	Reported TwinSideDTO        `json:"reported"`
+}
+
+// TwinDeltaDTO is the desired properties the device didn't report yet, with the versions of the sides compared
+type TwinDeltaDTO struct {
+	Properties      map[string]interface{} `json:"properties"`
+	DesiredVersion  int64                  `json:"desiredVersion"`
+	ReportedVersion int64                  `json:"reportedVersion"`
+}
+
+// ToTwinSideDTO maps a twin side model to a twin side dto response, a side never set having no properties
+func ToTwinSideDTO(m model.TwinSide) TwinSideDTO {
+	properties := map[string]interface{}(m.Properties)
+	if properties == nil {
+		properties = make(map[string]interface{})
+	}
+	return TwinSideDTO{
+		Properties: properties,
+		Version:    m.Version,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+// ToDeviceTwinDTO maps a device twin model to a device twin dto response
+func ToDeviceTwinDTO(m *model.DeviceTwin) *DeviceTwinDTO {
+	return &DeviceTwinDTO{
+		DeviceID: m.DeviceID,
+		Desired:  ToTwinSideDTO(m.Desired),
+		Reported: ToTwinSideDTO(m.Reported),
+	}
+}
+
+// ToTwinDeltaDTO maps the delta of a device twin model to a twin delta dto response
+func ToTwinDeltaDTO(m *model.DeviceTwin) *TwinDeltaDTO {
+	return &TwinDeltaDTO{
+		Properties:      m.Delta(),
+		DesiredVersion:  m.Desired.Version,
+		ReportedVersion: m.Reported.Version,
+	}
+}
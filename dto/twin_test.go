@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestToDeviceTwinDTO(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	at := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	twin := &model.DeviceTwin{
+		DeviceID: deviceID,
+		Desired:  model.TwinSide{Properties: model.TwinProperties{"interval": float64(60)}, Version: 2, UpdatedAt: &at},
+	}
+
+	require.Equal(t, &DeviceTwinDTO{
+		DeviceID: deviceID,
+		Desired:  TwinSideDTO{Properties: map[string]interface{}{"interval": float64(60)}, Version: 2, UpdatedAt: &at},
+		Reported: TwinSideDTO{Properties: map[string]interface{}{}},
+	}, ToDeviceTwinDTO(twin))
+
+	require.Equal(t, &TwinDeltaDTO{
+		Properties:     map[string]interface{}{"interval": float64(60)},
+		DesiredVersion: 2,
+	}, ToTwinDeltaDTO(twin))
+}
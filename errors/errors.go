@@ -1,27 +1,131 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"strings"
 )
 
 // Error prefix and codes
 const (
-	errorPrefix            = 1500
-	RequiredParameterCode  = 1
-	InvalidParameterCode   = 2
-	CreateErrorCode        = 3
-	ListErrorCode          = 4
-	CouldNotFindObjectCode = 5
-	UpdateErrorCode        = 6
-	DeleteErrorCode        = 7
-	DecodeErrorCode        = 8
+	errorPrefix              = 1500
+	RequiredParameterCode    = 1
+	InvalidParameterCode     = 2
+	CreateErrorCode          = 3
+	ListErrorCode            = 4
+	CouldNotFindObjectCode   = 5
+	UpdateErrorCode          = 6
+	DeleteErrorCode          = 7
+	DecodeErrorCode          = 8
+	BrandInUseCode           = 9
+	AlreadyExistsCode        = 10
+	ReadErrorCode            = 11
+	UnexpectedErrorCode      = 12
+	InvalidRequestCode       = 13
+	VersionMismatchCode      = 14
+	PreconditionRequiredCode = 15
+	UnsupportedMediaTypeCode = 16
+	ConcurrentChangeCode     = 17
+	PatchTestFailedCode      = 18
+	InvalidStateCode         = 19
+	IdempotencyKeyReusedCode = 20
+	RequestInProgressCode    = 21
+	IllegalTransitionCode    = 22
+	DeviceHasChildrenCode    = 23
+	UnauthorizedCode         = 24
+	PartialDeleteCode        = 25
 )
 
+// TypeURIPrefix is the prefix of the problem type URI of every error, followed by the error name
+const TypeURIPrefix = "urn:device-ms:error:"
+
+// Kind is the category of an error, used to choose how it is reported
+type Kind string
+
+// Error kinds
+const (
+	KindValidation Kind = "validation"
+	KindNotFound   Kind = "notFound"
+	KindConflict   Kind = "conflict"
+	KindStorage    Kind = "storage"
+	KindInternal   Kind = "internal"
+	// KindPreconditionFailed is a change conditioned on a version the object doesn't have anymore
+	KindPreconditionFailed Kind = "preconditionFailed"
+	// KindPreconditionRequired is a change that must be conditioned on the object version
+	KindPreconditionRequired Kind = "preconditionRequired"
+	// KindUnsupportedMediaType is a request body in a format the operation doesn't support
+	KindUnsupportedMediaType Kind = "unsupportedMediaType"
+	// KindUnprocessable is a well formed request that cannot be processed as it is
+	KindUnprocessable Kind = "unprocessable"
+	// KindUnauthorized is a request without valid credentials
+	KindUnauthorized Kind = "unauthorized"
+)
+
+var kindsByCode = map[int]Kind{
+	RequiredParameterCode:    KindValidation,
+	InvalidParameterCode:     KindValidation,
+	CreateErrorCode:          KindStorage,
+	ListErrorCode:            KindStorage,
+	CouldNotFindObjectCode:   KindNotFound,
+	UpdateErrorCode:          KindStorage,
+	DeleteErrorCode:          KindStorage,
+	DecodeErrorCode:          KindValidation,
+	BrandInUseCode:           KindConflict,
+	AlreadyExistsCode:        KindConflict,
+	ReadErrorCode:            KindStorage,
+	UnexpectedErrorCode:      KindInternal,
+	InvalidRequestCode:       KindValidation,
+	VersionMismatchCode:      KindPreconditionFailed,
+	PreconditionRequiredCode: KindPreconditionRequired,
+	UnsupportedMediaTypeCode: KindUnsupportedMediaType,
+	ConcurrentChangeCode:     KindConflict,
+	PatchTestFailedCode:      KindConflict,
+	InvalidStateCode:         KindConflict,
+	IdempotencyKeyReusedCode: KindUnprocessable,
+	RequestInProgressCode:    KindConflict,
+	IllegalTransitionCode:    KindConflict,
+	DeviceHasChildrenCode:    KindConflict,
+	UnauthorizedCode:         KindUnauthorized,
+	PartialDeleteCode:        KindConflict,
+}
+
+var namesByCode = map[int]string{
+	RequiredParameterCode:    "requiredParameter",
+	InvalidParameterCode:     "invalidParameter",
+	CreateErrorCode:          "createError",
+	ListErrorCode:            "listError",
+	CouldNotFindObjectCode:   "couldNotFindObject",
+	UpdateErrorCode:          "updateError",
+	DeleteErrorCode:          "deleteError",
+	DecodeErrorCode:          "decodeError",
+	BrandInUseCode:           "brandInUse",
+	AlreadyExistsCode:        "alreadyExists",
+	ReadErrorCode:            "readError",
+	UnexpectedErrorCode:      "unexpectedError",
+	InvalidRequestCode:       "invalidRequest",
+	VersionMismatchCode:      "versionMismatch",
+	PreconditionRequiredCode: "preconditionRequired",
+	UnsupportedMediaTypeCode: "unsupportedMediaType",
+	ConcurrentChangeCode:     "concurrentChange",
+	PatchTestFailedCode:      "patchTestFailed",
+	InvalidStateCode:         "invalidState",
+	IdempotencyKeyReusedCode: "idempotencyKeyReused",
+	RequestInProgressCode:    "requestInProgress",
+	IllegalTransitionCode:    "illegalTransition",
+	DeviceHasChildrenCode:    "deviceHasChildren",
+	UnauthorizedCode:         "unauthorized",
+	PartialDeleteCode:        "partialDelete",
+}
+
 type CustError struct {
 	Result  bool   `json:"result"`
 	Code    int64  `json:"code"`
 	Message string `json:"message"`
+	Kind    Kind   `json:"-"`
+	// Field is the parameter the error is about, if any
+	Field string `json:"-"`
+	// Details are the errors joined in this one
+	Details []CustError `json:"-"`
 }
 
 // Error returns the error message
@@ -29,23 +133,102 @@ func (e CustError) Error() string {
 	return fmt.Sprintf("result: %t; code: %d; message: %s", e.Result, e.Code, e.Message)
 }
 
+// Name returns the name of the error, as documented in the error code table
+func (e CustError) Name() string {
+	if name, ok := namesByCode[int(e.Code%1000)]; ok {
+		return name
+	}
+	return namesByCode[UnexpectedErrorCode]
+}
+
+// TypeURI returns the URI identifying the error type in a problem details response
+func (e CustError) TypeURI() string {
+	return TypeURIPrefix + e.Name()
+}
+
+// FieldErrors returns the errors about a parameter that are part of this error
+func (e CustError) FieldErrors() []CustError {
+	if len(e.Details) > 0 {
+		return e.Details
+	}
+	if e.Field != "" {
+		return []CustError{e}
+	}
+	return nil
+}
+
+// KindOf returns the kind of err, KindInternal when err is not a CustError
+func KindOf(err error) Kind {
+	var custErr CustError
+	if stderrors.As(err, &custErr) && custErr.Kind != "" {
+		return custErr.Kind
+	}
+	return KindInternal
+}
+
+// Wrap returns err as a CustError, wrapping it in an unexpected error when it is not one
+func Wrap(err error) CustError {
+	var custErr CustError
+	if stderrors.As(err, &custErr) {
+		return custErr
+	}
+	return UnexpectedError(err).(CustError)
+}
+
+// Join joins the non nil errors found validating a request.
+// It returns nil when there is none, the error itself when there is only one,
+// and an invalid request error with all of them as details otherwise.
+func Join(errs ...error) error {
+	var details []CustError
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		custErr := Wrap(err)
+		if len(custErr.Details) > 0 {
+			details = append(details, custErr.Details...)
+			continue
+		}
+		details = append(details, custErr)
+	}
+
+	switch len(details) {
+	case 0:
+		return nil
+	case 1:
+		return details[0]
+	}
+	custErr := newCustError(errorPrefix, InvalidRequestCode, fmt.Sprintf("the request has %d invalid parameters", len(details)))
+	custErr.Details = details
+	return custErr
+}
+
 // NewError creates an error using ms standard
 func newError(prefix, code int, message string) error {
+	return newCustError(prefix, code, message)
+}
+
+func newCustError(prefix, code int, message string) CustError {
 	return CustError{
 		Result:  false,
 		Code:    int64((prefix * 1000) + code),
 		Message: message,
+		Kind:    kindsByCode[code],
 	}
 }
 
 // RequiredParameterError parameter 'field' is required
 func RequiredParameterError(field, in string) error {
-	return newError(errorPrefix, RequiredParameterCode, fmt.Sprintf("parameter '%s' in %s is required", field, in))
+	custErr := newCustError(errorPrefix, RequiredParameterCode, fmt.Sprintf("parameter '%s' in %s is required", field, in))
+	custErr.Field = field
+	return custErr
 }
 
 // InvalidParameterError parameter 'field' is required
 func InvalidParameterError(field, reason string) error {
-	return newError(errorPrefix, InvalidParameterCode, fmt.Sprintf("parameter '%s' is invalid '%s'", field, reason))
+	custErr := newCustError(errorPrefix, InvalidParameterCode, fmt.Sprintf("parameter '%s' is invalid '%s'", field, reason))
+	custErr.Field = field
+	return custErr
 }
 
 // UpdateError error updating object
@@ -78,7 +261,89 @@ func ListError(objectName string, err error, fieldsAndValues ...string) error {
 	return newError(errorPrefix, ListErrorCode, fmt.Sprintf("the "+objectName+" queried by "+strings.Join(fieldsAndValues, ", ")+" returned an error: %s", err.Error()))
 }
 
-// DecodeError returns an error when decoding the response from the database
+// DecodeError returns an error when the body of a request cannot be decoded, a client error.
+// The documents of the database that cannot be decoded are reported with ReadError.
 func DecodeError(err error) error {
 	return newError(errorPrefix, DecodeErrorCode, fmt.Sprintf("decode error: %s", err.Error()))
 }
+
+// BrandInUseError returns an error when a brand cannot be deleted because there are devices using it
+func BrandInUseError(brand string, devices int64) error {
+	return newError(errorPrefix, BrandInUseCode, fmt.Sprintf("the brand %s is used by %d device(s)", brand, devices))
+}
+
+// AlreadyExistsError returns an error when an object with the same key already exists
+func AlreadyExistsError(objectName, key string) error {
+	return newError(errorPrefix, AlreadyExistsCode, fmt.Sprintf("the %s %s already exists", objectName, key))
+}
+
+// ReadError returns an error when an object cannot be read from the database
+func ReadError(objectName, reason string) error {
+	return newError(errorPrefix, ReadErrorCode, fmt.Sprintf("error reading %s reason %s", objectName, reason))
+}
+
+// UnexpectedError wraps an error that is not a CustError
+func UnexpectedError(err error) error {
+	return newError(errorPrefix, UnexpectedErrorCode, fmt.Sprintf("unexpected error: %s", err.Error()))
+}
+
+// VersionMismatchError returns an error when an object was not changed because it doesn't have the expected version
+func VersionMismatchError(objectName, id string) error {
+	return newError(errorPrefix, VersionMismatchCode, fmt.Sprintf("the %s with id %s does not have the expected version", objectName, id))
+}
+
+// PreconditionRequiredError returns an error when a change request doesn't have the header conditioning it
+func PreconditionRequiredError(header string) error {
+	return newError(errorPrefix, PreconditionRequiredCode, fmt.Sprintf("header '%s' is required", header))
+}
+
+// UnsupportedMediaTypeError returns an error when the request body has a media type the operation doesn't support
+func UnsupportedMediaTypeError(mediaType string, supported ...string) error {
+	return newError(errorPrefix, UnsupportedMediaTypeCode, fmt.Sprintf("unsupported media type [%s], expected one of [%s]", mediaType, strings.Join(supported, ", ")))
+}
+
+// ConcurrentChangeError returns an error when an object could not be changed because it kept being changed by other requests
+func ConcurrentChangeError(objectName, id string) error {
+	return newError(errorPrefix, ConcurrentChangeCode, fmt.Sprintf("the %s with id %s is being changed concurrently", objectName, id))
+}
+
+// PatchTestFailedError returns an error when a test operation of a JSON patch fails
+func PatchTestFailedError(path string) error {
+	return newError(errorPrefix, PatchTestFailedCode, fmt.Sprintf("the value at %s is not the one tested", path))
+}
+
+// InvalidStateError returns an error when an object cannot do an operation in its current state
+func InvalidStateError(objectName, id, state string) error {
+	return newError(errorPrefix, InvalidStateCode, fmt.Sprintf("the %s with id %s is %s", objectName, id, state))
+}
+
+// IdempotencyKeyReusedError returns an error when an idempotency key is sent again with a different request
+func IdempotencyKeyReusedError(key string) error {
+	return newError(errorPrefix, IdempotencyKeyReusedCode, fmt.Sprintf("the idempotency key %s was used for a different request", key))
+}
+
+// RequestInProgressError returns an error when the first request with an idempotency key is still being processed
+func RequestInProgressError(key string) error {
+	return newError(errorPrefix, RequestInProgressCode, fmt.Sprintf("the request with idempotency key %s is still in progress", key))
+}
+
+// IllegalTransitionError returns an error when an object cannot move from its state to another one
+func IllegalTransitionError(objectName, id, from, to string) error {
+	return newError(errorPrefix, IllegalTransitionCode, fmt.Sprintf("the %s with id %s cannot move from %s to %s", objectName, id, from, to))
+}
+
+// DeviceHasChildrenError returns an error when a device cannot be deleted because other devices are attached to it
+func DeviceHasChildrenError(id string, children int64) error {
+	return newError(errorPrefix, DeviceHasChildrenCode, fmt.Sprintf("the device with id %s has %d child device(s)", id, children))
+}
+
+// PartialDeleteError returns an error when a device is not deleted after some of its descendants were deleted with it
+func PartialDeleteError(id string, deleted, descendants int, err error) error {
+	return newError(errorPrefix, PartialDeleteCode, fmt.Sprintf("the device with id %s was not deleted, %d of its %d descendant(s) were: %s",
+		id, deleted, descendants, Wrap(err).Message))
+}
+
+// UnauthorizedError returns an error when a request doesn't have valid credentials for an object
+func UnauthorizedError(objectName, id string) error {
+	return newError(errorPrefix, UnauthorizedCode, fmt.Sprintf("the credentials are not valid for the %s with id %s", objectName, id))
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/controller"
+)
+
+// ActorHeader is the request header telling who makes the changes, recorded in the device history
+const ActorHeader = "X-User"
+
+// withActor is a middleware keeping in the request context who makes the changes
+func withActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(ActorHeader); actor != "" {
+			r = r.WithContext(controller.WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
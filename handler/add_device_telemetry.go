@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type addDeviceTelemetryRequest struct {
+	dto.AddTelemetryRequestDTO
+	samples []model.TelemetrySample
+	// token is the heartbeat token of the Authorization header, empty when it is missing
+	token string
+}
+
+// Build builds the add telemetry request dto, the samples without time being taken now
+func (req *addDeviceTelemetryRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(&req.AddTelemetryRequestDTO)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	var errs []error
+	req.DeviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		errs = append(errs, errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]"))
+	}
+	if len(req.Samples) == 0 || len(req.Samples) > model.MaxTelemetrySamples {
+		errs = append(errs, errors.InvalidParameterError("samples", "must have from 1 to "+strconv.Itoa(model.MaxTelemetrySamples)+" samples"))
+	}
+	now := time.Now()
+	req.samples = req.ToModel(now)
+	for i, sample := range req.samples {
+		if err := sample.Validate(now); err != nil {
+			errs = append(errs, errors.InvalidParameterError("samples["+strconv.Itoa(i)+"]", err.Error()))
+		}
+	}
+	req.token = bearerToken(r)
+
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) addDeviceTelemetry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(addDeviceTelemetryRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	err := h.service.DeviceController().AddTelemetry(ctx, req.DeviceID, req.token, req.samples)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/controller"
+	"github.com/gorilla/mux"
+)
+
+type brandHandler struct {
+	*mux.Router
+	service controller.ServiceController
+}
+
+func (handler brandHandler) addRoute(router *mux.Router, path, method string, f func(http.ResponseWriter, *http.Request)) {
+	router.Path(path).Methods(method).HandlerFunc(f)
+}
+
+func addBrandRoutes(router *mux.Router, handler brandHandler) {
+	handler.addRoute(router, "/{name}", http.MethodGet, handler.getBrand)
+	handler.addRoute(router, "/{name}", http.MethodPut, handler.updateBrand)
+	handler.addRoute(router, "/{name}", http.MethodDelete, handler.deleteBrand)
+	handler.addRoute(router, "", http.MethodPost, handler.createBrand)
+	handler.addRoute(router, "", http.MethodGet, handler.getBrands)
+}
+
+func newBrand(service controller.ServiceController) brandHandler {
+	router := mux.NewRouter().PathPrefix(BrandURLPath).Subrouter()
+	handler := brandHandler{
+		Router:  router,
+		service: service,
+	}
+	addBrandRoutes(router, handler)
+	return handler
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/util"
+)
+
+func (h deviceHandler) cancelBulkJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(bulkJobParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	res, err := h.service.BulkJobController().Cancel(ctx, params.jobID)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, res)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type checkinDeviceParameters struct {
+	deviceID primitive.ObjectID
+}
+
+func (params *checkinDeviceParameters) Build(r *http.Request) error {
+	var err error
+	params.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+
+	return nil
+}
+
+func (h deviceHandler) checkinDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(checkinDeviceParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	device, err := h.service.DeviceController().Checkin(ctx, params.deviceID, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(device.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceDTO(device))
+}
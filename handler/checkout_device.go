@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type checkoutDeviceRequest struct {
+	dto.CheckoutRequestDTO
+}
+
+// Build builds the checkout request dto
+func (req *checkoutDeviceRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	var idErr error
+	req.DeviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		idErr = errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+
+	return errors.Join(idErr, req.Validate())
+}
+
+// Validate validates the checkout request dto, the due date must be in the future
+func (req checkoutDeviceRequest) Validate() error {
+	var errs []error
+	if strings.TrimSpace(req.Assignee) == "" {
+		errs = append(errs, errors.RequiredParameterError("assignee", "body"))
+	} else if len(req.Assignee) > model.MaxAssigneeLength {
+		errs = append(errs, errors.InvalidParameterError("assignee", "must have at most "+strconv.Itoa(model.MaxAssigneeLength)+" characters"))
+	}
+	if req.DueAt == nil {
+		errs = append(errs, errors.RequiredParameterError("dueAt", "body"))
+	} else if !req.DueAt.After(time.Now()) {
+		errs = append(errs, errors.InvalidParameterError("dueAt", "must be in the future"))
+	}
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) checkoutDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(checkoutDeviceRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	device, err := h.service.DeviceController().Checkout(ctx, req.DeviceID, req.ToModel(), match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(device.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceDTO(device))
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// cacheValidators are the headers letting clients make conditional requests for a response
+type cacheValidators struct {
+	etag         string
+	lastModified *time.Time
+	cacheControl string
+}
+
+// bodyETag returns a strong entity tag for a JSON response body
+func bodyETag(body interface{}) (string, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// setHeaders sets the validators and the cache control headers of the response
+func (v cacheValidators) setHeaders(w http.ResponseWriter) {
+	if v.etag != "" {
+		w.Header().Set("ETag", v.etag)
+	}
+	if v.lastModified != nil {
+		w.Header().Set("Last-Modified", v.lastModified.UTC().Format(http.TimeFormat))
+	}
+	if v.cacheControl != "" {
+		w.Header().Set("Cache-Control", v.cacheControl)
+	}
+}
+
+// notModified tells if the client already has the current response: If-None-Match matches the ETag
+// or, when there is no If-None-Match, the response didn't change since If-Modified-Since
+func (v cacheValidators) notModified(r *http.Request) bool {
+	if values := r.Header.Values("If-None-Match"); len(values) > 0 {
+		for _, tag := range strings.Split(strings.Join(values, ","), ",") {
+			tag = strings.TrimSpace(tag)
+			// If-None-Match uses the weak comparison
+			if tag == "*" || (v.etag != "" && strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(v.etag, "W/")) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if v.lastModified == nil {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !v.lastModified.Truncate(time.Second).After(since)
+}
+
+// writeNotModified answers 304 Not Modified when the client already has the current response,
+// telling if it did. The validators are set in the response in any case.
+func (v cacheValidators) writeNotModified(w http.ResponseWriter, r *http.Request) bool {
+	v.setHeaders(w)
+	if !v.notModified(r) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/device-ms/model"
+)
+
+const (
+	envRequireIfMatch         = "REQUIRE_IF_MATCH"
+	envGetDeviceCacheControl  = "GET_DEVICE_CACHE_CONTROL"
+	envGetDevicesCacheControl = "GET_DEVICES_CACHE_CONTROL"
+	envIdempotencyKeyTTL      = "IDEMPOTENCY_KEY_TTL"
+	envDeleteChildren         = "DELETE_CHILDREN"
+	// defaultCacheControl lets clients keep the responses but makes them revalidate them with a conditional request
+	defaultCacheControl = "no-cache"
+	// defaultIdempotencyKeyTTL is how long a response is replayed for an idempotency key
+	defaultIdempotencyKeyTTL = 24 * time.Hour
+)
+
+// Config is the configuration of the handlers
+type Config struct {
+	// RequireIfMatch makes the If-Match header mandatory to update or delete a device
+	RequireIfMatch bool
+	// GetDeviceCacheControl is the Cache-Control header of GET /device/{id}, none when empty
+	GetDeviceCacheControl string
+	// GetDevicesCacheControl is the Cache-Control header of GET /device, none when empty
+	GetDevicesCacheControl string
+	// IdempotencyKeyTTL is how long the response of a request with an Idempotency-Key header is replayed
+	IdempotencyKeyTTL time.Duration
+	// DeletePolicy tells what happens to the children of a device deleted without children query parameter,
+	// deny when it is empty
+	DeletePolicy model.DeletePolicy
+}
+
+// ConfigFromEnv reads the handlers configuration from the environment
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		GetDeviceCacheControl:  defaultCacheControl,
+		GetDevicesCacheControl: defaultCacheControl,
+		IdempotencyKeyTTL:      defaultIdempotencyKeyTTL,
+		DeletePolicy:           model.DeleteDeny,
+	}
+	if value := os.Getenv(envRequireIfMatch); value != "" {
+		var err error
+		config.RequireIfMatch, err = strconv.ParseBool(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid %s [%s]: %w", envRequireIfMatch, value, err)
+		}
+	}
+	if value, ok := os.LookupEnv(envGetDeviceCacheControl); ok {
+		config.GetDeviceCacheControl = value
+	}
+	if value, ok := os.LookupEnv(envGetDevicesCacheControl); ok {
+		config.GetDevicesCacheControl = value
+	}
+	if value := os.Getenv(envIdempotencyKeyTTL); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return config, fmt.Errorf("invalid %s [%s]: must be a positive duration", envIdempotencyKeyTTL, value)
+		}
+		config.IdempotencyKeyTTL = ttl
+	}
+	if value := os.Getenv(envDeleteChildren); value != "" {
+		config.DeletePolicy = model.DeletePolicy(value)
+		if !config.DeletePolicy.IsValid() {
+			return config, fmt.Errorf("invalid %s [%s]: must be %s or %s", envDeleteChildren, value, model.DeleteDeny, model.DeleteCascade)
+		}
+	}
+	return config, nil
+}
+
+// idempotencyKeyTTL returns the idempotency key TTL, the default one when it is not set
+func (c Config) idempotencyKeyTTL() time.Duration {
+	if c.IdempotencyKeyTTL <= 0 {
+		return defaultIdempotencyKeyTTL
+	}
+	return c.IdempotencyKeyTTL
+}
+
+// deletePolicy returns the policy applied to the children of the deleted devices, deny when it is not set
+func (c Config) deletePolicy() model.DeletePolicy {
+	if c.DeletePolicy == "" {
+		return model.DeleteDeny
+	}
+	return c.DeletePolicy
+}
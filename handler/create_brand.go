@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+)
+
+// brandNameRegexp restricts brand names to values that can be used as a path parameter
+var brandNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+type createBrandRequest struct {
+	dto.CreateBrandRequestDTO
+}
+
+// Build builds the brand creation dto
+func (req *createBrandRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	return req.Validate()
+}
+
+// Validate validates the brand creation dto
+func (req createBrandRequest) Validate() error {
+	var errs []error
+	if req.Name == "" {
+		errs = append(errs, errors.RequiredParameterError("name", "body"))
+	} else if !brandNameRegexp.MatchString(string(req.Name)) {
+		errs = append(errs, errors.InvalidParameterError("name", "invalid value ["+string(req.Name)+"]"))
+	}
+	errs = append(errs, validateAttributesSchema(req.AttributesSchema))
+	return errors.Join(errs...)
+}
+
+// validateAttributesSchema validates the attributes schema of a brand, when there is one
+func validateAttributesSchema(raw json.RawMessage) error {
+	if !dto.HasAttributesSchema(raw) {
+		return nil
+	}
+	_, err := model.ParseAttributesSchema(raw)
+	if err != nil {
+		return errors.InvalidParameterError("attributesSchema", err.Error())
+	}
+	return nil
+}
+
+func (h brandHandler) createBrand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(createBrandRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	brand := req.ToModel()
+
+	err := h.service.BrandController().Create(ctx, brand)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusCreated, dto.ToBrandDTO(brand))
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+)
+
+type createBulkJobRequest struct {
+	dto.BulkJobRequestDTO
+}
+
+// Build builds the bulk job dto
+func (req *createBulkJobRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	return req.Validate()
+}
+
+// Validate validates the bulk job dto. The filter must have a criterion, so that a job never changes every device by mistake.
+func (req createBulkJobRequest) Validate() error {
+	var errs []error
+	filter := req.Filter
+	if len(filter.Brands) == 0 && filter.NamePrefix == "" && filter.CreatedFrom == nil && filter.CreatedTo == nil {
+		errs = append(errs, errors.InvalidParameterError("filter", "must have at least one criterion"))
+	}
+	for _, brand := range filter.Brands {
+		if !brand.IsValid() {
+			errs = append(errs, errors.InvalidParameterError("filter.brands", "invalid value ["+string(brand)+"]"))
+		}
+	}
+
+	action := req.Action
+	switch action.Type {
+	case model.BulkActionSetBrand:
+		if action.Brand == "" {
+			errs = append(errs, errors.RequiredParameterError("action.brand", "body"))
+		} else if !action.Brand.IsValid() {
+			errs = append(errs, errors.InvalidParameterError("action.brand", "invalid value ["+string(action.Brand)+"]"))
+		}
+	case model.BulkActionSetName:
+		if action.NamePattern == "" {
+			errs = append(errs, errors.RequiredParameterError("action.namePattern", "body"))
+		}
+	case model.BulkActionDelete:
+	case "":
+		errs = append(errs, errors.RequiredParameterError("action.type", "body"))
+	default:
+		errs = append(errs, errors.InvalidParameterError("action.type", "invalid value ["+string(action.Type)+"]"))
+	}
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) createBulkJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(createBulkJobRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	job := req.ToModel()
+	if req.DryRun {
+		matched, err := h.service.BulkJobController().Count(ctx, job.Filter)
+		if err != nil {
+			util.JSONErrorWithCtx(ctx, w, err)
+			return
+		}
+		util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.BulkJobDryRunDTO{Matched: matched})
+		return
+	}
+
+	err := h.service.BulkJobController().Create(ctx, job)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("Location", URLPath+"/jobs/"+job.ID.Hex())
+	util.JSONReturnWithCtx(ctx, w, http.StatusAccepted, dto.ToBulkJobDTO(job))
+}
@@ -3,10 +3,14 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/device-ms/dto"
 	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
 	"github.com/device-ms/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type createDeviceRequest struct {
@@ -25,20 +29,82 @@ func (req *createDeviceRequest) Build(r *http.Request) error {
 
 // Validate validates the creation dto
 func (req createDeviceRequest) Validate() error {
+	var errs []error
 	if req.Brand == "" {
-		return errors.RequiredParameterError("brand", "body")
+		errs = append(errs, errors.RequiredParameterError("brand", "body"))
+	} else if !req.Brand.IsValid() {
+		errs = append(errs, errors.InvalidParameterError("brand", "invalid value ["+string(req.Brand)+"]"))
 	}
-	if !req.Brand.IsValid() {
-		return errors.InvalidParameterError("brand", "invalid value ["+string(req.Brand)+"]")
+	if req.State != "" && !req.State.IsValid() {
+		errs = append(errs, errors.InvalidParameterError("state", "invalid value ["+string(req.State)+"]"))
 	}
-	return nil
+	errs = append(errs, validateIdentifiers(req.SerialNumber, req.ExternalIDs)...)
+	errs = append(errs, validateLabels(req.Labels)...)
+	errs = append(errs, dto.ValidateAttributes(req.Brand, req.Attributes)...)
+	errs = append(errs, validateLocation("location.", req.Location)...)
+	if req.ParentID != "" && !primitive.IsValidObjectID(req.ParentID) {
+		errs = append(errs, errors.InvalidParameterError("parentId", "invalid object id ["+req.ParentID+"]"))
+	}
+	return errors.Join(errs...)
+}
+
+// validateIdentifiers validates the serial number and the external ids of a device
+func validateIdentifiers(serialNumber string, externalIDs map[string]string) []error {
+	var errs []error
+	if len(serialNumber) > model.MaxSerialNumberLength {
+		errs = append(errs, errors.InvalidParameterError("serialNumber", "must have at most "+strconv.Itoa(model.MaxSerialNumberLength)+" characters"))
+	}
+	systems := make([]string, 0, len(externalIDs))
+	for system := range externalIDs {
+		systems = append(systems, system)
+	}
+	sort.Strings(systems)
+	for _, system := range systems {
+		field := "externalIds." + system
+		if !model.IsValidExternalSystem(system) {
+			errs = append(errs, errors.InvalidParameterError(field, "invalid system ["+system+"]"))
+		}
+		if value := externalIDs[system]; value == "" || len(value) > model.MaxExternalIDLength {
+			errs = append(errs, errors.InvalidParameterError(field, "must have between 1 and "+strconv.Itoa(model.MaxExternalIDLength)+" characters"))
+		}
+	}
+	return errs
+}
+
+// validateLabels validates the labels of a device
+func validateLabels(labels map[string]string) []error {
+	var errs []error
+	if len(labels) > model.MaxLabels {
+		errs = append(errs, errors.InvalidParameterError("labels", "must have at most "+strconv.Itoa(model.MaxLabels)+" labels"))
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		errs = append(errs, validateLabel("labels."+key, key, labels[key])...)
+	}
+	return errs
+}
+
+// validateLabel validates the key and the value of a label
+func validateLabel(field, key, value string) []error {
+	var errs []error
+	if !model.IsValidLabelKey(key) {
+		errs = append(errs, errors.InvalidParameterError(field, "invalid key ["+key+"]"))
+	}
+	if !model.IsValidLabelValue(value) {
+		errs = append(errs, errors.InvalidParameterError(field, "invalid value ["+value+"]"))
+	}
+	return errs
 }
 
 func (h deviceHandler) createDevice(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	req := new(createDeviceRequest)
 	if err := req.Build(r); err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
@@ -46,7 +112,7 @@ func (h deviceHandler) createDevice(w http.ResponseWriter, r *http.Request) {
 
 	err := h.service.DeviceController().Create(ctx, device)
 	if err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusInternalServerError)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
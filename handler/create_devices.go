@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+)
+
+// maxNDJSONLine is the maximum size of a line of a NDJSON body
+const maxNDJSONLine = 64 * 1024
+
+type createDevicesRequest struct {
+	items   []*model.BulkItem
+	ordered bool
+}
+
+// Build builds the devices to create from a JSON array or a NDJSON body, according to its content type.
+// The invalid devices are failed items instead of failing the request, so that the other ones are still created.
+func (req *createDevicesRequest) Build(r *http.Request) error {
+	var orderedErr, bodyErr error
+	req.ordered = true
+	if ordered := r.URL.Query().Get("ordered"); ordered != "" {
+		var err error
+		req.ordered, err = strconv.ParseBool(ordered)
+		if err != nil {
+			orderedErr = errors.InvalidParameterError("ordered", "invalid value ["+ordered+"]")
+		}
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	switch mediaType {
+	case "", "application/json":
+		bodyErr = req.decodeArray(r)
+	case dto.NDJSONMediaType:
+		bodyErr = req.decodeNDJSON(r)
+	default:
+		return errors.UnsupportedMediaTypeError(mediaType, "application/json", dto.NDJSONMediaType)
+	}
+	if bodyErr == nil && (len(req.items) == 0 || len(req.items) > model.MaxBulkItems) {
+		bodyErr = errors.InvalidParameterError("body", "must have between 1 and "+strconv.Itoa(model.MaxBulkItems)+" devices")
+	}
+
+	return errors.Join(orderedErr, bodyErr)
+}
+
+// decodeArray decodes a JSON array of devices one by one, failing the whole request when it is malformed.
+// The decoding stops after more devices than allowed, and a device cannot be larger than a NDJSON line.
+func (req *createDevicesRequest) decodeArray(r *http.Request) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, int64(model.MaxBulkItems+1)*maxNDJSONLine))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return errors.InvalidParameterError("body", "expected a JSON array of devices")
+	}
+	for decoder.More() {
+		if len(req.items) > model.MaxBulkItems {
+			return nil
+		}
+		var device createDeviceRequest
+		if err := decoder.Decode(&device); err != nil {
+			return errors.DecodeError(err)
+		}
+		req.add(device, device.Validate())
+	}
+	if _, err := decoder.Token(); err != nil {
+		return errors.DecodeError(err)
+	}
+	return nil
+}
+
+// decodeNDJSON decodes a device per line, a malformed line only failing its own device
+func (req *createDevicesRequest) decodeNDJSON(r *http.Request) error {
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxNDJSONLine)
+	for scanner.Scan() && len(req.items) <= model.MaxBulkItems {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var device createDeviceRequest
+		if err := json.Unmarshal(line, &device); err != nil {
+			req.add(device, errors.DecodeError(err))
+			continue
+		}
+		req.add(device, device.Validate())
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.DecodeError(err)
+	}
+	return nil
+}
+
+// add adds a device to create, failed when err is not nil
+func (req *createDevicesRequest) add(device createDeviceRequest, err error) {
+	item := &model.BulkItem{Device: device.ToModel()}
+	if err != nil {
+		item.Fail(err)
+	}
+	req.items = append(req.items, item)
+}
+
+func (h deviceHandler) createDevices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(createDevicesRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	if err := h.service.DeviceController().CreateMany(ctx, req.items, req.ordered); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusMultiStatus, dto.ToBulkCreateResponseDTO(req.items))
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/util"
+)
+
+func (h brandHandler) deleteBrand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(getBrandParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	err := h.service.BrandController().Delete(ctx, params.name)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusNoContent, nil)
+}
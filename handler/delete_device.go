@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
 	"github.com/device-ms/util"
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -11,29 +12,49 @@ import (
 
 type deleteDeviceParameters struct {
 	deviceID primitive.ObjectID
+	// policy is the policy of the children query parameter, empty when it is not set
+	policy model.DeletePolicy
 }
 
 func (params *deleteDeviceParameters) Build(r *http.Request) error {
+	var errs []error
 	var err error
 	params.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
 	if err != nil {
-		return errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+		errs = append(errs, errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]"))
 	}
 
-	return nil
+	if children := r.URL.Query().Get("children"); children != "" {
+		params.policy = model.DeletePolicy(children)
+		if !params.policy.IsValid() {
+			errs = append(errs, errors.InvalidParameterError("children", "invalid value ["+children+"]"))
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 func (h deviceHandler) deleteDevice(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	params := new(getDeviceParameters)
+	params := new(deleteDeviceParameters)
 	if err := params.Build(r); err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
-	err := h.service.DeviceController().Delete(ctx, params.deviceID)
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	policy := params.policy
+	if policy == "" {
+		policy = h.config.deletePolicy()
+	}
+	err = h.service.DeviceController().Delete(ctx, params.deviceID, match, policy)
 	if err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusInternalServerError)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+)
+
+// filterKeyRegexp matches query parameter keys like field or field[operator]
+var filterKeyRegexp = regexp.MustCompile(`^([A-Za-z]+)(?:\[([A-Za-z]*)\])?$`)
+
+// attributeFilterKeyRegexp matches query parameter keys like attributes.path or attributes.path[operator]
+var attributeFilterKeyRegexp = regexp.MustCompile(`^attributes\.([^\[\]]*)(?:\[([A-Za-z]*)\])?$`)
+
+// queryParamsWithoutOperator are the search query parameters that don't accept an operator
+var queryParamsWithoutOperator = map[string]bool{
+	"brand":        true,
+	"state":        true,
+	"updatedSince": true,
+	"assignee":     true,
+	"overdue":      true,
+	"labels":       true,
+	"depth":        true,
+	"near":         true,
+	"radius":       true,
+	"within":       true,
+	"connectivity": true,
+}
+
+// buildDeviceFilter builds a device filter from the query parameters:
+//
+//	brand=brand1,brand2                       devices of any of the brands
+//	state=inStock,inRepair                    devices in any of the states
+//	name=x, name[eq]=x                        exact name
+//	name[prefix]=x                            name starting with x
+//	name[contains]=x                          name containing x, case insensitive
+//	createdAt[gt|gte|lt|lte]=<RFC 3339 time>  creation time range
+//	updatedAt[gt|gte|lt|lte]=<RFC 3339 time>  update time range
+//	updatedSince=<RFC 3339 time>              devices created or updated since the time
+//	assignee=x                                devices checked out by x
+//	overdue=true                              devices checked out past their due date
+//	labels=env=prod,team!=qa,has(rack)        devices whose labels meet the selector, see model.ParseLabelSelector
+//	attributes.x=v, attributes.x[eq]=v        devices whose attribute at the dot separated path x is v
+//	attributes.x[ne|gt|gte|lt|lte]=v          devices whose attribute compares to v, see model.AttributeCondition
+//	depth=n                                   devices having n ancestors, 0 for the devices without parent
+//	near=lat,lng&radius=meters                devices located within the radius of the position
+//	within=lat,lng lat,lng lat,lng            devices located inside the polygon of the vertices
+//	connectivity=online,stale                 devices in any of the connectivities, see model.Device.Connectivity
+//
+// params are the other parameters of the operation, like limit or sort. Every invalid parameter, and every unknown
+// one, is reported in the returned error, so that a misspelled criterion doesn't select every device.
+func buildDeviceFilter(query url.Values, params ...string) (model.DeviceFilter, error) {
+	var filter model.DeviceFilter
+	var errs []error
+	var near *model.GeoPosition
+	var radius *float64
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if slices.Contains(params, key) {
+			continue
+		}
+		if match := attributeFilterKeyRegexp.FindStringSubmatch(key); match != nil {
+			condition, err := parseAttributeCondition(key, match[1], match[2], query.Get(key))
+			if err == nil {
+				filter.Attributes = append(filter.Attributes, condition)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		match := filterKeyRegexp.FindStringSubmatch(key)
+		if match == nil {
+			errs = append(errs, errors.InvalidParameterError(key, "unknown parameter"))
+			continue
+		}
+		field, operator, value := match[1], match[2], query.Get(key)
+		if queryParamsWithoutOperator[field] && key != field {
+			errs = append(errs, errors.InvalidParameterError(key, "unsupported operator ["+operator+"]"))
+			continue
+		}
+
+		var err error
+		switch field {
+		case "brand":
+			filter.Brands, err = parseBrands(key, query[key])
+		case "state":
+			filter.States, err = parseStates(key, query[key])
+		case "name":
+			filter.Name, err = parseNameFilter(key, operator, value, filter.Name)
+		case "createdAt":
+			err = parseTimeRange(key, operator, value, &filter.CreatedAt)
+		case "updatedAt":
+			err = parseTimeRange(key, operator, value, &filter.UpdatedAt)
+		case "updatedSince":
+			filter.UpdatedSince, err = parseTime(key, value)
+		case "assignee":
+			filter.Assignee = value
+			if value == "" {
+				err = errors.InvalidParameterError(key, "empty value")
+			}
+		case "labels":
+			filter.Labels, err = model.ParseLabelSelector(value)
+			if err != nil {
+				err = errors.InvalidParameterError(key, err.Error())
+			}
+		case "overdue":
+			filter.Overdue, err = strconv.ParseBool(value)
+			if err != nil {
+				err = errors.InvalidParameterError(key, "invalid value ["+value+"]")
+			}
+		case "depth":
+			var depth int
+			depth, err = strconv.Atoi(value)
+			switch {
+			case err != nil:
+				err = errors.InvalidParameterError(key, "invalid value ["+value+"]")
+			case depth < 0:
+				err = errors.InvalidParameterError(key, "must be at least 0")
+			default:
+				filter.Depth = &depth
+			}
+		case "near":
+			var position model.GeoPosition
+			position, err = model.ParseGeoPosition(value)
+			if err != nil {
+				err = errors.InvalidParameterError(key, err.Error())
+			} else {
+				near = &position
+			}
+		case "radius":
+			var meters float64
+			meters, err = strconv.ParseFloat(value, 64)
+			switch {
+			case err != nil:
+				err = errors.InvalidParameterError(key, "invalid value ["+value+"]")
+			case !(meters > 0 && meters <= model.MaxGeoRadius):
+				err = errors.InvalidParameterError(key, "must be greater than 0 and at most "+strconv.Itoa(model.MaxGeoRadius)+" meters")
+			default:
+				radius = &meters
+			}
+		case "within":
+			filter.Within, err = model.ParseGeoPolygon(value)
+			if err != nil {
+				err = errors.InvalidParameterError(key, err.Error())
+			}
+		case "connectivity":
+			filter.Connectivity, err = parseConnectivities(key, query[key])
+		default:
+			err = errors.InvalidParameterError(key, "unknown parameter")
+		}
+		errs = append(errs, err)
+	}
+
+	// near and radius only select devices together
+	switch {
+	case near != nil && radius != nil:
+		filter.Near = &model.GeoNear{GeoPosition: *near, Radius: *radius}
+	case query.Has("near") && !query.Has("radius"):
+		errs = append(errs, errors.RequiredParameterError("radius", "query"))
+	case query.Has("radius") && !query.Has("near"):
+		errs = append(errs, errors.InvalidParameterError("radius", "requires near"))
+	}
+
+	return filter, errors.Join(errs...)
+}
+
+func parseBrands(key string, values []string) ([]model.Brand, error) {
+	var brands []model.Brand
+	for _, value := range values {
+		for _, brand := range strings.Split(value, ",") {
+			brand = strings.TrimSpace(brand)
+			if brand == "" {
+				return nil, errors.InvalidParameterError(key, "empty brand in ["+value+"]")
+			}
+			brands = append(brands, model.Brand(brand))
+		}
+	}
+	return brands, nil
+}
+
+func parseStates(key string, values []string) ([]model.DeviceState, error) {
+	var states []model.DeviceState
+	for _, value := range values {
+		for _, state := range strings.Split(value, ",") {
+			state := model.DeviceState(strings.TrimSpace(state))
+			if !state.IsValid() {
+				return nil, errors.InvalidParameterError(key, "invalid value ["+string(state)+"]")
+			}
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+func parseConnectivities(key string, values []string) ([]model.Connectivity, error) {
+	var connectivities []model.Connectivity
+	for _, value := range values {
+		for _, connectivity := range strings.Split(value, ",") {
+			connectivity := model.Connectivity(strings.TrimSpace(connectivity))
+			if !connectivity.IsValid() {
+				return nil, errors.InvalidParameterError(key, "invalid value ["+string(connectivity)+"]")
+			}
+			connectivities = append(connectivities, connectivity)
+		}
+	}
+	return connectivities, nil
+}
+
+func parseNameFilter(key, operator, value string, current *model.NameFilter) (*model.NameFilter, error) {
+	if current != nil {
+		return current, errors.InvalidParameterError(key, "only one name operator is allowed")
+	}
+	match := model.NameMatchExact
+	if operator != "" {
+		match = model.NameMatch(operator)
+	}
+	if !match.IsValid() {
+		return nil, errors.InvalidParameterError(key, "unsupported operator ["+operator+"]")
+	}
+	if value == "" && match != model.NameMatchExact {
+		return nil, errors.InvalidParameterError(key, "empty value")
+	}
+	return &model.NameFilter{Match: match, Value: value}, nil
+}
+
+func parseAttributeCondition(key, path, operator, value string) (model.AttributeCondition, error) {
+	if !model.IsValidAttributePath(path) {
+		return model.AttributeCondition{}, errors.InvalidParameterError(key, "invalid attribute path ["+path+"]")
+	}
+	op := model.AttributeEquals
+	if operator != "" {
+		op = model.AttributeOperator(operator)
+	}
+	if !op.IsValid() {
+		return model.AttributeCondition{}, errors.InvalidParameterError(key, "unsupported operator ["+operator+"]")
+	}
+	return model.AttributeCondition{Path: path, Operator: op, Value: value}, nil
+}
+
+func parseTimeRange(key, operator, value string, tr *model.TimeRange) error {
+	t, err := parseTime(key, value)
+	if err != nil {
+		return err
+	}
+	switch operator {
+	case "gt":
+		tr.Gt = t
+	case "gte":
+		tr.Gte = t
+	case "lt":
+		tr.Lt = t
+	case "lte":
+		tr.Lte = t
+	default:
+		return errors.InvalidParameterError(key, "unsupported operator ["+operator+"]")
+	}
+	return nil
+}
+
+func parseTime(key, value string) (*time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.InvalidParameterError(key, "invalid time ["+value+"], expected RFC 3339")
+	}
+	t = t.UTC()
+	return &t, nil
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+)
+
+type exportDevicesRequest struct {
+	format dto.ExportFormat
+	search model.DeviceSearch
+}
+
+// Build builds the export from the same filters and sort as a search, the export having every matching device
+func (req *exportDevicesRequest) Build(r *http.Request) error {
+	query := r.URL.Query()
+	var errs []error
+	var err error
+	req.search.DeviceFilter, err = buildDeviceFilter(query, "sort", "format")
+	errs = append(errs, err)
+	req.search.Sort = model.DeviceSort(query.Get("sort"))
+	if req.search.Sort == "" {
+		req.search.Sort = model.SortCreatedAtAsc
+	}
+	req.format = dto.ExportFormat(query.Get("format"))
+	if req.format == "" {
+		req.format = dto.ExportJSON
+	}
+
+	return errors.Join(append(errs, req.Validate())...)
+}
+
+// Validate validates the export
+func (req exportDevicesRequest) Validate() error {
+	errs := validateBrands(req.search.Brands)
+	if !req.search.Sort.IsValid() {
+		errs = append(errs, errors.InvalidParameterError("sort", "invalid value ["+string(req.search.Sort)+"]"))
+	}
+	if !req.format.IsValid() {
+		errs = append(errs, errors.InvalidParameterError("format", "invalid value ["+string(req.format)+"]"))
+	}
+	return errors.Join(errs...)
+}
+
+// writeHeaders starts the response of the export as a file download
+func (req exportDevicesRequest) writeHeaders(w http.ResponseWriter) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Type", req.format.MediaType()+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="devices.`+string(req.format)+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h deviceHandler) exportDevices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(exportDevicesRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	// the headers are written with the first device, so that an error before it is still an error response
+	started := false
+	encoder := dto.NewDeviceEncoder(req.format, w)
+	err := h.service.DeviceController().Export(ctx, req.search, func(dv *model.Device) error {
+		if !started {
+			req.writeHeaders(w)
+			started = true
+		}
+		return encoder.Encode(dv)
+	})
+	if err != nil && !started {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+	if err != nil {
+		// the status is sent already: aborting the response tells the client the export is incomplete
+		log.Println("could not export devices: " + err.Error())
+		panic(http.ErrAbortHandler)
+	}
+
+	if !started {
+		req.writeHeaders(w)
+	}
+	if err := encoder.Close(); err != nil {
+		log.Println("could not export devices: " + err.Error())
+	}
+}
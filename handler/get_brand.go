@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+)
+
+type getBrandParameters struct {
+	name model.Brand
+}
+
+func (params *getBrandParameters) Build(r *http.Request) error {
+	params.name = model.Brand(mux.Vars(r)["name"])
+
+	return nil
+}
+
+func (h brandHandler) getBrand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(getBrandParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	brand, err := h.service.BrandController().GetBrand(ctx, params.name)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToBrandDTO(brand))
+}
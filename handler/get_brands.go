@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/util"
+)
+
+func (h brandHandler) getBrands(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	res, err := h.service.BrandController().GetBrands(ctx)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, res)
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type bulkJobParameters struct {
+	jobID primitive.ObjectID
+}
+
+func (params *bulkJobParameters) Build(r *http.Request) error {
+	var err error
+	params.jobID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+
+	return nil
+}
+
+func (h deviceHandler) getBulkJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(bulkJobParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	res, err := h.service.BulkJobController().GetJob(ctx, params.jobID)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, res)
+}
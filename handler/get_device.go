@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/device-ms/dto"
 	"github.com/device-ms/errors"
@@ -12,29 +13,60 @@ import (
 
 type getDeviceParameters struct {
 	deviceID primitive.ObjectID
+	// asOf is the time to get the device as it was, nil for the current device
+	asOf *time.Time
 }
 
 func (params *getDeviceParameters) Build(r *http.Request) error {
+	var idErr, asOfErr error
 	var err error
 	params.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
 	if err != nil {
-		return errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+		idErr = errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
 	}
 
-	return nil
+	if asOf := r.URL.Query().Get("asOf"); asOf != "" {
+		params.asOf, asOfErr = parseTime("asOf", asOf)
+	}
+
+	return errors.Join(idErr, asOfErr)
 }
 
 func (h deviceHandler) getDevice(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	params := new(getDeviceParameters)
 	if err := params.Build(r); err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	if params.asOf != nil {
+		// a past state of the device is not the current representation, so it has no validators
+		device, err := h.service.DeviceController().GetDeviceAsOf(ctx, params.deviceID, *params.asOf)
+		if err != nil {
+			util.JSONErrorWithCtx(ctx, w, err)
+			return
+		}
+		util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceDTO(device))
 		return
 	}
 
 	device, err := h.service.DeviceController().GetDevice(ctx, params.deviceID)
 	if err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusInternalServerError)
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	validators := cacheValidators{
+		etag:         deviceRepresentationETag(device, time.Now()),
+		cacheControl: h.config.GetDeviceCacheControl,
+	}
+	if device.LastSeenAt == nil {
+		// the connectivity of a device having sent a heartbeat changes with time, only its ETag tells it changed
+		lastModified := device.LastModified()
+		validators.lastModified = &lastModified
+	}
+	if validators.writeNotModified(w, r) {
 		return
 	}
 
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+)
+
+type getDeviceByExternalIDParameters struct {
+	system string
+	value  string
+}
+
+func (params *getDeviceByExternalIDParameters) Build(r *http.Request) error {
+	params.system = mux.Vars(r)["system"]
+	params.value = mux.Vars(r)["value"]
+	if !model.IsValidExternalSystem(params.system) {
+		return errors.InvalidParameterError("system", "invalid system ["+params.system+"]")
+	}
+	return nil
+}
+
+func (h deviceHandler) getDeviceByExternalID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(getDeviceByExternalIDParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	device, err := h.service.DeviceController().GetDeviceByExternalID(ctx, params.system, params.value)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(device.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceDTO(device))
+}
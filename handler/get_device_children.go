@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type getDeviceChildrenParameters struct {
+	deviceID primitive.ObjectID
+	// depth is the number of levels of descendants, 0 for all of them
+	depth int
+}
+
+// Build builds the device children parameters, only the children being returned by default
+func (params *getDeviceChildrenParameters) Build(r *http.Request) error {
+	var errs []error
+	var err error
+	params.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		errs = append(errs, errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]"))
+	}
+
+	params.depth = 1
+	if depth := r.URL.Query().Get("depth"); depth != "" {
+		params.depth, err = strconv.Atoi(depth)
+		if err != nil || params.depth < 0 {
+			errs = append(errs, errors.InvalidParameterError("depth", "invalid value ["+depth+"]"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) getDeviceChildren(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(getDeviceChildrenParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	res, err := h.service.DeviceController().GetChildren(ctx, params.deviceID, params.depth)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, res)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type getDeviceHistoryRequest struct {
+	model.RevisionSearch
+}
+
+// Build builds the device history search
+func (req *getDeviceHistoryRequest) Build(r *http.Request) error {
+	query := r.URL.Query()
+	var errs []error
+	var err error
+	req.DeviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		errs = append(errs, errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]"))
+	}
+
+	req.Limit = model.DefaultRevisionLimit
+	if limit := query.Get("limit"); limit != "" {
+		req.Limit, err = strconv.ParseInt(limit, 10, 64)
+		if err != nil || req.Limit < 1 || req.Limit > model.MaxRevisionLimit {
+			errs = append(errs, errors.InvalidParameterError("limit", "must be between 1 and "+strconv.Itoa(model.MaxRevisionLimit)))
+		}
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		req.Before, err = model.DecodeRevisionCursor(cursor)
+		if err != nil {
+			errs = append(errs, errors.InvalidParameterError("cursor", "invalid value ["+cursor+"]"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) getDeviceHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(getDeviceHistoryRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	res, err := h.service.DeviceController().GetHistory(ctx, req.RevisionSearch)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, res)
+}
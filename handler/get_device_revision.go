@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type getDeviceRevisionParameters struct {
+	deviceID primitive.ObjectID
+	revision int64
+}
+
+func (params *getDeviceRevisionParameters) Build(r *http.Request) error {
+	var idErr, revisionErr error
+	var err error
+	params.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		idErr = errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+
+	revision := mux.Vars(r)["revision"]
+	params.revision, err = strconv.ParseInt(revision, 10, 64)
+	if err != nil || params.revision < 1 {
+		revisionErr = errors.InvalidParameterError("revision", "invalid value ["+revision+"]")
+	}
+
+	return errors.Join(idErr, revisionErr)
+}
+
+func (h deviceHandler) getDeviceRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(getDeviceRevisionParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	revision, err := h.service.DeviceController().GetRevision(ctx, params.deviceID, params.revision)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceRevisionDTO(revision))
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type getDeviceTelemetryParameters struct {
+	query model.TelemetryQuery
+}
+
+// Build builds the telemetry query from the query parameters:
+//
+//	from=<RFC 3339 time>      start of the range, included, DefaultTelemetryWindow before its end by default
+//	to=<RFC 3339 time>        end of the range, excluded, now by default
+//	bucket=<Go duration>      duration of the buckets the samples are downsampled to, raw samples without it
+//	aggregate=avg|min|max     how the samples of a bucket are aggregated, avg by default
+//	metrics=battery,temp      metrics of the series, all of them by default
+func (params *getDeviceTelemetryParameters) Build(r *http.Request) error {
+	var errs []error
+	var err error
+	params.query.DeviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		errs = append(errs, errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]"))
+	}
+
+	query := r.URL.Query()
+	params.query.To = time.Now().UTC()
+	if to := query.Get("to"); to != "" {
+		t, err := parseTime("to", to)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			params.query.To = *t
+		}
+	}
+	params.query.From = params.query.To.Add(-model.DefaultTelemetryWindow)
+	if from := query.Get("from"); from != "" {
+		t, err := parseTime("from", from)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			params.query.From = *t
+		}
+	}
+	if !params.query.To.After(params.query.From) {
+		errs = append(errs, errors.InvalidParameterError("to", "must be after from"))
+	}
+
+	if bucket := query.Get("bucket"); bucket != "" {
+		params.query.Bucket, err = time.ParseDuration(bucket)
+		if err != nil || params.query.Bucket <= 0 {
+			errs = append(errs, errors.InvalidParameterError("bucket", "invalid duration ["+bucket+"]"))
+			params.query.Bucket = 0
+		}
+	}
+
+	if aggregate := query.Get("aggregate"); aggregate != "" {
+		params.query.Aggregate = model.TelemetryAggregate(aggregate)
+		switch {
+		case !params.query.Aggregate.IsValid():
+			errs = append(errs, errors.InvalidParameterError("aggregate", "invalid value ["+aggregate+"]"))
+		case !query.Has("bucket"):
+			errs = append(errs, errors.InvalidParameterError("aggregate", "requires bucket"))
+		}
+	} else {
+		params.query.Aggregate = model.TelemetryAverage
+	}
+
+	if metrics := query.Get("metrics"); metrics != "" {
+		for _, metric := range strings.Split(metrics, ",") {
+			metric = strings.TrimSpace(metric)
+			if !model.IsValidMetricName(metric) {
+				errs = append(errs, errors.InvalidParameterError("metrics", "invalid metric name ["+metric+"]"))
+				break
+			}
+			params.query.Metrics = append(params.query.Metrics, metric)
+		}
+	}
+
+	if len(errs) == 0 {
+		// the range, the aggregate and the metrics were checked with their parameters, only the bucket is left
+		if err := params.query.Validate(); err != nil {
+			errs = append(errs, errors.InvalidParameterError("bucket", err.Error()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) getDeviceTelemetry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(getDeviceTelemetryParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	series, err := h.service.DeviceController().GetTelemetry(ctx, params.query)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, series)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type getDeviceTwinParameters struct {
+	deviceID primitive.ObjectID
+}
+
+func (params *getDeviceTwinParameters) Build(r *http.Request) error {
+	var err error
+	params.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+	return nil
+}
+
+func (h deviceHandler) getDeviceTwin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(getDeviceTwinParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	twin, err := h.service.TwinController().GetTwin(ctx, params.deviceID)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceTwinDTO(twin))
+}
+
+// getDeviceTwinDelta gets the desired properties of a device that it didn't report yet
+func (h deviceHandler) getDeviceTwinDelta(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(getDeviceTwinParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	twin, err := h.service.TwinController().GetTwin(ctx, params.deviceID)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToTwinDeltaDTO(twin))
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/device-ms/dto"
 	"github.com/device-ms/errors"
@@ -13,45 +14,99 @@ type searchDevicesRequest struct {
 	dto.SearchDevicesRequestDTO
 }
 
-// Build builds the creation dto
+// Build builds the search dto
 func (req *searchDevicesRequest) Build(r *http.Request) error {
-	req.Brand = model.Brand(r.URL.Query().Get("brand"))
+	query := r.URL.Query()
+	var errs []error
+	var err error
+	req.DeviceFilter, err = buildDeviceFilter(query, "limit", "cursor", "sort", "total")
+	errs = append(errs, err)
+	req.Cursor = query.Get("cursor")
+	req.Sort = model.DeviceSort(query.Get("sort"))
+	if req.Sort == "" {
+		req.Sort = model.SortCreatedAtAsc
+	}
+
+	req.Limit = model.DefaultDeviceLimit
+	if limit := query.Get("limit"); limit != "" {
+		req.Limit, err = strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			// keep the default so Validate doesn't report the limit twice
+			req.Limit = model.DefaultDeviceLimit
+			errs = append(errs, errors.InvalidParameterError("limit", "invalid value ["+limit+"]"))
+		}
+	}
+
+	if total := query.Get("total"); total != "" {
+		req.Total, err = strconv.ParseBool(total)
+		if err != nil {
+			errs = append(errs, errors.InvalidParameterError("total", "invalid value ["+total+"]"))
+		}
+	}
 
-	return req.Validate()
+	return errors.Join(append(errs, req.Validate())...)
 }
 
-// Validate validates the creation dto
+// Validate validates the search dto
 func (req searchDevicesRequest) Validate() error {
-	if req.Brand != "" {
-		if !req.Brand.IsValid() {
-			return errors.InvalidParameterError("brand", "invalid value ["+string(req.Brand)+"]")
+	errs := validateBrands(req.Brands)
+	if req.Limit < 1 || req.Limit > model.MaxDeviceLimit {
+		errs = append(errs, errors.InvalidParameterError("limit", "must be between 1 and "+strconv.Itoa(model.MaxDeviceLimit)))
+	}
+	if !req.Sort.IsValid() {
+		errs = append(errs, errors.InvalidParameterError("sort", "invalid value ["+string(req.Sort)+"]"))
+	}
+	if req.Cursor != "" && req.Sort.IsValid() {
+		if _, err := model.DecodeDeviceCursor(req.Cursor, req.Sort); err != nil {
+			errs = append(errs, errors.InvalidParameterError("cursor", err.Error()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateBrands validates the brands of a search
+func validateBrands(brands []model.Brand) []error {
+	var errs []error
+	for _, brand := range brands {
+		if !brand.IsValid() {
+			errs = append(errs, errors.InvalidParameterError("brand", "invalid value ["+string(brand)+"]"))
 		}
 	}
-	return nil
+	return errs
 }
 
 func (dh deviceHandler) getDevices(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	req := new(searchDevicesRequest)
 	if err := req.Build(r); err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
-	var res []dto.DeviceDTO
-	var err error
-	if req.Brand != "" {
-		res, err = dh.service.DeviceController().GetDevicesByBrand(ctx, req.Brand)
-		if err != nil {
-			util.JSONErrorWithCtx(ctx, w, err, http.StatusInternalServerError)
-			return
-		}
-	} else {
-		res, err = dh.service.DeviceController().GetDevices(ctx)
-		if err != nil {
-			util.JSONErrorWithCtx(ctx, w, err, http.StatusInternalServerError)
-			return
-		}
+	search, err := req.ToModel()
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	res, err := dh.service.DeviceController().GetDevices(ctx, search)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	// a page has no Last-Modified: deleting a device changes it without changing the devices left
+	etag, err := bodyETag(res)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+	validators := cacheValidators{
+		etag:         etag,
+		cacheControl: dh.config.GetDevicesCacheControl,
+	}
+	if validators.writeNotModified(w, r) {
+		return
 	}
 
 	util.JSONReturnWithCtx(ctx, w, http.StatusOK, res)
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/util"
+)
+
+// getTrash lists the soft deleted devices, with the same parameters as getDevices
+func (dh deviceHandler) getTrash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(searchDevicesRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	search, err := req.ToModel()
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+	search.Deleted = true
+
+	res, err := dh.service.DeviceController().GetDevices(ctx, search)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, res)
+}
@@ -10,6 +10,7 @@ import (
 type deviceHandler struct {
 	*mux.Router
 	service controller.ServiceController
+	config  Config
 }
 
 func (handler deviceHandler) addRoute(router *mux.Router, path, method string, f func(http.ResponseWriter, *http.Request)) {
@@ -17,20 +18,50 @@ func (handler deviceHandler) addRoute(router *mux.Router, path, method string, f
 }
 
 func addRoutes(router *mux.Router, handler deviceHandler) {
+	handler.addRoute(router, "/jobs", http.MethodPost, handler.createBulkJob)
+	handler.addRoute(router, "/jobs/{id}", http.MethodGet, handler.getBulkJob)
+	handler.addRoute(router, "/jobs/{id}/cancel", http.MethodPost, handler.cancelBulkJob)
 	handler.addRoute(router, "/{id}/name", http.MethodPut, handler.updateDeviceName)
+	handler.addRoute(router, "/trash", http.MethodGet, handler.getTrash)
+	handler.addRoute(router, "/export", http.MethodGet, handler.exportDevices)
+	handler.addRoute(router, "/bulk", http.MethodPost, handler.createDevices)
+	handler.addRoute(router, "/import", http.MethodPost, handler.importDevices)
+	handler.addRoute(router, "/by-external/{system}/{value:.+}", http.MethodGet, handler.getDeviceByExternalID)
 	handler.addRoute(router, "/{id}/brand", http.MethodPut, handler.updateDeviceBrand)
+	handler.addRoute(router, "/{id}/location", http.MethodPut, handler.updateDeviceLocation)
+	handler.addRoute(router, "/{id}/restore", http.MethodPost, handler.restoreDevice)
+	handler.addRoute(router, "/{id}/transitions", http.MethodPost, handler.transitionDevice)
+	handler.addRoute(router, "/{id}/checkout", http.MethodPost, handler.checkoutDevice)
+	handler.addRoute(router, "/{id}/checkin", http.MethodPost, handler.checkinDevice)
+	handler.addRoute(router, "/{id}/labels/{key}", http.MethodPut, handler.setDeviceLabel)
+	handler.addRoute(router, "/{id}/labels/{key}", http.MethodDelete, handler.removeDeviceLabel)
+	handler.addRoute(router, "/{id}/parent", http.MethodPut, handler.setDeviceParent)
+	handler.addRoute(router, "/{id}/children", http.MethodGet, handler.getDeviceChildren)
+	handler.addRoute(router, "/{id}/ancestors", http.MethodGet, handler.getDeviceAncestors)
+	handler.addRoute(router, "/{id}/heartbeat", http.MethodPost, handler.heartbeatDevice)
+	handler.addRoute(router, "/{id}/heartbeat-token", http.MethodPost, handler.rotateHeartbeatToken)
+	handler.addRoute(router, "/{id}/telemetry", http.MethodPost, handler.addDeviceTelemetry)
+	handler.addRoute(router, "/{id}/telemetry", http.MethodGet, handler.getDeviceTelemetry)
+	handler.addRoute(router, "/{id}/twin", http.MethodGet, handler.getDeviceTwin)
+	handler.addRoute(router, "/{id}/twin/desired", http.MethodPut, handler.setDeviceTwinDesired)
+	handler.addRoute(router, "/{id}/twin/reported", http.MethodPut, handler.setDeviceTwinReported)
+	handler.addRoute(router, "/{id}/twin/delta", http.MethodGet, handler.getDeviceTwinDelta)
+	handler.addRoute(router, "/{id}/history", http.MethodGet, handler.getDeviceHistory)
+	handler.addRoute(router, "/{id}/history/{revision}", http.MethodGet, handler.getDeviceRevision)
 	handler.addRoute(router, "/{id}", http.MethodGet, handler.getDevice)
 	handler.addRoute(router, "/{id}", http.MethodPut, handler.updateDevice)
+	handler.addRoute(router, "/{id}", http.MethodPatch, handler.patchDevice)
 	handler.addRoute(router, "/{id}", http.MethodDelete, handler.deleteDevice)
-	handler.addRoute(router, "", http.MethodPost, handler.createDevice)
+	handler.addRoute(router, "", http.MethodPost, handler.idempotent(handler.createDevice))
 	handler.addRoute(router, "", http.MethodGet, handler.getDevices)
 }
 
-func newDevice(service controller.ServiceController) deviceHandler {
+func newDevice(service controller.ServiceController, config Config) deviceHandler {
 	router := mux.NewRouter().PathPrefix(URLPath).Subrouter()
 	handler := deviceHandler{
 		Router:  router,
 		service: service,
+		config:  config,
 	}
 	addRoutes(router, handler)
 	return handler
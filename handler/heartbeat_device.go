@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bearerPrefix is the prefix of the Authorization header carrying a heartbeat token
+const bearerPrefix = "Bearer "
+
+type heartbeatDeviceParameters struct {
+	deviceID primitive.ObjectID
+	// token is the heartbeat token of the Authorization header, empty when it is missing
+	token string
+}
+
+func (params *heartbeatDeviceParameters) Build(r *http.Request) error {
+	var err error
+	params.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+
+	params.token = bearerToken(r)
+
+	return nil
+}
+
+// bearerToken returns the token of the Authorization header of a request, empty when there is none
+func bearerToken(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) > len(bearerPrefix) && strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return strings.TrimSpace(authorization[len(bearerPrefix):])
+	}
+	return ""
+}
+
+func (h deviceHandler) heartbeatDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(heartbeatDeviceParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	err := h.service.DeviceController().Heartbeat(ctx, params.deviceID, params.token)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header making a request safe to retry: the response of its first request
+	// is replayed for the same request sent again with the same key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is the response header telling that the response is a replay
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength is the maximum length of an idempotency key
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBody is the maximum size of the body of a request sent with an idempotency key,
+	// read whole to be hashed before the request is handled: a device, as large as a NDJSON line at most
+	maxIdempotentBody = maxNDJSONLine
+)
+
+// idempotencyRecorder records the response sent to the client, to replay it
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *idempotencyRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// requestHash identifies a request by its method, path and body
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// idempotent makes a handler replay the response of the first request sent with an Idempotency-Key header,
+// when the same request is sent again with the key. Requests without the header are handled as usual.
+// Server errors are not replayed, the key being released so that the request can be retried.
+func (h deviceHandler) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		if len(key) > maxIdempotencyKeyLength {
+			util.JSONErrorWithCtx(ctx, w, errors.InvalidParameterError(IdempotencyKeyHeader, "must have at most "+strconv.Itoa(maxIdempotencyKeyLength)+" characters"))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxIdempotentBody))
+		if err != nil {
+			util.JSONErrorWithCtx(ctx, w, errors.DecodeError(err))
+			return
+		}
+
+		controller := h.service.IdempotencyController()
+		replay, err := controller.Begin(ctx, key, requestHash(r, body), h.config.idempotencyKeyTTL())
+		if err != nil {
+			util.JSONErrorWithCtx(ctx, w, err)
+			return
+		}
+		if replay != nil {
+			if replay.ContentType != "" {
+				w.Header().Set("Content-Type", replay.ContentType)
+			}
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(replay.Status)
+			if _, err := w.Write(replay.Body); err != nil {
+				log.Println("could not replay the response of idempotency key " + key + ": " + err.Error())
+			}
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		rec := &idempotencyRecorder{ResponseWriter: w}
+		next(rec, r)
+		if rec.status == 0 {
+			// nothing written, net/http sends 200
+			rec.status = http.StatusOK
+		}
+
+		if rec.status >= http.StatusInternalServerError {
+			err = controller.Release(ctx, key)
+		} else {
+			err = controller.Complete(ctx, key, rec.status, w.Header().Get("Content-Type"), rec.body.Bytes(), createdDeviceID(rec))
+		}
+		if err != nil {
+			log.Println("could not save the response of idempotency key " + key + ": " + err.Error())
+		}
+	}
+}
+
+// createdDeviceID returns the id of the device a recorded response created, if any, so that its key is purged with it
+func createdDeviceID(rec *idempotencyRecorder) *primitive.ObjectID {
+	if rec.status != http.StatusCreated {
+		return nil
+	}
+	var created dto.CreatedDeviceResponseDTO
+	if err := json.Unmarshal(rec.body.Bytes(), &created); err != nil {
+		return nil
+	}
+	id, err := primitive.ObjectIDFromHex(created.ID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/csv"
+	stderrors "errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+)
+
+type importDevicesRequest struct {
+	mode        dto.ImportMode
+	nameColumn  string
+	brandColumn string
+	items       []*model.BulkItem
+	lines       []int
+}
+
+// Build builds the devices to import from a CSV body whose header maps the name and brand columns.
+// The invalid rows are failed items instead of failing the request, so that they are all reported.
+func (req *importDevicesRequest) Build(r *http.Request) error {
+	var errs []error
+	query := r.URL.Query()
+	req.mode = dto.ImportTransactional
+	if mode := query.Get("mode"); mode != "" {
+		req.mode = dto.ImportMode(mode)
+		if !req.mode.IsValid() {
+			errs = append(errs, errors.InvalidParameterError("mode", "invalid value ["+mode+"]"))
+		}
+	}
+	req.nameColumn = columnParameter(query.Get("nameColumn"), "name")
+	req.brandColumn = columnParameter(query.Get("brandColumn"), "brand")
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	if mediaType != dto.CSVMediaType {
+		return errors.UnsupportedMediaTypeError(mediaType, dto.CSVMediaType)
+	}
+
+	errs = append(errs, req.decode(r.Body))
+	return errors.Join(errs...)
+}
+
+// columnParameter returns the header of a column, matched case insensitively
+func columnParameter(column, defaultColumn string) string {
+	column = strings.TrimSpace(column)
+	if column == "" {
+		return defaultColumn
+	}
+	return strings.ToLower(column)
+}
+
+// decode decodes a device per row, a malformed row only failing its own device
+func (req *importDevicesRequest) decode(body io.Reader) error {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return errors.InvalidParameterError("body", "must have a header line")
+	}
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	nameIndex, brandIndex := -1, -1
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		switch column {
+		case req.nameColumn:
+			nameIndex = i
+		case req.brandColumn:
+			brandIndex = i
+		}
+	}
+	var errs []error
+	if nameIndex < 0 {
+		errs = append(errs, errors.InvalidParameterError("nameColumn", "no column ["+req.nameColumn+"] in the header"))
+	}
+	if brandIndex < 0 {
+		errs = append(errs, errors.InvalidParameterError("brandColumn", "no column ["+req.brandColumn+"] in the header"))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for len(req.items) <= model.MaxImportRows {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if stderrors.As(err, &parseErr) {
+			req.add(parseErr.StartLine, createDeviceRequest{}, errors.DecodeError(err))
+			continue
+		}
+		if err != nil {
+			return errors.DecodeError(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		var device createDeviceRequest
+		device.Name = csvField(record, nameIndex)
+		device.Brand = model.Brand(csvField(record, brandIndex))
+		req.add(line, device, device.Validate())
+	}
+	if len(req.items) == 0 || len(req.items) > model.MaxImportRows {
+		return errors.InvalidParameterError("body", "must have between 1 and "+strconv.Itoa(model.MaxImportRows)+" rows")
+	}
+	return nil
+}
+
+// csvField returns the trimmed field of a record, empty when the record is too short
+func csvField(record []string, index int) string {
+	if index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+// add adds a device to import read from a line, failed when err is not nil
+func (req *importDevicesRequest) add(line int, device createDeviceRequest, err error) {
+	item := &model.BulkItem{Device: device.ToModel()}
+	if err != nil {
+		item.Fail(err)
+	}
+	req.items = append(req.items, item)
+	req.lines = append(req.lines, line)
+}
+
+func (h deviceHandler) importDevices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(importDevicesRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	err := h.service.DeviceController().Import(ctx, req.items, req.mode == dto.ImportTransactional)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	report := dto.ToImportReportDTO(req.mode, req.lines, req.items)
+	if !util.Accepts(r, dto.CSVMediaType) {
+		util.JSONReturnWithCtx(ctx, w, http.StatusOK, report)
+		return
+	}
+	w.Header().Set("Content-Type", dto.CSVMediaType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="import-report.csv"`)
+	w.WriteHeader(http.StatusOK)
+	if err := report.WriteCSV(w); err != nil {
+		log.Println("could not write the import report: " + err.Error())
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type patchDeviceRequest struct {
+	deviceID primitive.ObjectID
+	patch    dto.DevicePatchDTO
+}
+
+// Build builds the patch request from a merge patch or a JSON patch body, according to its content type
+func (req *patchDeviceRequest) Build(r *http.Request) error {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	switch mediaType {
+	case dto.MergePatchMediaType:
+		patch := dto.MergePatchDTO{}
+		err = json.NewDecoder(r.Body).Decode(&patch)
+		req.patch = patch
+	case dto.JSONPatchMediaType:
+		patch := dto.JSONPatchDTO{}
+		err = json.NewDecoder(r.Body).Decode(&patch)
+		req.patch = patch
+	default:
+		return errors.UnsupportedMediaTypeError(mediaType, dto.MergePatchMediaType, dto.JSONPatchMediaType)
+	}
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	var idErr error
+	req.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		idErr = errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+
+	return errors.Join(idErr, req.patch.Validate())
+}
+
+// apply patches the device, validating the result as an update request
+func (req patchDeviceRequest) apply(device *model.Device) error {
+	upd, err := req.patch.Apply(device)
+	if err != nil {
+		return err
+	}
+	err = updateDeviceRequest{UpdateDeviceRequestDTO: upd}.Validate()
+	if err != nil {
+		return err
+	}
+	patched, err := upd.ToModel()
+	if err != nil {
+		return err
+	}
+	device.Name = patched.Name
+	device.Brand = patched.Brand
+	device.SerialNumber = patched.SerialNumber
+	device.ExternalIDs = patched.ExternalIDs
+	device.Attributes = patched.Attributes
+	return nil
+}
+
+func (h deviceHandler) patchDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(patchDeviceRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	device, err := h.service.DeviceController().Patch(ctx, req.deviceID, match, req.apply)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(device.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceDTO(device))
+}
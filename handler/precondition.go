@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+)
+
+// deviceETag returns the entity tag of a device version
+func deviceETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// deviceRepresentationETag returns the entity tag of the representation of a device at a time. The last heartbeat
+// and the connectivity of a device change without its version, so they are part of the tag of the devices having
+// sent a heartbeat, after their version.
+func deviceRepresentationETag(device *model.Device, at time.Time) string {
+	if device.LastSeenAt == nil {
+		return deviceETag(device.Version)
+	}
+	return `"` + strconv.FormatInt(device.Version, 10) + "." + strconv.FormatInt(device.LastSeenAt.Unix(), 10) +
+		"." + string(device.Connectivity(at)) + `"`
+}
+
+// versionMatch returns the device versions the If-Match header allows to change.
+// Without the header, or with If-Match: *, any version can be changed, unless the header is required by the configuration.
+// The entity tags of the device representations match their version. Weak and unknown entity tags match no version.
+func (h deviceHandler) versionMatch(r *http.Request) (model.VersionMatch, error) {
+	values := r.Header.Values("If-Match")
+	if len(values) == 0 {
+		if h.config.RequireIfMatch {
+			return nil, errors.PreconditionRequiredError("If-Match")
+		}
+		return nil, nil
+	}
+
+	match := model.VersionMatch{}
+	for _, tag := range strings.Split(strings.Join(values, ","), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, nil
+		}
+		if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			continue
+		}
+		versionTag, _, _ := strings.Cut(tag[1:len(tag)-1], ".")
+		version, err := strconv.ParseInt(versionTag, 10, 64)
+		if err == nil {
+			match = append(match, version)
+		}
+	}
+	return match, nil
+}
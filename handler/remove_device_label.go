@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type removeDeviceLabelParameters struct {
+	deviceID primitive.ObjectID
+	key      string
+}
+
+func (params *removeDeviceLabelParameters) Build(r *http.Request) error {
+	var idErr, keyErr error
+	deviceID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		idErr = errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+	params.deviceID = deviceID
+	params.key = mux.Vars(r)["key"]
+	if !model.IsValidLabelKey(params.key) {
+		keyErr = errors.InvalidParameterError("key", "invalid value ["+params.key+"]")
+	}
+
+	return errors.Join(idErr, keyErr)
+}
+
+func (h deviceHandler) removeDeviceLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(removeDeviceLabelParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	device, err := h.service.DeviceController().RemoveLabel(ctx, params.deviceID, params.key, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(device.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceDTO(device))
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type rotateHeartbeatTokenParameters struct {
+	deviceID primitive.ObjectID
+}
+
+func (params *rotateHeartbeatTokenParameters) Build(r *http.Request) error {
+	var err error
+	params.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+	return nil
+}
+
+func (h deviceHandler) rotateHeartbeatToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := new(rotateHeartbeatTokenParameters)
+	if err := params.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	token, err := h.service.DeviceController().RotateHeartbeatToken(ctx, params.deviceID, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	// the token is only known by the caller from now on, it must not be kept by any cache
+	w.Header().Set("Cache-Control", "no-store")
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.HeartbeatTokenDTO{Token: token})
+}
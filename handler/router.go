@@ -7,12 +7,15 @@ import (
 	"net/http"
 
 	"github.com/device-ms/controller"
+	"github.com/device-ms/util"
 	"github.com/gorilla/mux"
 )
 
 const (
 	// URLPath Device resource base url
 	URLPath = "/device"
+	// BrandURLPath Brand resource base url
+	BrandURLPath = "/brand"
 )
 
 type (
@@ -56,13 +59,16 @@ func HandleNotFound(w http.ResponseWriter, req *http.Request) {
 }
 
 // NewDeviceRouter creates a router for this microservice.
-func NewDeviceRouter(service controller.ServiceController) Router {
+func NewDeviceRouter(service controller.ServiceController, config Config) Router {
 	router := Router{
 		Router: mux.NewRouter(),
 	}
 	router.HandleFunc("/heartbeat", HealthzHandler)
-	router.PathPrefix(URLPath).Handler(newDevice(service))
+	router.PathPrefix(BrandURLPath).Handler(newBrand(service))
+	router.PathPrefix(URLPath).Handler(newDevice(service, config))
 	router.NotFoundHandler = http.HandlerFunc(HandleNotFound)
+	router.Use(util.WithRequestInfo)
+	router.Use(withActor)
 
 	return router
 }
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type setDeviceLabelRequest struct {
+	dto.SetLabelRequestDTO
+}
+
+// Build builds the set label request dto, the key of the label being in the path
+func (req *setDeviceLabelRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	var idErr error
+	req.DeviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		idErr = errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+	req.Key = mux.Vars(r)["key"]
+
+	return errors.Join(idErr, req.Validate())
+}
+
+// Validate validates the set label request dto
+func (req setDeviceLabelRequest) Validate() error {
+	var errs []error
+	if !model.IsValidLabelKey(req.Key) {
+		errs = append(errs, errors.InvalidParameterError("key", "invalid value ["+req.Key+"]"))
+	}
+	if req.Value == nil {
+		errs = append(errs, errors.RequiredParameterError("value", "body"))
+	} else if !model.IsValidLabelValue(*req.Value) {
+		errs = append(errs, errors.InvalidParameterError("value", "invalid value ["+*req.Value+"]"))
+	}
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) setDeviceLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(setDeviceLabelRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	device, err := h.service.DeviceController().SetLabel(ctx, req.DeviceID, req.Key, *req.Value, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(device.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceDTO(device))
+}
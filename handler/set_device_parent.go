@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type setDeviceParentRequest struct {
+	dto.SetParentRequestDTO
+	parentID *primitive.ObjectID
+}
+
+// Build builds the set parent request dto, a null parent id detaching the device from its parent
+func (req *setDeviceParentRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(&req.SetParentRequestDTO)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	var errs []error
+	req.DeviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		errs = append(errs, errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]"))
+	}
+	if req.ParentID != nil {
+		parentID, err := primitive.ObjectIDFromHex(*req.ParentID)
+		if err != nil {
+			errs = append(errs, errors.InvalidParameterError("parentId", "invalid object id ["+*req.ParentID+"]"))
+		}
+		req.parentID = &parentID
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) setDeviceParent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(setDeviceParentRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	device, err := h.service.DeviceController().SetParent(ctx, req.DeviceID, req.parentID, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(device.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceDTO(device))
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type setDeviceTwinRequest struct {
+	deviceID   primitive.ObjectID
+	properties model.TwinProperties
+}
+
+// Build builds the set twin request from the path and from the body, the properties as a JSON object
+func (req *setDeviceTwinRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(&req.properties)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	var errs []error
+	req.deviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		errs = append(errs, errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]"))
+	}
+	if req.properties == nil {
+		errs = append(errs, errors.InvalidParameterError("properties", "must be an object"))
+	}
+	for _, violation := range req.properties.Violations() {
+		errs = append(errs, errors.InvalidParameterError("properties"+violation.Path, violation.Reason))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) setDeviceTwinDesired(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(setDeviceTwinRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	twin, err := h.service.TwinController().SetDesired(ctx, req.deviceID, req.properties, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(twin.Desired.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToTwinSideDTO(twin.Desired))
+}
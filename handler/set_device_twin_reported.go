@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/util"
+)
+
+// setDeviceTwinReported replaces the reported properties of a device, the device authenticating with its heartbeat token
+func (h deviceHandler) setDeviceTwinReported(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(setDeviceTwinRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	twin, err := h.service.TwinController().SetReported(ctx, req.deviceID, bearerToken(r), req.properties)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(twin.Reported.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToTwinSideDTO(twin.Reported))
+}
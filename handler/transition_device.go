@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type transitionDeviceRequest struct {
+	dto.TransitionRequestDTO
+}
+
+// Build builds the transition request dto
+func (req *transitionDeviceRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	var idErr error
+	req.DeviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		idErr = errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+	}
+
+	return errors.Join(idErr, req.Validate())
+}
+
+// Validate validates the transition request dto
+func (req transitionDeviceRequest) Validate() error {
+	var errs []error
+	if req.To == "" {
+		errs = append(errs, errors.RequiredParameterError("to", "body"))
+	} else if !req.To.IsValid() {
+		errs = append(errs, errors.InvalidParameterError("to", "invalid value ["+string(req.To)+"]"))
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		errs = append(errs, errors.RequiredParameterError("reason", "body"))
+	} else if len(req.Reason) > model.MaxTransitionReasonLength {
+		errs = append(errs, errors.InvalidParameterError("reason", "must have at most "+strconv.Itoa(model.MaxTransitionReasonLength)+" characters"))
+	}
+	return errors.Join(errs...)
+}
+
+func (h deviceHandler) transitionDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(transitionDeviceRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	device, err := h.service.DeviceController().Transition(ctx, req.DeviceID, req.To, req.Reason, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", deviceETag(device.Version))
+	util.JSONReturnWithCtx(ctx, w, http.StatusOK, dto.ToDeviceDTO(device))
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+)
+
+type updateBrandRequest struct {
+	dto.UpdateBrandRequestDTO
+}
+
+// Build builds the update brand request dto
+func (req *updateBrandRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	req.Name = model.Brand(mux.Vars(r)["name"])
+
+	return req.Validate()
+}
+
+// Validate validates the update brand request dto
+func (req updateBrandRequest) Validate() error {
+	return validateAttributesSchema(req.AttributesSchema)
+}
+
+func (h brandHandler) updateBrand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(updateBrandRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	err := h.service.BrandController().Update(ctx, req.ToModel())
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusNoContent, nil)
+}
@@ -22,42 +22,51 @@ func (req *updateDeviceRequest) Build(r *http.Request) error {
 		return errors.DecodeError(err)
 	}
 
+	var idErr error
 	req.DeviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
 	if err != nil {
-		return errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
+		idErr = errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]")
 	}
 
-	return req.Validate()
+	return errors.Join(idErr, req.Validate())
 }
 
 // Validate validates the update request dto
 func (req updateDeviceRequest) Validate() error {
+	var errs []error
 	if req.Brand == "" {
-		return errors.RequiredParameterError("brand", "body")
+		errs = append(errs, errors.RequiredParameterError("brand", "body"))
+	} else if !req.Brand.IsValid() {
+		errs = append(errs, errors.InvalidParameterError("brand", "invalid value ["+string(req.Brand)+"]"))
 	}
-	if !req.Brand.IsValid() {
-		return errors.InvalidParameterError("brand", "invalid value ["+string(req.Brand)+"]")
-	}
-	return nil
+	errs = append(errs, validateIdentifiers(req.SerialNumber, req.ExternalIDs)...)
+	errs = append(errs, dto.ValidateAttributes(req.Brand, req.Attributes)...)
+	return errors.Join(errs...)
 }
 
 func (h deviceHandler) updateDevice(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	req := new(updateDeviceRequest)
 	if err := req.Build(r); err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
 	device, err := req.ToModel()
 	if err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
-	err = h.service.DeviceController().Update(ctx, device)
+	err = h.service.DeviceController().Update(ctx, device, match)
 	if err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusInternalServerError)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
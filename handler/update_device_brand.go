@@ -34,19 +34,25 @@ func (h deviceHandler) updateDeviceBrand(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 	req := new(updateDeviceBrandRequest)
 	if err := req.Build(r); err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
 	device, err := req.ToModel()
 	if err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
-	err = h.service.DeviceController().UpdateBrand(ctx, device.ID, device.Brand)
+	match, err := h.versionMatch(r)
 	if err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusInternalServerError)
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	err = h.service.DeviceController().UpdateBrand(ctx, device.ID, device.Brand, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/device-ms/dto"
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/device-ms/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type updateDeviceLocationRequest struct {
+	dto.UpdateDeviceLocationRequestDTO
+}
+
+// Build builds the update device location request dto
+func (req *updateDeviceLocationRequest) Build(r *http.Request) error {
+	err := json.NewDecoder(r.Body).Decode(&req.LocationDTO)
+	if err != nil {
+		return errors.DecodeError(err)
+	}
+
+	var errs []error
+	req.DeviceID, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		errs = append(errs, errors.InvalidParameterError("id", "invalid object id ["+mux.Vars(r)["id"]+"]"))
+	}
+	errs = append(errs, validateLocation("", &req.LocationDTO)...)
+
+	return errors.Join(errs...)
+}
+
+// validateLocation validates the parts of a location, their fields being prefixed with prefix
+func validateLocation(prefix string, location *dto.LocationDTO) []error {
+	if location == nil {
+		return nil
+	}
+	var errs []error
+	parts := []struct{ field, value string }{
+		{"site", location.Site},
+		{"building", location.Building},
+		{"room", location.Room},
+	}
+	for _, part := range parts {
+		if len(part.value) > model.MaxLocationPartLength {
+			errs = append(errs, errors.InvalidParameterError(prefix+part.field, "must have at most "+strconv.Itoa(model.MaxLocationPartLength)+" characters"))
+		}
+	}
+	if location.Point != nil {
+		point := model.GeoPoint{Type: location.Point.Type, Coordinates: location.Point.Coordinates}
+		if err := point.Validate(); err != nil {
+			errs = append(errs, errors.InvalidParameterError(prefix+"point", err.Error()))
+		}
+	}
+	return errs
+}
+
+func (h deviceHandler) updateDeviceLocation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req := new(updateDeviceLocationRequest)
+	if err := req.Build(r); err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	device, err := req.ToModel()
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	match, err := h.versionMatch(r)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	err = h.service.DeviceController().UpdateLocation(ctx, device.ID, device.Location, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	util.JSONReturnWithCtx(ctx, w, http.StatusNoContent, nil)
+}
@@ -34,19 +34,25 @@ func (h deviceHandler) updateDeviceName(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 	req := new(updateDeviceNameRequest)
 	if err := req.Build(r); err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
 	device, err := req.ToModel()
 	if err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusBadRequest)
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
-	err = h.service.DeviceController().UpdateName(ctx, device.ID, device.Name)
+	match, err := h.versionMatch(r)
 	if err != nil {
-		util.JSONErrorWithCtx(ctx, w, err, http.StatusInternalServerError)
+		util.JSONErrorWithCtx(ctx, w, err)
+		return
+	}
+
+	err = h.service.DeviceController().UpdateName(ctx, device.ID, device.Name, match)
+	if err != nil {
+		util.JSONErrorWithCtx(ctx, w, err)
 		return
 	}
 
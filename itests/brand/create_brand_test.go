@@ -0,0 +1,66 @@
+package brand
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/brand"
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateBrand(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	t.Run("fail with missing required field name", func(t *testing.T) {
+		params := brand.NewCreateBrandParams().WithBrandCreationRequestBody(&models.CreateBrandRequest{})
+		_, err := iti.ServiceClient.Brand.CreateBrand(params)
+		require.EqualError(t, err, "[POST /brand][400] createBrandBadRequest {\"code\":1500001,\"message\":\"parameter 'name' in body is required\"}")
+	})
+
+	t.Run("fail with invalid name", func(t *testing.T) {
+		params := brand.NewCreateBrandParams().WithBrandCreationRequestBody(&models.CreateBrandRequest{
+			Name: "brand one",
+		})
+		_, err := iti.ServiceClient.Brand.CreateBrand(params)
+		require.EqualError(t, err, "[POST /brand][400] createBrandBadRequest {\"code\":1500002,\"message\":\"parameter 'name' is invalid 'invalid value [brand one]'\"}")
+	})
+
+	t.Run("fail brand already exists", func(t *testing.T) {
+		params := brand.NewCreateBrandParams().WithBrandCreationRequestBody(&models.CreateBrandRequest{
+			Name: "brand1",
+		})
+		_, err := iti.ServiceClient.Brand.CreateBrand(params)
+		require.EqualError(t, err, "[POST /brand][409] createBrandConflict {\"code\":1500010,\"message\":\"the brand brand1 already exists\"}")
+	})
+
+	t.Run("ok and usable by devices", func(t *testing.T) {
+		deviceParams := device.NewCreateDeviceParams().WithDeviceCreationRequestBody(&models.CreateDeviceRequest{
+			Brand: "acme",
+			Name:  "earth",
+		})
+		_, err := iti.ServiceClient.Device.CreateDevice(deviceParams)
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [acme]'\"}")
+
+		params := brand.NewCreateBrandParams().WithBrandCreationRequestBody(&models.CreateBrandRequest{
+			Name:        "acme",
+			Description: "acme devices",
+		})
+		brandCreated, err := iti.ServiceClient.Brand.CreateBrand(params)
+		require.NoError(t, err)
+		require.Equal(t, "acme", brandCreated.Payload.Name)
+		require.Equal(t, "acme devices", brandCreated.Payload.Description)
+
+		br, err := iti.BrandRepository.ByName(ctx, "acme")
+		require.NoError(t, err)
+		require.Equal(t, "acme devices", br.Description)
+
+		_, err = iti.ServiceClient.Device.CreateDevice(deviceParams)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,43 @@
+package brand
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/brand"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DeleteBrand(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	t.Run("fail brand not found", func(t *testing.T) {
+		params := brand.NewDeleteBrandParams().WithName("nobrand")
+		_, err := iti.ServiceClient.Brand.DeleteBrand(params)
+		require.EqualError(t, err, "[DELETE /brand/{name}][404] deleteBrandNotFound {\"code\":1500005,\"message\":\"the brand with id nobrand could not be found: mongo: no documents in result\"}")
+	})
+
+	t.Run("fail brand in use", func(t *testing.T) {
+		err := iti.DeviceRepository.Create(ctx, &model.Device{Brand: "brand1", Name: "earth"})
+		require.NoError(t, err)
+
+		params := brand.NewDeleteBrandParams().WithName("brand1")
+		_, err = iti.ServiceClient.Brand.DeleteBrand(params)
+		require.EqualError(t, err, "[DELETE /brand/{name}][409] deleteBrandConflict {\"code\":1500009,\"message\":\"the brand brand1 is used by 1 device(s)\"}")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		params := brand.NewDeleteBrandParams().WithName("brand3")
+		_, err := iti.ServiceClient.Brand.DeleteBrand(params)
+		require.NoError(t, err)
+
+		_, err = iti.BrandRepository.ByName(ctx, "brand3")
+		require.EqualError(t, err, "result: false; code: 1500005; message: the brand with id brand3 could not be found")
+		require.False(t, model.Brand("brand3").IsValid())
+	})
+}
@@ -0,0 +1,35 @@
+package brand
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/brand"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetBrand(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	t.Run("fail brand not found", func(t *testing.T) {
+		params := brand.NewGetBrandParams().WithName("nobrand")
+		_, err := iti.ServiceClient.Brand.GetBrand(params)
+		require.EqualError(t, err, "[GET /brand/{name}][404] getBrandNotFound {\"code\":1500005,\"message\":\"the brand with id nobrand could not be found\"}")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		err := iti.BrandRepository.Create(ctx, &model.BrandInfo{Name: "acme", Description: "acme devices"})
+		require.NoError(t, err)
+
+		params := brand.NewGetBrandParams().WithName("acme")
+		br, err := iti.ServiceClient.Brand.GetBrand(params)
+		require.NoError(t, err)
+		require.Equal(t, "acme", br.Payload.Name)
+		require.Equal(t, "acme devices", br.Payload.Description)
+	})
+}
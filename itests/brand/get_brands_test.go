@@ -0,0 +1,37 @@
+package brand
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/brand"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetBrands(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	t.Run("default brands", func(t *testing.T) {
+		brs, err := iti.ServiceClient.Brand.GetBrands(brand.NewGetBrandsParams())
+		require.NoError(t, err)
+		require.Len(t, brs.Payload, 3)
+		require.Equal(t, "brand1", brs.Payload[0].Name)
+		require.Equal(t, "brand2", brs.Payload[1].Name)
+		require.Equal(t, "brand3", brs.Payload[2].Name)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		err := iti.BrandRepository.Create(ctx, &model.BrandInfo{Name: "acme"})
+		require.NoError(t, err)
+
+		brs, err := iti.ServiceClient.Brand.GetBrands(brand.NewGetBrandsParams())
+		require.NoError(t, err)
+		require.Len(t, brs.Payload, 4)
+		require.Equal(t, "acme", brs.Payload[0].Name)
+	})
+}
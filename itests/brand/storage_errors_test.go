@@ -0,0 +1,49 @@
+package brand
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/brand"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BrandStorageErrors(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	serviceClient, closeServer := iti.StartUnreachableStorageServer(ctx, t)
+	defer closeServer()
+
+	t.Run("create brand", func(t *testing.T) {
+		params := brand.NewCreateBrandParams().WithBrandCreationRequestBody(&models.CreateBrandRequest{
+			Name: "acme",
+		})
+		_, err := serviceClient.Brand.CreateBrand(params)
+		require.EqualError(t, err, "[POST /brand][500] createBrandInternalServerError {\"code\":1500003,\"message\":\"error creating brand reason client is disconnected\"}")
+	})
+
+	t.Run("get brand", func(t *testing.T) {
+		_, err := serviceClient.Brand.GetBrand(brand.NewGetBrandParams().WithName("acme"))
+		require.EqualError(t, err, "[GET /brand/{name}][500] getBrandInternalServerError {\"code\":1500011,\"message\":\"error reading brand reason client is disconnected\"}")
+	})
+
+	t.Run("get brands", func(t *testing.T) {
+		_, err := serviceClient.Brand.GetBrands(brand.NewGetBrandsParams())
+		require.EqualError(t, err, "[GET /brand][500] getBrandsInternalServerError {\"code\":1500004,\"message\":\"the brand queried by ALL returned an error: client is disconnected\"}")
+	})
+
+	t.Run("update brand", func(t *testing.T) {
+		params := brand.NewUpdateBrandParams().WithName("acme").WithBrandUpdateRequestBody(&models.UpdateBrandRequest{
+			Description: "acme devices",
+		})
+		_, err := serviceClient.Brand.UpdateBrand(params)
+		require.EqualError(t, err, "[PUT /brand/{name}][500] updateBrandInternalServerError {\"code\":1500006,\"message\":\"error updating brand reason client is disconnected\"}")
+	})
+
+	t.Run("delete brand", func(t *testing.T) {
+		_, err := serviceClient.Brand.DeleteBrand(brand.NewDeleteBrandParams().WithName("acme"))
+		require.EqualError(t, err, "[DELETE /brand/{name}][500] deleteBrandInternalServerError {\"code\":1500004,\"message\":\"the device queried by brand, acme returned an error: client is disconnected\"}")
+	})
+}
@@ -0,0 +1,38 @@
+package brand
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/brand"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UpdateBrand(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	t.Run("fail brand not found", func(t *testing.T) {
+		params := brand.NewUpdateBrandParams().WithName("nobrand").WithBrandUpdateRequestBody(&models.UpdateBrandRequest{
+			Description: "no brand",
+		})
+		_, err := iti.ServiceClient.Brand.UpdateBrand(params)
+		require.EqualError(t, err, "[PUT /brand/{name}][404] updateBrandNotFound {\"code\":1500005,\"message\":\"the brand with id nobrand could not be found: mongo: no documents in result\"}")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		params := brand.NewUpdateBrandParams().WithName("brand2").WithBrandUpdateRequestBody(&models.UpdateBrandRequest{
+			Description: "second brand",
+		})
+		_, err := iti.ServiceClient.Brand.UpdateBrand(params)
+		require.NoError(t, err)
+
+		br, err := iti.BrandRepository.ByName(ctx, "brand2")
+		require.NoError(t, err)
+		require.Equal(t, "second brand", br.Description)
+	})
+}
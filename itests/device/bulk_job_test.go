@@ -0,0 +1,112 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_BulkJob(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	var ids []primitive.ObjectID
+	for _, dv := range []*model.Device{{Name: "io", Brand: "brand1"}, {Name: "europa", Brand: "brand1"}, {Name: "titan", Brand: "brand2"}} {
+		require.NoError(t, iti.DeviceRepository.Create(ctx, dv))
+		ids = append(ids, dv.ID)
+	}
+	filter := &models.BulkJobFilter{Brands: []string{"brand1"}}
+
+	t.Run("fail without filter", func(t *testing.T) {
+		params := device.NewCreateBulkJobParams().WithJob(&models.BulkJobRequest{
+			Filter: &models.BulkJobFilter{},
+			Action: &models.BulkAction{Type: models.BulkActionTypeDelete},
+		})
+		_, _, err := iti.ServiceClient.Device.CreateBulkJob(params)
+		require.EqualError(t, err, "[POST /device/jobs][400] createBulkJobBadRequest {\"code\":1500002,\"message\":\"parameter 'filter' is invalid 'must have at least one criterion'\"}")
+	})
+
+	t.Run("fail without new brand", func(t *testing.T) {
+		params := device.NewCreateBulkJobParams().WithJob(&models.BulkJobRequest{
+			Filter: filter,
+			Action: &models.BulkAction{Type: models.BulkActionTypeSetBrand},
+		})
+		_, _, err := iti.ServiceClient.Device.CreateBulkJob(params)
+		require.EqualError(t, err, "[POST /device/jobs][400] createBulkJobBadRequest {\"code\":1500001,\"message\":\"parameter 'action.brand' in body is required\"}")
+	})
+
+	t.Run("dry run counts the devices", func(t *testing.T) {
+		params := device.NewCreateBulkJobParams().WithJob(&models.BulkJobRequest{
+			Filter: filter,
+			Action: &models.BulkAction{Type: models.BulkActionTypeDelete},
+			DryRun: true,
+		})
+		dryRun, accepted, err := iti.ServiceClient.Device.CreateBulkJob(params)
+		require.NoError(t, err)
+		require.Nil(t, accepted)
+		require.Equal(t, int64(2), dryRun.Payload.Matched)
+	})
+
+	t.Run("ok job changes the devices", func(t *testing.T) {
+		params := device.NewCreateBulkJobParams().WithJob(&models.BulkJobRequest{
+			Filter: filter,
+			Action: &models.BulkAction{Type: models.BulkActionTypeSetBrand, Brand: "brand3"},
+		})
+		_, accepted, err := iti.ServiceClient.Device.CreateBulkJob(params)
+		require.NoError(t, err)
+		require.Equal(t, models.BulkJobStatusPending, accepted.Payload.Status)
+		require.Equal(t, int64(2), accepted.Payload.Total)
+		require.Equal(t, "/device/jobs/"+accepted.Payload.ID, accepted.Location)
+
+		ran, err := iti.Controller.BulkJobController().RunNext(ctx)
+		require.NoError(t, err)
+		require.True(t, ran)
+
+		job, err := iti.ServiceClient.Device.GetBulkJob(device.NewGetBulkJobParams().WithID(accepted.Payload.ID))
+		require.NoError(t, err)
+		require.Equal(t, models.BulkJobStatusSucceeded, job.Payload.Status)
+		require.Equal(t, int64(2), job.Payload.Processed)
+		require.Equal(t, int64(0), job.Payload.Failed)
+		for i, brand := range []model.Brand{"brand3", "brand3", "brand2"} {
+			dv, err := iti.DeviceRepository.ByID(ctx, ids[i])
+			require.NoError(t, err)
+			require.Equal(t, brand, dv.Brand)
+		}
+
+		_, err = iti.ServiceClient.Device.CancelBulkJob(device.NewCancelBulkJobParams().WithID(accepted.Payload.ID))
+		require.EqualError(t, err, "[POST /device/jobs/{id}/cancel][409] cancelBulkJobConflict {\"code\":1500019,\"message\":\"the job with id "+accepted.Payload.ID+" is succeeded\"}")
+	})
+
+	t.Run("cancel a pending job", func(t *testing.T) {
+		params := device.NewCreateBulkJobParams().WithJob(&models.BulkJobRequest{
+			Filter: &models.BulkJobFilter{NamePrefix: "ti"},
+			Action: &models.BulkAction{Type: models.BulkActionTypeDelete},
+		})
+		_, accepted, err := iti.ServiceClient.Device.CreateBulkJob(params)
+		require.NoError(t, err)
+
+		cancelled, err := iti.ServiceClient.Device.CancelBulkJob(device.NewCancelBulkJobParams().WithID(accepted.Payload.ID))
+		require.NoError(t, err)
+		require.Equal(t, models.BulkJobStatusCancelled, cancelled.Payload.Status)
+
+		ran, err := iti.Controller.BulkJobController().RunNext(ctx)
+		require.NoError(t, err)
+		require.False(t, ran)
+		_, err = iti.DeviceRepository.ByID(ctx, ids[2])
+		require.NoError(t, err)
+	})
+
+	t.Run("fail job not found", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		_, err := iti.ServiceClient.Device.GetBulkJob(device.NewGetBulkJobParams().WithID(id.Hex()))
+		require.EqualError(t, err, "[GET /device/jobs/{id}][404] getBulkJobNotFound {\"code\":1500005,\"message\":\"the job with id "+id.Hex()+" could not be found\"}")
+	})
+}
@@ -21,7 +21,7 @@ func Test_CreateDevice(t *testing.T) {
 	t.Run("fail with missing required field brand", func(t *testing.T) {
 		params := device.NewCreateDeviceParams().WithDeviceCreationRequestBody(&models.CreateDeviceRequest{})
 		_, err := iti.ServiceClient.Device.CreateDevice(params)
-		require.EqualError(t, err, "[POST /][400] createDeviceBadRequest {\"code\":1500001,\"message\":\"parameter 'brand' in body is required\"}")
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500001,\"message\":\"parameter 'brand' in body is required\"}")
 	})
 
 	t.Run("fail with invalid brand", func(t *testing.T) {
@@ -29,7 +29,7 @@ func Test_CreateDevice(t *testing.T) {
 			Brand: "brand one",
 		})
 		_, err := iti.ServiceClient.Device.CreateDevice(params)
-		require.EqualError(t, err, "[POST /][400] createDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [brand one]'\"}")
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [brand one]'\"}")
 	})
 
 	t.Run("ok", func(t *testing.T) {
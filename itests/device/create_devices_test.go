@@ -0,0 +1,100 @@
+package device
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/handler"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// createDevices sends a bulk creation request, the generated client cannot send NDJSON
+func createDevices(t *testing.T, iti itests.IntTestInfra, query, contentType, body string) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodPost, "http://"+iti.ServerAddress+handler.URLPath+"/bulk?"+query, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(b)
+}
+
+func Test_CreateDevices(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	devices := []*models.CreateDeviceRequest{
+		{Name: "io", Brand: "brand1"},
+		{Name: "europa", Brand: "brand one"},
+		{Name: "ganymede", Brand: "brand2"},
+	}
+
+	t.Run("fail without devices", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.CreateDevices(device.NewCreateDevicesParams().WithDevices([]*models.CreateDeviceRequest{}))
+		require.EqualError(t, err, "[POST /device/bulk][400] createDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'body' is invalid 'must have between 1 and 1000 devices'\"}")
+	})
+
+	t.Run("fail too many devices", func(t *testing.T) {
+		body := "[" + strings.Repeat("{\"name\":\"io\",\"brand\":\"brand1\"},", 1001) + "{}]"
+		resp, respBody := createDevices(t, iti, "", "application/json", body)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Contains(t, respBody, "parameter 'body' is invalid 'must have between 1 and 1000 devices'")
+	})
+
+	t.Run("fail not an array", func(t *testing.T) {
+		resp, respBody := createDevices(t, iti, "", "application/json", "{\"name\":\"io\"}")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Contains(t, respBody, "parameter 'body' is invalid 'expected a JSON array of devices'")
+	})
+
+	t.Run("fail unsupported media type", func(t *testing.T) {
+		resp, body := createDevices(t, iti, "", "text/csv", "name,brand\nio,brand1\n")
+		require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500016,\"message\":\"unsupported media type [text/csv], expected one of [application/json, application/x-ndjson]\"}\n", body)
+	})
+
+	t.Run("ordered creation stops at the first failure", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.CreateDevices(device.NewCreateDevicesParams().WithDevices(devices))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), res.Payload.Created)
+		require.Equal(t, int64(1), res.Payload.Failed)
+		require.Equal(t, int64(1), res.Payload.Skipped)
+		require.Equal(t, models.BulkCreateItemStatusCreated, res.Payload.Items[0].Status)
+		require.Equal(t, models.BulkCreateItemStatusFailed, res.Payload.Items[1].Status)
+		require.Equal(t, "parameter 'brand' is invalid 'invalid value [brand one]'", res.Payload.Items[1].Error.Message)
+		require.Equal(t, models.BulkCreateItemStatusSkipped, res.Payload.Items[2].Status)
+
+		id, err := primitive.ObjectIDFromHex(res.Payload.Items[0].ID)
+		require.NoError(t, err)
+		dv, err := iti.DeviceRepository.ByID(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, "io", dv.Name)
+	})
+
+	t.Run("unordered creation creates the valid devices", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.CreateDevices(device.NewCreateDevicesParams().WithDevices(devices).WithOrdered(itests.NewBool(false)))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), res.Payload.Created)
+		require.Equal(t, int64(1), res.Payload.Failed)
+		require.Equal(t, models.BulkCreateItemStatusCreated, res.Payload.Items[2].Status)
+		require.NotEmpty(t, res.Payload.Items[2].ID)
+	})
+
+	t.Run("ndjson with a malformed line", func(t *testing.T) {
+		resp, body := createDevices(t, iti, "ordered=false", "application/x-ndjson", "{\"name\":\"callisto\",\"brand\":\"brand3\"}\n{\"name\":\n\n{\"brand\":\"brand1\"}\n")
+		require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+		require.Contains(t, body, "\"created\":2,\"failed\":1,\"skipped\":0")
+		require.Contains(t, body, "{\"index\":1,\"status\":\"failed\",\"error\":{\"code\":1500008,\"message\":\"decode error: unexpected end of JSON input\"}}")
+	})
+}
@@ -21,14 +21,14 @@ func Test_DeleteDevice(t *testing.T) {
 		dvIDStr := "12345"
 		params := device.NewDeleteDeviceParams().WithID(dvIDStr)
 		_, err := iti.ServiceClient.Device.DeleteDevice(params)
-		require.EqualError(t, err, "[DELETE /{id}][400] deleteDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id ["+dvIDStr+"]'\"}")
+		require.EqualError(t, err, "[DELETE /device/{id}][400] deleteDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id ["+dvIDStr+"]'\"}")
 	})
 
 	t.Run("fail device not found", func(t *testing.T) {
 		dvID := primitive.NewObjectID()
 		params := device.NewDeleteDeviceParams().WithID(dvID.Hex())
 		_, err := iti.ServiceClient.Device.DeleteDevice(params)
-		require.EqualError(t, err, "[DELETE /{id}][500] deleteDeviceInternalServerError {\"code\":1500005,\"message\":\"the device with id "+dvID.Hex()+" could not be found: mongo: no documents in result\"}")
+		require.EqualError(t, err, "[DELETE /device/{id}][404] deleteDeviceNotFound {\"code\":1500005,\"message\":\"the device with id "+dvID.Hex()+" could not be found: mongo: no documents in result\"}")
 	})
 
 	t.Run("ok", func(t *testing.T) {
@@ -46,4 +46,22 @@ func Test_DeleteDevice(t *testing.T) {
 		_, err = iti.DeviceRepository.ByID(ctx, dv.ID)
 		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+dv.ID.Hex()+" could not be found")
 	})
+
+	t.Run("fail version mismatch", func(t *testing.T) {
+		dv := &model.Device{
+			Brand: "brand3",
+			Name:  "moon",
+		}
+		err := iti.DeviceRepository.Create(ctx, dv)
+		require.NoError(t, err)
+		_, err = iti.DeviceRepository.UpdateName(ctx, dv.ID, "luna", nil)
+		require.NoError(t, err)
+
+		params := device.NewDeleteDeviceParams().WithID(dv.ID.Hex()).WithIfMatch(itests.NewStr("\"1\""))
+		_, err = iti.ServiceClient.Device.DeleteDevice(params)
+		require.EqualError(t, err, "[DELETE /device/{id}][412] deleteDevicePreconditionFailed {\"code\":1500014,\"message\":\"the device with id "+dv.ID.Hex()+" does not have the expected version\"}")
+
+		_, err = iti.ServiceClient.Device.DeleteDevice(params.WithIfMatch(itests.NewStr("\"1\", \"2\"")))
+		require.NoError(t, err)
+	})
 }
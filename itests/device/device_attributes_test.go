@@ -0,0 +1,145 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/device-ms/client/brand"
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/handler"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DeviceAttributes(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	_, err := iti.ServiceClient.Brand.CreateBrand(brand.NewCreateBrandParams().WithBrandCreationRequestBody(&models.CreateBrandRequest{
+		Name: "acme",
+		AttributesSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"ram":    map[string]interface{}{"type": "integer", "minimum": 1},
+				"os":     map[string]interface{}{"enum": []string{"android", "ios"}},
+				"screen": map[string]interface{}{"type": "object", "properties": map[string]interface{}{"size": map[string]interface{}{"type": "number"}}},
+			},
+			"required":             []string{"ram"},
+			"additionalProperties": false,
+		},
+	}))
+	require.NoError(t, err)
+
+	create := func(name string, attributes map[string]interface{}) (*device.CreateDeviceCreated, error) {
+		return iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().
+			WithDeviceCreationRequestBody(&models.CreateDeviceRequest{Name: name, Brand: "acme", Attributes: attributes}))
+	}
+	selectNames := func(query url.Values) []string {
+		resp, err := http.Get("http://" + iti.ServerAddress + handler.URLPath + "?" + query.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		page := new(models.DevicePage)
+		require.NoError(t, json.Unmarshal(body, page))
+		var names []string
+		for _, dv := range page.Items {
+			names = append(names, dv.Name)
+		}
+		return names
+	}
+
+	t.Run("fail attributes violating the schema of the brand", func(t *testing.T) {
+		_, err := create("x", map[string]interface{}{"ram": 0.5, "os": "windows", "color": "red"})
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500013,\"errors\":["+
+			"{\"code\":1500002,\"field\":\"attributes.color\",\"message\":\"parameter 'attributes.color' is invalid 'is not allowed'\"},"+
+			"{\"code\":1500002,\"field\":\"attributes.os\",\"message\":\"parameter 'attributes.os' is invalid 'must be one of [\\\"android\\\",\\\"ios\\\"]'\"},"+
+			"{\"code\":1500002,\"field\":\"attributes.ram\",\"message\":\"parameter 'attributes.ram' is invalid 'expected integer'\"}],"+
+			"\"message\":\"the request has 3 invalid parameters\"}")
+
+		_, err = create("x", nil)
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'attributes.ram' is invalid 'is required'\"}")
+	})
+
+	phone, err := create("io", map[string]interface{}{"ram": 8, "os": "android", "screen": map[string]interface{}{"size": 6.1}})
+	require.NoError(t, err)
+	_, err = create("europa", map[string]interface{}{"ram": 16, "os": "ios"})
+	require.NoError(t, err)
+	moon, err := iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().
+		WithDeviceCreationRequestBody(&models.CreateDeviceRequest{Name: "callisto", Brand: "brand1", Attributes: map[string]interface{}{"color": "red"}}))
+	require.NoError(t, err)
+
+	t.Run("get attributes", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(phone.Payload.ID))
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"ram": 8.0, "os": "android", "screen": map[string]interface{}{"size": 6.1}}, res.Payload.Attributes)
+	})
+
+	t.Run("select by attributes", func(t *testing.T) {
+		require.Equal(t, []string{"europa"}, selectNames(url.Values{"attributes.ram[gt]": {"8"}}))
+		require.Equal(t, []string{"io"}, selectNames(url.Values{"attributes.screen.size": {"6.1"}}))
+		require.Equal(t, []string{"europa", "callisto"}, selectNames(url.Values{"attributes.os[ne]": {"android"}}))
+		require.Equal(t, []string{"callisto"}, selectNames(url.Values{"attributes.color": {"red"}, "brand": {"brand1"}}))
+	})
+
+	t.Run("fail invalid attribute filter", func(t *testing.T) {
+		resp, err := http.Get("http://" + iti.ServerAddress + handler.URLPath + "?" + url.Values{"attributes.ram[in]": {"8"}}.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500002,\"message\":\"parameter 'attributes.ram[in]' is invalid 'unsupported operator [in]'\"}\n", string(body))
+	})
+
+	t.Run("fail brand change to a brand whose schema the attributes violate", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.UpdateDeviceBrand(device.NewUpdateDeviceBrandParams().WithID(moon.Payload.ID).
+			WithDeviceBrandUpdate(&models.DeviceBrandUpdateRequest{Brand: "acme"}))
+		require.EqualError(t, err, "[PUT /device/{id}/brand][400] updateDeviceBrandBadRequest {\"code\":1500013,\"errors\":["+
+			"{\"code\":1500002,\"field\":\"attributes.color\",\"message\":\"parameter 'attributes.color' is invalid 'is not allowed'\"},"+
+			"{\"code\":1500002,\"field\":\"attributes.ram\",\"message\":\"parameter 'attributes.ram' is invalid 'is required'\"}],"+
+			"\"message\":\"the request has 2 invalid parameters\"}")
+
+		res, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(moon.Payload.ID))
+		require.NoError(t, err)
+		require.Equal(t, "brand1", res.Payload.Brand)
+	})
+
+	t.Run("update against the new schema of the brand", func(t *testing.T) {
+		_, err := iti.ServiceClient.Brand.UpdateBrand(brand.NewUpdateBrandParams().WithName("acme").WithBrandUpdateRequestBody(&models.UpdateBrandRequest{
+			AttributesSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"ram": map[string]interface{}{"type": "integer", "maximum": 12}},
+			},
+		}))
+		require.NoError(t, err)
+
+		update := func(attributes map[string]interface{}) error {
+			_, err := iti.ServiceClient.Device.UpdateDevice(device.NewUpdateDeviceParams().WithID(phone.Payload.ID).
+				WithDeviceUpdateRequestBody(&models.UpdateDeviceRequest{Name: "io", Brand: "acme", Attributes: attributes}))
+			return err
+		}
+		err = update(map[string]interface{}{"ram": 16})
+		require.EqualError(t, err, "[PUT /device/{id}][400] updateDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'attributes.ram' is invalid 'must be at most 12'\"}")
+		require.NoError(t, update(map[string]interface{}{"ram": 12, "color": "black"}))
+
+		history, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(phone.Payload.ID))
+		require.NoError(t, err)
+		require.Equal(t, "attributes", history.Payload.Items[0].Changes[0].Field)
+	})
+
+	t.Run("fail invalid schema", func(t *testing.T) {
+		_, err := iti.ServiceClient.Brand.UpdateBrand(brand.NewUpdateBrandParams().WithName("acme").WithBrandUpdateRequestBody(&models.UpdateBrandRequest{
+			AttributesSchema: map[string]interface{}{"type": "object", "anyOf": []interface{}{}},
+		}))
+		require.EqualError(t, err, "[PUT /brand/{name}][400] updateBrandBadRequest {\"code\":1500002,\"message\":\"parameter 'attributesSchema' is invalid 'unsupported keyword [/anyOf]'\"}")
+	})
+}
@@ -0,0 +1,101 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_DeviceHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	dv := &model.Device{Name: "sensor", Brand: "brand1"}
+	require.NoError(t, iti.DeviceRepository.Create(ctx, dv))
+	other := &model.Device{Name: "silent", Brand: "brand1"}
+	require.NoError(t, iti.DeviceRepository.Create(ctx, other))
+
+	heartbeat := func(id, token string) error {
+		_, err := iti.ServiceClient.Device.HeartbeatDevice(device.NewHeartbeatDeviceParams().WithID(id).WithAuthorization("Bearer " + token))
+		return err
+	}
+
+	t.Run("fail without token", func(t *testing.T) {
+		err := heartbeat(dv.ID.Hex(), "")
+		require.EqualError(t, err, "[POST /device/{id}/heartbeat][401] heartbeatDeviceUnauthorized {\"code\":1500024,\"message\":\"the credentials are not valid for the device with id "+dv.ID.Hex()+"\"}")
+	})
+
+	t.Run("fail unknown device", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		err := heartbeat(id.Hex(), "token")
+		require.EqualError(t, err, "[POST /device/{id}/heartbeat][401] heartbeatDeviceUnauthorized {\"code\":1500024,\"message\":\"the credentials are not valid for the device with id "+id.Hex()+"\"}")
+	})
+
+	res, err := iti.ServiceClient.Device.RotateHeartbeatToken(device.NewRotateHeartbeatTokenParams().WithID(dv.ID.Hex()))
+	require.NoError(t, err)
+	token := res.Payload.Token
+	require.NotEmpty(t, token)
+
+	t.Run("fail wrong token", func(t *testing.T) {
+		err := heartbeat(dv.ID.Hex(), token+"x")
+		require.EqualError(t, err, "[POST /device/{id}/heartbeat][401] heartbeatDeviceUnauthorized {\"code\":1500024,\"message\":\"the credentials are not valid for the device with id "+dv.ID.Hex()+"\"}")
+	})
+
+	t.Run("heartbeat and search the connectivity", func(t *testing.T) {
+		require.NoError(t, heartbeat(dv.ID.Hex(), token))
+
+		got, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, "online", got.Payload.Connectivity)
+		require.NotZero(t, got.Payload.LastSeenAt)
+		// the rotation changed the version, not the heartbeat
+		require.Equal(t, int64(2), got.Payload.Version)
+		require.Equal(t, fmt.Sprintf(`"2.%d.online"`, time.Time(got.Payload.LastSeenAt).Unix()), got.ETag)
+		require.Empty(t, got.LastModified)
+
+		_, err = iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()).WithIfNoneMatch(&got.ETag))
+		require.EqualError(t, err, "[GET /device/{id}][304] getDeviceNotModified")
+
+		online, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithConnectivity([]string{"online"}))
+		require.NoError(t, err)
+		require.Len(t, online.Payload.Items, 1)
+		require.Equal(t, "sensor", online.Payload.Items[0].Name)
+
+		offline, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithConnectivity([]string{"stale", "offline"}))
+		require.NoError(t, err)
+		require.Len(t, offline.Payload.Items, 1)
+		require.Equal(t, "silent", offline.Payload.Items[0].Name)
+
+		history, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, "rotateHeartbeatToken", history.Payload.Items[0].Operation)
+
+		// the version part of the ETag is what If-Match checks
+		_, err = iti.ServiceClient.Device.UpdateDeviceName(device.NewUpdateDeviceNameParams().WithID(dv.ID.Hex()).WithIfMatch(&got.ETag).
+			WithDeviceNameUpdate(&models.DeviceNameUpdateRequest{Name: "sensor"}))
+		require.NoError(t, err)
+	})
+
+	t.Run("rotation revokes the previous token", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.RotateHeartbeatToken(device.NewRotateHeartbeatTokenParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+
+		err = heartbeat(dv.ID.Hex(), token)
+		require.EqualError(t, err, "[POST /device/{id}/heartbeat][401] heartbeatDeviceUnauthorized {\"code\":1500024,\"message\":\"the credentials are not valid for the device with id "+dv.ID.Hex()+"\"}")
+	})
+
+	t.Run("fail invalid connectivity", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithConnectivity([]string{"asleep"}))
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'connectivity' is invalid 'invalid value [asleep]'\"}")
+	})
+}
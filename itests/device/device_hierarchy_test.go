@@ -0,0 +1,95 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DeviceHierarchy(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	create := func(name, parentID string) string {
+		res, err := iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().
+			WithDeviceCreationRequestBody(&models.CreateDeviceRequest{Name: name, Brand: "brand1", ParentID: parentID}))
+		require.NoError(t, err)
+		return res.Payload.ID
+	}
+	names := func(devices []*models.Device) []string {
+		var names []string
+		for _, dv := range devices {
+			names = append(names, dv.Name)
+		}
+		return names
+	}
+	children := func(id string, depth int64) []string {
+		res, err := iti.ServiceClient.Device.GetDeviceChildren(device.NewGetDeviceChildrenParams().WithID(id).WithDepth(&depth))
+		require.NoError(t, err)
+		return names(res.Payload.Items)
+	}
+
+	rack := create("rack", "")
+	chassis := create("chassis", rack)
+	module := create("module", chassis)
+	spare := create("spare", "")
+
+	t.Run("fail create with unknown parent", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().
+			WithDeviceCreationRequestBody(&models.CreateDeviceRequest{Name: "x", Brand: "brand1", ParentID: "x"}))
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'parentId' is invalid 'invalid object id [x]'\"}")
+	})
+
+	t.Run("children and ancestors", func(t *testing.T) {
+		require.Equal(t, []string{"chassis"}, children(rack, 1))
+		require.Equal(t, []string{"chassis", "module"}, children(rack, 0))
+
+		res, err := iti.ServiceClient.Device.GetDeviceAncestors(device.NewGetDeviceAncestorsParams().WithID(module))
+		require.NoError(t, err)
+		require.Equal(t, []string{"chassis", "rack"}, names(res.Payload.Items))
+		require.Equal(t, rack, res.Payload.Items[0].ParentID)
+	})
+
+	t.Run("filter by depth", func(t *testing.T) {
+		depth := int64(2)
+		res, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithDepth(&depth))
+		require.NoError(t, err)
+		require.Equal(t, []string{"module"}, names(res.Payload.Items))
+	})
+
+	t.Run("fail set parent making a cycle", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.SetDeviceParent(device.NewSetDeviceParentParams().WithID(rack).
+			WithParent(&models.SetParentRequest{ParentID: &module}))
+		require.EqualError(t, err, "[PUT /device/{id}/parent][400] setDeviceParentBadRequest {\"code\":1500002,\"message\":\"parameter 'parentId' is invalid 'the device "+module+" is a descendant of the device'\"}")
+	})
+
+	t.Run("set and remove the parent", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.SetDeviceParent(device.NewSetDeviceParentParams().WithID(module).
+			WithParent(&models.SetParentRequest{ParentID: &spare}))
+		require.NoError(t, err)
+		require.Equal(t, spare, res.Payload.ParentID)
+		require.Equal(t, []string{"module"}, children(spare, 1))
+
+		res, err = iti.ServiceClient.Device.SetDeviceParent(device.NewSetDeviceParentParams().WithID(module).
+			WithParent(&models.SetParentRequest{}))
+		require.NoError(t, err)
+		require.Empty(t, res.Payload.ParentID)
+		require.Empty(t, children(spare, 1))
+	})
+
+	t.Run("delete a parent", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.DeleteDevice(device.NewDeleteDeviceParams().WithID(rack))
+		require.EqualError(t, err, "[DELETE /device/{id}][409] deleteDeviceConflict {\"code\":1500023,\"message\":\"the device with id "+rack+" has 1 child device(s)\"}")
+
+		_, err = iti.ServiceClient.Device.DeleteDevice(device.NewDeleteDeviceParams().WithID(rack).WithChildren(itests.NewStr("cascade")))
+		require.NoError(t, err)
+		_, err = iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(chassis))
+		require.Error(t, err)
+	})
+}
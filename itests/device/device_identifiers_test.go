@@ -0,0 +1,84 @@
+package device
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DeviceIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	create := func(dv *models.CreateDeviceRequest) (*device.CreateDeviceCreated, error) {
+		return iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().WithDeviceCreationRequestBody(dv))
+	}
+	byExternalID := func(system, value string) (*device.GetDeviceByExternalIDOK, error) {
+		return iti.ServiceClient.Device.GetDeviceByExternalID(device.NewGetDeviceByExternalIDParams().WithSystem(system).WithValue(value))
+	}
+
+	created, err := create(&models.CreateDeviceRequest{
+		Name:         "ganymede",
+		Brand:        "brand1",
+		SerialNumber: "SN-1",
+		ExternalIDs:  map[string]string{"erp": "E-1", "assetTag": "A-1"},
+	})
+	require.NoError(t, err)
+
+	t.Run("get by external id", func(t *testing.T) {
+		resp, err := byExternalID("assetTag", "A-1")
+		require.NoError(t, err)
+		require.Equal(t, created.Payload.ID, resp.Payload.ID)
+		require.Equal(t, "SN-1", resp.Payload.SerialNumber)
+		require.Equal(t, map[string]string{"erp": "E-1", "assetTag": "A-1"}, resp.Payload.ExternalIDs)
+		require.Equal(t, `"1"`, resp.ETag)
+	})
+
+	t.Run("fail external id not found", func(t *testing.T) {
+		_, err := byExternalID("erp", "A-1")
+		var notFound *device.GetDeviceByExternalIDNotFound
+		require.ErrorAs(t, err, &notFound)
+		require.Equal(t, "the device with id erp/A-1 could not be found", notFound.Payload.Message)
+	})
+
+	t.Run("fail serial number of the brand already exists", func(t *testing.T) {
+		_, err := create(&models.CreateDeviceRequest{Name: "callisto", Brand: "brand1", SerialNumber: "SN-1"})
+		require.EqualError(t, err, "[POST /device][409] createDeviceConflict {\"code\":1500010,\"message\":\"the device serial number SN-1 of brand brand1 already exists\"}")
+
+		_, err = create(&models.CreateDeviceRequest{Name: "callisto", Brand: "brand2", SerialNumber: "SN-1"})
+		require.NoError(t, err)
+	})
+
+	t.Run("fail external id already exists", func(t *testing.T) {
+		_, err := create(&models.CreateDeviceRequest{Name: "callisto", Brand: "brand3", ExternalIDs: map[string]string{"erp": "E-1"}})
+		require.EqualError(t, err, "[POST /device][409] createDeviceConflict {\"code\":1500010,\"message\":\"the device external id erp/E-1 already exists\"}")
+	})
+
+	t.Run("fail invalid identifiers", func(t *testing.T) {
+		_, err := create(&models.CreateDeviceRequest{Name: "callisto", Brand: "brand3", ExternalIDs: map[string]string{"erp": ""}})
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'externalIds.erp' is invalid 'must have between 1 and 128 characters'\"}")
+	})
+
+	t.Run("merge patch of the external ids", func(t *testing.T) {
+		resp, body := patchDevice(t, iti, created.Payload.ID, "application/merge-patch+json", "",
+			`{"serialNumber":null,"externalIds":{"assetTag":null,"erp":"E-2"}}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode, body)
+
+		_, err := byExternalID("assetTag", "A-1")
+		require.Error(t, err)
+		dv, err := byExternalID("erp", "E-2")
+		require.NoError(t, err)
+		require.Empty(t, dv.Payload.SerialNumber)
+		require.Equal(t, map[string]string{"erp": "E-2"}, dv.Payload.ExternalIDs)
+
+		_, err = create(&models.CreateDeviceRequest{Name: "callisto", Brand: "brand1", SerialNumber: "SN-1", ExternalIDs: map[string]string{"erp": "E-1"}})
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,79 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DeviceLabels(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	create := func(name string, labels map[string]string) string {
+		res, err := iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().
+			WithDeviceCreationRequestBody(&models.CreateDeviceRequest{Name: name, Brand: "brand1", Labels: labels}))
+		require.NoError(t, err)
+		return res.Payload.ID
+	}
+	selectNames := func(selector string) []string {
+		res, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithLabels(itests.NewStr(selector)))
+		require.NoError(t, err)
+		var names []string
+		for _, dv := range res.Payload.Items {
+			names = append(names, dv.Name)
+		}
+		return names
+	}
+
+	io := create("io", map[string]string{"env": "prod", "rack": "r1"})
+	create("europa", map[string]string{"env": "qa", "team": "qa"})
+	create("callisto", nil)
+
+	t.Run("fail invalid labels", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().
+			WithDeviceCreationRequestBody(&models.CreateDeviceRequest{Name: "x", Brand: "brand1", Labels: map[string]string{"a.b": "c"}}))
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'labels.a.b' is invalid 'invalid key [a.b]'\"}")
+	})
+
+	t.Run("select by labels", func(t *testing.T) {
+		require.Equal(t, []string{"io"}, selectNames("env=prod"))
+		require.Equal(t, []string{"io", "callisto"}, selectNames("team!=qa"))
+		require.Equal(t, []string{"io"}, selectNames("env in (prod,staging),has(rack)"))
+		require.Equal(t, []string{"europa", "callisto"}, selectNames("!has(rack)"))
+	})
+
+	t.Run("fail invalid selector", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithLabels(itests.NewStr("env")))
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'labels' is invalid 'invalid requirement [env]'\"}")
+	})
+
+	t.Run("set and remove a label", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.SetDeviceLabel(device.NewSetDeviceLabelParams().WithID(io).WithKey("env").
+			WithLabel(&models.SetLabelRequest{Value: "staging"}))
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"env": "staging", "rack": "r1"}, res.Payload.Labels)
+		require.Equal(t, `"2"`, res.ETag)
+
+		removed, err := iti.ServiceClient.Device.RemoveDeviceLabel(device.NewRemoveDeviceLabelParams().WithID(io).WithKey("rack"))
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"env": "staging"}, removed.Payload.Labels)
+
+		history, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(io))
+		require.NoError(t, err)
+		require.Equal(t, "removeLabel", history.Payload.Items[0].Operation)
+		require.Equal(t, "setLabel", history.Payload.Items[1].Operation)
+		require.Equal(t, "labels", history.Payload.Items[1].Changes[0].Field)
+	})
+
+	t.Run("fail remove a missing label", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.RemoveDeviceLabel(device.NewRemoveDeviceLabelParams().WithID(io).WithKey("rack"))
+		require.EqualError(t, err, "[DELETE /device/{id}/labels/{key}][404] removeDeviceLabelNotFound {\"code\":1500005,\"message\":\"the label rack of the device with id "+io+" could not be found\"}")
+	})
+}
@@ -0,0 +1,93 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/device-ms/models"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_DeviceTelemetry(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	dv := &model.Device{Name: "thermometer", Brand: "brand1"}
+	require.NoError(t, iti.DeviceRepository.Create(ctx, dv))
+	res, err := iti.ServiceClient.Device.RotateHeartbeatToken(device.NewRotateHeartbeatTokenParams().WithID(dv.ID.Hex()))
+	require.NoError(t, err)
+	token := res.Payload.Token
+
+	start := time.Now().UTC().Truncate(time.Hour).Add(-time.Hour)
+	sample := func(at time.Time, metrics map[string]float64) *models.TelemetrySample {
+		return &models.TelemetrySample{At: strfmt.DateTime(at), Metrics: metrics}
+	}
+	post := func(id, token string, samples ...*models.TelemetrySample) error {
+		params := device.NewAddDeviceTelemetryParams().WithID(id).WithAuthorization("Bearer " + token).
+			WithSamples(&models.AddTelemetryRequest{Samples: samples})
+		_, err := iti.ServiceClient.Device.AddDeviceTelemetry(params)
+		return err
+	}
+
+	t.Run("fail wrong token", func(t *testing.T) {
+		err := post(dv.ID.Hex(), token+"x", sample(start, map[string]float64{"temperature": 20}))
+		require.EqualError(t, err, "[POST /device/{id}/telemetry][401] addDeviceTelemetryUnauthorized {\"code\":1500024,\"message\":\"the credentials are not valid for the device with id "+dv.ID.Hex()+"\"}")
+	})
+
+	t.Run("fail invalid samples", func(t *testing.T) {
+		err := post(dv.ID.Hex(), token)
+		require.EqualError(t, err, "[POST /device/{id}/telemetry][400] addDeviceTelemetryBadRequest {\"code\":1500002,\"message\":\"parameter 'samples' is invalid 'must have from 1 to 1000 samples'\"}")
+
+		err = post(dv.ID.Hex(), token, sample(start, map[string]float64{"disk.used": 20}))
+		require.EqualError(t, err, "[POST /device/{id}/telemetry][400] addDeviceTelemetryBadRequest {\"code\":1500002,\"message\":\"parameter 'samples[0]' is invalid 'invalid metric name [disk.used]'\"}")
+	})
+
+	t.Run("post and read the telemetry", func(t *testing.T) {
+		err := post(dv.ID.Hex(), token,
+			sample(start, map[string]float64{"temperature": 20, "battery": 90}),
+			sample(start.Add(30*time.Second), map[string]float64{"temperature": 22}),
+			sample(start.Add(time.Minute), map[string]float64{"temperature": 25, "battery": 88}),
+		)
+		require.NoError(t, err)
+
+		from, to := strfmt.DateTime(start), strfmt.DateTime(start.Add(time.Hour))
+		series, err := iti.ServiceClient.Device.GetDeviceTelemetry(device.NewGetDeviceTelemetryParams().WithID(dv.ID.Hex()).WithFrom(&from).WithTo(&to))
+		require.NoError(t, err)
+		require.Len(t, series.Payload.Items, 3)
+		require.Equal(t, map[string]float64{"temperature": 22}, series.Payload.Items[1].Metrics)
+
+		bucket, aggregate := "1m", "max"
+		series, err = iti.ServiceClient.Device.GetDeviceTelemetry(device.NewGetDeviceTelemetryParams().WithID(dv.ID.Hex()).
+			WithFrom(&from).WithTo(&to).WithBucket(&bucket).WithAggregate(&aggregate).WithMetrics([]string{"temperature"}))
+		require.NoError(t, err)
+		require.Len(t, series.Payload.Items, 2)
+		require.Equal(t, strfmt.DateTime(start), series.Payload.Items[0].At)
+		require.Equal(t, map[string]float64{"temperature": 22}, series.Payload.Items[0].Metrics)
+		require.Equal(t, map[string]float64{"temperature": 25}, series.Payload.Items[1].Metrics)
+
+		// the post was a heartbeat too
+		got, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, "online", got.Payload.Connectivity)
+	})
+
+	t.Run("fail too many buckets", func(t *testing.T) {
+		bucket := "1s"
+		_, err := iti.ServiceClient.Device.GetDeviceTelemetry(device.NewGetDeviceTelemetryParams().WithID(dv.ID.Hex()).WithBucket(&bucket))
+		require.EqualError(t, err, "[GET /device/{id}/telemetry][400] getDeviceTelemetryBadRequest {\"code\":1500002,\"message\":\"parameter 'bucket' is invalid 'the bucket 1s splits the range in more than 1000 buckets'\"}")
+	})
+
+	t.Run("device not found", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		_, err := iti.ServiceClient.Device.GetDeviceTelemetry(device.NewGetDeviceTelemetryParams().WithID(id.Hex()))
+		require.EqualError(t, err, "[GET /device/{id}/telemetry][404] getDeviceTelemetryNotFound {\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found\"}")
+	})
+}
@@ -0,0 +1,87 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_DeviceTwin(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	dv := &model.Device{Name: "sensor", Brand: "brand1"}
+	require.NoError(t, iti.DeviceRepository.Create(ctx, dv))
+	res, err := iti.ServiceClient.Device.RotateHeartbeatToken(device.NewRotateHeartbeatTokenParams().WithID(dv.ID.Hex()))
+	require.NoError(t, err)
+	token := res.Payload.Token
+
+	t.Run("twin never set", func(t *testing.T) {
+		twin, err := iti.ServiceClient.Device.GetDeviceTwin(device.NewGetDeviceTwinParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, dv.ID.Hex(), twin.Payload.DeviceID)
+		require.Zero(t, twin.Payload.Desired.Version)
+		require.Empty(t, twin.Payload.Desired.Properties)
+	})
+
+	t.Run("fail invalid properties", func(t *testing.T) {
+		params := device.NewSetDeviceTwinDesiredParams().WithID(dv.ID.Hex()).
+			WithProperties(map[string]interface{}{"led.on": true})
+		_, err := iti.ServiceClient.Device.SetDeviceTwinDesired(params)
+		require.EqualError(t, err, "[PUT /device/{id}/twin/desired][400] setDeviceTwinDesiredBadRequest {\"code\":1500002,\"message\":\"parameter 'properties.led.on' is invalid 'invalid name [led.on]'\"}")
+	})
+
+	t.Run("set desired and reported", func(t *testing.T) {
+		params := device.NewSetDeviceTwinDesiredParams().WithID(dv.ID.Hex()).WithIfMatch(itests.NewStr("\"0\"")).
+			WithProperties(map[string]interface{}{"interval": 60, "led": map[string]interface{}{"on": true, "color": "red"}})
+		desired, err := iti.ServiceClient.Device.SetDeviceTwinDesired(params)
+		require.NoError(t, err)
+		require.Equal(t, `"1"`, desired.ETag)
+		require.Equal(t, int64(1), desired.Payload.Version)
+
+		_, err = iti.ServiceClient.Device.SetDeviceTwinDesired(params)
+		require.EqualError(t, err, "[PUT /device/{id}/twin/desired][412] setDeviceTwinDesiredPreconditionFailed {\"code\":1500014,\"message\":\"the device twin desired side with id "+dv.ID.Hex()+" does not have the expected version\"}")
+
+		delta, err := iti.ServiceClient.Device.GetDeviceTwinDelta(device.NewGetDeviceTwinDeltaParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"interval": float64(60), "led": map[string]interface{}{"on": true, "color": "red"}}, delta.Payload.Properties)
+		require.Equal(t, int64(1), delta.Payload.DesiredVersion)
+		require.Zero(t, delta.Payload.ReportedVersion)
+
+		reported, err := iti.ServiceClient.Device.SetDeviceTwinReported(device.NewSetDeviceTwinReportedParams().WithID(dv.ID.Hex()).
+			WithAuthorization("Bearer " + token).
+			WithProperties(map[string]interface{}{"interval": 60, "led": map[string]interface{}{"on": true, "color": "blue"}}))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), reported.Payload.Version)
+
+		delta, err = iti.ServiceClient.Device.GetDeviceTwinDelta(device.NewGetDeviceTwinDeltaParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"led": map[string]interface{}{"color": "red"}}, delta.Payload.Properties)
+		require.Equal(t, int64(1), delta.Payload.ReportedVersion)
+
+		// the twin doesn't change the device, the report was a heartbeat
+		got, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), got.Payload.Version)
+		require.Equal(t, "online", got.Payload.Connectivity)
+	})
+
+	t.Run("fail wrong token", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.SetDeviceTwinReported(device.NewSetDeviceTwinReportedParams().WithID(dv.ID.Hex()).
+			WithAuthorization("Bearer " + token + "x").WithProperties(map[string]interface{}{}))
+		require.EqualError(t, err, "[PUT /device/{id}/twin/reported][401] setDeviceTwinReportedUnauthorized {\"code\":1500024,\"message\":\"the credentials are not valid for the device with id "+dv.ID.Hex()+"\"}")
+	})
+
+	t.Run("device not found", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		_, err := iti.ServiceClient.Device.GetDeviceTwin(device.NewGetDeviceTwinParams().WithID(id.Hex()))
+		require.EqualError(t, err, "[GET /device/{id}/twin][404] getDeviceTwinNotFound {\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found\"}")
+	})
+}
@@ -0,0 +1,89 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/dto"
+	"github.com/device-ms/handler"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+)
+
+// exportDevices sends an export request and reads the whole response
+func exportDevices(t *testing.T, iti itests.IntTestInfra, query string) (*http.Response, string) {
+	resp, err := http.Get("http://" + iti.ServerAddress + handler.URLPath + "/export?" + query)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(b)
+}
+
+func Test_ExportDevices(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	for _, dv := range []*models.CreateDeviceRequest{
+		{Name: "io", Brand: "brand1"},
+		{Name: "europa", Brand: "brand2"},
+		{Name: "ganymede", Brand: "brand1"},
+	} {
+		_, err := iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().WithDeviceCreationRequestBody(dv))
+		require.NoError(t, err)
+	}
+
+	t.Run("json array of the matching devices", func(t *testing.T) {
+		resp, body := exportDevices(t, iti, "brand=brand1&sort=name")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.Equal(t, `attachment; filename="devices.json"`, resp.Header.Get("Content-Disposition"))
+		var devices []dto.DeviceDTO
+		require.NoError(t, json.Unmarshal([]byte(body), &devices))
+		require.Len(t, devices, 2)
+		require.Equal(t, "ganymede", devices[0].Name)
+		require.Equal(t, "io", devices[1].Name)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		resp, body := exportDevices(t, iti, "format=ndjson&name[prefix]=eu")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/x-ndjson; charset=utf-8", resp.Header.Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(body), "\n")
+		require.Len(t, lines, 1)
+		dv := new(dto.DeviceDTO)
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), dv))
+		require.Equal(t, "europa", dv.Name)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		resp, body := exportDevices(t, iti, "format=csv&sort=-name")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.Equal(t, `attachment; filename="devices.csv"`, resp.Header.Get("Content-Disposition"))
+		lines := strings.Split(strings.TrimSpace(body), "\n")
+		require.Len(t, lines, 4)
+		require.Equal(t, "id,name,brand,state,version,createdAt", lines[0])
+		require.Contains(t, lines[1], ",io,brand1,inStock,1,")
+	})
+
+	t.Run("empty export", func(t *testing.T) {
+		resp, body := exportDevices(t, iti, "brand=brand3")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "[]\n", body)
+	})
+
+	t.Run("fail invalid format", func(t *testing.T) {
+		resp, body := exportDevices(t, iti, "format=xml")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500002,\"message\":\"parameter 'format' is invalid 'invalid value [xml]'\"}\n", body)
+	})
+}
@@ -2,10 +2,12 @@ package device
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/device-ms/client/device"
+	"github.com/device-ms/handler"
 	"github.com/device-ms/itests"
 	"github.com/device-ms/model"
 	"github.com/stretchr/testify/require"
@@ -22,14 +24,14 @@ func Test_GetDevice(t *testing.T) {
 		dvIDStr := "12345"
 		params := device.NewGetDeviceParams().WithID(dvIDStr)
 		_, err := iti.ServiceClient.Device.GetDevice(params)
-		require.EqualError(t, err, "[GET /{id}][400] getDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"}")
+		require.EqualError(t, err, "[GET /device/{id}][400] getDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"}")
 	})
 
 	t.Run("fail device not found", func(t *testing.T) {
 		dvID := primitive.NewObjectID()
 		params := device.NewGetDeviceParams().WithID(dvID.Hex())
 		_, err := iti.ServiceClient.Device.GetDevice(params)
-		require.EqualError(t, err, "[GET /{id}][500] getDeviceInternalServerError {\"code\":1500005,\"message\":\"the device with id "+dvID.Hex()+" could not be found\"}")
+		require.EqualError(t, err, "[GET /device/{id}][404] getDeviceNotFound {\"code\":1500005,\"message\":\"the device with id "+dvID.Hex()+" could not be found\"}")
 	})
 
 	t.Run("ok", func(t *testing.T) {
@@ -47,8 +49,60 @@ func Test_GetDevice(t *testing.T) {
 
 		require.Equal(t, "earth", res.Payload.Name)
 		require.Equal(t, "brand3", res.Payload.Brand)
+		require.Equal(t, int64(1), res.Payload.Version)
+		require.Equal(t, "\"1\"", res.ETag)
 		nowStr := now.Format(time.RFC3339)[:19]
 		createdAtStr := res.Payload.CreatedAt.String()[:19]
 		require.Equal(t, nowStr, createdAtStr)
 	})
+
+	t.Run("not modified", func(t *testing.T) {
+		dv := &model.Device{
+			Brand: "brand1",
+			Name:  "mars",
+		}
+		err := iti.DeviceRepository.Create(ctx, dv)
+		require.NoError(t, err)
+
+		res, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, dv.CreatedAt.Format(http.TimeFormat), res.LastModified)
+
+		params := device.NewGetDeviceParams().WithID(dv.ID.Hex()).WithIfNoneMatch(&res.ETag)
+		_, err = iti.ServiceClient.Device.GetDevice(params)
+		require.EqualError(t, err, "[GET /device/{id}][304] getDeviceNotModified")
+
+		params = device.NewGetDeviceParams().WithID(dv.ID.Hex()).WithIfModifiedSince(&res.LastModified)
+		_, err = iti.ServiceClient.Device.GetDevice(params)
+		require.EqualError(t, err, "[GET /device/{id}][304] getDeviceNotModified")
+
+		_, err = iti.DeviceRepository.UpdateName(ctx, dv.ID, "marte", nil)
+		require.NoError(t, err)
+
+		params = device.NewGetDeviceParams().WithID(dv.ID.Hex()).WithIfNoneMatch(&res.ETag)
+		res, err = iti.ServiceClient.Device.GetDevice(params)
+		require.NoError(t, err)
+		require.Equal(t, "marte", res.Payload.Name)
+		require.Equal(t, "\"2\"", res.ETag)
+	})
+
+	t.Run("cache control", func(t *testing.T) {
+		serviceClient, closeServer := iti.StartTestServerWithConfig(ctx, t, handler.Config{GetDeviceCacheControl: "max-age=60"})
+		defer closeServer()
+
+		dv := &model.Device{
+			Brand: "brand1",
+			Name:  "phobos",
+		}
+		err := iti.DeviceRepository.Create(ctx, dv)
+		require.NoError(t, err)
+
+		res, err := serviceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, "max-age=60", res.CacheControl)
+
+		res, err = iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+		require.Empty(t, res.CacheControl)
+	})
 }
@@ -2,9 +2,14 @@ package device
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/device-ms/client/device"
+	"github.com/device-ms/handler"
 	"github.com/device-ms/itests"
 	"github.com/device-ms/model"
 	"github.com/stretchr/testify/require"
@@ -20,7 +25,91 @@ func Test_GetDevices(t *testing.T) {
 		brand := "brand one"
 		params := device.NewGetDevicesParams().WithBrand(&brand)
 		_, err := iti.ServiceClient.Device.GetDevices(params)
-		require.EqualError(t, err, "[GET /][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [brand one]'\"}")
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [brand one]'\"}")
+	})
+
+	t.Run("fail invalid limit", func(t *testing.T) {
+		limit := int64(501)
+		params := device.NewGetDevicesParams().WithLimit(&limit)
+		_, err := iti.ServiceClient.Device.GetDevices(params)
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'limit' is invalid 'must be between 1 and 500'\"}")
+	})
+
+	t.Run("fail invalid sort", func(t *testing.T) {
+		sort := "brand"
+		params := device.NewGetDevicesParams().WithSort(&sort)
+		_, err := iti.ServiceClient.Device.GetDevices(params)
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'sort' is invalid 'invalid value [brand]'\"}")
+	})
+
+	t.Run("fail invalid cursor", func(t *testing.T) {
+		cursor := "blabla"
+		params := device.NewGetDevicesParams().WithCursor(&cursor)
+		_, err := iti.ServiceClient.Device.GetDevices(params)
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'cursor' is invalid 'invalid value [blabla]'\"}")
+	})
+
+	t.Run("fail unsupported name operator", func(t *testing.T) {
+		// the operator is not in the API definition, so the generated client cannot send it
+		resp, err := http.Get("http://" + iti.ServerAddress + handler.URLPath + "?" + url.Values{"name[regex]": {".*"}}.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500002,\"message\":\"parameter 'name[regex]' is invalid 'unsupported operator [regex]'\"}\n", string(body))
+	})
+
+	t.Run("fail unknown parameter", func(t *testing.T) {
+		// a misspelled criterion must not list every device
+		resp, err := http.Get("http://" + iti.ServerAddress + handler.URLPath + "?" + url.Values{"brnd": {"brand1"}}.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500002,\"message\":\"parameter 'brnd' is invalid 'unknown parameter'\"}\n", string(body))
+	})
+
+	t.Run("fail several invalid parameters", func(t *testing.T) {
+		limit := int64(501)
+		params := device.NewGetDevicesParams().WithLimit(&limit).WithSort(itests.NewStr("brand"))
+		_, err := iti.ServiceClient.Device.GetDevices(params)
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500013,\"errors\":["+
+			"{\"code\":1500002,\"field\":\"limit\",\"message\":\"parameter 'limit' is invalid 'must be between 1 and 500'\"},"+
+			"{\"code\":1500002,\"field\":\"sort\",\"message\":\"parameter 'sort' is invalid 'invalid value [brand]'\"}],"+
+			"\"message\":\"the request has 2 invalid parameters\"}")
+	})
+
+	t.Run("fail invalid parameters as problem details", func(t *testing.T) {
+		query := url.Values{"name[regex]": {".*"}, "createdAt[gte]": {"yesterday"}, "sort": {"brand"}}
+		req, err := http.NewRequest(http.MethodGet, "http://"+iti.ServerAddress+handler.URLPath+"?"+query.Encode(), nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/problem+json")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "application/problem+json; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.Equal(t, "{\"type\":\"urn:device-ms:error:invalidRequest\",\"title\":\"Bad Request\",\"status\":400,"+
+			"\"detail\":\"the request has 3 invalid parameters\",\"instance\":\"/device\",\"code\":1500013,\"errors\":["+
+			"{\"field\":\"createdAt[gte]\",\"code\":1500002,\"message\":\"parameter 'createdAt[gte]' is invalid 'invalid time [yesterday], expected RFC 3339'\"},"+
+			"{\"field\":\"name[regex]\",\"code\":1500002,\"message\":\"parameter 'name[regex]' is invalid 'unsupported operator [regex]'\"},"+
+			"{\"field\":\"sort\",\"code\":1500002,\"message\":\"parameter 'sort' is invalid 'invalid value [brand]'\"}]}\n", string(body))
+	})
+
+	t.Run("fail invalid created time", func(t *testing.T) {
+		params := device.NewGetDevicesParams().WithCreatedAtGte(itests.NewStr("yesterday"))
+		_, err := iti.ServiceClient.Device.GetDevices(params)
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'createdAt[gte]' is invalid 'invalid time [yesterday], expected RFC 3339'\"}")
+	})
+
+	t.Run("fail invalid brand in list", func(t *testing.T) {
+		params := device.NewGetDevicesParams().WithBrand(itests.NewStr("brand1,brand one"))
+		_, err := iti.ServiceClient.Device.GetDevices(params)
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [brand one]'\"}")
 	})
 
 	t.Run("no device found with brand", func(t *testing.T) {
@@ -28,14 +117,14 @@ func Test_GetDevices(t *testing.T) {
 		params := device.NewGetDevicesParams().WithBrand(&brand)
 		dvs, err := iti.ServiceClient.Device.GetDevices(params)
 		require.NoError(t, err)
-		require.Len(t, dvs.Payload, 0)
+		require.Len(t, dvs.Payload.Items, 0)
 	})
 
 	t.Run("no device found at all", func(t *testing.T) {
 		params := device.NewGetDevicesParams()
 		dvs, err := iti.ServiceClient.Device.GetDevices(params)
 		require.NoError(t, err)
-		require.Len(t, dvs.Payload, 0)
+		require.Len(t, dvs.Payload.Items, 0)
 	})
 
 	t.Run("ok", func(t *testing.T) {
@@ -49,9 +138,9 @@ func Test_GetDevices(t *testing.T) {
 		params := device.NewGetDevicesParams()
 		dvs, err := iti.ServiceClient.Device.GetDevices(params)
 		require.NoError(t, err)
-		require.Len(t, dvs.Payload, 1)
-		require.Equal(t, "earth", dvs.Payload[0].Name)
-		require.Equal(t, "brand3", dvs.Payload[0].Brand)
+		require.Len(t, dvs.Payload.Items, 1)
+		require.Equal(t, "earth", dvs.Payload.Items[0].Name)
+		require.Equal(t, "brand3", dvs.Payload.Items[0].Brand)
 
 		dv2 := &model.Device{
 			Brand: "brand1",
@@ -63,24 +152,106 @@ func Test_GetDevices(t *testing.T) {
 		params2 := device.NewGetDevicesParams()
 		dvs2, err := iti.ServiceClient.Device.GetDevices(params2)
 		require.NoError(t, err)
-		require.Len(t, dvs2.Payload, 2)
-		require.Equal(t, "earth", dvs2.Payload[0].Name)
-		require.Equal(t, "brand3", dvs2.Payload[0].Brand)
-		require.Equal(t, "venus", dvs2.Payload[1].Name)
-		require.Equal(t, "brand1", dvs2.Payload[1].Brand)
+		require.Len(t, dvs2.Payload.Items, 2)
+		require.Equal(t, "earth", dvs2.Payload.Items[0].Name)
+		require.Equal(t, "brand3", dvs2.Payload.Items[0].Brand)
+		require.Equal(t, "venus", dvs2.Payload.Items[1].Name)
+		require.Equal(t, "brand1", dvs2.Payload.Items[1].Brand)
 
 		brand3 := "brand3"
 		params3 := device.NewGetDevicesParams().WithBrand(&brand3)
 		dvs3, err := iti.ServiceClient.Device.GetDevices(params3)
 		require.NoError(t, err)
-		require.Len(t, dvs3.Payload, 1)
-		require.Equal(t, "earth", dvs3.Payload[0].Name)
-		require.Equal(t, "brand3", dvs3.Payload[0].Brand)
+		require.Len(t, dvs3.Payload.Items, 1)
+		require.Equal(t, "earth", dvs3.Payload.Items[0].Name)
+		require.Equal(t, "brand3", dvs3.Payload.Items[0].Brand)
 
 		brand2 := "brand2"
 		params4 := device.NewGetDevicesParams().WithBrand(&brand2)
 		dvs4, err := iti.ServiceClient.Device.GetDevices(params4)
 		require.NoError(t, err)
-		require.Len(t, dvs4.Payload, 0)
+		require.Len(t, dvs4.Payload.Items, 0)
+	})
+
+	t.Run("ok - pages", func(t *testing.T) {
+		for _, name := range []string{"mars", "jupiter", "saturn"} {
+			err := iti.DeviceRepository.Create(ctx, &model.Device{Brand: "brand2", Name: name})
+			require.NoError(t, err)
+		}
+
+		brand := "brand2"
+		limit := int64(2)
+		sort := "name"
+		total := true
+		params := device.NewGetDevicesParams().WithBrand(&brand).WithLimit(&limit).WithSort(&sort).WithTotal(&total)
+		page1, err := iti.ServiceClient.Device.GetDevices(params)
+		require.NoError(t, err)
+		require.Len(t, page1.Payload.Items, 2)
+		require.Equal(t, "jupiter", page1.Payload.Items[0].Name)
+		require.Equal(t, "mars", page1.Payload.Items[1].Name)
+		require.Equal(t, int64(3), page1.Payload.Total)
+		require.NotEmpty(t, page1.Payload.NextCursor)
+
+		params = device.NewGetDevicesParams().WithBrand(&brand).WithLimit(&limit).WithSort(&sort).WithCursor(&page1.Payload.NextCursor)
+		page2, err := iti.ServiceClient.Device.GetDevices(params)
+		require.NoError(t, err)
+		require.Len(t, page2.Payload.Items, 1)
+		require.Equal(t, "saturn", page2.Payload.Items[0].Name)
+		require.Empty(t, page2.Payload.NextCursor)
+		require.Zero(t, page2.Payload.Total)
+
+		otherSort := "-name"
+		params = device.NewGetDevicesParams().WithSort(&otherSort).WithCursor(&page1.Payload.NextCursor)
+		_, err = iti.ServiceClient.Device.GetDevices(params)
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500002,\"message\":\"parameter 'cursor' is invalid 'cursor was created for sort [name]'\"}")
+	})
+
+	t.Run("ok - filters", func(t *testing.T) {
+		params := device.NewGetDevicesParams().WithBrand(itests.NewStr("brand1,brand3"))
+		dvs, err := iti.ServiceClient.Device.GetDevices(params)
+		require.NoError(t, err)
+		require.Len(t, dvs.Payload.Items, 2)
+
+		params = device.NewGetDevicesParams().WithNamePrefix(itests.NewStr("sat"))
+		dvs, err = iti.ServiceClient.Device.GetDevices(params)
+		require.NoError(t, err)
+		require.Len(t, dvs.Payload.Items, 1)
+		require.Equal(t, "saturn", dvs.Payload.Items[0].Name)
+
+		params = device.NewGetDevicesParams().WithNameContains(itests.NewStr("AR"))
+		dvs, err = iti.ServiceClient.Device.GetDevices(params)
+		require.NoError(t, err)
+		require.Len(t, dvs.Payload.Items, 2)
+		require.Equal(t, "earth", dvs.Payload.Items[0].Name)
+		require.Equal(t, "mars", dvs.Payload.Items[1].Name)
+
+		future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		params = device.NewGetDevicesParams().WithUpdatedSince(&future)
+		dvs, err = iti.ServiceClient.Device.GetDevices(params)
+		require.NoError(t, err)
+		require.Len(t, dvs.Payload.Items, 0)
+
+		params = device.NewGetDevicesParams().WithCreatedAtLt(&future)
+		dvs, err = iti.ServiceClient.Device.GetDevices(params)
+		require.NoError(t, err)
+		require.Len(t, dvs.Payload.Items, 5)
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		params := device.NewGetDevicesParams().WithBrand(itests.NewStr("brand2"))
+		res, err := iti.ServiceClient.Device.GetDevices(params)
+		require.NoError(t, err)
+		require.NotEmpty(t, res.ETag)
+
+		_, err = iti.ServiceClient.Device.GetDevices(params.WithIfNoneMatch(&res.ETag))
+		require.EqualError(t, err, "[GET /device][304] getDevicesNotModified")
+
+		err = iti.DeviceRepository.Create(ctx, &model.Device{Brand: "brand2", Name: "deimos"})
+		require.NoError(t, err)
+
+		res2, err := iti.ServiceClient.Device.GetDevices(params.WithIfNoneMatch(&res.ETag))
+		require.NoError(t, err)
+		require.NotEqual(t, res.ETag, res2.ETag)
+		require.Len(t, res2.Payload.Items, len(res.Payload.Items)+1)
 	})
 }
@@ -0,0 +1,114 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/dto"
+	"github.com/device-ms/handler"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_DeviceHistory(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	// the generated client cannot send the X-User header
+	req, err := http.NewRequest(http.MethodPost, "http://"+iti.ServerAddress+handler.URLPath, strings.NewReader(`{"name":"io","brand":"brand1"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handler.ActorHeader, "alice")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := new(dto.CreatedDeviceResponseDTO)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(created))
+	id := created.ID
+
+	time.Sleep(10 * time.Millisecond)
+	afterCreate := strfmt.DateTime(time.Now().UTC())
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = iti.ServiceClient.Device.UpdateDeviceName(device.NewUpdateDeviceNameParams().WithID(id).WithDeviceNameUpdate(&models.DeviceNameUpdateRequest{
+		Name: "europa",
+	}))
+	require.NoError(t, err)
+	_, err = iti.ServiceClient.Device.DeleteDevice(device.NewDeleteDeviceParams().WithID(id))
+	require.NoError(t, err)
+
+	t.Run("history lists the revisions, the newest first", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(id))
+		require.NoError(t, err)
+		require.Len(t, res.Payload.Items, 3)
+		require.Empty(t, res.Payload.NextCursor)
+		require.Equal(t, models.DeviceRevisionOperationDelete, res.Payload.Items[0].Operation)
+		require.Equal(t, models.DeviceRevisionOperationUpdateName, res.Payload.Items[1].Operation)
+		require.Equal(t, models.DeviceRevisionOperationCreate, res.Payload.Items[2].Operation)
+		require.Equal(t, "alice", res.Payload.Items[2].Actor)
+		require.Empty(t, res.Payload.Items[1].Actor)
+	})
+
+	t.Run("history pages", func(t *testing.T) {
+		limit := int64(2)
+		res, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(id).WithLimit(&limit))
+		require.NoError(t, err)
+		require.Len(t, res.Payload.Items, 2)
+		require.Equal(t, "2", res.Payload.NextCursor)
+
+		res, err = iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(id).WithLimit(&limit).WithCursor(&res.Payload.NextCursor))
+		require.NoError(t, err)
+		require.Len(t, res.Payload.Items, 1)
+		require.Equal(t, int64(1), res.Payload.Items[0].Revision)
+		require.Empty(t, res.Payload.NextCursor)
+	})
+
+	t.Run("fail invalid limit", func(t *testing.T) {
+		limit := int64(501)
+		_, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(id).WithLimit(&limit))
+		require.EqualError(t, err, "[GET /device/{id}/history][400] getDeviceHistoryBadRequest {\"code\":1500002,\"message\":\"parameter 'limit' is invalid 'must be between 1 and 500'\"}")
+	})
+
+	t.Run("revision with its changes", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.GetDeviceRevision(device.NewGetDeviceRevisionParams().WithID(id).WithRevision(2))
+		require.NoError(t, err)
+		require.Equal(t, "io", res.Payload.Before.Name)
+		require.Equal(t, "europa", res.Payload.After.Name)
+		require.Equal(t, []*models.FieldChange{{Field: "name", Before: "io", After: "europa"}}, res.Payload.Changes)
+	})
+
+	t.Run("fail revision not found", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.GetDeviceRevision(device.NewGetDeviceRevisionParams().WithID(id).WithRevision(9))
+		require.EqualError(t, err, "[GET /device/{id}/history/{revision}][404] getDeviceRevisionNotFound {\"code\":1500005,\"message\":\"the device revision with id "+id+"/9 could not be found\"}")
+	})
+
+	t.Run("device as of a time", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(id).WithAsOf(&afterCreate))
+		require.NoError(t, err)
+		require.Equal(t, "io", res.Payload.Name)
+		require.Equal(t, int64(1), res.Payload.Version)
+	})
+
+	t.Run("fail device deleted as of a time", func(t *testing.T) {
+		now := strfmt.DateTime(time.Now().UTC())
+		_, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(id).WithAsOf(&now))
+		require.EqualError(t, err, "[GET /device/{id}][404] getDeviceNotFound {\"code\":1500005,\"message\":\"the device with id "+id+" could not be found\"}")
+	})
+
+	t.Run("history of an unknown device is empty", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(primitive.NewObjectID().Hex()))
+		require.NoError(t, err)
+		require.Empty(t, res.Payload.Items)
+	})
+}
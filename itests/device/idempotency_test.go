@@ -0,0 +1,77 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/handler"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_CreateDeviceIdempotency(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	create := func(key string, dv *models.CreateDeviceRequest) (*device.CreateDeviceCreated, error) {
+		return iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().WithDeviceCreationRequestBody(dv).WithIdempotencyKey(&key))
+	}
+	io := &models.CreateDeviceRequest{Name: "io", Brand: "brand1"}
+
+	first, err := create("key1", io)
+	require.NoError(t, err)
+	require.Empty(t, first.IdempotentReplayed)
+
+	t.Run("retry replays the first response", func(t *testing.T) {
+		retry, err := create("key1", io)
+		require.NoError(t, err)
+		require.Equal(t, "true", retry.IdempotentReplayed)
+		require.Equal(t, first.Payload, retry.Payload)
+
+		count, err := iti.DeviceRepository.Collection.CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+	})
+
+	t.Run("fail key reused for another device", func(t *testing.T) {
+		_, err := create("key1", &models.CreateDeviceRequest{Name: "europa", Brand: "brand1"})
+		require.EqualError(t, err, "[POST /device][422] createDeviceUnprocessableEntity {\"code\":1500020,\"message\":\"the idempotency key key1 was used for a different request\"}")
+	})
+
+	t.Run("validation errors are replayed too", func(t *testing.T) {
+		invalid := &models.CreateDeviceRequest{Name: "europa"}
+		_, err := create("key2", invalid)
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500001,\"message\":\"parameter 'brand' in body is required\"}")
+		_, err = create("key2", invalid)
+		require.EqualError(t, err, "[POST /device][400] createDeviceBadRequest {\"code\":1500001,\"message\":\"parameter 'brand' in body is required\"}")
+	})
+
+	t.Run("fail body too large", func(t *testing.T) {
+		body := `{"name": "europa", "brand": "brand1", "attributes": {"notes": "` + strings.Repeat("x", 64*1024) + `"}}`
+		req, err := http.NewRequest(http.MethodPost, "http://"+iti.ServerAddress+handler.URLPath, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "key4")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		var decodeErr map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&decodeErr))
+		require.Equal(t, "decode error: http: request body too large", decodeErr["message"])
+	})
+
+	t.Run("another key creates another device", func(t *testing.T) {
+		other, err := create("key3", io)
+		require.NoError(t, err)
+		require.NotEqual(t, first.Payload.ID, other.Payload.ID)
+	})
+}
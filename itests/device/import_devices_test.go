@@ -0,0 +1,102 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/device-ms/handler"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// importDevices sends an import request, the generated client cannot send a CSV body
+func importDevices(t *testing.T, iti itests.IntTestInfra, query, contentType, accept, body string) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodPost, "http://"+iti.ServerAddress+handler.URLPath+"/import?"+query, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(b)
+}
+
+func Test_ImportDevices(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	countDevices := func() int64 {
+		count, err := iti.DeviceRepository.Collection.CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		return count
+	}
+	csv := "serial,Label,Brand\n1,io,brand1\n2,europa,brand one\n3,ganymede,brand2\n"
+
+	t.Run("fail missing column", func(t *testing.T) {
+		resp, body := importDevices(t, iti, "", "text/csv", "", csv)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500002,\"message\":\"parameter 'nameColumn' is invalid 'no column [name] in the header'\"}\n", body)
+	})
+
+	t.Run("transactional import with a rejected line creates nothing", func(t *testing.T) {
+		resp, body := importDevices(t, iti, "nameColumn=label", "text/csv", "", csv)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		report := new(models.ImportReport)
+		require.NoError(t, json.Unmarshal([]byte(body), report))
+		require.Equal(t, models.ImportReportModeTransactional, report.Mode)
+		require.Equal(t, int64(3), report.Rows)
+		require.Equal(t, int64(0), report.Created)
+		require.Equal(t, int64(1), report.Rejected)
+		require.Equal(t, int64(2), report.Skipped)
+		require.Equal(t, []*models.ImportError{{
+			Line: 3, Name: "europa", Brand: "brand one", Code: 1500002, Message: "parameter 'brand' is invalid 'invalid value [brand one]'",
+		}}, report.Errors)
+		require.Equal(t, int64(0), countDevices())
+	})
+
+	t.Run("partial import creates the valid lines", func(t *testing.T) {
+		resp, body := importDevices(t, iti, "nameColumn=label&mode=partial", "text/csv", "", csv)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		report := new(models.ImportReport)
+		require.NoError(t, json.Unmarshal([]byte(body), report))
+		require.Equal(t, int64(2), report.Created)
+		require.Equal(t, int64(1), report.Rejected)
+		require.Equal(t, int64(2), countDevices())
+	})
+
+	t.Run("transactional import creates all the lines", func(t *testing.T) {
+		resp, body := importDevices(t, iti, "", "text/csv", "", "name,brand\ncallisto,brand1\nthebe,brand2\n")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		report := new(models.ImportReport)
+		require.NoError(t, json.Unmarshal([]byte(body), report))
+		require.Equal(t, int64(2), report.Created)
+		require.Empty(t, report.Errors)
+		require.Equal(t, int64(4), countDevices())
+	})
+
+	t.Run("csv report", func(t *testing.T) {
+		resp, body := importDevices(t, iti, "nameColumn=label&mode=partial", "text/csv", "text/csv", csv)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.Equal(t, `attachment; filename="import-report.csv"`, resp.Header.Get("Content-Disposition"))
+		require.Equal(t, "line,name,brand,code,message\n3,europa,brand one,1500002,parameter 'brand' is invalid 'invalid value [brand one]'\n", body)
+	})
+
+	t.Run("fail unsupported media type", func(t *testing.T) {
+		resp, body := importDevices(t, iti, "", "application/json", "", `[{"name":"io","brand":"brand1"}]`)
+		require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500016,\"message\":\"unsupported media type [application/json], expected one of [text/csv]\"}\n", body)
+	})
+}
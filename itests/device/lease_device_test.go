@@ -0,0 +1,100 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/device-ms/models"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LeaseDevice(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	checkout := func(id, assignee string, dueAt time.Time) (*device.CheckoutDeviceOK, error) {
+		params := device.NewCheckoutDeviceParams().WithID(id).
+			WithCheckout(&models.CheckoutRequest{Assignee: assignee, DueAt: strfmt.DateTime(dueAt)})
+		return iti.ServiceClient.Device.CheckoutDevice(params)
+	}
+
+	dv := &model.Device{Brand: "brand1", Name: "io"}
+	require.NoError(t, iti.DeviceRepository.Create(ctx, dv))
+	id := dv.ID.Hex()
+	dueAt := time.Now().UTC().Truncate(time.Second).Add(24 * time.Hour)
+
+	t.Run("fail invalid checkout request", func(t *testing.T) {
+		_, err := checkout(id, "", time.Now().Add(-time.Hour))
+		require.EqualError(t, err, "[POST /device/{id}/checkout][400] checkoutDeviceBadRequest {\"code\":1500013,\"errors\":["+
+			"{\"code\":1500001,\"field\":\"assignee\",\"message\":\"parameter 'assignee' in body is required\"},"+
+			"{\"code\":1500002,\"field\":\"dueAt\",\"message\":\"parameter 'dueAt' is invalid 'must be in the future'\"}],"+
+			"\"message\":\"the request has 2 invalid parameters\"}")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		res, err := checkout(id, "ada", dueAt)
+		require.NoError(t, err)
+		require.Equal(t, `"2"`, res.ETag)
+		require.Equal(t, "ada", res.Payload.Lease.Assignee)
+		require.Equal(t, dueAt, time.Time(res.Payload.Lease.DueAt).UTC())
+		require.False(t, res.Payload.Lease.Overdue)
+
+		history, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(id))
+		require.NoError(t, err)
+		require.Equal(t, "checkout", history.Payload.Items[0].Operation)
+		require.Equal(t, "lease", history.Payload.Items[0].Changes[0].Field)
+	})
+
+	t.Run("fail double checkout", func(t *testing.T) {
+		_, err := checkout(id, "grace", dueAt)
+		require.EqualError(t, err, "[POST /device/{id}/checkout][409] checkoutDeviceConflict {\"code\":1500019,\"message\":\"the device with id "+id+" is checked out\"}")
+	})
+
+	t.Run("filter by assignee", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithAssignee(itests.NewStr("ada")))
+		require.NoError(t, err)
+		require.Len(t, res.Payload.Items, 1)
+		require.Equal(t, id, res.Payload.Items[0].ID)
+
+		res, err = iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithOverdue(itests.NewBool(true)))
+		require.NoError(t, err)
+		require.Len(t, res.Payload.Items, 0)
+	})
+
+	t.Run("overdue lease expired by the sweeper", func(t *testing.T) {
+		count, err := iti.Controller.DeviceController().ExpireLeases(ctx, dueAt.Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+
+		res, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(id))
+		require.NoError(t, err)
+		require.NotZero(t, res.Payload.Lease.ExpiredAt)
+		require.True(t, res.Payload.Lease.Overdue)
+		require.Equal(t, `"3"`, res.ETag)
+
+		overdue, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithOverdue(itests.NewBool(true)))
+		require.NoError(t, err)
+		require.Len(t, overdue.Payload.Items, 1)
+		require.True(t, overdue.Payload.Items[0].Lease.Overdue)
+
+		history, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(id))
+		require.NoError(t, err)
+		require.Equal(t, "leaseExpired", history.Payload.Items[0].Operation)
+	})
+
+	t.Run("checkin", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.CheckinDevice(device.NewCheckinDeviceParams().WithID(id))
+		require.NoError(t, err)
+		require.Nil(t, res.Payload.Lease)
+
+		_, err = iti.ServiceClient.Device.CheckinDevice(device.NewCheckinDeviceParams().WithID(id))
+		require.EqualError(t, err, "[POST /device/{id}/checkin][409] checkinDeviceConflict {\"code\":1500019,\"message\":\"the device with id "+id+" is not checked out\"}")
+	})
+}
@@ -0,0 +1,117 @@
+package device
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/device-ms/handler"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// patchDevice sends a PATCH request, the generated client cannot choose the patch media type
+func patchDevice(t *testing.T, iti itests.IntTestInfra, id, contentType, ifMatch, body string) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodPatch, "http://"+iti.ServerAddress+handler.URLPath+"/"+id, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(b)
+}
+
+func Test_PatchDevice(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	dv := &model.Device{
+		Brand: "brand1",
+		Name:  "earth",
+	}
+	err := iti.DeviceRepository.Create(ctx, dv)
+	require.NoError(t, err)
+
+	t.Run("fail unsupported media type", func(t *testing.T) {
+		resp, body := patchDevice(t, iti, dv.ID.Hex(), "application/json", "", `{"name":"mars"}`)
+		require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500016,\"message\":\"unsupported media type [application/json], expected one of [application/merge-patch+json, application/json-patch+json]\"}\n", body)
+	})
+
+	t.Run("fail invalid id and unknown field", func(t *testing.T) {
+		resp, body := patchDevice(t, iti, "12345", "application/merge-patch+json", "", `{"createdAt":"2024-12-01T00:00:00Z"}`)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500013,\"message\":\"the request has 2 invalid parameters\",\"errors\":["+
+			"{\"field\":\"id\",\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"},"+
+			"{\"field\":\"createdAt\",\"code\":1500002,\"message\":\"parameter 'createdAt' is invalid 'unknown field'\"}]}\n", body)
+	})
+
+	t.Run("fail invalid patched device", func(t *testing.T) {
+		resp, body := patchDevice(t, iti, dv.ID.Hex(), "application/json-patch+json", "", `[{"op":"replace","path":"/brand","value":"brand one"}]`)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [brand one]'\"}\n", body)
+
+		resp, body = patchDevice(t, iti, dv.ID.Hex(), "application/merge-patch+json", "", `{"brand":null}`)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500001,\"message\":\"parameter 'brand' in body is required\"}\n", body)
+	})
+
+	t.Run("fail test operation", func(t *testing.T) {
+		resp, body := patchDevice(t, iti, dv.ID.Hex(), "application/json-patch+json", "", `[{"op":"test","path":"/name","value":"mars"},{"op":"replace","path":"/name","value":"venus"}]`)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500018,\"message\":\"the value at /name is not the one tested\"}\n", body)
+	})
+
+	t.Run("fail device not found", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		resp, body := patchDevice(t, iti, id.Hex(), "application/merge-patch+json", "", `{"name":"mars"}`)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found\"}\n", body)
+	})
+
+	t.Run("fail version mismatch", func(t *testing.T) {
+		resp, body := patchDevice(t, iti, dv.ID.Hex(), "application/merge-patch+json", `"7"`, `{"name":"mars"}`)
+		require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+		require.Equal(t, "{\"result\":false,\"code\":1500014,\"message\":\"the device with id "+dv.ID.Hex()+" does not have the expected version\"}\n", body)
+	})
+
+	t.Run("ok merge patch", func(t *testing.T) {
+		resp, body := patchDevice(t, iti, dv.ID.Hex(), "application/merge-patch+json", `"1"`, `{"name":"mars"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, `"2"`, resp.Header.Get("ETag"))
+		require.Contains(t, body, "\"name\":\"mars\",\"brand\":\"brand1\"")
+
+		dvBD, err := iti.DeviceRepository.ByID(ctx, dv.ID)
+		require.NoError(t, err)
+		require.Equal(t, "mars", dvBD.Name)
+		require.Equal(t, model.Brand("brand1"), dvBD.Brand)
+		require.Equal(t, int64(2), dvBD.Version)
+	})
+
+	t.Run("ok JSON patch", func(t *testing.T) {
+		resp, body := patchDevice(t, iti, dv.ID.Hex(), "application/json-patch+json; charset=utf-8", "", `[
+			{"op":"test","path":"/name","value":"mars"},
+			{"op":"replace","path":"/brand","value":"brand3"},
+			{"op":"replace","path":"/name","value":"venus"}
+		]`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, `"3"`, resp.Header.Get("ETag"))
+		require.Contains(t, body, "\"name\":\"venus\",\"brand\":\"brand3\"")
+
+		dvBD, err := iti.DeviceRepository.ByID(ctx, dv.ID)
+		require.NoError(t, err)
+		require.Equal(t, "venus", dvBD.Name)
+		require.Equal(t, model.Brand("brand3"), dvBD.Brand)
+	})
+}
@@ -0,0 +1,70 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_DeviceStorageErrors(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	serviceClient, closeServer := iti.StartUnreachableStorageServer(ctx, t)
+	defer closeServer()
+
+	id := primitive.NewObjectID().Hex()
+
+	t.Run("create device", func(t *testing.T) {
+		params := device.NewCreateDeviceParams().WithDeviceCreationRequestBody(&models.CreateDeviceRequest{
+			Brand: "brand1",
+			Name:  "earth",
+		})
+		_, err := serviceClient.Device.CreateDevice(params)
+		require.EqualError(t, err, "[POST /device][500] createDeviceInternalServerError {\"code\":1500003,\"message\":\"error creating device reason client is disconnected\"}")
+	})
+
+	t.Run("get device", func(t *testing.T) {
+		_, err := serviceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(id))
+		require.EqualError(t, err, "[GET /device/{id}][500] getDeviceInternalServerError {\"code\":1500011,\"message\":\"error reading device reason client is disconnected\"}")
+	})
+
+	t.Run("get devices", func(t *testing.T) {
+		_, err := serviceClient.Device.GetDevices(device.NewGetDevicesParams())
+		require.EqualError(t, err, "[GET /device][500] getDevicesInternalServerError {\"code\":1500004,\"message\":\"the device queried by ALL returned an error: client is disconnected\"}")
+	})
+
+	t.Run("update device", func(t *testing.T) {
+		params := device.NewUpdateDeviceParams().WithID(id).WithDeviceUpdateRequestBody(&models.UpdateDeviceRequest{
+			Brand: "brand1",
+			Name:  "earth",
+		})
+		_, err := serviceClient.Device.UpdateDevice(params)
+		require.EqualError(t, err, "[PUT /device/{id}][500] updateDeviceInternalServerError {\"code\":1500006,\"message\":\"error updating device reason client is disconnected\"}")
+	})
+
+	t.Run("update device name", func(t *testing.T) {
+		params := device.NewUpdateDeviceNameParams().WithID(id).WithDeviceNameUpdate(&models.DeviceNameUpdateRequest{
+			Name: "earth",
+		})
+		_, err := serviceClient.Device.UpdateDeviceName(params)
+		require.EqualError(t, err, "[PUT /device/{id}/name][500] updateDeviceNameInternalServerError {\"code\":1500006,\"message\":\"error updating device reason client is disconnected\"}")
+	})
+
+	t.Run("update device brand", func(t *testing.T) {
+		params := device.NewUpdateDeviceBrandParams().WithID(id).WithDeviceBrandUpdate(&models.DeviceBrandUpdateRequest{
+			Brand: "brand1",
+		})
+		_, err := serviceClient.Device.UpdateDeviceBrand(params)
+		require.EqualError(t, err, "[PUT /device/{id}/brand][500] updateDeviceBrandInternalServerError {\"code\":1500006,\"message\":\"error updating device reason client is disconnected\"}")
+	})
+
+	t.Run("delete device", func(t *testing.T) {
+		_, err := serviceClient.Device.DeleteDevice(device.NewDeleteDeviceParams().WithID(id))
+		require.EqualError(t, err, "[DELETE /device/{id}][500] deleteDeviceInternalServerError {\"code\":1500004,\"message\":\"the device queried by parentId, "+id+" returned an error: client is disconnected\"}")
+	})
+}
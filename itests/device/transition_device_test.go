@@ -0,0 +1,83 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TransitionDevice(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	transition := func(id, to, reason string) (*device.TransitionDeviceOK, error) {
+		params := device.NewTransitionDeviceParams().WithID(id).WithTransition(&models.TransitionRequest{To: to, Reason: reason})
+		return iti.ServiceClient.Device.TransitionDevice(params)
+	}
+
+	dv := &model.Device{Brand: "brand1", Name: "io"}
+	require.NoError(t, iti.DeviceRepository.Create(ctx, dv))
+	id := dv.ID.Hex()
+
+	t.Run("created in stock", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(id))
+		require.NoError(t, err)
+		require.Equal(t, "inStock", res.Payload.State)
+	})
+
+	t.Run("fail invalid transition request", func(t *testing.T) {
+		_, err := transition(id, "lost", "")
+		require.EqualError(t, err, "[POST /device/{id}/transitions][400] transitionDeviceBadRequest {\"code\":1500013,\"errors\":["+
+			"{\"code\":1500002,\"field\":\"to\",\"message\":\"parameter 'to' is invalid 'invalid value [lost]'\"},"+
+			"{\"code\":1500001,\"field\":\"reason\",\"message\":\"parameter 'reason' in body is required\"}],"+
+			"\"message\":\"the request has 2 invalid parameters\"}")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		res, err := transition(id, "inUse", "assigned to bob")
+		require.NoError(t, err)
+		require.Equal(t, "inUse", res.Payload.State)
+		require.Equal(t, `"2"`, res.ETag)
+
+		history, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(id))
+		require.NoError(t, err)
+		revision := history.Payload.Items[0]
+		require.Equal(t, "transition", revision.Operation)
+		require.Equal(t, "assigned to bob", revision.Reason)
+		require.Equal(t, "state", revision.Changes[0].Field)
+	})
+
+	t.Run("filter by state", func(t *testing.T) {
+		res, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithState(itests.NewStr("inUse,inRepair")))
+		require.NoError(t, err)
+		require.Len(t, res.Payload.Items, 1)
+		require.Equal(t, id, res.Payload.Items[0].ID)
+	})
+
+	t.Run("fail illegal transition", func(t *testing.T) {
+		_, err := transition(id, "retired", "lost")
+		require.EqualError(t, err, "[POST /device/{id}/transitions][409] transitionDeviceConflict {\"code\":1500022,\"message\":\"the device with id "+id+" cannot move from inUse to retired\"}")
+	})
+
+	t.Run("fail brand change or delete while in use", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.UpdateDeviceBrand(device.NewUpdateDeviceBrandParams().WithID(id).WithDeviceBrandUpdate(&models.DeviceBrandUpdateRequest{Brand: "brand2"}))
+		require.EqualError(t, err, "[PUT /device/{id}/brand][409] updateDeviceBrandConflict {\"code\":1500019,\"message\":\"the device with id "+id+" is inUse\"}")
+
+		_, err = iti.ServiceClient.Device.DeleteDevice(device.NewDeleteDeviceParams().WithID(id))
+		require.EqualError(t, err, "[DELETE /device/{id}][409] deleteDeviceConflict {\"code\":1500019,\"message\":\"the device with id "+id+" is inUse\"}")
+	})
+
+	t.Run("fail version mismatch", func(t *testing.T) {
+		params := device.NewTransitionDeviceParams().WithID(id).WithIfMatch(itests.NewStr(`"1"`)).
+			WithTransition(&models.TransitionRequest{To: "inStock", Reason: "returned"})
+		_, err := iti.ServiceClient.Device.TransitionDevice(params)
+		require.EqualError(t, err, "[POST /device/{id}/transitions][412] transitionDevicePreconditionFailed {\"code\":1500014,\"message\":\"the device with id "+id+" does not have the expected version\"}")
+	})
+}
@@ -0,0 +1,84 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_Trash(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	dv := &model.Device{
+		Brand: "brand2",
+		Name:  "ceres",
+	}
+	err := iti.DeviceRepository.Create(ctx, dv)
+	require.NoError(t, err)
+	_, err = iti.ServiceClient.Device.DeleteDevice(device.NewDeleteDeviceParams().WithID(dv.ID.Hex()))
+	require.NoError(t, err)
+
+	t.Run("deleted device is in the trash", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()))
+		require.EqualError(t, err, "[GET /device/{id}][404] getDeviceNotFound {\"code\":1500005,\"message\":\"the device with id "+dv.ID.Hex()+" could not be found\"}")
+
+		res, err := iti.ServiceClient.Device.GetTrash(device.NewGetTrashParams())
+		require.NoError(t, err)
+		require.Len(t, res.Payload.Items, 1)
+		require.Equal(t, dv.ID.Hex(), res.Payload.Items[0].ID)
+		require.Equal(t, int64(2), res.Payload.Items[0].Version)
+		require.False(t, res.Payload.Items[0].DeletedAt.IsZero())
+
+		devices, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams())
+		require.NoError(t, err)
+		require.Empty(t, devices.Payload.Items)
+	})
+
+	t.Run("fail invalid trash filter", func(t *testing.T) {
+		brand := "brand one"
+		_, err := iti.ServiceClient.Device.GetTrash(device.NewGetTrashParams().WithBrand(&brand))
+		require.EqualError(t, err, "[GET /device/trash][400] getTrashBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [brand one]'\"}")
+	})
+
+	t.Run("fail restore invalid id", func(t *testing.T) {
+		_, err := iti.ServiceClient.Device.RestoreDevice(device.NewRestoreDeviceParams().WithID("12345"))
+		require.EqualError(t, err, "[POST /device/{id}/restore][400] restoreDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"}")
+	})
+
+	t.Run("fail restore device not deleted", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		_, err := iti.ServiceClient.Device.RestoreDevice(device.NewRestoreDeviceParams().WithID(id.Hex()))
+		require.EqualError(t, err, "[POST /device/{id}/restore][404] restoreDeviceNotFound {\"code\":1500005,\"message\":\"the deleted device with id "+id.Hex()+" could not be found\"}")
+	})
+
+	t.Run("fail restore version mismatch", func(t *testing.T) {
+		params := device.NewRestoreDeviceParams().WithID(dv.ID.Hex()).WithIfMatch(itests.NewStr("\"1\""))
+		_, err := iti.ServiceClient.Device.RestoreDevice(params)
+		require.EqualError(t, err, "[POST /device/{id}/restore][412] restoreDevicePreconditionFailed {\"code\":1500014,\"message\":\"the device with id "+dv.ID.Hex()+" does not have the expected version\"}")
+	})
+
+	t.Run("ok restore", func(t *testing.T) {
+		params := device.NewRestoreDeviceParams().WithID(dv.ID.Hex()).WithIfMatch(itests.NewStr("\"2\""))
+		res, err := iti.ServiceClient.Device.RestoreDevice(params)
+		require.NoError(t, err)
+		require.Equal(t, "ceres", res.Payload.Name)
+		require.Equal(t, int64(3), res.Payload.Version)
+		require.True(t, res.Payload.DeletedAt.IsZero())
+		require.Equal(t, "\"3\"", res.ETag)
+
+		_, err = iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+
+		trash, err := iti.ServiceClient.Device.GetTrash(device.NewGetTrashParams())
+		require.NoError(t, err)
+		require.Empty(t, trash.Payload.Items)
+	})
+}
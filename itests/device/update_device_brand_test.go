@@ -22,7 +22,7 @@ func Test_UpdateDeviceBrand(t *testing.T) {
 		id := "12345"
 		params := device.NewUpdateDeviceBrandParams().WithID(id)
 		_, err := iti.ServiceClient.Device.UpdateDeviceBrand(params)
-		require.EqualError(t, err, "[PUT /{id}/brand][400] updateDeviceBrandBadRequest {\"code\":1500008,\"message\":\"decode error: EOF\"}")
+		require.EqualError(t, err, "[PUT /device/{id}/brand][400] updateDeviceBrandBadRequest {\"code\":1500008,\"message\":\"decode error: EOF\"}")
 	})
 
 	t.Run("fail invalid id", func(t *testing.T) {
@@ -31,14 +31,14 @@ func Test_UpdateDeviceBrand(t *testing.T) {
 			Brand: "brand3",
 		})
 		_, err := iti.ServiceClient.Device.UpdateDeviceBrand(params)
-		require.EqualError(t, err, "[PUT /{id}/brand][400] updateDeviceBrandBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"}")
+		require.EqualError(t, err, "[PUT /device/{id}/brand][400] updateDeviceBrandBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"}")
 	})
 
 	t.Run("fail no brand", func(t *testing.T) {
 		id := primitive.NewObjectID()
 		params := device.NewUpdateDeviceBrandParams().WithID(id.Hex()).WithDeviceBrandUpdate(&models.DeviceBrandUpdateRequest{})
 		_, err := iti.ServiceClient.Device.UpdateDeviceBrand(params)
-		require.EqualError(t, err, "[PUT /{id}/brand][500] updateDeviceBrandInternalServerError {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value'\"}")
+		require.EqualError(t, err, "[PUT /device/{id}/brand][400] updateDeviceBrandBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value'\"}")
 	})
 
 	t.Run("fail invalid brand", func(t *testing.T) {
@@ -47,7 +47,7 @@ func Test_UpdateDeviceBrand(t *testing.T) {
 			Brand: "brand two",
 		})
 		_, err := iti.ServiceClient.Device.UpdateDeviceBrand(params)
-		require.EqualError(t, err, "[PUT /{id}/brand][500] updateDeviceBrandInternalServerError {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value'\"}")
+		require.EqualError(t, err, "[PUT /device/{id}/brand][400] updateDeviceBrandBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value'\"}")
 	})
 
 	t.Run("device not found", func(t *testing.T) {
@@ -56,7 +56,7 @@ func Test_UpdateDeviceBrand(t *testing.T) {
 			Brand: "brand2",
 		})
 		_, err := iti.ServiceClient.Device.UpdateDeviceBrand(params)
-		require.EqualError(t, err, "[PUT /{id}/brand][500] updateDeviceBrandInternalServerError {\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found: mongo: no documents in result\"}")
+		require.EqualError(t, err, "[PUT /device/{id}/brand][404] updateDeviceBrandNotFound {\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found: mongo: no documents in result\"}")
 	})
 
 	t.Run("ok", func(t *testing.T) {
@@ -0,0 +1,94 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/client/device"
+	"github.com/device-ms/itests"
+	"github.com/device-ms/model"
+	"github.com/device-ms/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_UpdateDeviceLocation(t *testing.T) {
+	ctx := context.Background()
+	iti := itests.NewITests(ctx, t)
+	_, closeServer := iti.StartTestServer(ctx, t)
+	defer closeServer()
+
+	point := func(lat, lng float64) *models.GeoPoint {
+		return &models.GeoPoint{Type: "Point", Coordinates: []float64{lng, lat}}
+	}
+	names := func(devices []*models.Device) []string {
+		var names []string
+		for _, dv := range devices {
+			names = append(names, dv.Name)
+		}
+		return names
+	}
+
+	t.Run("fail invalid point", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		params := device.NewUpdateDeviceLocationParams().WithID(id.Hex()).WithLocation(&models.Location{Point: point(95, 2)})
+		_, err := iti.ServiceClient.Device.UpdateDeviceLocation(params)
+		require.EqualError(t, err, "[PUT /device/{id}/location][400] updateDeviceLocationBadRequest {\"code\":1500002,\"message\":\"parameter 'point' is invalid 'latitude 95 is not between -90 and 90'\"}")
+	})
+
+	t.Run("device not found", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		params := device.NewUpdateDeviceLocationParams().WithID(id.Hex()).WithLocation(&models.Location{Site: "paris"})
+		_, err := iti.ServiceClient.Device.UpdateDeviceLocation(params)
+		require.EqualError(t, err, "[PUT /device/{id}/location][404] updateDeviceLocationNotFound {\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found: mongo: no documents in result\"}")
+	})
+
+	eiffel, err := iti.ServiceClient.Device.CreateDevice(device.NewCreateDeviceParams().WithDeviceCreationRequestBody(&models.CreateDeviceRequest{
+		Name: "eiffel", Brand: "brand1", Location: &models.Location{Site: "paris", Point: point(48.8584, 2.2945)},
+	}))
+	require.NoError(t, err)
+	louvre := &model.Device{Name: "louvre", Brand: "brand1"}
+	require.NoError(t, iti.DeviceRepository.Create(ctx, louvre))
+
+	t.Run("update and search the location", func(t *testing.T) {
+		params := device.NewUpdateDeviceLocationParams().WithID(louvre.ID.Hex()).WithLocation(&models.Location{
+			Site: "paris", Building: "pyramid", Point: point(48.8606, 2.3376),
+		})
+		_, err := iti.ServiceClient.Device.UpdateDeviceLocation(params)
+		require.NoError(t, err)
+
+		near, radius := "48.8583,2.2944", 1000.0
+		res, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithNear(&near).WithRadius(&radius))
+		require.NoError(t, err)
+		require.Equal(t, []string{"eiffel"}, names(res.Payload.Items))
+		require.Equal(t, []float64{2.2945, 48.8584}, res.Payload.Items[0].Location.Point.Coordinates)
+
+		within := "48.85,2.33 48.87,2.33 48.87,2.35 48.85,2.35"
+		res, err = iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithWithin(&within))
+		require.NoError(t, err)
+		require.Equal(t, []string{"louvre"}, names(res.Payload.Items))
+		require.Equal(t, "pyramid", res.Payload.Items[0].Location.Building)
+
+		history, err := iti.ServiceClient.Device.GetDeviceHistory(device.NewGetDeviceHistoryParams().WithID(louvre.ID.Hex()))
+		require.NoError(t, err)
+		require.Equal(t, "updateLocation", history.Payload.Items[0].Operation)
+	})
+
+	t.Run("fail near without radius", func(t *testing.T) {
+		near := "48.8583,2.2944"
+		_, err := iti.ServiceClient.Device.GetDevices(device.NewGetDevicesParams().WithNear(&near))
+		require.EqualError(t, err, "[GET /device][400] getDevicesBadRequest {\"code\":1500001,\"message\":\"parameter 'radius' in query is required\"}")
+	})
+
+	t.Run("remove the location", func(t *testing.T) {
+		params := device.NewUpdateDeviceLocationParams().WithID(eiffel.Payload.ID).WithLocation(&models.Location{})
+		_, err := iti.ServiceClient.Device.UpdateDeviceLocation(params)
+		require.NoError(t, err)
+
+		id, err := primitive.ObjectIDFromHex(eiffel.Payload.ID)
+		require.NoError(t, err)
+		saved, err := iti.DeviceRepository.ByID(ctx, id)
+		require.NoError(t, err)
+		require.Nil(t, saved.Location)
+	})
+}
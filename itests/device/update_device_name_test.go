@@ -22,7 +22,7 @@ func Test_UpdateDeviceName(t *testing.T) {
 		id := "12345"
 		params := device.NewUpdateDeviceNameParams().WithID(id)
 		_, err := iti.ServiceClient.Device.UpdateDeviceName(params)
-		require.EqualError(t, err, "[PUT /{id}/name][400] updateDeviceNameBadRequest {\"code\":1500008,\"message\":\"decode error: EOF\"}")
+		require.EqualError(t, err, "[PUT /device/{id}/name][400] updateDeviceNameBadRequest {\"code\":1500008,\"message\":\"decode error: EOF\"}")
 	})
 
 	t.Run("fail invalid id", func(t *testing.T) {
@@ -31,7 +31,7 @@ func Test_UpdateDeviceName(t *testing.T) {
 			Name: "terra",
 		})
 		_, err := iti.ServiceClient.Device.UpdateDeviceName(params)
-		require.EqualError(t, err, "[PUT /{id}/name][400] updateDeviceNameBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"}")
+		require.EqualError(t, err, "[PUT /device/{id}/name][400] updateDeviceNameBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"}")
 	})
 
 	t.Run("device not found", func(t *testing.T) {
@@ -40,7 +40,7 @@ func Test_UpdateDeviceName(t *testing.T) {
 			Name: "jupiter",
 		})
 		_, err := iti.ServiceClient.Device.UpdateDeviceName(params)
-		require.EqualError(t, err, "[PUT /{id}/name][500] updateDeviceNameInternalServerError {\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found: mongo: no documents in result\"}")
+		require.EqualError(t, err, "[PUT /device/{id}/name][404] updateDeviceNameNotFound {\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found: mongo: no documents in result\"}")
 	})
 
 	t.Run("ok", func(t *testing.T) {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/device-ms/client/device"
+	"github.com/device-ms/handler"
 	"github.com/device-ms/itests"
 	"github.com/device-ms/model"
 	"github.com/device-ms/models"
@@ -26,7 +27,20 @@ func Test_UpdateDevice(t *testing.T) {
 		}
 		params := device.NewUpdateDeviceParams().WithID(id).WithDeviceUpdateRequestBody(&dvUpd)
 		_, err := iti.ServiceClient.Device.UpdateDevice(params)
-		require.EqualError(t, err, "[PUT /{id}][400] updateDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"}")
+		require.EqualError(t, err, "[PUT /device/{id}][400] updateDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"}")
+	})
+
+	t.Run("fail invalid id and brand", func(t *testing.T) {
+		dvUpd := models.UpdateDeviceRequest{
+			Name:  "earth",
+			Brand: "brand two",
+		}
+		params := device.NewUpdateDeviceParams().WithID("12345").WithDeviceUpdateRequestBody(&dvUpd)
+		_, err := iti.ServiceClient.Device.UpdateDevice(params)
+		require.EqualError(t, err, "[PUT /device/{id}][400] updateDeviceBadRequest {\"code\":1500013,\"errors\":["+
+			"{\"code\":1500002,\"field\":\"id\",\"message\":\"parameter 'id' is invalid 'invalid object id [12345]'\"},"+
+			"{\"code\":1500002,\"field\":\"brand\",\"message\":\"parameter 'brand' is invalid 'invalid value [brand two]'\"}],"+
+			"\"message\":\"the request has 2 invalid parameters\"}")
 	})
 
 	t.Run("fail no brand", func(t *testing.T) {
@@ -36,7 +50,7 @@ func Test_UpdateDevice(t *testing.T) {
 		}
 		params := device.NewUpdateDeviceParams().WithID(id.Hex()).WithDeviceUpdateRequestBody(&dvUpd)
 		_, err := iti.ServiceClient.Device.UpdateDevice(params)
-		require.EqualError(t, err, "[PUT /{id}][400] updateDeviceBadRequest {\"code\":1500001,\"message\":\"parameter 'brand' in body is required\"}")
+		require.EqualError(t, err, "[PUT /device/{id}][400] updateDeviceBadRequest {\"code\":1500001,\"message\":\"parameter 'brand' in body is required\"}")
 	})
 
 	t.Run("fail invalid brand", func(t *testing.T) {
@@ -47,7 +61,7 @@ func Test_UpdateDevice(t *testing.T) {
 		}
 		params := device.NewUpdateDeviceParams().WithID(id.Hex()).WithDeviceUpdateRequestBody(&dvUpd)
 		_, err := iti.ServiceClient.Device.UpdateDevice(params)
-		require.EqualError(t, err, "[PUT /{id}][400] updateDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [brand two]'\"}")
+		require.EqualError(t, err, "[PUT /device/{id}][400] updateDeviceBadRequest {\"code\":1500002,\"message\":\"parameter 'brand' is invalid 'invalid value [brand two]'\"}")
 	})
 
 	t.Run("device not found", func(t *testing.T) {
@@ -58,7 +72,7 @@ func Test_UpdateDevice(t *testing.T) {
 		}
 		params := device.NewUpdateDeviceParams().WithID(id.Hex()).WithDeviceUpdateRequestBody(&dvUpd)
 		_, err := iti.ServiceClient.Device.UpdateDevice(params)
-		require.EqualError(t, err, "[PUT /{id}][500] updateDeviceInternalServerError {\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found: mongo: no documents in result\"}")
+		require.EqualError(t, err, "[PUT /device/{id}][404] updateDeviceNotFound {\"code\":1500005,\"message\":\"the device with id "+id.Hex()+" could not be found: mongo: no documents in result\"}")
 	})
 
 	t.Run("ok", func(t *testing.T) {
@@ -92,4 +106,75 @@ func Test_UpdateDevice(t *testing.T) {
 		require.Equal(t, "marte", dvBD2.Name)
 		require.Equal(t, model.Brand("brand3"), dvBD2.Brand)
 	})
+
+	t.Run("fail version mismatch", func(t *testing.T) {
+		dv := &model.Device{
+			Brand: "brand1",
+			Name:  "mercurio",
+		}
+		err := iti.DeviceRepository.Create(ctx, dv)
+		require.NoError(t, err)
+
+		params := device.NewUpdateDeviceParams().WithID(dv.ID.Hex()).WithIfMatch(itests.NewStr("\"2\"")).WithDeviceUpdateRequestBody(&models.UpdateDeviceRequest{
+			Name:  "marte",
+			Brand: "brand3",
+		})
+		_, err = iti.ServiceClient.Device.UpdateDevice(params)
+		require.EqualError(t, err, "[PUT /device/{id}][412] updateDevicePreconditionFailed {\"code\":1500014,\"message\":\"the device with id "+dv.ID.Hex()+" does not have the expected version\"}")
+
+		dvBD, err := iti.DeviceRepository.ByID(ctx, dv.ID)
+		require.NoError(t, err)
+		require.Equal(t, "mercurio", dvBD.Name)
+		require.Equal(t, int64(1), dvBD.Version)
+	})
+
+	t.Run("ok with version", func(t *testing.T) {
+		dv := &model.Device{
+			Brand: "brand1",
+			Name:  "saturno",
+		}
+		err := iti.DeviceRepository.Create(ctx, dv)
+		require.NoError(t, err)
+
+		res, err := iti.ServiceClient.Device.GetDevice(device.NewGetDeviceParams().WithID(dv.ID.Hex()))
+		require.NoError(t, err)
+
+		params := device.NewUpdateDeviceParams().WithID(dv.ID.Hex()).WithIfMatch(&res.ETag).WithDeviceUpdateRequestBody(&models.UpdateDeviceRequest{
+			Name:  "urano",
+			Brand: "brand2",
+		})
+		_, err = iti.ServiceClient.Device.UpdateDevice(params)
+		require.NoError(t, err)
+
+		// the ETag is outdated after the update
+		_, err = iti.ServiceClient.Device.UpdateDevice(params)
+		require.EqualError(t, err, "[PUT /device/{id}][412] updateDevicePreconditionFailed {\"code\":1500014,\"message\":\"the device with id "+dv.ID.Hex()+" does not have the expected version\"}")
+
+		dvBD, err := iti.DeviceRepository.ByID(ctx, dv.ID)
+		require.NoError(t, err)
+		require.Equal(t, "urano", dvBD.Name)
+		require.Equal(t, int64(2), dvBD.Version)
+	})
+
+	t.Run("fail If-Match required", func(t *testing.T) {
+		serviceClient, closeServer := iti.StartTestServerWithConfig(ctx, t, handler.Config{RequireIfMatch: true})
+		defer closeServer()
+
+		dv := &model.Device{
+			Brand: "brand1",
+			Name:  "netuno",
+		}
+		err := iti.DeviceRepository.Create(ctx, dv)
+		require.NoError(t, err)
+
+		params := device.NewUpdateDeviceParams().WithID(dv.ID.Hex()).WithDeviceUpdateRequestBody(&models.UpdateDeviceRequest{
+			Name:  "plutao",
+			Brand: "brand1",
+		})
+		_, err = serviceClient.Device.UpdateDevice(params)
+		require.EqualError(t, err, "[PUT /device/{id}][428] updateDevicePreconditionRequired {\"code\":1500015,\"message\":\"header 'If-Match' is required\"}")
+
+		_, err = serviceClient.Device.UpdateDevice(params.WithIfMatch(itests.NewStr("*")))
+		require.NoError(t, err)
+	})
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/device-ms/client/device"
 	"github.com/device-ms/controller"
 	"github.com/device-ms/handler"
+	"github.com/device-ms/model"
 	"github.com/device-ms/mongo"
 	"github.com/go-openapi/runtime"
 	httptransport "github.com/go-openapi/runtime/client"
@@ -25,17 +26,22 @@ import (
 type (
 	// IntTestInfra is the infrastructure for integration tests
 	IntTestInfra struct {
-		DB               *mongodriver.Database
-		DeviceRepository *mongo.DeviceRepository
-		ServerAddress    string
-		Router           handler.Router
-		CloseServices    func()
-		AuthDelegate     runtime.ClientAuthInfoWriter
-		ServiceClient    *client.Swagger
-		ValidVenueID     primitive.ObjectID
-		ValidDeviceID    primitive.ObjectID
-		ValidProfileID   primitive.ObjectID
-		Controller       controller.Service
+		DB                    *mongodriver.Database
+		DeviceRepository      *mongo.DeviceRepository
+		HistoryRepository     *mongo.DeviceHistoryRepository
+		BrandRepository       *mongo.BrandRepository
+		JobRepository         *mongo.BulkJobRepository
+		IdempotencyRepository *mongo.IdempotencyRepository
+		TwinRepository        *mongo.DeviceTwinRepository
+		ServerAddress         string
+		Router                handler.Router
+		CloseServices         func()
+		AuthDelegate          runtime.ClientAuthInfoWriter
+		ServiceClient         *client.Swagger
+		ValidVenueID          primitive.ObjectID
+		ValidDeviceID         primitive.ObjectID
+		ValidProfileID        primitive.ObjectID
+		Controller            controller.Service
 	}
 )
 
@@ -49,6 +55,11 @@ func NewStr(s string) *string {
 	return &s
 }
 
+// NewBool transforms a bool into *bool
+func NewBool(b bool) *bool {
+	return &b
+}
+
 // NewITests creates a new itests struct
 func NewITests(ctx context.Context, t *testing.T) IntTestInfra {
 	iti := IntTestInfra{}
@@ -59,6 +70,17 @@ func NewITests(ctx context.Context, t *testing.T) IntTestInfra {
 	var drop func()
 	iti.DeviceRepository, drop = mongo.CreateDeviceTestRepo(ctx, t)
 	drop()
+	iti.HistoryRepository, drop = mongo.CreateDeviceHistoryTestRepo(ctx, t)
+	drop()
+	iti.BrandRepository, drop = mongo.CreateBrandTestRepo(ctx, t)
+	drop()
+	iti.JobRepository, drop = mongo.CreateBulkJobTestRepo(ctx, t)
+	drop()
+	iti.IdempotencyRepository, drop = mongo.CreateIdempotencyTestRepo(ctx, t)
+	drop()
+	iti.TwinRepository, drop = mongo.CreateDeviceTwinTestRepo(ctx, t)
+	drop()
+	model.SetBrandRegistry(iti.BrandRepository)
 
 	iti.ValidVenueID = primitive.NewObjectID()
 	iti.ValidDeviceID = primitive.NewObjectID()
@@ -66,9 +88,14 @@ func NewITests(ctx context.Context, t *testing.T) IntTestInfra {
 	iti.Controller = controller.New(
 		ctx,
 		iti.DeviceRepository,
+		iti.HistoryRepository,
+		iti.BrandRepository,
+		iti.JobRepository,
+		iti.IdempotencyRepository,
+		iti.TwinRepository,
 	)
 
-	iti.Router = handler.NewDeviceRouter(iti.Controller)
+	iti.Router = handler.NewDeviceRouter(iti.Controller, handler.Config{})
 	iti.CloseServices = func() {
 	}
 
@@ -84,7 +111,7 @@ func (iti *IntTestInfra) StartTestServer(ctx context.Context, t *testing.T) (gen
 
 	iti.ServerAddress = strings.TrimPrefix(server.URL, "http://")
 
-	unauthenticatedTransport := httptransport.New(iti.ServerAddress, handler.URLPath, []string{"http"})
+	unauthenticatedTransport := httptransport.New(iti.ServerAddress, client.DefaultBasePath, []string{"http"})
 	iti.ServiceClient = client.New(unauthenticatedTransport, strfmt.Default)
 
 	closeServers = func() {
@@ -94,3 +121,26 @@ func (iti *IntTestInfra) StartTestServer(ctx context.Context, t *testing.T) (gen
 
 	return
 }
+
+// StartUnreachableStorageServer starts a test server whose repositories cannot reach the database
+func (iti *IntTestInfra) StartUnreachableStorageServer(ctx context.Context, t *testing.T) (serviceClient *client.Swagger, closeServer func()) {
+	deviceRepository, historyRepository, brandRepository, jobRepository, idempotencyRepository, twinRepository := mongo.CreateUnreachableTestRepos(ctx, t)
+	service := controller.New(ctx, deviceRepository, historyRepository, brandRepository, jobRepository, idempotencyRepository, twinRepository)
+
+	TestMutex.Lock()
+	server := httptest.NewServer(handler.NewDeviceRouter(service, handler.Config{}))
+	TestMutex.Unlock()
+
+	transport := httptransport.New(strings.TrimPrefix(server.URL, "http://"), client.DefaultBasePath, []string{"http"})
+	return client.New(transport, strfmt.Default), server.Close
+}
+
+// StartTestServerWithConfig starts a test server using the handler configuration
+func (iti *IntTestInfra) StartTestServerWithConfig(ctx context.Context, t *testing.T, config handler.Config) (serviceClient *client.Swagger, closeServer func()) {
+	TestMutex.Lock()
+	server := httptest.NewServer(handler.NewDeviceRouter(iti.Controller, config))
+	TestMutex.Unlock()
+
+	transport := httptransport.New(strings.TrimPrefix(server.URL, "http://"), client.DefaultBasePath, []string{"http"})
+	return client.New(transport, strfmt.Default), server.Close
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/device-ms/controller"
 	"github.com/device-ms/handler"
+	"github.com/device-ms/model"
 	"github.com/device-ms/mongo"
 )
 
@@ -25,7 +26,62 @@ func initRouter(ctx context.Context) (router handler.Router) {
 		log.Fatal("Could not initialize device repository: " + err.Error())
 	}
 
-	service := controller.New(ctx, deviceRepository)
+	historyRepository, err := mongo.CreateDeviceHistoryRepo(ctx)
+	if err != nil {
+		log.Fatal("Could not initialize device history repository: " + err.Error())
+	}
+
+	jobRepository, err := mongo.CreateBulkJobRepo(ctx)
+	if err != nil {
+		log.Fatal("Could not initialize bulk job repository: " + err.Error())
+	}
+
+	idempotencyRepository, err := mongo.CreateIdempotencyRepo(ctx)
+	if err != nil {
+		log.Fatal("Could not initialize idempotency key repository: " + err.Error())
+	}
+
+	twinRepository, err := mongo.CreateDeviceTwinRepo(ctx)
+	if err != nil {
+		log.Fatal("Could not initialize device twin repository: " + err.Error())
+	}
+
+	brandRepository, err := mongo.CreateBrandRepo(ctx)
+	if err != nil {
+		log.Fatal("Could not initialize brand repository: " + err.Error())
+	}
+	model.SetBrandRegistry(brandRepository)
+
+	connectivityThresholds, err := controller.ConnectivityThresholdsFromEnv()
+	if err != nil {
+		log.Fatal("Could not read connectivity configuration: " + err.Error())
+	}
+	model.SetConnectivityThresholds(connectivityThresholds)
+
+	service := controller.New(ctx, deviceRepository, historyRepository, brandRepository, jobRepository, idempotencyRepository, twinRepository)
+
+	purgeConfig, err := controller.PurgeConfigFromEnv()
+	if err != nil {
+		log.Fatal("Could not read purge configuration: " + err.Error())
+	}
+	go controller.NewPurgeJob(service.DeviceController(), purgeConfig).Run(ctx)
+
+	sweeperConfig, err := controller.LeaseSweeperConfigFromEnv()
+	if err != nil {
+		log.Fatal("Could not read lease sweeper configuration: " + err.Error())
+	}
+	go controller.NewLeaseSweeper(service.DeviceController(), sweeperConfig).Run(ctx)
+
+	workerConfig, err := controller.BulkJobWorkerConfigFromEnv()
+	if err != nil {
+		log.Fatal("Could not read bulk job worker configuration: " + err.Error())
+	}
+	go controller.NewBulkJobWorker(service.BulkJobController(), workerConfig).Run(ctx)
+
+	config, err := handler.ConfigFromEnv()
+	if err != nil {
+		log.Fatal("Could not read handler configuration: " + err.Error())
+	}
 
-	return handler.NewDeviceRouter(service)
+	return handler.NewDeviceRouter(service, config)
 }
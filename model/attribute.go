@@ -0,0 +1,520 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// attributeNameRegexp matches the names of the attributes and of their members, without dots nor $ as they
+// are field names of the device documents
+var attributeNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]{0,63}$`)
+
+// IsValidAttributeName checks if the name of an attribute, or of a member of an object attribute, is valid:
+// up to 64 letters, digits, _ and -, not starting with a digit nor -
+func IsValidAttributeName(name string) bool {
+	return attributeNameRegexp.MatchString(name)
+}
+
+// IsValidAttributePath checks if the dot separated path of an attribute is valid
+func IsValidAttributePath(path string) bool {
+	for _, name := range strings.Split(path, ".") {
+		if !IsValidAttributeName(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// Attributes are the free-form attributes of a device, checked against the attributes schema of its brand.
+// The values are JSON values: nil, bool, float64, string, []interface{} and map[string]interface{}.
+type Attributes map[string]interface{}
+
+// UnmarshalBSONValue decodes the attributes as JSON values, whatever the types the database decodes them to
+func (a *Attributes) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bsontype.Null {
+		*a = nil
+		return nil
+	}
+	var m bson.M
+	err := bson.RawValue{Type: t, Value: data}.Unmarshal(&m)
+	if err != nil {
+		return err
+	}
+	*a = jsonValue(m).(map[string]interface{})
+	return nil
+}
+
+// jsonValue converts a value decoded from the database to the JSON value it was encoded from
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.M:
+		return jsonValue(map[string]interface{}(v))
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for name, member := range v {
+			object[name] = jsonValue(member)
+		}
+		return object
+	case primitive.D:
+		object := make(map[string]interface{}, len(v))
+		for _, e := range v {
+			object[e.Key] = jsonValue(e.Value)
+		}
+		return object
+	case primitive.A:
+		return jsonValue([]interface{}(v))
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for i, item := range v {
+			array[i] = jsonValue(item)
+		}
+		return array
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+// AttributeViolation is a value of device attributes that is invalid
+type AttributeViolation struct {
+	// Path is the path of the value in the attributes, like .screen.size or .ports[1], empty for the attributes
+	Path   string
+	Reason string
+}
+
+// Violations checks the names of the attributes and, when there is a schema, their values against it.
+// Every violation is returned, ordered by path.
+func (a Attributes) Violations(schema *AttributesSchema) []AttributeViolation {
+	var violations []AttributeViolation
+	value := map[string]interface{}(a)
+	if value == nil {
+		value = map[string]interface{}{}
+	}
+	checkAttributeNames("", value, &violations)
+	if schema != nil {
+		schema.validate("", value, &violations)
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+	return violations
+}
+
+// checkAttributeNames checks the names of the members of the objects of a value
+func checkAttributeNames(path string, value interface{}, violations *[]AttributeViolation) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, member := range v {
+			if !IsValidAttributeName(name) {
+				*violations = append(*violations, AttributeViolation{Path: path + "." + name, Reason: "invalid name [" + name + "]"})
+				continue
+			}
+			checkAttributeNames(path+"."+name, member, violations)
+		}
+	case []interface{}:
+		for i, item := range v {
+			checkAttributeNames(path+"["+strconv.Itoa(i)+"]", item, violations)
+		}
+	}
+}
+
+// attributeTypes are the JSON Schema types
+var attributeTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+// attributeAnnotations are the JSON Schema keywords that don't validate anything
+var attributeAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true, "default": true, "examples": true,
+}
+
+// AttributesSchema is a JSON Schema of device attributes.
+// It supports the type, enum, const, properties, required, additionalProperties, items, minItems, maxItems,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, minLength, maxLength and pattern keywords, patterns
+// being RE2 regular expressions. Annotations are ignored and any other keyword is rejected.
+type AttributesSchema struct {
+	// never is set for the false schema, that no value is valid against
+	never                bool
+	types                []string
+	enum                 []interface{}
+	properties           map[string]*AttributesSchema
+	required             []string
+	additionalProperties *AttributesSchema
+	items                *AttributesSchema
+	minItems, maxItems   *int
+	minimum, maximum     *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+}
+
+// ParseAttributesSchema parses the JSON Schema of device attributes, which must be of type object.
+// Errors tell the location of the invalid keyword, like /properties/ram/minimum.
+func ParseAttributesSchema(raw []byte) (*AttributesSchema, error) {
+	var value interface{}
+	err := json.Unmarshal(raw, &value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	schema, err := parseAttributesSchema("", value)
+	if err != nil {
+		return nil, err
+	}
+	if len(schema.types) != 1 || schema.types[0] != "object" {
+		return nil, fmt.Errorf("the schema must be of type object")
+	}
+	return schema, nil
+}
+
+func parseAttributesSchema(location string, value interface{}) (*AttributesSchema, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		if b, ok := value.(bool); ok {
+			return &AttributesSchema{never: !b}, nil
+		}
+		return nil, fmt.Errorf("invalid schema at [%s], expected an object or a boolean", schemaLocation(location))
+	}
+
+	keywords := make([]string, 0, len(object))
+	for keyword := range object {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	schema := new(AttributesSchema)
+	for _, keyword := range keywords {
+		value := object[keyword]
+		at := location + "/" + keyword
+		var err error
+		switch keyword {
+		case "type":
+			schema.types, err = parseSchemaTypes(at, value)
+		case "enum":
+			enum, ok := value.([]interface{})
+			if !ok || len(enum) == 0 {
+				err = fmt.Errorf("invalid value at [%s], expected a non empty array", at)
+			}
+			schema.enum = enum
+		case "const":
+			schema.enum = []interface{}{value}
+		case "properties":
+			schema.properties, err = parseSchemaProperties(at, value)
+		case "required":
+			schema.required, err = parseSchemaNames(at, value)
+		case "additionalProperties":
+			schema.additionalProperties, err = parseAttributesSchema(at, value)
+		case "items":
+			schema.items, err = parseAttributesSchema(at, value)
+		case "minimum":
+			schema.minimum, err = parseSchemaNumber(at, value)
+		case "maximum":
+			schema.maximum, err = parseSchemaNumber(at, value)
+		case "exclusiveMinimum":
+			schema.exclusiveMinimum, err = parseSchemaNumber(at, value)
+		case "exclusiveMaximum":
+			schema.exclusiveMaximum, err = parseSchemaNumber(at, value)
+		case "minLength":
+			schema.minLength, err = parseSchemaCount(at, value)
+		case "maxLength":
+			schema.maxLength, err = parseSchemaCount(at, value)
+		case "minItems":
+			schema.minItems, err = parseSchemaCount(at, value)
+		case "maxItems":
+			schema.maxItems, err = parseSchemaCount(at, value)
+		case "pattern":
+			pattern, ok := value.(string)
+			if !ok {
+				err = fmt.Errorf("invalid value at [%s], expected a string", at)
+				break
+			}
+			schema.pattern, err = regexp.Compile(pattern)
+			if err != nil {
+				err = fmt.Errorf("invalid pattern at [%s]: %v", at, err)
+			}
+		default:
+			if !attributeAnnotations[keyword] {
+				err = fmt.Errorf("unsupported keyword [%s]", at)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+func parseSchemaTypes(at string, value interface{}) ([]string, error) {
+	var types []interface{}
+	switch v := value.(type) {
+	case string:
+		types = []interface{}{v}
+	case []interface{}:
+		types = v
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("invalid value at [%s], expected a type or an array of types", at)
+	}
+	names := make([]string, len(types))
+	for i, t := range types {
+		name, ok := t.(string)
+		if !ok || !attributeTypes[name] {
+			return nil, fmt.Errorf("invalid type at [%s]: %v", at, t)
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+func parseSchemaProperties(at string, value interface{}) (map[string]*AttributesSchema, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid value at [%s], expected an object", at)
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	properties := make(map[string]*AttributesSchema, len(object))
+	for _, name := range names {
+		if !IsValidAttributeName(name) {
+			return nil, fmt.Errorf("invalid attribute name at [%s/%s]", at, name)
+		}
+		schema, err := parseAttributesSchema(at+"/"+name, object[name])
+		if err != nil {
+			return nil, err
+		}
+		properties[name] = schema
+	}
+	return properties, nil
+}
+
+func parseSchemaNames(at string, value interface{}) ([]string, error) {
+	array, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid value at [%s], expected an array of attribute names", at)
+	}
+	names := make([]string, len(array))
+	for i, item := range array {
+		name, ok := item.(string)
+		if !ok || !IsValidAttributeName(name) {
+			return nil, fmt.Errorf("invalid attribute name at [%s]: %v", at, item)
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+func parseSchemaNumber(at string, value interface{}) (*float64, error) {
+	n, ok := value.(float64)
+	if !ok {
+		return nil, fmt.Errorf("invalid value at [%s], expected a number", at)
+	}
+	return &n, nil
+}
+
+func parseSchemaCount(at string, value interface{}) (*int, error) {
+	n, ok := value.(float64)
+	if !ok || n < 0 || n != math.Trunc(n) || n > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid value at [%s], expected a non negative integer", at)
+	}
+	count := int(n)
+	return &count, nil
+}
+
+// schemaLocation returns the location of a schema, / for the root schema
+func schemaLocation(location string) string {
+	if location == "" {
+		return "/"
+	}
+	return location
+}
+
+// validate checks a value against the schema, appending its violations
+func (s *AttributesSchema) validate(path string, value interface{}, violations *[]AttributeViolation) {
+	violate := func(reason string) {
+		*violations = append(*violations, AttributeViolation{Path: path, Reason: reason})
+	}
+	if s.never {
+		violate("is not allowed")
+		return
+	}
+	if len(s.types) > 0 && !s.hasTypeOf(value) {
+		violate("expected " + strings.Join(s.types, " or "))
+		return
+	}
+	if len(s.enum) > 0 && !s.inEnum(value) {
+		enum, _ := json.Marshal(s.enum)
+		violate("must be one of " + string(enum))
+	}
+
+	switch v := value.(type) {
+	case float64:
+		s.validateNumber(v, violate)
+	case string:
+		s.validateString(v, violate)
+	case []interface{}:
+		s.validateArray(path, v, violate, violations)
+	case map[string]interface{}:
+		s.validateObject(path, v, violations)
+	}
+}
+
+func (s *AttributesSchema) validateNumber(n float64, violate func(string)) {
+	if s.minimum != nil && n < *s.minimum {
+		violate("must be at least " + formatNumber(*s.minimum))
+	}
+	if s.maximum != nil && n > *s.maximum {
+		violate("must be at most " + formatNumber(*s.maximum))
+	}
+	if s.exclusiveMinimum != nil && n <= *s.exclusiveMinimum {
+		violate("must be greater than " + formatNumber(*s.exclusiveMinimum))
+	}
+	if s.exclusiveMaximum != nil && n >= *s.exclusiveMaximum {
+		violate("must be less than " + formatNumber(*s.exclusiveMaximum))
+	}
+}
+
+func (s *AttributesSchema) validateString(str string, violate func(string)) {
+	length := utf8.RuneCountInString(str)
+	if s.minLength != nil && length < *s.minLength {
+		violate("must have at least " + strconv.Itoa(*s.minLength) + " characters")
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		violate("must have at most " + strconv.Itoa(*s.maxLength) + " characters")
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		violate("must match [" + s.pattern.String() + "]")
+	}
+}
+
+func (s *AttributesSchema) validateArray(path string, array []interface{}, violate func(string), violations *[]AttributeViolation) {
+	if s.minItems != nil && len(array) < *s.minItems {
+		violate("must have at least " + strconv.Itoa(*s.minItems) + " items")
+	}
+	if s.maxItems != nil && len(array) > *s.maxItems {
+		violate("must have at most " + strconv.Itoa(*s.maxItems) + " items")
+	}
+	if s.items != nil {
+		for i, item := range array {
+			s.items.validate(path+"["+strconv.Itoa(i)+"]", item, violations)
+		}
+	}
+}
+
+func (s *AttributesSchema) validateObject(path string, object map[string]interface{}, violations *[]AttributeViolation) {
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			*violations = append(*violations, AttributeViolation{Path: path + "." + name, Reason: "is required"})
+		}
+	}
+	for name, member := range object {
+		if !IsValidAttributeName(name) {
+			continue
+		}
+		if schema, ok := s.properties[name]; ok {
+			schema.validate(path+"."+name, member, violations)
+		} else if s.additionalProperties != nil {
+			s.additionalProperties.validate(path+"."+name, member, violations)
+		}
+	}
+}
+
+// hasTypeOf tells if a value has one of the types of the schema, integers being numbers without fraction
+func (s *AttributesSchema) hasTypeOf(value interface{}) bool {
+	for _, t := range s.types {
+		var ok bool
+		switch v := value.(type) {
+		case nil:
+			ok = t == "null"
+		case bool:
+			ok = t == "boolean"
+		case float64:
+			ok = t == "number" || t == "integer" && v == math.Trunc(v)
+		case string:
+			ok = t == "string"
+		case []interface{}:
+			ok = t == "array"
+		case map[string]interface{}:
+			ok = t == "object"
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AttributesSchema) inEnum(value interface{}) bool {
+	for _, v := range s.enum {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+// AttributeOperator enum
+type AttributeOperator string
+
+// Enum values
+const (
+	AttributeEquals         AttributeOperator = "eq"
+	AttributeNotEquals      AttributeOperator = "ne"
+	AttributeGreater        AttributeOperator = "gt"
+	AttributeGreaterOrEqual AttributeOperator = "gte"
+	AttributeLess           AttributeOperator = "lt"
+	AttributeLessOrEqual    AttributeOperator = "lte"
+)
+
+// IsValid checks if the attribute operator is valid
+func (op AttributeOperator) IsValid() bool {
+	switch op {
+	case AttributeEquals, AttributeNotEquals, AttributeGreater, AttributeGreaterOrEqual, AttributeLess, AttributeLessOrEqual:
+		return true
+	}
+	return false
+}
+
+// AttributeCondition is a condition on an attribute of the devices, by its dot separated path.
+// The value is compared as a number or a boolean when it reads as one, equality also matching it as a string.
+// Devices without the attribute meet the ne conditions.
+type AttributeCondition struct {
+	Path     string            `bson:"path"`
+	Operator AttributeOperator `bson:"operator"`
+	Value    string            `bson:"value"`
+}
+
+// TypedValue returns the value of the condition as a number or a boolean, nil when it reads as neither
+func (c AttributeCondition) TypedValue() interface{} {
+	switch c.Value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	n, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return nil
+	}
+	return n
+}
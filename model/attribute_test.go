@@ -0,0 +1,152 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const testAttributesSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"ram": {"type": "integer", "minimum": 1, "maximum": 256, "description": "GB"},
+		"screen": {
+			"type": "object",
+			"properties": {"size": {"type": "number", "exclusiveMinimum": 0}},
+			"required": ["size"]
+		},
+		"firmware": {"type": "string", "pattern": "^v[0-9]+(\\.[0-9]+)*$", "maxLength": 16},
+		"os": {"enum": ["android", "ios"]},
+		"ports": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+	},
+	"required": ["ram"],
+	"additionalProperties": false
+}`
+
+func TestAttributes(t *testing.T) {
+	schema, err := ParseAttributesSchema([]byte(testAttributesSchema))
+	require.NoError(t, err)
+
+	t.Run("names", func(t *testing.T) {
+		require.True(t, IsValidAttributeName("ram"))
+		require.True(t, IsValidAttributeName("_screen-size2"))
+		require.False(t, IsValidAttributeName(""))
+		require.False(t, IsValidAttributeName("screen.size"))
+		require.False(t, IsValidAttributeName("$where"))
+		require.False(t, IsValidAttributeName("2g"))
+
+		require.True(t, IsValidAttributePath("screen.size"))
+		require.False(t, IsValidAttributePath("screen..size"))
+	})
+
+	t.Run("valid attributes", func(t *testing.T) {
+		require.Empty(t, Attributes{"ram": 8.0}.Violations(schema))
+		require.Empty(t, Attributes{
+			"ram":      256.0,
+			"screen":   map[string]interface{}{"size": 6.1},
+			"firmware": "v1.2.3",
+			"os":       "ios",
+			"ports":    []interface{}{"usb-c"},
+		}.Violations(schema))
+	})
+
+	t.Run("every violation by path", func(t *testing.T) {
+		require.Equal(t, []AttributeViolation{
+			{Path: ".color", Reason: "is not allowed"},
+			{Path: ".firmware", Reason: "must have at most 16 characters"},
+			{Path: ".firmware", Reason: "must match [^v[0-9]+(\\.[0-9]+)*$]"},
+			{Path: ".os", Reason: `must be one of ["android","ios"]`},
+			{Path: ".ports", Reason: "must have at most 2 items"},
+			{Path: ".ports[1]", Reason: "expected string"},
+			{Path: ".ram", Reason: "expected integer"},
+			{Path: ".screen.size", Reason: "is required"},
+		}, Attributes{
+			"ram":      1.5,
+			"screen":   map[string]interface{}{},
+			"firmware": "1.2.3 beta build 45",
+			"os":       "windows",
+			"ports":    []interface{}{"usb", 2.0, "hdmi"},
+			"color":    "red",
+		}.Violations(schema))
+	})
+
+	t.Run("missing attributes", func(t *testing.T) {
+		require.Equal(t, []AttributeViolation{{Path: ".ram", Reason: "is required"}}, Attributes(nil).Violations(schema))
+	})
+
+	t.Run("free-form attributes", func(t *testing.T) {
+		require.Empty(t, Attributes{"anything": []interface{}{1.0, "two"}}.Violations(nil))
+		require.Equal(t, []AttributeViolation{
+			{Path: ".a.b.c", Reason: "invalid name [b.c]"},
+			{Path: ".list[0].$gt", Reason: "invalid name [$gt]"},
+		}, Attributes{
+			"a":    map[string]interface{}{"b.c": 1.0},
+			"list": []interface{}{map[string]interface{}{"$gt": 1.0}},
+		}.Violations(nil))
+	})
+
+	t.Run("fail invalid schema", func(t *testing.T) {
+		for schema, message := range map[string]string{
+			`[`:                  "invalid JSON: unexpected end of JSON input",
+			`{"type": "string"}`: "the schema must be of type object",
+			`{"type": "object", "properties": {"ram": {"type": "int"}}}`:               "invalid type at [/properties/ram/type]: int",
+			`{"type": "object", "properties": {"ram": {"minimum": "1"}}}`:              "invalid value at [/properties/ram/minimum], expected a number",
+			`{"type": "object", "properties": {"a.b": true}}`:                          "invalid attribute name at [/properties/a.b]",
+			`{"type": "object", "properties": {"ram": 1}}`:                             "invalid schema at [/properties/ram], expected an object or a boolean",
+			`{"type": "object", "oneOf": []}`:                                          "unsupported keyword [/oneOf]",
+			`{"type": "object", "properties": {"fw": {"pattern": "("}}}`:               "invalid pattern at [/properties/fw/pattern]: error parsing regexp: missing closing ): `(`",
+			`{"type": "object", "properties": {"fw": {"maxLength": -1}}}`:              "invalid value at [/properties/fw/maxLength], expected a non negative integer",
+			`{"type": "object", "required": ["ram", 1]}`:                               "invalid attribute name at [/required]: 1",
+			`{"type": "object", "properties": {"os": {"enum": []}}}`:                   "invalid value at [/properties/os/enum], expected a non empty array",
+			`{"type": "object", "additionalProperties": {"type": ["string"]}}`:         "",
+			`{"type": "object", "additionalProperties": {"type": []}}`:                 "invalid value at [/additionalProperties/type], expected a type or an array of types",
+			`{"type": "object", "properties": {"os": {"const": "ios"}}}`:               "",
+			`{"type": "object", "properties": {"ram": {"type": ["integer", "null"]}}}`: "",
+		} {
+			_, err := ParseAttributesSchema([]byte(schema))
+			if message == "" {
+				require.NoError(t, err, schema)
+				continue
+			}
+			require.EqualError(t, err, message, schema)
+		}
+	})
+
+	t.Run("database values", func(t *testing.T) {
+		type document struct {
+			Attributes Attributes `bson:"attributes,omitempty"`
+		}
+		attributes := Attributes{
+			"ram":    8.0,
+			"screen": map[string]interface{}{"size": 6.1},
+			"ports":  []interface{}{"usb", map[string]interface{}{"kind": "hdmi"}},
+			"nfc":    true,
+		}
+		data, err := bson.Marshal(document{Attributes: attributes})
+		require.NoError(t, err)
+		var decoded document
+		require.NoError(t, bson.Unmarshal(data, &decoded))
+		require.Equal(t, attributes, decoded.Attributes)
+
+		data, err = bson.Marshal(bson.M{"attributes": bson.M{"ram": int32(8), "count": int64(2)}})
+		require.NoError(t, err)
+		require.NoError(t, bson.Unmarshal(data, &decoded))
+		require.Equal(t, Attributes{"ram": 8.0, "count": 2.0}, decoded.Attributes)
+
+		data, err = bson.Marshal(bson.M{"attributes": nil})
+		require.NoError(t, err)
+		require.NoError(t, bson.Unmarshal(data, &decoded))
+		require.Nil(t, decoded.Attributes)
+	})
+
+	t.Run("condition values", func(t *testing.T) {
+		require.Equal(t, 16.0, AttributeCondition{Value: "16"}.TypedValue())
+		require.Equal(t, true, AttributeCondition{Value: "true"}.TypedValue())
+		require.Nil(t, AttributeCondition{Value: "android"}.TypedValue())
+		require.Nil(t, AttributeCondition{Value: "NaN"}.TypedValue())
+		require.True(t, AttributeGreaterOrEqual.IsValid())
+		require.False(t, AttributeOperator("in").IsValid())
+	})
+}
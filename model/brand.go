@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BrandInfo is the brand information model.
+// AttributesSchema is the JSON text of the JSON Schema of the attributes of the devices of the brand,
+// empty when they are free-form, see ParseAttributesSchema.
+type BrandInfo struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	Name             Brand              `bson:"name"`
+	Description      string             `bson:"description,omitempty"`
+	AttributesSchema string             `bson:"attributesSchema,omitempty"`
+	CreatedAt        time.Time          `bson:"createdAt"`
+	UpdatedAt        *time.Time         `bson:"updatedAt,omitempty"`
+}
@@ -0,0 +1,35 @@
+package model
+
+const (
+	// MaxBulkItems is the maximum number of devices of a bulk creation
+	MaxBulkItems = 1000
+	// MaxImportRows is the maximum number of rows of a CSV import
+	MaxImportRows = 10000
+)
+
+// BulkStatus is the outcome of an item of a bulk creation
+type BulkStatus string
+
+// Enum values
+const (
+	// BulkPending is an item not processed yet
+	BulkPending BulkStatus = ""
+	BulkCreated BulkStatus = "created"
+	BulkFailed  BulkStatus = "failed"
+	// BulkSkipped is an item not created because an earlier item of an ordered bulk creation,
+	// or any item of a transactional import, failed
+	BulkSkipped BulkStatus = "skipped"
+)
+
+// BulkItem is a device of a bulk creation with its outcome
+type BulkItem struct {
+	Device *Device
+	Status BulkStatus
+	Err    error
+}
+
+// Fail marks the item as failed with err
+func (item *BulkItem) Fail(err error) {
+	item.Status = BulkFailed
+	item.Err = err
+}
@@ -1,16 +1,154 @@
 package model
 
 import (
+	"regexp"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	// MaxSerialNumberLength is the maximum length of a device serial number
+	MaxSerialNumberLength = 64
+	// MaxExternalIDLength is the maximum length of the value of a device external id
+	MaxExternalIDLength = 128
+	// MaxTransitionReasonLength is the maximum length of the reason of a state transition
+	MaxTransitionReasonLength = 500
+	// MaxAssigneeLength is the maximum length of the assignee of a device lease
+	MaxAssigneeLength = 128
+)
+
+// externalSystemRegexp matches the names of the systems of the device external ids
+var externalSystemRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
 // Device is the device information model
 type Device struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	Name      string             `bson:"name"`
-	Brand     Brand              `bson:"brand"`
-	CreatedAt time.Time          `bson:"createdAt"`
-	UpdatedAt *time.Time         `bson:"updatedAt,omitempty"`
+	ID    primitive.ObjectID `bson:"_id,omitempty"`
+	Name  string             `bson:"name"`
+	Brand Brand              `bson:"brand"`
+	// SerialNumber is unique per brand, soft deleted devices keeping theirs until purged
+	SerialNumber string `bson:"serialNumber,omitempty"`
+	// ExternalIDs are unique, soft deleted devices keeping theirs until purged
+	ExternalIDs []ExternalID `bson:"externalIds,omitempty"`
+	// State only changes with transitions, a device in use cannot change brand nor be deleted
+	State DeviceState `bson:"state,omitempty"`
+	// Lease is set while the device is checked out, only once at a time
+	Lease *Lease `bson:"lease,omitempty"`
+	// Labels are free-form key/value pairs used to select devices, up to MaxLabels
+	Labels map[string]string `bson:"labels,omitempty"`
+	// Attributes are free-form values checked against the attributes schema of the brand when it has one
+	Attributes Attributes `bson:"attributes,omitempty"`
+	// ParentID is the device containing it: a device cannot be one of its own ancestors, and a device that is not
+	// soft deleted has a parent that is not soft deleted either
+	ParentID *primitive.ObjectID `bson:"parentId,omitempty"`
+	// Location is where the device is, only changed on its own
+	Location *Location `bson:"location,omitempty"`
+	// LastSeenAt is the time of the last heartbeat, which changes neither the version nor the history
+	LastSeenAt *time.Time `bson:"lastSeenAt,omitempty"`
+	// HeartbeatTokenHash is the hash of the token the heartbeats are sent with
+	HeartbeatTokenHash string     `bson:"heartbeatTokenHash,omitempty"`
+	CreatedAt          time.Time  `bson:"createdAt"`
+	UpdatedAt          *time.Time `bson:"updatedAt,omitempty"`
+	// Version is incremented on every change, devices created before versioning have version 0
+	Version int64 `bson:"version"`
+	// DeletedAt is set when the device is soft deleted, it is hard deleted once the retention period is over
+	DeletedAt *time.Time `bson:"deletedAt,omitempty"`
+}
+
+// ExternalID identifies a device in another system, like an asset tag or an ERP id
+type ExternalID struct {
+	System string `bson:"system"`
+	Value  string `bson:"value"`
+}
+
+// Lease is the checkout of a device by an assignee until a due date.
+// ExpiredAt is set when the lease is found overdue, the device staying checked out until it is checked in.
+type Lease struct {
+	Assignee     string     `bson:"assignee"`
+	CheckedOutAt time.Time  `bson:"checkedOutAt"`
+	DueAt        time.Time  `bson:"dueAt"`
+	ExpiredAt    *time.Time `bson:"expiredAt,omitempty"`
+}
+
+// String returns the external id as system/value
+func (id ExternalID) String() string {
+	return id.System + "/" + id.Value
+}
+
+// IsValidExternalSystem checks if the name of the system of an external id is valid
+func IsValidExternalSystem(system string) bool {
+	return externalSystemRegexp.MatchString(system)
+}
+
+// NewExternalIDs maps the values of external ids by system to external ids sorted by system, nil when there are none
+func NewExternalIDs(bySystem map[string]string) []ExternalID {
+	if len(bySystem) == 0 {
+		return nil
+	}
+	ids := make([]ExternalID, 0, len(bySystem))
+	for system, value := range bySystem {
+		ids = append(ids, ExternalID{System: system, Value: value})
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].System < ids[j].System })
+	return ids
+}
+
+// ExternalIDsBySystem returns the values of the external ids of the device by system, nil when there are none
+func (d Device) ExternalIDsBySystem() map[string]string {
+	if len(d.ExternalIDs) == 0 {
+		return nil
+	}
+	bySystem := make(map[string]string, len(d.ExternalIDs))
+	for _, id := range d.ExternalIDs {
+		bySystem[id.System] = id.Value
+	}
+	return bySystem
+}
+
+// CurrentState returns the state of the device, the default state for the devices created before states
+func (d Device) CurrentState() DeviceState {
+	if d.State == "" {
+		return DefaultDeviceState
+	}
+	return d.State
+}
+
+// LastModified returns the time of the last change of the device, its deletion for a soft deleted device
+func (d Device) LastModified() time.Time {
+	if d.DeletedAt != nil {
+		return *d.DeletedAt
+	}
+	if d.UpdatedAt != nil {
+		return *d.UpdatedAt
+	}
+	return d.CreatedAt
+}
+
+// DeviceChange is a device before and after a change
+type DeviceChange struct {
+	Before *Device
+	After  *Device
+}
+
+// VersionMatch restricts a change to a device to some of its versions.
+// A nil VersionMatch matches any version, an empty one matches none.
+type VersionMatch []int64
+
+// Any tells if the match allows any version
+func (m VersionMatch) Any() bool {
+	return m == nil
+}
+
+// Matches tells if the match allows a version
+func (m VersionMatch) Matches(version int64) bool {
+	if m.Any() {
+		return true
+	}
+	for _, v := range m {
+		if v == version {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceLastModified(t *testing.T) {
+	createdAt := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	device := Device{CreatedAt: createdAt}
+	require.Equal(t, createdAt, device.LastModified())
+
+	updatedAt := createdAt.Add(time.Hour)
+	device.UpdatedAt = &updatedAt
+	require.Equal(t, updatedAt, device.LastModified())
+
+	deletedAt := updatedAt.Add(time.Hour)
+	device.DeletedAt = &deletedAt
+	require.Equal(t, deletedAt, device.LastModified())
+}
+
+func TestDeviceExternalIDs(t *testing.T) {
+	require.Nil(t, NewExternalIDs(nil))
+	require.Nil(t, Device{}.ExternalIDsBySystem())
+
+	ids := NewExternalIDs(map[string]string{"erp": "E-1", "assetTag": "A-1"})
+	require.Equal(t, []ExternalID{{System: "assetTag", Value: "A-1"}, {System: "erp", Value: "E-1"}}, ids)
+	require.Equal(t, "erp/E-1", ids[1].String())
+	require.Equal(t, map[string]string{"erp": "E-1", "assetTag": "A-1"}, Device{ExternalIDs: ids}.ExternalIDsBySystem())
+
+	require.True(t, IsValidExternalSystem("asset-tag.v2"))
+	require.False(t, IsValidExternalSystem(""))
+	require.False(t, IsValidExternalSystem("erp/id"))
+}
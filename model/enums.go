@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 // Brand enum
 type Brand string
 
@@ -10,13 +12,216 @@ const (
 	Bbrand3 Brand = "brand3"
 )
 
-var mapDeviceBrand = map[Brand]bool{
-	Bbrand1: true,
-	Bbrand2: true,
-	Bbrand3: true,
+// DefaultBrands are the brands accepted while no brand registry is configured.
+// They are also used to seed an empty brand registry.
+var DefaultBrands = []Brand{Bbrand1, Bbrand2, Bbrand3}
+
+// BrandRegistry is the source of the accepted brands
+type BrandRegistry interface {
+	Exists(brand Brand) bool
 }
 
-// IsValid is valid enum value
+type defaultBrandRegistry struct{}
+
+// Exists tells if brand is one of the default brands
+func (defaultBrandRegistry) Exists(brand Brand) bool {
+	for _, b := range DefaultBrands {
+		if b == brand {
+			return true
+		}
+	}
+	return false
+}
+
+var brandRegistry BrandRegistry = defaultBrandRegistry{}
+
+// SetBrandRegistry sets the registry consulted by Brand.IsValid.
+// A nil registry restores the default brands.
+func SetBrandRegistry(registry BrandRegistry) {
+	if registry == nil {
+		registry = defaultBrandRegistry{}
+	}
+	brandRegistry = registry
+}
+
+// IsValid tells if the brand exists in the brand registry
 func (brand Brand) IsValid() bool {
-	return mapDeviceBrand[brand]
+	if brand == "" {
+		return false
+	}
+	return brandRegistry.Exists(brand)
+}
+
+// AttributesSchemaRegistry is a brand registry that also knows the attributes schemas of the brands
+type AttributesSchemaRegistry interface {
+	AttributesSchema(brand Brand) *AttributesSchema
+}
+
+// AttributesSchema returns the attributes schema of the brand from the brand registry, nil when the attributes
+// of its devices are free-form
+func (brand Brand) AttributesSchema() *AttributesSchema {
+	registry, ok := brandRegistry.(AttributesSchemaRegistry)
+	if !ok {
+		return nil
+	}
+	return registry.AttributesSchema(brand)
+}
+
+// DeviceSort enum
+type DeviceSort string
+
+// Enum values
+const (
+	SortCreatedAtAsc  DeviceSort = "createdAt"
+	SortCreatedAtDesc DeviceSort = "-createdAt"
+	SortNameAsc       DeviceSort = "name"
+	SortNameDesc      DeviceSort = "-name"
+)
+
+var mapDeviceSort = map[DeviceSort]bool{
+	SortCreatedAtAsc:  true,
+	SortCreatedAtDesc: true,
+	SortNameAsc:       true,
+	SortNameDesc:      true,
+}
+
+// IsValid is valid enum value
+func (sort DeviceSort) IsValid() bool {
+	return mapDeviceSort[sort]
+}
+
+// Descending tells if the sort is in descending order
+func (sort DeviceSort) Descending() bool {
+	return strings.HasPrefix(string(sort), "-")
+}
+
+// Field returns the sorted field name
+func (sort DeviceSort) Field() string {
+	return strings.TrimPrefix(string(sort), "-")
+}
+
+// Value returns the sorted field value of a device
+func (sort DeviceSort) Value(device *Device) interface{} {
+	if sort.Field() == "name" {
+		return device.Name
+	}
+	return device.CreatedAt
+}
+
+// NameMatch enum
+type NameMatch string
+
+// Enum values
+const (
+	NameMatchExact    NameMatch = "eq"
+	NameMatchPrefix   NameMatch = "prefix"
+	NameMatchContains NameMatch = "contains"
+)
+
+var mapNameMatch = map[NameMatch]bool{
+	NameMatchExact:    true,
+	NameMatchPrefix:   true,
+	NameMatchContains: true,
+}
+
+// IsValid is valid enum value
+func (match NameMatch) IsValid() bool {
+	return mapNameMatch[match]
+}
+
+// DeviceState enum, the states of the lifecycle of a device
+type DeviceState string
+
+// Enum values
+const (
+	StateOrdered  DeviceState = "ordered"
+	StateInStock  DeviceState = "inStock"
+	StateInUse    DeviceState = "inUse"
+	StateInRepair DeviceState = "inRepair"
+	StateRetired  DeviceState = "retired"
+)
+
+// DefaultDeviceState is the state of the devices created without state, and of the devices created before states
+const DefaultDeviceState = StateInStock
+
+var mapDeviceState = map[DeviceState]bool{
+	StateOrdered:  true,
+	StateInStock:  true,
+	StateInUse:    true,
+	StateInRepair: true,
+	StateRetired:  true,
+}
+
+// IsValid is valid enum value
+func (state DeviceState) IsValid() bool {
+	return mapDeviceState[state]
+}
+
+// DeletePolicy enum, what happens to the children of a device when it is deleted
+type DeletePolicy string
+
+// Enum values
+const (
+	// DeleteDeny refuses to delete a device that has children
+	DeleteDeny DeletePolicy = "deny"
+	// DeleteCascade deletes the descendants of a device with it
+	DeleteCascade DeletePolicy = "cascade"
+)
+
+var mapDeletePolicy = map[DeletePolicy]bool{
+	DeleteDeny:    true,
+	DeleteCascade: true,
+}
+
+// IsValid is valid enum value
+func (policy DeletePolicy) IsValid() bool {
+	return mapDeletePolicy[policy]
+}
+
+// Connectivity enum, the liveness of a device derived from its last heartbeat
+type Connectivity string
+
+// Enum values
+const (
+	// ConnectivityOnline is a device that sent a heartbeat recently
+	ConnectivityOnline Connectivity = "online"
+	// ConnectivityStale is a device whose last heartbeat is getting old
+	ConnectivityStale Connectivity = "stale"
+	// ConnectivityOffline is a device that stopped sending heartbeats, or never sent one
+	ConnectivityOffline Connectivity = "offline"
+)
+
+var mapConnectivity = map[Connectivity]bool{
+	ConnectivityOnline:  true,
+	ConnectivityStale:   true,
+	ConnectivityOffline: true,
+}
+
+// IsValid is valid enum value
+func (connectivity Connectivity) IsValid() bool {
+	return mapConnectivity[connectivity]
+}
+
+// TelemetryAggregate enum, how the samples of a bucket are downsampled to a point
+type TelemetryAggregate string
+
+// Enum values
+const (
+	// TelemetryAverage is the mean of the values of a metric in a bucket
+	TelemetryAverage TelemetryAggregate = "avg"
+	// TelemetryMinimum is the lowest value of a metric in a bucket
+	TelemetryMinimum TelemetryAggregate = "min"
+	// TelemetryMaximum is the highest value of a metric in a bucket
+	TelemetryMaximum TelemetryAggregate = "max"
+)
+
+var mapTelemetryAggregate = map[TelemetryAggregate]bool{
+	TelemetryAverage: true,
+	TelemetryMinimum: true,
+	TelemetryMaximum: true,
+}
+
+// IsValid is valid enum value
+func (aggregate TelemetryAggregate) IsValid() bool {
+	return mapTelemetryAggregate[aggregate]
 }
@@ -6,6 +6,23 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+type testBrandRegistry map[Brand]bool
+
+func (r testBrandRegistry) Exists(brand Brand) bool {
+	return r[brand]
+}
+
+type testSchemaRegistry map[Brand]*AttributesSchema
+
+func (r testSchemaRegistry) Exists(brand Brand) bool {
+	_, ok := r[brand]
+	return ok
+}
+
+func (r testSchemaRegistry) AttributesSchema(brand Brand) *AttributesSchema {
+	return r[brand]
+}
+
 func TestEnums(t *testing.T) {
 	t.Run("success brand type enum", func(t *testing.T) {
 		brand := Brand("brand1")
@@ -15,4 +32,59 @@ func TestEnums(t *testing.T) {
 		brand = Brand("blabla")
 		require.False(t, brand.IsValid())
 	})
+
+	t.Run("brand registry", func(t *testing.T) {
+		SetBrandRegistry(testBrandRegistry{"acme": true})
+		defer SetBrandRegistry(nil)
+
+		require.True(t, Brand("acme").IsValid())
+		require.False(t, Brand("brand1").IsValid())
+		require.False(t, Brand("").IsValid())
+
+		SetBrandRegistry(nil)
+		require.True(t, Brand("brand1").IsValid())
+		require.False(t, Brand("acme").IsValid())
+	})
+
+	t.Run("brand attributes schema", func(t *testing.T) {
+		require.Nil(t, Brand("brand1").AttributesSchema())
+
+		schema := new(AttributesSchema)
+		SetBrandRegistry(testSchemaRegistry{"acme": schema, "other": nil})
+		defer SetBrandRegistry(nil)
+
+		require.Same(t, schema, Brand("acme").AttributesSchema())
+		require.Nil(t, Brand("other").AttributesSchema())
+	})
+}
+
+func TestDeviceSort(t *testing.T) {
+	t.Run("success device sort enum", func(t *testing.T) {
+		require.True(t, SortCreatedAtAsc.IsValid())
+		require.True(t, SortNameDesc.IsValid())
+		require.False(t, DeviceSort("brand").IsValid())
+	})
+
+	t.Run("field and order", func(t *testing.T) {
+		require.Equal(t, "createdAt", SortCreatedAtDesc.Field())
+		require.True(t, SortCreatedAtDesc.Descending())
+		require.Equal(t, "name", SortNameAsc.Field())
+		require.False(t, SortNameAsc.Descending())
+	})
+}
+
+func TestDeviceState(t *testing.T) {
+	require.True(t, StateInUse.IsValid())
+	require.False(t, DeviceState("lost").IsValid())
+	require.False(t, DeviceState("").IsValid())
+
+	require.Equal(t, StateInStock, Device{}.CurrentState())
+	require.Equal(t, StateRetired, Device{State: StateRetired}.CurrentState())
+}
+
+func TestDeletePolicy(t *testing.T) {
+	require.True(t, DeleteDeny.IsValid())
+	require.True(t, DeleteCascade.IsValid())
+	require.False(t, DeletePolicy("orphan").IsValid())
+	require.False(t, DeletePolicy("").IsValid())
 }
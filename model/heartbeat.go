@@ -0,0 +1,93 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// heartbeatTokenSize is the number of random bytes of a heartbeat token
+const heartbeatTokenSize = 32
+
+// ConnectivityThresholds tell how long after its last heartbeat a device becomes stale, then offline
+type ConnectivityThresholds struct {
+	StaleAfter   time.Duration
+	OfflineAfter time.Duration
+}
+
+// DefaultConnectivityThresholds are the thresholds used until others are set
+var DefaultConnectivityThresholds = ConnectivityThresholds{
+	StaleAfter:   2 * time.Minute,
+	OfflineAfter: 10 * time.Minute,
+}
+
+var connectivityThresholds = DefaultConnectivityThresholds
+
+// Validate checks that the thresholds are positive and that a device becomes stale before it becomes offline
+func (t ConnectivityThresholds) Validate() error {
+	if t.StaleAfter <= 0 {
+		return fmt.Errorf("stale threshold %s must be positive", t.StaleAfter)
+	}
+	if t.OfflineAfter <= t.StaleAfter {
+		return fmt.Errorf("offline threshold %s must be greater than the stale threshold %s", t.OfflineAfter, t.StaleAfter)
+	}
+	return nil
+}
+
+// SetConnectivityThresholds sets the thresholds the connectivity of the devices is derived with.
+// Zero thresholds restore the default ones.
+func SetConnectivityThresholds(thresholds ConnectivityThresholds) {
+	if thresholds == (ConnectivityThresholds{}) {
+		thresholds = DefaultConnectivityThresholds
+	}
+	connectivityThresholds = thresholds
+}
+
+// Connectivity derives the connectivity of the device at a time from its last heartbeat
+func (d Device) Connectivity(at time.Time) Connectivity {
+	if d.LastSeenAt == nil {
+		return ConnectivityOffline
+	}
+	switch silence := at.Sub(*d.LastSeenAt); {
+	case silence < connectivityThresholds.StaleAfter:
+		return ConnectivityOnline
+	case silence < connectivityThresholds.OfflineAfter:
+		return ConnectivityStale
+	default:
+		return ConnectivityOffline
+	}
+}
+
+// LastSeenRange returns the range of the last heartbeats of the devices having the connectivity at a time.
+// The offline range has no lower bound and doesn't include the devices that never sent a heartbeat.
+func (connectivity Connectivity) LastSeenRange(at time.Time) TimeRange {
+	stale := at.Add(-connectivityThresholds.StaleAfter)
+	offline := at.Add(-connectivityThresholds.OfflineAfter)
+	switch connectivity {
+	case ConnectivityOnline:
+		return TimeRange{Gt: &stale}
+	case ConnectivityStale:
+		return TimeRange{Gt: &offline, Lte: &stale}
+	default:
+		return TimeRange{Lte: &offline}
+	}
+}
+
+// NewHeartbeatToken generates a random heartbeat token, returning it with its hash, the only one kept
+func NewHeartbeatToken() (token, hash string, err error) {
+	b := make([]byte, heartbeatTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashHeartbeatToken(token), nil
+}
+
+// HashHeartbeatToken returns the hash of a heartbeat token
+func HashHeartbeatToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
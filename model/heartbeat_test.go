@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectivity(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	seen := func(ago time.Duration) Device {
+		lastSeenAt := now.Add(-ago)
+		return Device{LastSeenAt: &lastSeenAt}
+	}
+
+	t.Run("enum", func(t *testing.T) {
+		require.True(t, ConnectivityStale.IsValid())
+		require.False(t, Connectivity("away").IsValid())
+	})
+
+	t.Run("derived from the last heartbeat", func(t *testing.T) {
+		require.Equal(t, ConnectivityOffline, Device{}.Connectivity(now))
+		require.Equal(t, ConnectivityOnline, seen(0).Connectivity(now))
+		require.Equal(t, ConnectivityOnline, seen(time.Minute).Connectivity(now))
+		require.Equal(t, ConnectivityStale, seen(2*time.Minute).Connectivity(now))
+		require.Equal(t, ConnectivityOffline, seen(10*time.Minute).Connectivity(now))
+	})
+
+	t.Run("configured thresholds", func(t *testing.T) {
+		SetConnectivityThresholds(ConnectivityThresholds{StaleAfter: 30 * time.Second, OfflineAfter: time.Minute})
+		defer SetConnectivityThresholds(ConnectivityThresholds{})
+
+		require.Equal(t, ConnectivityStale, seen(30*time.Second).Connectivity(now))
+		require.Equal(t, ConnectivityOffline, seen(time.Minute).Connectivity(now))
+	})
+
+	t.Run("last seen ranges", func(t *testing.T) {
+		stale := now.Add(-2 * time.Minute)
+		offline := now.Add(-10 * time.Minute)
+		require.Equal(t, TimeRange{Gt: &stale}, ConnectivityOnline.LastSeenRange(now))
+		require.Equal(t, TimeRange{Gt: &offline, Lte: &stale}, ConnectivityStale.LastSeenRange(now))
+		require.Equal(t, TimeRange{Lte: &offline}, ConnectivityOffline.LastSeenRange(now))
+	})
+
+	t.Run("validate thresholds", func(t *testing.T) {
+		require.NoError(t, DefaultConnectivityThresholds.Validate())
+		require.EqualError(t, ConnectivityThresholds{OfflineAfter: time.Minute}.Validate(), "stale threshold 0s must be positive")
+		require.EqualError(t, ConnectivityThresholds{StaleAfter: time.Minute, OfflineAfter: time.Minute}.Validate(),
+			"offline threshold 1m0s must be greater than the stale threshold 1m0s")
+	})
+}
+
+func TestHeartbeatToken(t *testing.T) {
+	token, hash, err := NewHeartbeatToken()
+	require.NoError(t, err)
+	require.Len(t, token, 43)
+	require.Equal(t, HashHeartbeatToken(token), hash)
+	require.NotEqual(t, token, hash)
+
+	other, _, err := NewHeartbeatToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+}
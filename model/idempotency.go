@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IdempotentRequest is a request sent with an idempotency key. Once completed, its response is replayed
+// for the same request sent again with the key.
+type IdempotentRequest struct {
+	Key string `bson:"_id"`
+	// RequestHash identifies the request, a key being reusable only for the same request
+	RequestHash string    `bson:"requestHash"`
+	Completed   bool      `bson:"completed"`
+	Status      int       `bson:"status,omitempty"`
+	ContentType string    `bson:"contentType,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"createdAt"`
+	// LockedUntil is when a request not completed is considered abandoned, letting a retry process it again
+	LockedUntil time.Time `bson:"lockedUntil"`
+	// ExpiresAt is when the key is forgotten
+	ExpiresAt time.Time `bson:"expiresAt"`
+	// DeviceID is the device the request created, whose purge removes the key
+	DeviceID *primitive.ObjectID `bson:"deviceId,omitempty"`
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxBulkJobErrors is how many device errors a bulk job keeps, the last ones
+const MaxBulkJobErrors = 20
+
+// JobStatus enum
+type JobStatus string
+
+// Enum values
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Finished tells if a job with the status will not change anymore
+func (status JobStatus) Finished() bool {
+	return status == JobSucceeded || status == JobFailed || status == JobCancelled
+}
+
+// BulkActionType enum
+type BulkActionType string
+
+// Enum values
+const (
+	BulkActionSetBrand BulkActionType = "setBrand"
+	BulkActionSetName  BulkActionType = "setName"
+	BulkActionDelete   BulkActionType = "delete"
+)
+
+var mapBulkActionType = map[BulkActionType]bool{
+	BulkActionSetBrand: true,
+	BulkActionSetName:  true,
+	BulkActionDelete:   true,
+}
+
+// IsValid is valid enum value
+func (actionType BulkActionType) IsValid() bool {
+	return mapBulkActionType[actionType]
+}
+
+// BulkAction is the change a bulk job makes to every device it matches.
+// NamePattern is the new name of the devices, where {name}, {brand} and {id} are replaced by the ones of each device.
+type BulkAction struct {
+	Type        BulkActionType `bson:"type"`
+	Brand       Brand          `bson:"brand,omitempty"`
+	NamePattern string         `bson:"namePattern,omitempty"`
+}
+
+// Name returns the name of a device after the action
+func (a BulkAction) Name(device *Device) string {
+	return strings.NewReplacer("{name}", device.Name, "{brand}", string(device.Brand), "{id}", device.ID.Hex()).Replace(a.NamePattern)
+}
+
+// BulkJob is a change of all the devices matching a filter, made in the background.
+// Total is the number of devices matching the filter when the job was created, Cursor the position of the last
+// device processed, so that a job interrupted is resumed where it stopped by the worker whose lease expired.
+type BulkJob struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	Filter          DeviceFilter       `bson:"filter"`
+	Action          BulkAction         `bson:"action"`
+	Status          JobStatus          `bson:"status"`
+	Actor           string             `bson:"actor,omitempty"`
+	Total           int64              `bson:"total"`
+	Processed       int64              `bson:"processed"`
+	Failed          int64              `bson:"failed"`
+	Errors          []BulkJobError     `bson:"errors,omitempty"`
+	Error           string             `bson:"error,omitempty"`
+	Cursor          string             `bson:"cursor,omitempty"`
+	CancelRequested bool               `bson:"cancelRequested,omitempty"`
+	CreatedAt       time.Time          `bson:"createdAt"`
+	StartedAt       *time.Time         `bson:"startedAt,omitempty"`
+	FinishedAt      *time.Time         `bson:"finishedAt,omitempty"`
+	LeaseUntil      *time.Time         `bson:"leaseUntil,omitempty"`
+}
+
+// BulkJobError is the error of a device a bulk job could not change
+type BulkJobError struct {
+	DeviceID primitive.ObjectID `bson:"deviceId"`
+	Message  string             `bson:"message"`
+}
+
+// BulkJobProgress is what a bulk job did since its last progress
+type BulkJobProgress struct {
+	Cursor     string
+	Processed  int64
+	Errors     []BulkJobError
+	LeaseUntil time.Time
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBulkJob(t *testing.T) {
+	t.Run("job status", func(t *testing.T) {
+		require.False(t, JobPending.Finished())
+		require.False(t, JobRunning.Finished())
+		require.True(t, JobSucceeded.Finished())
+		require.True(t, JobFailed.Finished())
+		require.True(t, JobCancelled.Finished())
+	})
+
+	t.Run("action type enum", func(t *testing.T) {
+		require.True(t, BulkActionSetBrand.IsValid())
+		require.True(t, BulkActionDelete.IsValid())
+		require.False(t, BulkActionType("restore").IsValid())
+	})
+
+	t.Run("name pattern", func(t *testing.T) {
+		device := &Device{ID: primitive.NewObjectID(), Name: "io", Brand: "brand1"}
+		action := BulkAction{Type: BulkActionSetName, NamePattern: "retired-{name}-{brand}-{id}"}
+		require.Equal(t, "retired-io-brand1-"+device.ID.Hex(), action.Name(device))
+	})
+
+	t.Run("empty filter", func(t *testing.T) {
+		require.True(t, DeviceFilter{}.IsZero())
+		require.False(t, DeviceFilter{Brands: []Brand{"brand1"}}.IsZero())
+		require.False(t, DeviceFilter{Name: &NameFilter{Match: NameMatchPrefix, Value: "io"}}.IsZero())
+		require.False(t, DeviceFilter{Overdue: true}.IsZero())
+		require.False(t, DeviceFilter{Attributes: []AttributeCondition{{Path: "ram", Operator: AttributeEquals, Value: "16"}}}.IsZero())
+		roots := 0
+		require.False(t, DeviceFilter{Depth: &roots}.IsZero())
+		require.False(t, DeviceFilter{Near: &GeoNear{Radius: 100}}.IsZero())
+		require.False(t, DeviceFilter{Connectivity: []Connectivity{ConnectivityOnline}}.IsZero())
+	})
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxLabels is the maximum number of labels of a device
+const MaxLabels = 64
+
+var (
+	// labelKeyRegexp matches the label keys, without dots as they are field names of the device documents
+	labelKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_/-]{0,61}[A-Za-z0-9])?$`)
+	// labelValueRegexp matches the label values, empty values being allowed
+	labelValueRegexp = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9_.-]{0,61}[A-Za-z0-9])?)?$`)
+	// existsRequirementRegexp matches the has(key) and !has(key) requirements
+	existsRequirementRegexp = regexp.MustCompile(`^(!?)has\(\s*([^()\s]*)\s*\)$`)
+	// setRequirementRegexp matches the key in (v1,v2) and key notin (v1,v2) requirements
+	setRequirementRegexp = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\(([^()]*)\)$`)
+)
+
+// IsValidLabelKey checks if a label key is valid: up to 63 letters, digits, _, / and -, starting and ending
+// with a letter or a digit
+func IsValidLabelKey(key string) bool {
+	return labelKeyRegexp.MatchString(key)
+}
+
+// IsValidLabelValue checks if a label value is valid: empty, or up to 63 letters, digits, _, . and -, starting
+// and ending with a letter or a digit
+func IsValidLabelValue(value string) bool {
+	return labelValueRegexp.MatchString(value)
+}
+
+// LabelOperator enum
+type LabelOperator string
+
+// Enum values
+const (
+	LabelEquals    LabelOperator = "eq"
+	LabelNotEquals LabelOperator = "neq"
+	LabelIn        LabelOperator = "in"
+	LabelNotIn     LabelOperator = "notIn"
+	LabelExists    LabelOperator = "exists"
+	LabelNotExists LabelOperator = "notExists"
+)
+
+// LabelRequirement is a condition on a label of the devices.
+// Devices without the label meet the neq and notIn requirements.
+type LabelRequirement struct {
+	Key      string        `bson:"key"`
+	Operator LabelOperator `bson:"operator"`
+	Values   []string      `bson:"values,omitempty"`
+}
+
+// LabelSelector selects the devices meeting all its requirements
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelector parses a label selector in the Kubernetes style, comma separated requirements:
+//
+//	key=value, key==value  the label has the value
+//	key!=value             the label doesn't have the value or is missing
+//	key in (v1,v2)         the label has one of the values
+//	key notin (v1,v2)      the label has none of the values or is missing
+//	has(key), !has(key)    the label is set, or missing
+func ParseLabelSelector(s string) (LabelSelector, error) {
+	var selector LabelSelector
+	for _, requirement := range splitRequirements(s) {
+		requirement = strings.TrimSpace(requirement)
+		if requirement == "" {
+			return nil, fmt.Errorf("empty requirement in [%s]", s)
+		}
+		parsed, err := parseLabelRequirement(requirement)
+		if err != nil {
+			return nil, err
+		}
+		if !IsValidLabelKey(parsed.Key) {
+			return nil, fmt.Errorf("invalid label key [%s]", parsed.Key)
+		}
+		for _, value := range parsed.Values {
+			if !IsValidLabelValue(value) {
+				return nil, fmt.Errorf("invalid label value [%s]", value)
+			}
+		}
+		selector = append(selector, parsed)
+	}
+	return selector, nil
+}
+
+// splitRequirements splits a selector at the commas that are not between parentheses
+func splitRequirements(s string) []string {
+	var requirements []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				requirements = append(requirements, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(requirements, s[start:])
+}
+
+func parseLabelRequirement(requirement string) (LabelRequirement, error) {
+	if match := existsRequirementRegexp.FindStringSubmatch(requirement); match != nil {
+		if match[1] == "!" {
+			return LabelRequirement{Key: match[2], Operator: LabelNotExists}, nil
+		}
+		return LabelRequirement{Key: match[2], Operator: LabelExists}, nil
+	}
+
+	if match := setRequirementRegexp.FindStringSubmatch(requirement); match != nil {
+		var values []string
+		for _, value := range strings.Split(match[3], ",") {
+			values = append(values, strings.TrimSpace(value))
+		}
+		if match[2] == "notin" {
+			return LabelRequirement{Key: match[1], Operator: LabelNotIn, Values: values}, nil
+		}
+		return LabelRequirement{Key: match[1], Operator: LabelIn, Values: values}, nil
+	}
+
+	for _, op := range []struct {
+		token    string
+		operator LabelOperator
+	}{{"!=", LabelNotEquals}, {"==", LabelEquals}, {"=", LabelEquals}} {
+		if key, value, found := strings.Cut(requirement, op.token); found {
+			return LabelRequirement{Key: strings.TrimSpace(key), Operator: op.operator, Values: []string{strings.TrimSpace(value)}}, nil
+		}
+	}
+	return LabelRequirement{}, fmt.Errorf("invalid requirement [%s]", requirement)
+}
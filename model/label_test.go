@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	t.Run("keys and values", func(t *testing.T) {
+		require.True(t, IsValidLabelKey("env"))
+		require.True(t, IsValidLabelKey("team/owner-1"))
+		require.False(t, IsValidLabelKey(""))
+		require.False(t, IsValidLabelKey("app.kubernetes.io"))
+		require.False(t, IsValidLabelKey("$where"))
+		require.False(t, IsValidLabelKey("-env"))
+
+		require.True(t, IsValidLabelValue(""))
+		require.True(t, IsValidLabelValue("v1.2"))
+		require.False(t, IsValidLabelValue("a,b"))
+		require.False(t, IsValidLabelValue("prod "))
+	})
+
+	t.Run("parse selector", func(t *testing.T) {
+		selector, err := ParseLabelSelector("env=prod, team!=qa,has(rack),!has(spare),tier==gold,zone in (a, b),os notin (win)")
+		require.NoError(t, err)
+		require.Equal(t, LabelSelector{
+			{Key: "env", Operator: LabelEquals, Values: []string{"prod"}},
+			{Key: "team", Operator: LabelNotEquals, Values: []string{"qa"}},
+			{Key: "rack", Operator: LabelExists},
+			{Key: "spare", Operator: LabelNotExists},
+			{Key: "tier", Operator: LabelEquals, Values: []string{"gold"}},
+			{Key: "zone", Operator: LabelIn, Values: []string{"a", "b"}},
+			{Key: "os", Operator: LabelNotIn, Values: []string{"win"}},
+		}, selector)
+	})
+
+	t.Run("fail invalid selector", func(t *testing.T) {
+		for s, message := range map[string]string{
+			"env":             "invalid requirement [env]",
+			"env=prod,":       "empty requirement in [env=prod,]",
+			"env.name=prod":   "invalid label key [env.name]",
+			"has($where)":     "invalid label key [$where]",
+			"env=prod env":    "invalid label value [prod env]",
+			"zone in (a,b c)": "invalid label value [b c]",
+			"zone in (a),(b)": "invalid requirement [(b)]",
+		} {
+			_, err := ParseLabelSelector(s)
+			require.EqualError(t, err, message, s)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxLocationPartLength is the maximum length of the site, the building and the room of a location
+	MaxLocationPartLength = 128
+	// GeoPointType is the GeoJSON type of the location points
+	GeoPointType = "Point"
+	// EarthRadius is the equatorial radius of the Earth in meters, converting distances to angles
+	EarthRadius = 6378100.0
+	// MaxGeoRadius is the maximum radius in meters of a search around a point, about half the circumference of the Earth
+	MaxGeoRadius = 20000000
+)
+
+// Location is where a device is, each part being optional.
+// Point is a GeoJSON point indexed for the geospatial searches.
+type Location struct {
+	Site     string    `bson:"site,omitempty"`
+	Building string    `bson:"building,omitempty"`
+	Room     string    `bson:"room,omitempty"`
+	Point    *GeoPoint `bson:"point,omitempty"`
+}
+
+// IsZero tells if the location has no part
+func (l Location) IsZero() bool {
+	return l.Site == "" && l.Building == "" && l.Room == "" && l.Point == nil
+}
+
+// GeoPoint is a GeoJSON point, its coordinates being the longitude and the latitude in that order
+type GeoPoint struct {
+	Type        string    `bson:"type"`
+	Coordinates []float64 `bson:"coordinates"`
+}
+
+// NewGeoPoint creates the GeoJSON point of a position
+func NewGeoPoint(position GeoPosition) *GeoPoint {
+	return &GeoPoint{Type: GeoPointType, Coordinates: []float64{position.Lng, position.Lat}}
+}
+
+// Validate checks that the point is a GeoJSON point with a longitude and a latitude in their ranges
+func (p GeoPoint) Validate() error {
+	if p.Type != GeoPointType {
+		return fmt.Errorf("invalid type [%s], expected %s", p.Type, GeoPointType)
+	}
+	if len(p.Coordinates) != 2 {
+		return fmt.Errorf("must have 2 coordinates, the longitude and the latitude")
+	}
+	return GeoPosition{Lat: p.Coordinates[1], Lng: p.Coordinates[0]}.Validate()
+}
+
+// GeoPosition is a position on the Earth in degrees
+type GeoPosition struct {
+	Lat float64 `bson:"lat"`
+	Lng float64 `bson:"lng"`
+}
+
+// Validate checks that the latitude is between -90 and 90 and the longitude between -180 and 180
+func (p GeoPosition) Validate() error {
+	if math.IsNaN(p.Lat) || p.Lat < -90 || p.Lat > 90 {
+		return fmt.Errorf("latitude %v is not between -90 and 90", p.Lat)
+	}
+	if math.IsNaN(p.Lng) || p.Lng < -180 || p.Lng > 180 {
+		return fmt.Errorf("longitude %v is not between -180 and 180", p.Lng)
+	}
+	return nil
+}
+
+// String returns the position as lat,lng
+func (p GeoPosition) String() string {
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(p.Lng, 'f', -1, 64)
+}
+
+// ParseGeoPosition parses a position written lat,lng
+func ParseGeoPosition(s string) (GeoPosition, error) {
+	lat, lng, ok := strings.Cut(s, ",")
+	if !ok {
+		return GeoPosition{}, fmt.Errorf("invalid position [%s], expected lat,lng", s)
+	}
+	var position GeoPosition
+	var err error
+	position.Lat, err = strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil {
+		return GeoPosition{}, fmt.Errorf("invalid latitude [%s]", lat)
+	}
+	position.Lng, err = strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	if err != nil {
+		return GeoPosition{}, fmt.Errorf("invalid longitude [%s]", lng)
+	}
+	return position, position.Validate()
+}
+
+// GeoNear selects the devices located within a radius in meters of a position
+type GeoNear struct {
+	GeoPosition `bson:",inline"`
+	Radius      float64 `bson:"radius"`
+}
+
+// Validate checks the position and that the radius is positive and at most MaxGeoRadius
+func (n GeoNear) Validate() error {
+	if err := n.GeoPosition.Validate(); err != nil {
+		return err
+	}
+	if !(n.Radius > 0 && n.Radius <= MaxGeoRadius) {
+		return fmt.Errorf("radius %v is not greater than 0 and at most %d meters", n.Radius, MaxGeoRadius)
+	}
+	return nil
+}
+
+// GeoPolygon is a polygon of at least 3 vertices, its last vertex being joined to its first one
+type GeoPolygon []GeoPosition
+
+// ParseGeoPolygon parses a polygon written as its vertices separated by spaces, lat,lng lat,lng lat,lng.
+// The polygon doesn't have to repeat its first vertex at the end.
+func ParseGeoPolygon(s string) (GeoPolygon, error) {
+	var polygon GeoPolygon
+	for _, vertex := range strings.Fields(s) {
+		position, err := ParseGeoPosition(vertex)
+		if err != nil {
+			return nil, err
+		}
+		polygon = append(polygon, position)
+	}
+	return polygon, polygon.Validate()
+}
+
+// Validate checks the vertices and that there are at least 3 distinct ones
+func (p GeoPolygon) Validate() error {
+	distinct := make(map[GeoPosition]bool, len(p))
+	for _, vertex := range p {
+		if err := vertex.Validate(); err != nil {
+			return err
+		}
+		distinct[vertex] = true
+	}
+	if len(distinct) < 3 {
+		return fmt.Errorf("a polygon must have at least 3 distinct vertices")
+	}
+	return nil
+}
+
+// Ring returns the GeoJSON ring of the polygon, its [lng, lat] coordinates closed by its first vertex
+func (p GeoPolygon) Ring() [][]float64 {
+	ring := make([][]float64, 0, len(p)+1)
+	for _, vertex := range p {
+		ring = append(ring, []float64{vertex.Lng, vertex.Lat})
+	}
+	if len(p) > 0 && p[0] != p[len(p)-1] {
+		ring = append(ring, []float64{p[0].Lng, p[0].Lat})
+	}
+	return ring
+}
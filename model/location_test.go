@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeoPosition(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		position, err := ParseGeoPosition("48.8584, 2.2945")
+		require.NoError(t, err)
+		require.Equal(t, GeoPosition{Lat: 48.8584, Lng: 2.2945}, position)
+		require.Equal(t, "48.8584,2.2945", position.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseGeoPosition("48.8584")
+		require.EqualError(t, err, "invalid position [48.8584], expected lat,lng")
+		_, err = ParseGeoPosition("north,2")
+		require.EqualError(t, err, "invalid latitude [north]")
+		_, err = ParseGeoPosition("91,2")
+		require.EqualError(t, err, "latitude 91 is not between -90 and 90")
+		_, err = ParseGeoPosition("0,-180.5")
+		require.EqualError(t, err, "longitude -180.5 is not between -180 and 180")
+	})
+
+	t.Run("point", func(t *testing.T) {
+		point := NewGeoPoint(GeoPosition{Lat: 48.8584, Lng: 2.2945})
+		require.Equal(t, &GeoPoint{Type: "Point", Coordinates: []float64{2.2945, 48.8584}}, point)
+		require.NoError(t, point.Validate())
+		require.EqualError(t, GeoPoint{Type: "LineString", Coordinates: []float64{0, 0}}.Validate(), "invalid type [LineString], expected Point")
+		require.EqualError(t, GeoPoint{Type: "Point", Coordinates: []float64{0}}.Validate(), "must have 2 coordinates, the longitude and the latitude")
+		require.EqualError(t, GeoPoint{Type: "Point", Coordinates: []float64{0, 95}}.Validate(), "latitude 95 is not between -90 and 90")
+	})
+
+	t.Run("near", func(t *testing.T) {
+		require.NoError(t, GeoNear{GeoPosition: GeoPosition{Lat: 1, Lng: 2}, Radius: 500}.Validate())
+		require.Error(t, GeoNear{GeoPosition: GeoPosition{Lat: 1, Lng: 2}}.Validate())
+		require.Error(t, GeoNear{GeoPosition: GeoPosition{Lat: 1, Lng: 2}, Radius: 2 * MaxGeoRadius}.Validate())
+	})
+}
+
+func TestGeoPolygon(t *testing.T) {
+	t.Run("parse and close the ring", func(t *testing.T) {
+		polygon, err := ParseGeoPolygon("0,0 0,1  1,1")
+		require.NoError(t, err)
+		require.Equal(t, GeoPolygon{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}, {Lat: 1, Lng: 1}}, polygon)
+		require.Equal(t, [][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}, polygon.Ring())
+
+		polygon, err = ParseGeoPolygon("0,0 0,1 1,1 0,0")
+		require.NoError(t, err)
+		require.Len(t, polygon.Ring(), 4)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseGeoPolygon("0,0 0,1 0,0")
+		require.EqualError(t, err, "a polygon must have at least 3 distinct vertices")
+		_, err = ParseGeoPolygon("0,0 0,1 x,1")
+		require.EqualError(t, err, "invalid latitude [x]")
+		_, err = ParseGeoPolygon("")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Device history limits
+const (
+	DefaultRevisionLimit = 50
+	MaxRevisionLimit     = 500
+)
+
+// RevisionOperation enum
+type RevisionOperation string
+
+// Enum values
+const (
+	OperationCreate               RevisionOperation = "create"
+	OperationUpdate               RevisionOperation = "update"
+	OperationUpdateName           RevisionOperation = "updateName"
+	OperationUpdateBrand          RevisionOperation = "updateBrand"
+	OperationPatch                RevisionOperation = "patch"
+	OperationDelete               RevisionOperation = "delete"
+	OperationRestore              RevisionOperation = "restore"
+	OperationTransition           RevisionOperation = "transition"
+	OperationCheckout             RevisionOperation = "checkout"
+	OperationCheckin              RevisionOperation = "checkin"
+	OperationLeaseExpired         RevisionOperation = "leaseExpired"
+	OperationSetLabel             RevisionOperation = "setLabel"
+	OperationRemoveLabel          RevisionOperation = "removeLabel"
+	OperationSetParent            RevisionOperation = "setParent"
+	OperationUpdateLocation       RevisionOperation = "updateLocation"
+	OperationRotateHeartbeatToken RevisionOperation = "rotateHeartbeatToken"
+)
+
+// DeviceRevision is the immutable record of a change of a device.
+// Revision is the version of the device after the change, Before is nil for its creation.
+// Reason is the reason given for a state transition.
+type DeviceRevision struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	DeviceID  primitive.ObjectID `bson:"deviceId"`
+	Revision  int64              `bson:"revision"`
+	Operation RevisionOperation  `bson:"operation"`
+	Actor     string             `bson:"actor,omitempty"`
+	Reason    string             `bson:"reason,omitempty"`
+	At        time.Time          `bson:"at"`
+	Before    *Device            `bson:"before,omitempty"`
+	After     *Device            `bson:"after"`
+}
+
+// NewDeviceRevision creates the revision of a change made by actor.
+// The heartbeat token hash of the device is a secret, it is not kept in the history.
+func NewDeviceRevision(operation RevisionOperation, actor string, change DeviceChange) *DeviceRevision {
+	return &DeviceRevision{
+		DeviceID:  change.After.ID,
+		Revision:  change.After.Version,
+		Operation: operation,
+		Actor:     actor,
+		At:        change.After.LastModified(),
+		Before:    withoutSecrets(change.Before),
+		After:     withoutSecrets(change.After),
+	}
+}
+
+// withoutSecrets returns a copy of a device without its heartbeat token hash
+func withoutSecrets(device *Device) *Device {
+	if device == nil || device.HeartbeatTokenHash == "" {
+		return device
+	}
+	copied := *device
+	copied.HeartbeatTokenHash = ""
+	return &copied
+}
+
+// RevisionSearch is the criteria used to list the revisions of a device, the newest first
+type RevisionSearch struct {
+	DeviceID primitive.ObjectID
+	Limit    int64
+	// Before selects the revisions older than it, when it is not 0
+	Before int64
+}
+
+// RevisionPage is a page of revisions
+type RevisionPage struct {
+	Revisions  []DeviceRevision
+	NextCursor string
+}
+
+// NewRevisionCursor creates the cursor of the page after a revision
+func NewRevisionCursor(revision int64) string {
+	return strconv.FormatInt(revision, 10)
+}
+
+// DecodeRevisionCursor decodes a cursor created by NewRevisionCursor
+func DecodeRevisionCursor(s string) (int64, error) {
+	revision, err := strconv.ParseInt(s, 10, 64)
+	if err == nil && revision < 1 {
+		err = strconv.ErrRange
+	}
+	return revision, err
+}
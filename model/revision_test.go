@@ -0,0 +1,21 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewDeviceRevision(t *testing.T) {
+	before := &Device{ID: primitive.NewObjectID(), Name: "earth", Version: 1}
+	after := *before
+	after.Version = 2
+	after.HeartbeatTokenHash = "hash"
+
+	revision := NewDeviceRevision(OperationRotateHeartbeatToken, "alice", DeviceChange{Before: before, After: &after})
+	require.Equal(t, int64(2), revision.Revision)
+	require.Same(t, before, revision.Before)
+	require.Empty(t, revision.After.HeartbeatTokenHash)
+	require.Equal(t, "hash", after.HeartbeatTokenHash)
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Device list limits
+const (
+	DefaultDeviceLimit = 50
+	MaxDeviceLimit     = 500
+)
+
+// DeviceFilter is the criteria devices must match, saved as is in the bulk jobs
+type DeviceFilter struct {
+	Brands    []Brand       `bson:"brands,omitempty"`
+	States    []DeviceState `bson:"states,omitempty"`
+	Name      *NameFilter   `bson:"name,omitempty"`
+	CreatedAt TimeRange     `bson:"createdAt,omitempty"`
+	UpdatedAt TimeRange     `bson:"updatedAt,omitempty"`
+	// UpdatedSince selects the devices created or updated at or after it
+	UpdatedSince *time.Time `bson:"updatedSince,omitempty"`
+	// Assignee selects the devices checked out by it
+	Assignee string `bson:"assignee,omitempty"`
+	// Overdue selects the devices checked out past their due date, when they are searched
+	Overdue bool `bson:"overdue,omitempty"`
+	// Labels selects the devices whose labels meet all its requirements
+	Labels LabelSelector `bson:"labels,omitempty"`
+	// Attributes selects the devices whose attributes meet all its conditions
+	Attributes []AttributeCondition `bson:"attributes,omitempty"`
+	// Depth selects the devices having that many ancestors, 0 for the devices without parent
+	Depth *int `bson:"depth,omitempty"`
+	// Near selects the devices located within a radius of a position
+	Near *GeoNear `bson:"near,omitempty"`
+	// Within selects the devices located inside a polygon
+	Within GeoPolygon `bson:"within,omitempty"`
+	// Connectivity selects the devices having any of the connectivities when they are searched
+	Connectivity []Connectivity `bson:"connectivity,omitempty"`
+}
+
+// IsZero tells if the filter has no criteria, matching every device
+func (f DeviceFilter) IsZero() bool {
+	return len(f.Brands) == 0 && len(f.States) == 0 && f.Name == nil && f.CreatedAt.IsZero() && f.UpdatedAt.IsZero() && f.UpdatedSince == nil &&
+		f.Assignee == "" && !f.Overdue && len(f.Labels) == 0 && len(f.Attributes) == 0 && f.Depth == nil &&
+		f.Near == nil && len(f.Within) == 0 && len(f.Connectivity) == 0
+}
+
+// NameFilter is the criteria device names must match
+type NameFilter struct {
+	Match NameMatch `bson:"match"`
+	Value string    `bson:"value"`
+}
+
+// TimeRange is a range of instants, each bound being optional
+type TimeRange struct {
+	Gt  *time.Time `bson:"gt,omitempty"`
+	Gte *time.Time `bson:"gte,omitempty"`
+	Lt  *time.Time `bson:"lt,omitempty"`
+	Lte *time.Time `bson:"lte,omitempty"`
+}
+
+// IsZero tells if no bound is defined
+func (tr TimeRange) IsZero() bool {
+	return tr.Gt == nil && tr.Gte == nil && tr.Lt == nil && tr.Lte == nil
+}
+
+// DeviceSearch is the criteria used to list devices
+type DeviceSearch struct {
+	DeviceFilter
+	Limit     int64
+	Cursor    *DeviceCursor
+	Sort      DeviceSort
+	WithTotal bool
+	// Deleted lists the soft deleted devices instead of the other ones
+	Deleted bool
+}
+
+// DevicePage is a page of devices
+type DevicePage struct {
+	Devices    []Device
+	NextCursor string
+	Total      *int64
+}
+
+// DeviceCursor is the position of the last device of a page
+type DeviceCursor struct {
+	Sort  DeviceSort         `bson:"s"`
+	Value interface{}        `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+// NewDeviceCursor creates the cursor positioned at device for a sort
+func NewDeviceCursor(sort DeviceSort, device *Device) DeviceCursor {
+	return DeviceCursor{
+		Sort:  sort,
+		Value: sort.Value(device),
+		ID:    device.ID,
+	}
+}
+
+// Encode encodes the cursor as an opaque string
+func (c DeviceCursor) Encode() string {
+	b, err := bson.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeDeviceCursor decodes a cursor encoded by DeviceCursor.Encode for a sort.
+// The cursor comes from the clients, so it must have been created for that sort and its value must have the type
+// of the sorted field, a string for the name and a time for the creation time, to be used in a query as it is.
+func DecodeDeviceCursor(s string, sort DeviceSort) (*DeviceCursor, error) {
+	invalid := fmt.Errorf("invalid value [%s]", s)
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, invalid
+	}
+	var raw struct {
+		Sort  DeviceSort         `bson:"s"`
+		Value bson.RawValue      `bson:"v"`
+		ID    primitive.ObjectID `bson:"id"`
+	}
+	err = bson.Unmarshal(b, &raw)
+	if err != nil {
+		return nil, invalid
+	}
+	if raw.Sort != sort {
+		return nil, fmt.Errorf("cursor was created for sort [%s]", raw.Sort)
+	}
+
+	cursor := &DeviceCursor{Sort: raw.Sort, ID: raw.ID}
+	if sort.Field() == "name" {
+		name, ok := raw.Value.StringValueOK()
+		if !ok {
+			return nil, invalid
+		}
+		cursor.Value = name
+	} else {
+		createdAt, ok := raw.Value.DateTimeOK()
+		if !ok {
+			return nil, invalid
+		}
+		cursor.Value = time.UnixMilli(createdAt).UTC()
+	}
+	return cursor, nil
+}
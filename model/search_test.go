@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeviceCursor(t *testing.T) {
+	device := Device{
+		ID:        primitive.NewObjectID(),
+		Name:      "jupiter",
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	t.Run("encode and decode", func(t *testing.T) {
+		encoded := NewDeviceCursor(SortNameDesc, &device).Encode()
+		cursor, err := DecodeDeviceCursor(encoded, SortNameDesc)
+		require.NoError(t, err)
+		require.Equal(t, SortNameDesc, cursor.Sort)
+		require.Equal(t, "jupiter", cursor.Value)
+		require.Equal(t, device.ID, cursor.ID)
+
+		encoded = NewDeviceCursor(SortCreatedAtAsc, &device).Encode()
+		cursor, err = DecodeDeviceCursor(encoded, SortCreatedAtAsc)
+		require.NoError(t, err)
+		require.Equal(t, device.CreatedAt, cursor.Value)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := DecodeDeviceCursor("not a cursor", SortNameAsc)
+		require.EqualError(t, err, "invalid value [not a cursor]")
+		_, err = DecodeDeviceCursor("bm90IGJzb24", SortNameAsc)
+		require.EqualError(t, err, "invalid value [bm90IGJzb24]")
+	})
+
+	t.Run("cursor of another sort", func(t *testing.T) {
+		_, err := DecodeDeviceCursor(NewDeviceCursor(SortNameDesc, &device).Encode(), SortNameAsc)
+		require.EqualError(t, err, "cursor was created for sort [-name]")
+	})
+
+	t.Run("value of another type", func(t *testing.T) {
+		for _, value := range []interface{}{primitive.M{"$ne": nil}, primitive.Regex{Pattern: ".*"}, int64(1), "jupiter"} {
+			encoded := DeviceCursor{Sort: SortCreatedAtAsc, Value: value, ID: device.ID}.Encode()
+			_, err := DecodeDeviceCursor(encoded, SortCreatedAtAsc)
+			require.EqualError(t, err, "invalid value ["+encoded+"]")
+		}
+		encoded := DeviceCursor{Sort: SortNameAsc, Value: primitive.M{"$regex": ".*"}, ID: device.ID}.Encode()
+		_, err := DecodeDeviceCursor(encoded, SortNameAsc)
+		require.EqualError(t, err, "invalid value ["+encoded+"]")
+	})
+}
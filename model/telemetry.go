@@ -0,0 +1,125 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxTelemetrySamples is the maximum number of samples a device posts at once
+	MaxTelemetrySamples = 1000
+	// MaxTelemetryMetrics is the maximum number of metrics of a sample
+	MaxTelemetryMetrics = 64
+	// MaxTelemetryPoints is the maximum number of points of a series, samples or buckets
+	MaxTelemetryPoints = 1000
+	// TelemetryClockSkew is how far in the future the time of a sample can be, the clocks of the devices drifting
+	TelemetryClockSkew = time.Minute
+	// DefaultTelemetryWindow is how far back a series goes when its start is not given
+	DefaultTelemetryWindow = 24 * time.Hour
+)
+
+// metricNameRegexp matches the names of the metrics, without dots nor $ as they are field names of the samples
+var metricNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]{0,63}$`)
+
+// IsValidMetricName checks if the name of a metric is valid: up to 64 letters, digits, _ and -, not starting
+// with a digit nor -
+func IsValidMetricName(name string) bool {
+	return metricNameRegexp.MatchString(name)
+}
+
+// TelemetrySample is the values of the metrics of a device at a time, like its temperature or its battery level.
+// The samples are kept in a time-series collection, the device being its meta field.
+type TelemetrySample struct {
+	DeviceID primitive.ObjectID `bson:"deviceId"`
+	At       time.Time          `bson:"at"`
+	Metrics  map[string]float64 `bson:"metrics"`
+}
+
+// Validate checks that the sample has a time at most TelemetryClockSkew after now, and from 1 to MaxTelemetryMetrics
+// metrics with valid names and finite values
+func (s TelemetrySample) Validate(now time.Time) error {
+	if s.At.IsZero() {
+		return fmt.Errorf("the time is required")
+	}
+	if s.At.After(now.Add(TelemetryClockSkew)) {
+		return fmt.Errorf("the time %s is in the future", s.At.Format(time.RFC3339))
+	}
+	if len(s.Metrics) == 0 || len(s.Metrics) > MaxTelemetryMetrics {
+		return fmt.Errorf("must have from 1 to %d metrics", MaxTelemetryMetrics)
+	}
+	for _, name := range sortedMetricNames(s.Metrics) {
+		if !IsValidMetricName(name) {
+			return fmt.Errorf("invalid metric name [%s]", name)
+		}
+		if value := s.Metrics[name]; math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("the value of the metric %s is not a finite number", name)
+		}
+	}
+	return nil
+}
+
+// sortedMetricNames returns the names of the metrics in order, so that the errors are always the same
+func sortedMetricNames(metrics map[string]float64) []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TelemetryQuery selects the samples of a device taken from From included to To excluded.
+// With a Bucket, the samples are downsampled with Aggregate to a point per bucket of that duration,
+// the buckets being aligned on the Unix epoch. Metrics restricts the series to some metrics, all of them when empty.
+type TelemetryQuery struct {
+	DeviceID  primitive.ObjectID
+	From      time.Time
+	To        time.Time
+	Bucket    time.Duration
+	Aggregate TelemetryAggregate
+	Metrics   []string
+}
+
+// Validate checks that the range is not empty and that a bucket is a whole number of seconds
+// splitting the range in at most MaxTelemetryPoints buckets
+func (q TelemetryQuery) Validate() error {
+	if !q.To.After(q.From) {
+		return fmt.Errorf("the end %s is not after the start %s", q.To.Format(time.RFC3339), q.From.Format(time.RFC3339))
+	}
+	for _, name := range q.Metrics {
+		if !IsValidMetricName(name) {
+			return fmt.Errorf("invalid metric name [%s]", name)
+		}
+	}
+	if q.Bucket == 0 {
+		return nil
+	}
+	if q.Bucket < time.Second || q.Bucket%time.Second != 0 {
+		return fmt.Errorf("the bucket %s is not a whole number of seconds", q.Bucket)
+	}
+	if !q.Aggregate.IsValid() {
+		return fmt.Errorf("invalid aggregate [%s]", q.Aggregate)
+	}
+	if buckets := q.To.Sub(q.From) / q.Bucket; buckets > MaxTelemetryPoints {
+		return fmt.Errorf("the bucket %s splits the range in more than %d buckets", q.Bucket, MaxTelemetryPoints)
+	}
+	return nil
+}
+
+// TelemetryPoint is a point of a series: a sample, or the aggregated samples of the bucket starting at At
+type TelemetryPoint struct {
+	At      time.Time          `bson:"at"`
+	Metrics map[string]float64 `bson:"metrics"`
+}
+
+// TelemetrySeries is the points of a telemetry query, the oldest first.
+// Truncated tells that the samples of the range don't all fit in MaxTelemetryPoints points.
+type TelemetrySeries struct {
+	Points    []TelemetryPoint
+	Truncated bool
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetry(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("aggregate enum", func(t *testing.T) {
+		require.True(t, TelemetryMaximum.IsValid())
+		require.False(t, TelemetryAggregate("sum").IsValid())
+	})
+
+	t.Run("metric names", func(t *testing.T) {
+		require.True(t, IsValidMetricName("temperature"))
+		require.True(t, IsValidMetricName("cpu_load-1m"))
+		require.False(t, IsValidMetricName("1m"))
+		require.False(t, IsValidMetricName("disk.used"))
+		require.False(t, IsValidMetricName("$sum"))
+	})
+
+	t.Run("validate sample", func(t *testing.T) {
+		sample := TelemetrySample{At: now, Metrics: map[string]float64{"temperature": 21.5, "battery": 80}}
+		require.NoError(t, sample.Validate(now))
+		sample.At = now.Add(TelemetryClockSkew)
+		require.NoError(t, sample.Validate(now))
+
+		sample.At = now.Add(2 * TelemetryClockSkew)
+		require.EqualError(t, sample.Validate(now), "the time 2025-01-15T12:02:00Z is in the future")
+		require.EqualError(t, TelemetrySample{Metrics: sample.Metrics}.Validate(now), "the time is required")
+		require.EqualError(t, TelemetrySample{At: now}.Validate(now), "must have from 1 to 64 metrics")
+		require.EqualError(t, TelemetrySample{At: now, Metrics: map[string]float64{"a.b": 1}}.Validate(now), "invalid metric name [a.b]")
+		require.EqualError(t, TelemetrySample{At: now, Metrics: map[string]float64{"battery": math.Inf(1)}}.Validate(now),
+			"the value of the metric battery is not a finite number")
+	})
+
+	t.Run("validate query", func(t *testing.T) {
+		query := TelemetryQuery{From: now.Add(-time.Hour), To: now}
+		require.NoError(t, query.Validate())
+
+		query.Bucket, query.Aggregate = time.Minute, TelemetryAverage
+		require.NoError(t, query.Validate())
+
+		query.Bucket = 3 * time.Second
+		require.EqualError(t, query.Validate(), "the bucket 3s splits the range in more than 1000 buckets")
+		query.Bucket = 1500 * time.Millisecond
+		require.EqualError(t, query.Validate(), "the bucket 1.5s is not a whole number of seconds")
+		query.Bucket, query.Aggregate = time.Minute, "sum"
+		require.EqualError(t, query.Validate(), "invalid aggregate [sum]")
+		query.Aggregate, query.Metrics = TelemetryMinimum, []string{"temperature", "a.b"}
+		require.EqualError(t, query.Validate(), "invalid metric name [a.b]")
+
+		require.EqualError(t, TelemetryQuery{From: now, To: now}.Validate(), "the end 2025-01-15T12:00:00Z is not after the start 2025-01-15T12:00:00Z")
+	})
+}
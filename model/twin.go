@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxTwinPropertiesSize is the maximum size in bytes of the JSON of the desired or of the reported properties
+const MaxTwinPropertiesSize = 32 * 1024
+
+// TwinProperties are the free-form properties of a side of a device twin, named like the attributes.
+// The values are JSON values: nil, bool, float64, string, []interface{} and map[string]interface{}.
+type TwinProperties map[string]interface{}
+
+// UnmarshalBSONValue decodes the properties as JSON values, whatever the types the database decodes them to
+func (p *TwinProperties) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bsontype.Null {
+		*p = nil
+		return nil
+	}
+	var m bson.M
+	err := bson.RawValue{Type: t, Value: data}.Unmarshal(&m)
+	if err != nil {
+		return err
+	}
+	*p = jsonValue(m).(map[string]interface{})
+	return nil
+}
+
+// Violations checks the names of the properties and the size of their JSON.
+// Every violation is returned, ordered by path.
+func (p TwinProperties) Violations() []AttributeViolation {
+	var violations []AttributeViolation
+	checkAttributeNames("", map[string]interface{}(p), &violations)
+	if b, err := json.Marshal(p); err != nil || len(b) > MaxTwinPropertiesSize {
+		violations = append(violations, AttributeViolation{Reason: "must be a JSON object of at most " + strconv.Itoa(MaxTwinPropertiesSize) + " bytes"})
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+	return violations
+}
+
+// TwinSide is a side of a device twin, its properties being versioned independently of the other side
+type TwinSide struct {
+	Properties TwinProperties `bson:"properties,omitempty"`
+	// Version is incremented on every change of the side, 0 until it is set
+	Version   int64      `bson:"version"`
+	UpdatedAt *time.Time `bson:"updatedAt,omitempty"`
+}
+
+// DeviceTwin is the configuration of a device as the operators want it, the desired side,
+// and as the device applied it, the reported side.
+// It is kept apart from the device, so its changes don't change the version nor the history of the device.
+type DeviceTwin struct {
+	DeviceID primitive.ObjectID `bson:"_id"`
+	Desired  TwinSide           `bson:"desired"`
+	Reported TwinSide           `bson:"reported"`
+}
+
+// Delta returns the desired properties that the reported ones don't match yet. The objects are compared member
+// by member, so only their members that differ are kept. The properties reported but not desired are ignored.
+func (t DeviceTwin) Delta() TwinProperties {
+	return deltaOf(t.Desired.Properties, t.Reported.Properties)
+}
+
+func deltaOf(desired, reported map[string]interface{}) map[string]interface{} {
+	delta := make(map[string]interface{})
+	for name, want := range desired {
+		have, ok := reported[name]
+		wantObject, isObject := want.(map[string]interface{})
+		haveObject, hasObject := have.(map[string]interface{})
+		if isObject && hasObject {
+			if members := deltaOf(wantObject, haveObject); len(members) > 0 {
+				delta[name] = members
+			}
+			continue
+		}
+		if !ok || !reflect.DeepEqual(want, have) {
+			delta[name] = want
+		}
+	}
+	return delta
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDeviceTwin(t *testing.T) {
+	t.Run("delta", func(t *testing.T) {
+		twin := DeviceTwin{
+			Desired: TwinSide{Properties: TwinProperties{
+				"interval": 60.0,
+				"firmware": "2.1.0",
+				"network":  map[string]interface{}{"ssid": "lab", "dhcp": true},
+				"ports":    []interface{}{80.0, 443.0},
+			}},
+			Reported: TwinSide{Properties: TwinProperties{
+				"interval": 60.0,
+				"firmware": "2.0.3",
+				"network":  map[string]interface{}{"ssid": "lab", "dhcp": false},
+				"ports":    []interface{}{80.0},
+				"uptime":   3600.0,
+			}},
+		}
+		require.Equal(t, TwinProperties{
+			"firmware": "2.1.0",
+			"network":  map[string]interface{}{"dhcp": true},
+			"ports":    []interface{}{80.0, 443.0},
+		}, twin.Delta())
+
+		twin.Reported.Properties = nil
+		require.Equal(t, map[string]interface{}(twin.Desired.Properties), map[string]interface{}(twin.Delta()))
+
+		twin.Reported.Properties = twin.Desired.Properties
+		require.Empty(t, twin.Delta())
+		require.NotNil(t, twin.Delta())
+	})
+
+	t.Run("violations", func(t *testing.T) {
+		require.Empty(t, TwinProperties{"network": map[string]interface{}{"ssid": "lab"}}.Violations())
+		require.Equal(t, []AttributeViolation{
+			{Path: ".a.b", Reason: "invalid name [a.b]"},
+			{Path: ".network.$ssid", Reason: "invalid name [$ssid]"},
+		}, TwinProperties{"a.b": 1.0, "network": map[string]interface{}{"$ssid": "lab"}}.Violations())
+		require.Equal(t, []AttributeViolation{{Reason: "must be a JSON object of at most 32768 bytes"}},
+			TwinProperties{"blob": strings.Repeat("x", MaxTwinPropertiesSize)}.Violations())
+	})
+
+	t.Run("decoded as JSON values", func(t *testing.T) {
+		b, err := bson.Marshal(bson.M{"properties": bson.M{"interval": int32(60), "tags": bson.A{"a"}}})
+		require.NoError(t, err)
+		var side TwinSide
+		require.NoError(t, bson.Unmarshal(b, &side))
+		require.Equal(t, TwinProperties{"interval": 60.0, "tags": []interface{}{"a"}}, side.Properties)
+	})
+}
@@ -8,8 +8,10 @@ package models
 import (
 	"context"
 
+	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
 )
 
 // CreateDeviceRequest CreateDeviceRequest
@@ -17,20 +19,155 @@ import (
 // swagger:model CreateDeviceRequest
 type CreateDeviceRequest struct {
 
+	// Free-form attributes of the device, like its specs, valid against the attributes schema of its brand (names of up to 64 letters, digits, _ and -)
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+
 	// The brand of the device
 	Brand string `json:"brand,omitempty"`
 
+	// The ids of the device in external systems (1 to 128 characters), by system name (letters, digits, _, . and -)
+	ExternalIDs map[string]string `json:"externalIds,omitempty"`
+
+	// Free-form labels of the device, up to 64 (keys of up to 63 letters, digits, _, / and -, values of up to 63 letters, digits, _, . and -)
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// location
+	Location *Location `json:"location,omitempty"`
+
 	// The name of the device
 	Name string `json:"name,omitempty"`
+
+	// The id of the device containing the device, an existing device
+	ParentID string `json:"parentId,omitempty"`
+
+	// The serial number of the device, unique in its brand (up to 64 characters)
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// The initial state of the device, inStock by default
+	// Enum: ["ordered","inStock","inUse","inRepair","retired"]
+	State string `json:"state,omitempty"`
 }
 
 // Validate validates this create device request
 func (m *CreateDeviceRequest) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateLocation(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateState(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
 	return nil
 }
 
-// ContextValidate validates this create device request based on context it is used
+func (m *CreateDeviceRequest) validateLocation(formats strfmt.Registry) error {
+	if swag.IsZero(m.Location) { // not required
+		return nil
+	}
+
+	if m.Location != nil {
+		if err := m.Location.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("location")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("location")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+var createDeviceRequestTypeStatePropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := swag.ReadJSON([]byte(`["ordered","inStock","inUse","inRepair","retired"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		createDeviceRequestTypeStatePropEnum = append(createDeviceRequestTypeStatePropEnum, v)
+	}
+}
+
+const (
+
+	// CreateDeviceRequestStateOrdered captures enum value "ordered"
+	CreateDeviceRequestStateOrdered string = "ordered"
+
+	// CreateDeviceRequestStateInStock captures enum value "inStock"
+	CreateDeviceRequestStateInStock string = "inStock"
+
+	// CreateDeviceRequestStateInUse captures enum value "inUse"
+	CreateDeviceRequestStateInUse string = "inUse"
+
+	// CreateDeviceRequestStateInRepair captures enum value "inRepair"
+	CreateDeviceRequestStateInRepair string = "inRepair"
+
+	// CreateDeviceRequestStateRetired captures enum value "retired"
+	CreateDeviceRequestStateRetired string = "retired"
+)
+
+// prop value enum
+func (m *CreateDeviceRequest) validateStateEnum(path, location string, value string) error {
+	if err := validate.EnumCase(path, location, value, createDeviceRequestTypeStatePropEnum, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *CreateDeviceRequest) validateState(formats strfmt.Registry) error {
+	if swag.IsZero(m.State) { // not required
+		return nil
+	}
+
+	// value enum
+	if err := m.validateStateEnum("state", "body", m.State); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ContextValidate validate this create device request based on the context it is used
 func (m *CreateDeviceRequest) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateLocation(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *CreateDeviceRequest) contextValidateLocation(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Location != nil {
+
+		if swag.IsZero(m.Location) { // not required
+			return nil
+		}
+
+		if err := m.Location.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("location")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("location")
+			}
+			return err
+		}
+	}
+
 	return nil
 }
 
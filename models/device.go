@@ -19,34 +19,143 @@ import (
 // swagger:model Device
 type Device struct {
 
+	// Free-form attributes of the device, like its specs, valid against the attributes schema of its brand (names of up to 64 letters, digits, _ and -)
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+
 	// The brand of the device
 	Brand string `json:"brand,omitempty"`
 
+	// Whether the device sent a heartbeat recently, derived from lastSeenAt and the connectivity thresholds
+	// Enum: ["online","stale","offline"]
+	Connectivity string `json:"connectivity,omitempty"`
+
 	// The time the device was created
 	// Format: date-time
 	CreatedAt strfmt.DateTime `json:"createdAt,omitempty"`
 
+	// The time the device was deleted, only set for the devices in the trash
+	// Format: date-time
+	DeletedAt strfmt.DateTime `json:"deletedAt,omitempty"`
+
+	// The ids of the device in external systems (1 to 128 characters), by system name (letters, digits, _, . and -)
+	ExternalIDs map[string]string `json:"externalIds,omitempty"`
+
 	// The id of the device
 	ID string `json:"id,omitempty"`
 
+	// Free-form labels of the device, up to 64 (keys of up to 63 letters, digits, _, / and -, values of up to 63 letters, digits, _, . and -)
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// The time of the last heartbeat of the device
+	// Format: date-time
+	LastSeenAt strfmt.DateTime `json:"lastSeenAt,omitempty"`
+
+	// lease
+	Lease *Lease `json:"lease,omitempty"`
+
+	// location
+	Location *Location `json:"location,omitempty"`
+
 	// The name of the device
 	Name string `json:"name,omitempty"`
+
+	// The id of the device containing the device, like the chassis of a module, none for the devices at the root of their tree
+	ParentID string `json:"parentId,omitempty"`
+
+	// The serial number of the device, unique in its brand (up to 64 characters)
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// The state of the device in its lifecycle, changed with transitions
+	// Enum: ["ordered","inStock","inUse","inRepair","retired"]
+	State string `json:"state,omitempty"`
+
+	// The version of the device, incremented on every change
+	Version int64 `json:"version,omitempty"`
 }
 
 // Validate validates this device
 func (m *Device) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateConnectivity(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateCreatedAt(formats); err != nil {
 		res = append(res, err)
 	}
 
+	if err := m.validateDeletedAt(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateLastSeenAt(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateLease(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateLocation(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateState(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+var deviceTypeConnectivityPropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := swag.ReadJSON([]byte(`["online","stale","offline"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		deviceTypeConnectivityPropEnum = append(deviceTypeConnectivityPropEnum, v)
+	}
+}
+
+const (
+
+	// DeviceConnectivityOnline captures enum value "online"
+	DeviceConnectivityOnline string = "online"
+
+	// DeviceConnectivityStale captures enum value "stale"
+	DeviceConnectivityStale string = "stale"
+
+	// DeviceConnectivityOffline captures enum value "offline"
+	DeviceConnectivityOffline string = "offline"
+)
+
+// prop value enum
+func (m *Device) validateConnectivityEnum(path, location string, value string) error {
+	if err := validate.EnumCase(path, location, value, deviceTypeConnectivityPropEnum, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Device) validateConnectivity(formats strfmt.Registry) error {
+	if swag.IsZero(m.Connectivity) { // not required
+		return nil
+	}
+
+	// value enum
+	if err := m.validateConnectivityEnum("connectivity", "body", m.Connectivity); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *Device) validateCreatedAt(formats strfmt.Registry) error {
 	if swag.IsZero(m.CreatedAt) { // not required
 		return nil
@@ -59,8 +168,176 @@ func (m *Device) validateCreatedAt(formats strfmt.Registry) error {
 	return nil
 }
 
-// ContextValidate validates this device based on context it is used
+func (m *Device) validateDeletedAt(formats strfmt.Registry) error {
+	if swag.IsZero(m.DeletedAt) { // not required
+		return nil
+	}
+
+	if err := validate.FormatOf("deletedAt", "body", "date-time", m.DeletedAt.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Device) validateLastSeenAt(formats strfmt.Registry) error {
+	if swag.IsZero(m.LastSeenAt) { // not required
+		return nil
+	}
+
+	if err := validate.FormatOf("lastSeenAt", "body", "date-time", m.LastSeenAt.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Device) validateLease(formats strfmt.Registry) error {
+	if swag.IsZero(m.Lease) { // not required
+		return nil
+	}
+
+	if m.Lease != nil {
+		if err := m.Lease.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("lease")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("lease")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Device) validateLocation(formats strfmt.Registry) error {
+	if swag.IsZero(m.Location) { // not required
+		return nil
+	}
+
+	if m.Location != nil {
+		if err := m.Location.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("location")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("location")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+var deviceTypeStatePropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := swag.ReadJSON([]byte(`["ordered","inStock","inUse","inRepair","retired"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		deviceTypeStatePropEnum = append(deviceTypeStatePropEnum, v)
+	}
+}
+
+const (
+
+	// DeviceStateOrdered captures enum value "ordered"
+	DeviceStateOrdered string = "ordered"
+
+	// DeviceStateInStock captures enum value "inStock"
+	DeviceStateInStock string = "inStock"
+
+	// DeviceStateInUse captures enum value "inUse"
+	DeviceStateInUse string = "inUse"
+
+	// DeviceStateInRepair captures enum value "inRepair"
+	DeviceStateInRepair string = "inRepair"
+
+	// DeviceStateRetired captures enum value "retired"
+	DeviceStateRetired string = "retired"
+)
+
+// prop value enum
+func (m *Device) validateStateEnum(path, location string, value string) error {
+	if err := validate.EnumCase(path, location, value, deviceTypeStatePropEnum, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Device) validateState(formats strfmt.Registry) error {
+	if swag.IsZero(m.State) { // not required
+		return nil
+	}
+
+	// value enum
+	if err := m.validateStateEnum("state", "body", m.State); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ContextValidate validate this device based on the context it is used
 func (m *Device) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateLease(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateLocation(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *Device) contextValidateLease(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Lease != nil {
+
+		if swag.IsZero(m.Lease) { // not required
+			return nil
+		}
+
+		if err := m.Lease.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("lease")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("lease")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Device) contextValidateLocation(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Location != nil {
+
+		if swag.IsZero(m.Location) { // not required
+			return nil
+		}
+
+		if err := m.Location.ContextValidate(ctx, formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("location")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("location")
+			}
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -17,11 +17,20 @@ import (
 // swagger:model UpdateDeviceRequest
 type UpdateDeviceRequest struct {
 
+	// Free-form attributes of the device, like its specs, valid against the attributes schema of its brand (names of up to 64 letters, digits, _ and -)
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+
 	// The brand of the device
 	Brand string `json:"brand,omitempty"`
 
+	// The ids of the device in external systems (1 to 128 characters), by system name (letters, digits, _, . and -)
+	ExternalIDs map[string]string `json:"externalIds,omitempty"`
+
 	// The name of the device
 	Name string `json:"name,omitempty"`
+
+	// The serial number of the device, unique in its brand (up to 64 characters)
+	SerialNumber string `json:"serialNumber,omitempty"`
 }
 
 // Validate validates this update device request
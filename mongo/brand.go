@@ -0,0 +1,347 @@
+package mongo
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// BrandCollectionName is the base name for the brand collection
+	BrandCollectionName = "brand"
+	// MigrationCollectionName is the name of the collection marking the one-time migrations done
+	MigrationCollectionName = "migration"
+	// brandSeedMigration marks that the default brands were seeded
+	brandSeedMigration = "brandSeed"
+	// brandCacheTTL is how long the in-process brand cache is trusted before reloading it. The cache is only
+	// invalidated by the changes of the instance it belongs to: a brand missing from the cache is read again at once,
+	// but a brand deleted, or its attributes schema changed, by another instance is seen by this one up to
+	// brandCacheTTL later.
+	brandCacheTTL = time.Minute
+	// brandCacheLoadTimeout bounds the reading of the brands reloading the cache
+	brandCacheLoadTimeout = 5 * time.Second
+	// brandCacheRetryAfter is how long the cache is kept as is after it could not be reloaded
+	brandCacheRetryAfter = 5 * time.Second
+)
+
+// BrandDB Brand database
+type BrandDB interface {
+	Create(ctx context.Context, brand *model.BrandInfo) error
+	ByName(ctx context.Context, name model.Brand) (*model.BrandInfo, error)
+	List(ctx context.Context) ([]model.BrandInfo, error)
+	Update(ctx context.Context, brand *model.BrandInfo) error
+	Delete(ctx context.Context, name model.Brand) (*model.BrandInfo, error)
+	Restore(ctx context.Context, brand *model.BrandInfo) error
+}
+
+// brandCache keeps the brand names and their attributes schemas in memory so brand and attributes validation
+// doesn't hit the database
+type brandCache struct {
+	mutex    sync.RWMutex
+	brands   map[model.Brand]*model.AttributesSchema
+	loadedAt time.Time
+}
+
+func (c *brandCache) expired() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return time.Since(c.loadedAt) > brandCacheTTL
+}
+
+func (c *brandCache) has(brand model.Brand) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	_, ok := c.brands[brand]
+	return ok
+}
+
+func (c *brandCache) schema(brand model.Brand) *model.AttributesSchema {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.brands[brand]
+}
+
+func (c *brandCache) set(brands []model.BrandInfo) {
+	m := make(map[model.Brand]*model.AttributesSchema, len(brands))
+	for i := range brands {
+		m[brands[i].Name] = brandSchema(&brands[i])
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.brands = m
+	c.loadedAt = time.Now()
+}
+
+// add adds a brand read after the cache was loaded, until the cache is reloaded
+func (c *brandCache) add(brand *model.BrandInfo) {
+	schema := brandSchema(brand)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.brands == nil {
+		c.brands = make(map[model.Brand]*model.AttributesSchema)
+	}
+	c.brands[brand.Name] = schema
+}
+
+// brandSchema parses the attributes schema of a brand, nil when it has none or when it is invalid
+func brandSchema(brand *model.BrandInfo) *model.AttributesSchema {
+	if brand.AttributesSchema == "" {
+		return nil
+	}
+	schema, err := model.ParseAttributesSchema([]byte(brand.AttributesSchema))
+	if err != nil {
+		log.Println("ignoring invalid attributes schema of brand " + string(brand.Name) + ": " + err.Error())
+		return nil
+	}
+	return schema
+}
+
+// retryLater keeps the brands as they are for brandCacheRetryAfter, the cache could not be reloaded
+func (c *brandCache) retryLater() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.loadedAt = time.Now().Add(brandCacheRetryAfter - brandCacheTTL)
+}
+
+func (c *brandCache) invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.loadedAt = time.Time{}
+}
+
+// BrandRepository repository
+type BrandRepository struct {
+	Collection *mongo.Collection
+	cache      *brandCache
+}
+
+// NewBrandDB creates new brand collection, seeding it with the default brands the first time.
+// The brand cache is loaded before the repository is returned, so that it starts with the registered brands.
+func NewBrandDB(ctx context.Context, db *mongo.Database) (*BrandRepository, error) {
+	Collection := db.Collection(BrandCollectionName, nil)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := Collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &BrandRepository{
+		Collection: Collection,
+		cache:      new(brandCache),
+	}
+
+	err = repo.seed(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	brands, err := repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repo.cache.set(brands)
+
+	return repo, nil
+}
+
+// seed inserts the default brands once, the first time the service starts. The seed is marked done in the migration
+// collection, so the brands deleted later are not seeded again on restart. A brand collection that already has brands
+// is only marked as seeded.
+func (br BrandRepository) seed(ctx context.Context) error {
+	migrations := br.Collection.Database().Collection(MigrationCollectionName)
+	err := migrations.FindOne(ctx, bson.M{"_id": brandSeedMigration}).Err()
+	if err == nil {
+		return nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	count, err := br.Collection.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := br.insertDefaultBrands(ctx); err != nil {
+			return err
+		}
+	}
+	_, err = migrations.InsertOne(ctx, bson.M{"_id": brandSeedMigration, "doneAt": time.Now().UTC().Truncate(time.Second)})
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	return nil
+}
+
+// insertDefaultBrands inserts the default brands, the ones already inserted by another instance being kept
+func (br BrandRepository) insertDefaultBrands(ctx context.Context) error {
+	for _, name := range model.DefaultBrands {
+		err := br.Create(ctx, &model.BrandInfo{Name: name})
+		if err != nil && errors.KindOf(err) != errors.KindConflict {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create saves new brand to db
+func (br BrandRepository) Create(ctx context.Context, brand *model.BrandInfo) error {
+	brand.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	res, err := br.Collection.InsertOne(ctx, brand)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.AlreadyExistsError(BrandCollectionName, string(brand.Name))
+		}
+		return errors.CreateError(BrandCollectionName, err.Error())
+	}
+	brand.ID = res.InsertedID.(primitive.ObjectID)
+	br.cache.invalidate()
+	return nil
+}
+
+// ByName gets brand by its name
+func (br BrandRepository) ByName(ctx context.Context, name model.Brand) (*model.BrandInfo, error) {
+	brand := new(model.BrandInfo)
+	err := br.Collection.FindOne(ctx, bson.M{"name": name}).Decode(brand)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.CouldNotFindObject(BrandCollectionName, string(name))
+		}
+		return nil, errors.ReadError(BrandCollectionName, err.Error())
+	}
+	return brand, nil
+}
+
+// List lists all brands in db ordered by name
+func (br BrandRepository) List(ctx context.Context) ([]model.BrandInfo, error) {
+	cur, err := br.Collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, errors.ListError(BrandCollectionName, err, "ALL")
+	}
+
+	brands := make([]model.BrandInfo, 0)
+	err = cur.All(ctx, &brands)
+	if err != nil {
+		return nil, errors.ListError(BrandCollectionName, err, "ALL")
+	}
+
+	return brands, nil
+}
+
+// Update updates the description and the attributes schema of an existing brand, an empty schema removing it.
+// Timestamp updated on success.
+func (br BrandRepository) Update(ctx context.Context, brand *model.BrandInfo) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	set := bson.M{
+		"updatedAt":   &now,
+		"description": brand.Description,
+	}
+	update := bson.M{"$set": set}
+	if brand.AttributesSchema != "" {
+		set["attributesSchema"] = brand.AttributesSchema
+	} else {
+		update["$unset"] = bson.M{"attributesSchema": ""}
+	}
+	result, err := br.Collection.UpdateOne(ctx, bson.M{"name": brand.Name}, update)
+	if err != nil {
+		return errors.UpdateError(BrandCollectionName, err.Error())
+	}
+	if result.MatchedCount == 0 {
+		return errors.CouldNotFindObjectError(BrandCollectionName, string(brand.Name), mongo.ErrNoDocuments)
+	}
+	brand.UpdatedAt = &now
+	br.cache.invalidate()
+	return nil
+}
+
+// Delete deletes brand from database, returning the brand deleted
+func (br BrandRepository) Delete(ctx context.Context, name model.Brand) (*model.BrandInfo, error) {
+	brand := new(model.BrandInfo)
+	err := br.Collection.FindOneAndDelete(ctx, bson.M{"name": name}).Decode(brand)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.CouldNotFindObjectError(BrandCollectionName, string(name), err)
+		}
+		return nil, errors.DeleteError(BrandCollectionName, err.Error())
+	}
+	br.cache.invalidate()
+	return brand, nil
+}
+
+// Restore saves again a brand deleted, as it was before its deletion
+func (br BrandRepository) Restore(ctx context.Context, brand *model.BrandInfo) error {
+	_, err := br.Collection.InsertOne(ctx, brand)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.AlreadyExistsError(BrandCollectionName, string(brand.Name))
+		}
+		return errors.CreateError(BrandCollectionName, err.Error())
+	}
+	br.cache.invalidate()
+	return nil
+}
+
+// Exists tells if a brand is registered, using the in-process cache, a brand missing from it being read again.
+// It makes BrandRepository a model.BrandRegistry.
+func (br BrandRepository) Exists(brand model.Brand) bool {
+	br.reloadCache()
+	return br.cache.has(brand) || br.loadBrand(brand)
+}
+
+// AttributesSchema returns the attributes schema of a brand, using the in-process cache.
+// It makes BrandRepository a model.AttributesSchemaRegistry.
+func (br BrandRepository) AttributesSchema(brand model.Brand) *model.AttributesSchema {
+	br.reloadCache()
+	if !br.cache.has(brand) {
+		br.loadBrand(brand)
+	}
+	return br.cache.schema(brand)
+}
+
+// loadBrand reads a brand missing from the cache, created by another instance since the cache was loaded,
+// and adds it to the cache. It tells if the brand exists.
+func (br BrandRepository) loadBrand(name model.Brand) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), brandCacheLoadTimeout)
+	defer cancel()
+	brand, err := br.ByName(ctx, name)
+	if err != nil {
+		if errors.KindOf(err) != errors.KindNotFound {
+			log.Println("could not read brand " + string(name) + ": " + err.Error())
+		}
+		return false
+	}
+	br.cache.add(brand)
+	return true
+}
+
+// reloadCache reloads the brand cache once it expired. When the brands cannot be read in time, the last brands read
+// are kept and the reload is retried after brandCacheRetryAfter.
+func (br BrandRepository) reloadCache() {
+	if !br.cache.expired() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), brandCacheLoadTimeout)
+	defer cancel()
+	brands, err := br.List(ctx)
+	if err != nil {
+		log.Println("could not reload brand cache: " + err.Error())
+		br.cache.retryLater()
+		return
+	}
+	br.cache.set(brands)
+}
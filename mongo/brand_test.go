@@ -0,0 +1,141 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_Brand_Seed_Create_ByName(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestBrandRepo(t)
+	defer drop()
+
+	t.Run("default brands seeded", func(t *testing.T) {
+		brands, err := repo.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, brands, len(model.DefaultBrands))
+		require.True(t, repo.Exists("brand1"))
+		require.False(t, repo.Exists("acme"))
+	})
+
+	t.Run("deleted brands not seeded again", func(t *testing.T) {
+		_, err := repo.Collection.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+		require.NoError(t, repo.seed(ctx))
+
+		brands, err := repo.List(ctx)
+		require.NoError(t, err)
+		require.Empty(t, brands)
+		require.NoError(t, repo.insertDefaultBrands(ctx))
+	})
+
+	t.Run("create and by name ok", func(t *testing.T) {
+		brand := model.BrandInfo{
+			Name:        "acme",
+			Description: "acme devices",
+		}
+		err := repo.Create(ctx, &brand)
+		require.NoError(t, err)
+		require.False(t, brand.ID.IsZero())
+		require.False(t, brand.CreatedAt.IsZero())
+
+		dbBrand, err := repo.ByName(ctx, "acme")
+		require.NoError(t, err)
+		require.Equal(t, "acme devices", dbBrand.Description)
+		require.True(t, repo.Exists("acme"))
+	})
+
+	t.Run("create duplicated", func(t *testing.T) {
+		err := repo.Create(ctx, &model.BrandInfo{Name: "brand1"})
+		require.EqualError(t, err, "result: false; code: 1500010; message: the brand brand1 already exists")
+	})
+
+	t.Run("by name - not found", func(t *testing.T) {
+		_, err := repo.ByName(ctx, "nobrand")
+		require.EqualError(t, err, "result: false; code: 1500005; message: the brand with id nobrand could not be found")
+	})
+}
+
+func Test_BrandUpdate_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestBrandRepo(t)
+	defer drop()
+
+	t.Run("success update", func(t *testing.T) {
+		err := repo.Update(ctx, &model.BrandInfo{Name: "brand2", Description: "second brand"})
+		require.NoError(t, err)
+
+		brand, err := repo.ByName(ctx, "brand2")
+		require.NoError(t, err)
+		require.Equal(t, "second brand", brand.Description)
+		require.NotNil(t, brand.UpdatedAt)
+	})
+	t.Run("update attributes schema", func(t *testing.T) {
+		require.Nil(t, repo.AttributesSchema("brand2"))
+
+		schema := `{"type":"object","properties":{"ram":{"type":"integer"}}}`
+		err := repo.Update(ctx, &model.BrandInfo{Name: "brand2", AttributesSchema: schema})
+		require.NoError(t, err)
+		brand, err := repo.ByName(ctx, "brand2")
+		require.NoError(t, err)
+		require.Equal(t, schema, brand.AttributesSchema)
+		require.NotNil(t, repo.AttributesSchema("brand2"))
+		require.Len(t, model.Attributes{"ram": 1.5}.Violations(repo.AttributesSchema("brand2")), 1)
+
+		err = repo.Update(ctx, &model.BrandInfo{Name: "brand2"})
+		require.NoError(t, err)
+		brand, err = repo.ByName(ctx, "brand2")
+		require.NoError(t, err)
+		require.Empty(t, brand.AttributesSchema)
+		require.Nil(t, repo.AttributesSchema("brand2"))
+	})
+	t.Run("update failed", func(t *testing.T) {
+		err := repo.Update(ctx, &model.BrandInfo{Name: "nobrand"})
+		require.EqualError(t, err, "result: false; code: 1500005; message: the brand with id nobrand could not be found: mongo: no documents in result")
+	})
+
+	t.Run("failed and success delete", func(t *testing.T) {
+		_, err := repo.Delete(ctx, "nobrand")
+		require.EqualError(t, err, "result: false; code: 1500005; message: the brand with id nobrand could not be found: mongo: no documents in result")
+
+		require.True(t, repo.Exists("brand3"))
+		deleted, err := repo.Delete(ctx, "brand3")
+		require.NoError(t, err)
+		require.Equal(t, model.Brand("brand3"), deleted.Name)
+		require.False(t, repo.Exists("brand3"))
+
+		require.NoError(t, repo.Restore(ctx, deleted))
+		require.True(t, repo.Exists("brand3"))
+		restored, err := repo.ByName(ctx, "brand3")
+		require.NoError(t, err)
+		require.Equal(t, deleted, restored)
+	})
+
+	t.Run("brand created by another instance read on a cache miss", func(t *testing.T) {
+		require.False(t, repo.Exists("globex"))
+		_, err := repo.Collection.InsertOne(ctx, &model.BrandInfo{Name: "globex", AttributesSchema: `{"type": "object"}`})
+		require.NoError(t, err)
+
+		require.True(t, repo.Exists("globex"))
+		require.NotNil(t, repo.AttributesSchema("globex"))
+	})
+}
+
+func NewTestBrandRepo(t *testing.T) (repo *BrandRepository, drop func()) {
+	return CreateBrandTestRepo(context.Background(), t)
+}
+
+func Test_brandCacheKeptWhenReloadFails(t *testing.T) {
+	_, _, repo, _, _, _ := CreateUnreachableTestRepos(context.Background(), t)
+	repo.cache.set([]model.BrandInfo{{Name: "brand1"}})
+	repo.cache.invalidate()
+
+	require.True(t, repo.Exists("brand1"))
+	require.False(t, repo.Exists("acme"))
+	// the reload is not retried before brandCacheRetryAfter
+	require.False(t, repo.cache.expired())
+}
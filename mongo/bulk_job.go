@@ -0,0 +1,174 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BulkJobCollectionName is the base name for the bulk job collection
+const BulkJobCollectionName = "device_jobs"
+
+// bulkJobName is the name of a bulk job in errors
+const bulkJobName = "job"
+
+// BulkJobDB Bulk job database
+type BulkJobDB interface {
+	Create(ctx context.Context, job *model.BulkJob) error
+	ByID(ctx context.Context, id primitive.ObjectID) (*model.BulkJob, error)
+	Claim(ctx context.Context, leaseUntil time.Time) (*model.BulkJob, error)
+	Progress(ctx context.Context, id primitive.ObjectID, progress model.BulkJobProgress) (*model.BulkJob, error)
+	Finish(ctx context.Context, id primitive.ObjectID, status model.JobStatus, reason string) error
+	Cancel(ctx context.Context, id primitive.ObjectID) (*model.BulkJob, error)
+}
+
+// BulkJobRepository repository
+type BulkJobRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewBulkJobDB creates new bulk job collection
+func NewBulkJobDB(ctx context.Context, db *mongo.Database) (*BulkJobRepository, error) {
+	Collection := db.Collection(BulkJobCollectionName, nil)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}},
+			Options: options.Index(),
+		},
+	}
+
+	_, err := Collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BulkJobRepository{
+		Collection: Collection,
+	}, nil
+}
+
+// Create saves a new pending job
+func (jr BulkJobRepository) Create(ctx context.Context, job *model.BulkJob) error {
+	job.Status = model.JobPending
+	job.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	res, err := jr.Collection.InsertOne(ctx, job)
+	if err != nil {
+		return errors.CreateError(BulkJobCollectionName, err.Error())
+	}
+	job.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// ByID gets a job by its id
+func (jr BulkJobRepository) ByID(ctx context.Context, id primitive.ObjectID) (*model.BulkJob, error) {
+	job := new(model.BulkJob)
+	err := jr.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.CouldNotFindObject(bulkJobName, id.Hex())
+		}
+		return nil, errors.ReadError(BulkJobCollectionName, err.Error())
+	}
+	return job, nil
+}
+
+// Claim takes the oldest pending job, or a running job whose worker lease expired, running it until leaseUntil.
+// It returns nil when there is no job to run.
+func (jr BulkJobRepository) Claim(ctx context.Context, leaseUntil time.Time) (*model.BulkJob, error) {
+	now := time.Now().UTC()
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": model.JobPending},
+		bson.M{"status": model.JobRunning, "leaseUntil": bson.M{"$lt": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": model.JobRunning, "leaseUntil": leaseUntil},
+		// keeps the start of a resumed job
+		"$min": bson.M{"startedAt": now},
+	}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "createdAt", Value: 1}}).SetReturnDocument(options.After)
+
+	job := new(model.BulkJob)
+	err := jr.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, errors.UpdateError(BulkJobCollectionName, err.Error())
+	}
+	return job, nil
+}
+
+// Progress saves what a running job did and extends its lease, returning the job to know if it was cancelled
+func (jr BulkJobRepository) Progress(ctx context.Context, id primitive.ObjectID, progress model.BulkJobProgress) (*model.BulkJob, error) {
+	update := bson.M{
+		"$set": bson.M{"cursor": progress.Cursor, "leaseUntil": progress.LeaseUntil},
+		"$inc": bson.M{"processed": progress.Processed, "failed": int64(len(progress.Errors))},
+	}
+	if len(progress.Errors) > 0 {
+		update["$push"] = bson.M{"errors": bson.M{"$each": progress.Errors, "$slice": -model.MaxBulkJobErrors}}
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	job := new(model.BulkJob)
+	err := jr.Collection.FindOneAndUpdate(ctx, bson.M{"_id": id, "status": model.JobRunning}, update, opts).Decode(job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.CouldNotFindObject("running "+bulkJobName, id.Hex())
+		}
+		return nil, errors.UpdateError(BulkJobCollectionName, err.Error())
+	}
+	return job, nil
+}
+
+// Finish ends a running job with a final status, reason being why it failed
+func (jr BulkJobRepository) Finish(ctx context.Context, id primitive.ObjectID, status model.JobStatus, reason string) error {
+	set := bson.M{"status": status, "finishedAt": time.Now().UTC()}
+	if reason != "" {
+		set["error"] = reason
+	}
+	update := bson.M{"$set": set, "$unset": bson.M{"leaseUntil": ""}}
+	res, err := jr.Collection.UpdateOne(ctx, bson.M{"_id": id, "status": model.JobRunning}, update)
+	if err != nil {
+		return errors.UpdateError(BulkJobCollectionName, err.Error())
+	}
+	if res.MatchedCount == 0 {
+		return errors.CouldNotFindObject("running "+bulkJobName, id.Hex())
+	}
+	return nil
+}
+
+// Cancel cancels a pending job right away, and asks the worker of a running job to stop it.
+// It fails when the job is already finished.
+func (jr BulkJobRepository) Cancel(ctx context.Context, id primitive.ObjectID) (*model.BulkJob, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	updates := []struct {
+		status model.JobStatus
+		set    bson.M
+	}{
+		{model.JobPending, bson.M{"status": model.JobCancelled, "finishedAt": time.Now().UTC()}},
+		{model.JobRunning, bson.M{"cancelRequested": true}},
+	}
+	for _, update := range updates {
+		job := new(model.BulkJob)
+		err := jr.Collection.FindOneAndUpdate(ctx, bson.M{"_id": id, "status": update.status}, bson.M{"$set": update.set}, opts).Decode(job)
+		if err == nil {
+			return job, nil
+		}
+		if err != mongo.ErrNoDocuments {
+			return nil, errors.UpdateError(BulkJobCollectionName, err.Error())
+		}
+	}
+
+	job, err := jr.ByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.InvalidStateError(bulkJobName, id.Hex(), string(job.Status))
+}
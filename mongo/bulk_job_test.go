@@ -0,0 +1,96 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_BulkJob(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := CreateBulkJobTestRepo(ctx, t)
+	defer drop()
+
+	job := &model.BulkJob{
+		Filter: model.DeviceFilter{Brands: []model.Brand{"brand1"}},
+		Action: model.BulkAction{Type: model.BulkActionSetBrand, Brand: "brand2"},
+		Actor:  "alice",
+		Total:  3,
+	}
+	require.NoError(t, repo.Create(ctx, job))
+	require.False(t, job.ID.IsZero())
+	require.Equal(t, model.JobPending, job.Status)
+
+	t.Run("by id", func(t *testing.T) {
+		saved, err := repo.ByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, job.Filter, saved.Filter)
+		require.Equal(t, job.Action, saved.Action)
+
+		id := primitive.NewObjectID()
+		_, err = repo.ByID(ctx, id)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the job with id "+id.Hex()+" could not be found")
+	})
+
+	t.Run("claim and progress", func(t *testing.T) {
+		claimed, err := repo.Claim(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, job.ID, claimed.ID)
+		require.Equal(t, model.JobRunning, claimed.Status)
+		require.NotNil(t, claimed.StartedAt)
+
+		none, err := repo.Claim(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Nil(t, none)
+
+		deviceID := primitive.NewObjectID()
+		progressed, err := repo.Progress(ctx, job.ID, model.BulkJobProgress{
+			Cursor:     "cursor1",
+			Processed:  2,
+			Errors:     []model.BulkJobError{{DeviceID: deviceID, Message: "failed"}},
+			LeaseUntil: time.Now().Add(time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), progressed.Processed)
+		require.Equal(t, int64(1), progressed.Failed)
+		require.Equal(t, "cursor1", progressed.Cursor)
+		require.Equal(t, []model.BulkJobError{{DeviceID: deviceID, Message: "failed"}}, progressed.Errors)
+	})
+
+	t.Run("claim a job whose lease expired", func(t *testing.T) {
+		_, err := repo.Progress(ctx, job.ID, model.BulkJobProgress{Cursor: "cursor1", LeaseUntil: time.Now().Add(-time.Second)})
+		require.NoError(t, err)
+
+		claimed, err := repo.Claim(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, job.ID, claimed.ID)
+		require.Equal(t, "cursor1", claimed.Cursor)
+	})
+
+	t.Run("cancel a running job", func(t *testing.T) {
+		cancelled, err := repo.Cancel(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.JobRunning, cancelled.Status)
+		require.True(t, cancelled.CancelRequested)
+
+		require.NoError(t, repo.Finish(ctx, job.ID, model.JobCancelled, ""))
+		_, err = repo.Cancel(ctx, job.ID)
+		require.EqualError(t, err, "result: false; code: 1500019; message: the job with id "+job.ID.Hex()+" is cancelled")
+
+		err = repo.Finish(ctx, job.ID, model.JobSucceeded, "")
+		require.EqualError(t, err, "result: false; code: 1500005; message: the running job with id "+job.ID.Hex()+" could not be found")
+	})
+
+	t.Run("cancel a pending job", func(t *testing.T) {
+		pending := &model.BulkJob{Action: model.BulkAction{Type: model.BulkActionDelete}}
+		require.NoError(t, repo.Create(ctx, pending))
+		cancelled, err := repo.Cancel(ctx, pending.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.JobCancelled, cancelled.Status)
+		require.NotNil(t, cancelled.FinishedAt)
+	})
+}
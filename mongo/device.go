@@ -2,6 +2,9 @@ package mongo
 
 import (
 	"context"
+	stderrors "errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/device-ms/errors"
@@ -15,26 +18,65 @@ import (
 // DeviceCollectionName is the base name for the collection
 const (
 	DeviceCollectionName = "device"
+	// patchAttempts is how many times a patch is applied when the device keeps being changed meanwhile
+	patchAttempts = 3
+	// streamBatchSize is how many devices a streaming cursor gets from the database at once
+	streamBatchSize = 500
+	// serialNumberIndex is the unique index of the serial numbers of a brand
+	serialNumberIndex = "brand_serialNumber"
+	// externalIDsIndex is the unique index of the external ids of the devices
+	externalIDsIndex = "externalIds"
+	// duplicateKeyCode is the code of the write errors of a unique index
+	duplicateKeyCode = 11000
+	// purgeBatchSize is how many devices a purge deletes at once, with their dependent documents
+	purgeBatchSize = 500
 )
 
 // DeviceDB Device database
 type DeviceDB interface {
 	Create(ctx context.Context, device *model.Device) error
+	CreateMany(ctx context.Context, items []*model.BulkItem, ordered bool)
+	Remove(ctx context.Context, ids []primitive.ObjectID) error
 	ByID(ctx context.Context, id primitive.ObjectID) (*model.Device, error)
-	List(ctx context.Context) ([]model.Device, error)
-	Update(ctx context.Context, device *model.Device) (*model.Device, error)
-	UpdateName(ctx context.Context, id primitive.ObjectID, name string) error
-	UpdateBrand(ctx context.Context, id primitive.ObjectID, brand model.Brand) error
-	Delete(ctx context.Context, id primitive.ObjectID) error
-	ListByBrand(ctx context.Context, brand model.Brand) ([]model.Device, error)
+	ByExternalID(ctx context.Context, system, value string) (*model.Device, error)
+	List(ctx context.Context, search model.DeviceSearch) (*model.DevicePage, error)
+	Stream(ctx context.Context, search model.DeviceSearch, fn func(dv *model.Device) error) error
+	Update(ctx context.Context, device *model.Device, match model.VersionMatch) (*model.DeviceChange, error)
+	UpdateName(ctx context.Context, id primitive.ObjectID, name string, match model.VersionMatch) (*model.DeviceChange, error)
+	UpdateBrand(ctx context.Context, id primitive.ObjectID, brand model.Brand, match model.VersionMatch) (*model.DeviceChange, error)
+	Patch(ctx context.Context, id primitive.ObjectID, match model.VersionMatch, apply func(device *model.Device) error) (*model.DeviceChange, error)
+	Delete(ctx context.Context, id primitive.ObjectID, match model.VersionMatch) (*model.DeviceChange, error)
+	Restore(ctx context.Context, id primitive.ObjectID, match model.VersionMatch) (*model.DeviceChange, error)
+	Checkout(ctx context.Context, id primitive.ObjectID, lease model.Lease, match model.VersionMatch) (*model.DeviceChange, error)
+	Checkin(ctx context.Context, id primitive.ObjectID, match model.VersionMatch) (*model.DeviceChange, error)
+	ExpireLeases(ctx context.Context, at time.Time) ([]*model.DeviceChange, error)
+	SetLabel(ctx context.Context, id primitive.ObjectID, key, value string, match model.VersionMatch) (*model.DeviceChange, error)
+	RemoveLabel(ctx context.Context, id primitive.ObjectID, key string, match model.VersionMatch) (*model.DeviceChange, error)
+	SetParent(ctx context.Context, id primitive.ObjectID, parentID *primitive.ObjectID, match model.VersionMatch) (*model.DeviceChange, error)
+	UpdateLocation(ctx context.Context, id primitive.ObjectID, location *model.Location, match model.VersionMatch) (*model.DeviceChange, error)
+	SetHeartbeatToken(ctx context.Context, id primitive.ObjectID, tokenHash string, match model.VersionMatch) (*model.DeviceChange, error)
+	Heartbeat(ctx context.Context, id primitive.ObjectID, tokenHash string, at time.Time) error
+	AddTelemetry(ctx context.Context, samples []model.TelemetrySample) error
+	TelemetrySeries(ctx context.Context, query model.TelemetryQuery) (*model.TelemetrySeries, error)
+	Ancestors(ctx context.Context, id primitive.ObjectID) ([]model.Device, error)
+	Descendants(ctx context.Context, id primitive.ObjectID, depth int) ([]model.Device, error)
+	CountChildren(ctx context.Context, id primitive.ObjectID) (int64, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	CountByBrand(ctx context.Context, brand model.Brand) (int64, error)
 }
 
 // DeviceRepository  repository
 type DeviceRepository struct {
 	Collection *mongo.Collection
+	// Telemetry is the time-series collection of the telemetry samples of the devices
+	Telemetry *mongo.Collection
+	// History, Idempotency and Twins are the collections of the revisions, idempotency keys and twins purged with the devices
+	History     *mongo.Collection
+	Idempotency *mongo.Collection
+	Twins       *mongo.Collection
 }
 
-// NewDeviceDB creates new  collection
+// NewDeviceDB creates new  collection, and the telemetry collection alongside
 func NewDeviceDB(ctx context.Context, db *mongo.Database) (*DeviceRepository, error) {
 	Collection := db.Collection(DeviceCollectionName, nil)
 
@@ -43,6 +85,83 @@ func NewDeviceDB(ctx context.Context, db *mongo.Database) (*DeviceRepository, er
 			Keys:    bson.D{{Key: "brand", Value: 1}},
 			Options: options.Index(),
 		},
+		{
+			Keys:    bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			Keys:    bson.D{{Key: "brand", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			Keys:    bson.D{{Key: "brand", Value: 1}, {Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			Keys:    bson.D{{Key: "updatedAt", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			Keys:    bson.D{{Key: "deletedAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// the devices being purged are marked until they are deleted
+			Keys:    bson.D{{Key: "purgedAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "state", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			// the label selectors query any label key
+			Keys:    bson.D{{Key: "labels.$**", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			// the attribute conditions query any attribute path
+			Keys:    bson.D{{Key: "attributes.$**", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			// the children of a device are looked up by their parent
+			Keys:    bson.D{{Key: "parentId", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// the devices without point are not indexed
+			Keys:    bson.D{{Key: "location.point", Value: "2dsphere"}},
+			Options: options.Index(),
+		},
+		{
+			// the devices are searched by connectivity with the time of their last heartbeat
+			Keys:    bson.D{{Key: "lastSeenAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "lease.assignee", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "lease.dueAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// devices without serial number are not indexed, soft deleted devices keep theirs until purged
+			Keys: bson.D{{Key: "brand", Value: 1}, {Key: "serialNumber", Value: 1}},
+			Options: options.Index().SetName(serialNumberIndex).SetUnique(true).
+				SetPartialFilterExpression(bson.M{"serialNumber": bson.M{"$gt": ""}}),
+		},
+		{
+			Keys: bson.D{{Key: "externalIds.system", Value: 1}, {Key: "externalIds.value", Value: 1}},
+			Options: options.Index().SetName(externalIDsIndex).SetUnique(true).
+				SetPartialFilterExpression(bson.M{"externalIds.system": bson.M{"$exists": true}}),
+		},
 	}
 
 	_, err := Collection.Indexes().CreateMany(ctx, indexes)
@@ -50,138 +169,1024 @@ func NewDeviceDB(ctx context.Context, db *mongo.Database) (*DeviceRepository, er
 		return nil, err
 	}
 
+	telemetry, err := newTelemetryCollection(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
 	return &DeviceRepository{
-		Collection: Collection,
+		Collection:  Collection,
+		Telemetry:   telemetry,
+		History:     db.Collection(DeviceHistoryCollectionName, nil),
+		Idempotency: db.Collection(IdempotencyCollectionName, nil),
+		Twins:       db.Collection(DeviceTwinCollectionName, nil),
 	}, nil
 }
 
 // Create saves new device to db
 func (dr DeviceRepository) Create(ctx context.Context, device *model.Device) error {
 	device.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	device.Version = 1
+	device.State = device.CurrentState()
 	res, err := dr.Collection.InsertOne(ctx, device)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return dr.duplicateError(ctx, device, err.Error())
+		}
 		return errors.CreateError(DeviceCollectionName, err.Error())
 	}
 	device.ID = res.InsertedID.(primitive.ObjectID)
 	return nil
 }
 
-// ByID gets device by its id
+// CreateMany saves the devices of items with a single insert, setting the status of every item.
+// An ordered creation stops at the first device that fails, the devices after it are skipped.
+func (dr DeviceRepository) CreateMany(ctx context.Context, items []*model.BulkItem, ordered bool) {
+	if len(items) == 0 {
+		return
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	documents := make([]interface{}, len(items))
+	for i, item := range items {
+		item.Device.ID = primitive.NewObjectID()
+		item.Device.CreatedAt = now
+		item.Device.Version = 1
+		item.Device.State = item.Device.CurrentState()
+		documents[i] = item.Device
+	}
+
+	failures := make(map[int]error)
+	_, err := dr.Collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(ordered))
+	var bulkErr mongo.BulkWriteException
+	if stderrors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil && len(bulkErr.WriteErrors) > 0 {
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Code == duplicateKeyCode {
+				failures[writeErr.Index] = dr.duplicateError(ctx, items[writeErr.Index].Device, writeErr.Message)
+				continue
+			}
+			failures[writeErr.Index] = errors.CreateError(DeviceCollectionName, writeErr.Message)
+		}
+	} else if err != nil {
+		for i := range items {
+			failures[i] = errors.CreateError(DeviceCollectionName, err.Error())
+		}
+	}
+
+	failed := false
+	for i, item := range items {
+		if failure, ok := failures[i]; ok {
+			item.Fail(failure)
+			failed = true
+		} else if ordered && failed {
+			item.Status = model.BulkSkipped
+		} else {
+			item.Status = model.BulkCreated
+		}
+	}
+}
+
+// Remove hard deletes devices right away, to undo their creation
+func (dr DeviceRepository) Remove(ctx context.Context, ids []primitive.ObjectID) error {
+	_, err := dr.Collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return errors.DeleteError(DeviceCollectionName, err.Error())
+	}
+	return nil
+}
+
+// ByID gets device by its id, unless it is soft deleted
 func (dr DeviceRepository) ByID(ctx context.Context, id primitive.ObjectID) (*model.Device, error) {
 	device := new(model.Device)
-	err := dr.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(device)
+	err := dr.Collection.FindOne(ctx, bson.M{"_id": id, "deletedAt": deletedCondition(false)}).Decode(device)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.CouldNotFindObject(DeviceCollectionName, id.Hex())
 		}
-		return nil, errors.CouldNotFindObjectError(DeviceCollectionName, id.Hex(), err)
+		return nil, errors.ReadError(DeviceCollectionName, err.Error())
 	}
 	return device, nil
 }
 
-// List lists all device in db
-func (dr DeviceRepository) List(ctx context.Context) ([]model.Device, error) {
-	cur, err := dr.Collection.Find(ctx, bson.D{})
+// ByExternalID gets the device having an external id of a system, unless it is soft deleted
+func (dr DeviceRepository) ByExternalID(ctx context.Context, system, value string) (*model.Device, error) {
+	device := new(model.Device)
+	err := dr.Collection.FindOne(ctx, bson.M{
+		"externalIds": externalIDCondition(model.ExternalID{System: system, Value: value}),
+		"deletedAt":   deletedCondition(false),
+	}).Decode(device)
 	if err != nil {
-		return nil, errors.ListError(DeviceCollectionName, err, "ALL")
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.CouldNotFindObject(DeviceCollectionName, system+"/"+value)
+		}
+		return nil, errors.ReadError(DeviceCollectionName, err.Error())
 	}
+	return device, nil
+}
 
-	devices := make([]model.Device, 0)
+// externalIDCondition selects the devices having an external id
+func externalIDCondition(id model.ExternalID) bson.M {
+	return bson.M{"$elemMatch": bson.M{"system": id.System, "value": id.Value}}
+}
+
+// duplicateError tells which identifier of a device another device already has, from the message
+// of a duplicate key error: its serial number in its brand, or one of its external ids
+func (dr DeviceRepository) duplicateError(ctx context.Context, device *model.Device, message string) error {
+	if strings.Contains(message, serialNumberIndex) {
+		return errors.AlreadyExistsError("device serial number", device.SerialNumber+" of brand "+string(device.Brand))
+	}
+	for _, id := range device.ExternalIDs {
+		count, err := dr.Collection.CountDocuments(ctx, bson.M{"_id": bson.M{"$ne": device.ID}, "externalIds": externalIDCondition(id)})
+		if err != nil {
+			return errors.ReadError(DeviceCollectionName, err.Error())
+		}
+		if count > 0 {
+			return errors.AlreadyExistsError("device external id", id.String())
+		}
+	}
+	return errors.AlreadyExistsError(DeviceCollectionName, device.ID.Hex())
+}
+
+// List lists a page of devices matching the search, either the soft deleted ones or the other ones.
+// One device more than the limit is read to know if there is a next page.
+func (dr DeviceRepository) List(ctx context.Context, search model.DeviceSearch) (*model.DevicePage, error) {
+	filter, err := dr.searchFilter(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	fieldsAndValues := filterFieldsAndValues(search.DeviceFilter)
+
+	page := new(model.DevicePage)
+	if search.WithTotal {
+		total, err := dr.Collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, errors.ListError(DeviceCollectionName, err, fieldsAndValues...)
+		}
+		page.Total = &total
+	}
+
+	sort, filter, opts := searchOptions(search, filter)
+	if search.Limit > 0 {
+		opts = opts.SetLimit(search.Limit + 1)
+	}
+
+	cur, err := dr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.ListError(DeviceCollectionName, err, fieldsAndValues...)
+	}
+
+	page.Devices = make([]model.Device, 0)
+	err = cur.All(ctx, &page.Devices)
+	if err != nil {
+		return nil, errors.ListError(DeviceCollectionName, err, fieldsAndValues...)
+	}
+
+	if search.Limit > 0 && int64(len(page.Devices)) > search.Limit {
+		page.Devices = page.Devices[:search.Limit]
+		page.NextCursor = model.NewDeviceCursor(sort, &page.Devices[search.Limit-1]).Encode()
+	}
+
+	return page, nil
+}
+
+// Stream calls fn with every device matching the search in its order, decoding the devices one at a time from
+// the cursor so that memory doesn't grow with their number. The limit and the total of the search are ignored.
+// Streaming stops at the first error of fn, which is returned.
+func (dr DeviceRepository) Stream(ctx context.Context, search model.DeviceSearch, fn func(dv *model.Device) error) error {
+	filter, err := dr.searchFilter(ctx, search)
+	if err != nil {
+		return err
+	}
+	fieldsAndValues := filterFieldsAndValues(search.DeviceFilter)
+
+	_, filter, opts := searchOptions(search, filter)
+	cur, err := dr.Collection.Find(ctx, filter, opts.SetBatchSize(streamBatchSize))
+	if err != nil {
+		return errors.ListError(DeviceCollectionName, err, fieldsAndValues...)
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		device := new(model.Device)
+		if err := cur.Decode(device); err != nil {
+			return errors.ReadError(DeviceCollectionName, err.Error())
+		}
+		if err := fn(device); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return errors.ListError(DeviceCollectionName, err, fieldsAndValues...)
+	}
+	return nil
+}
+
+// searchFilter builds the filter of the devices of a search, before its cursor
+func (dr DeviceRepository) searchFilter(ctx context.Context, search model.DeviceSearch) (bson.M, error) {
+	filter, err := deviceFilter(search.DeviceFilter)
+	if err != nil {
+		return nil, err
+	}
+	filter = andFilter(bson.M{"deletedAt": deletedCondition(search.Deleted)}, filter)
+	if search.Depth == nil {
+		return filter, nil
+	}
+	depth, err := dr.depthCondition(ctx, *search.Depth, search.Deleted)
+	if err != nil {
+		return nil, err
+	}
+	return andFilter(filter, depth), nil
+}
+
+// depthCondition selects the devices, soft deleted or not, having depth ancestors.
+// The devices below the roots are found by looking up their ancestors, one more than depth at most.
+func (dr DeviceRepository) depthCondition(ctx context.Context, depth int, deleted bool) (bson.M, error) {
+	if depth < 0 {
+		return nil, errors.InvalidParameterError("depth", "must be at least 0")
+	}
+	if depth == 0 {
+		return bson.M{"parentId": nil}, nil
+	}
+
+	cur, err := dr.Collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"parentId": bson.M{"$ne": nil}, "deletedAt": deletedCondition(deleted)}}},
+		{{Key: "$graphLookup", Value: bson.M{
+			"from":             DeviceCollectionName,
+			"startWith":        "$parentId",
+			"connectFromField": "parentId",
+			"connectToField":   "_id",
+			"as":               "ancestors",
+			"maxDepth":         depth,
+		}}},
+		{{Key: "$match", Value: bson.M{"$expr": bson.M{"$eq": bson.A{bson.M{"$size": "$ancestors"}, depth}}}}},
+		{{Key: "$project", Value: bson.M{"_id": 1}}},
+	})
+	if err != nil {
+		return nil, errors.ListError(DeviceCollectionName, err, "depth", strconv.Itoa(depth))
+	}
+	var devices []model.Device
 	err = cur.All(ctx, &devices)
 	if err != nil {
-		return nil, errors.ListError(DeviceCollectionName, err, "ALL")
+		return nil, errors.ListError(DeviceCollectionName, err, "depth", strconv.Itoa(depth))
+	}
+	ids := make(bson.A, len(devices))
+	for i := range devices {
+		ids[i] = devices[i].ID
 	}
+	return bson.M{"_id": bson.M{"$in": ids}}, nil
+}
 
-	return devices, nil
+// searchOptions returns the sort of a search, its filter restricted to the devices after its cursor and its find options
+func searchOptions(search model.DeviceSearch, filter bson.M) (model.DeviceSort, bson.M, *options.FindOptions) {
+	sort := search.Sort
+	if sort == "" {
+		sort = model.SortCreatedAtAsc
+	}
+	if search.Cursor != nil {
+		filter = andFilter(filter, cursorFilter(sort, search.Cursor))
+	}
+
+	order := 1
+	if sort.Descending() {
+		order = -1
+	}
+	return sort, filter, options.Find().SetSort(bson.D{{Key: sort.Field(), Value: order}, {Key: "_id", Value: order}})
 }
 
-// Update updates an existing Device in the database.
-// Timestamp updated on success.
-func (dr DeviceRepository) Update(ctx context.Context, device *model.Device) (*model.Device, error) {
-	now := time.Now().UTC().Truncate(time.Second)
+// filterFieldsAndValues describes a device filter for list errors
+func filterFieldsAndValues(filter model.DeviceFilter) []string {
+	var fieldsAndValues []string
+	for _, brand := range filter.Brands {
+		fieldsAndValues = append(fieldsAndValues, "brand", string(brand))
+	}
+	for _, state := range filter.States {
+		fieldsAndValues = append(fieldsAndValues, "state", string(state))
+	}
+	if filter.Name != nil {
+		fieldsAndValues = append(fieldsAndValues, "name["+string(filter.Name.Match)+"]", filter.Name.Value)
+	}
+	if !filter.CreatedAt.IsZero() {
+		fieldsAndValues = append(fieldsAndValues, "createdAt")
+	}
+	if !filter.UpdatedAt.IsZero() {
+		fieldsAndValues = append(fieldsAndValues, "updatedAt")
+	}
+	if filter.UpdatedSince != nil {
+		fieldsAndValues = append(fieldsAndValues, "updatedSince", filter.UpdatedSince.Format(time.RFC3339))
+	}
+	if filter.Assignee != "" {
+		fieldsAndValues = append(fieldsAndValues, "assignee", filter.Assignee)
+	}
+	if filter.Overdue {
+		fieldsAndValues = append(fieldsAndValues, "overdue")
+	}
+	for _, requirement := range filter.Labels {
+		fieldsAndValues = append(fieldsAndValues, "labels["+string(requirement.Operator)+"]", requirement.Key)
+	}
+	for _, condition := range filter.Attributes {
+		fieldsAndValues = append(fieldsAndValues, "attributes."+condition.Path+"["+string(condition.Operator)+"]", condition.Value)
+	}
+	if filter.Depth != nil {
+		fieldsAndValues = append(fieldsAndValues, "depth", strconv.Itoa(*filter.Depth))
+	}
+	if filter.Near != nil {
+		fieldsAndValues = append(fieldsAndValues, "near", filter.Near.String(), "radius", strconv.FormatFloat(filter.Near.Radius, 'f', -1, 64))
+	}
+	if len(filter.Within) > 0 {
+		fieldsAndValues = append(fieldsAndValues, "within")
+	}
+	for _, connectivity := range filter.Connectivity {
+		fieldsAndValues = append(fieldsAndValues, "connectivity", string(connectivity))
+	}
+	if len(fieldsAndValues) == 0 {
+		return []string{"ALL"}
+	}
+	return fieldsAndValues
+}
 
-	result, err := dr.Collection.UpdateByID(ctx, device.ID, bson.M{
-		"$set": bson.M{
-			"updatedAt": &now,
-			"name":      device.Name,
-			"brand":     device.Brand,
-		}})
+// cursorFilter selects the devices after the cursor position for a sort
+func cursorFilter(sort model.DeviceSort, cursor *model.DeviceCursor) bson.M {
+	op := "$gt"
+	if sort.Descending() {
+		op = "$lt"
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{sort.Field(): bson.M{op: cursor.Value}},
+		bson.M{sort.Field(): cursor.Value, "_id": bson.M{op: cursor.ID}},
+	}}
+}
+
+// Update updates an existing Device in the database when its version matches, its state being kept.
+// A device in use cannot change brand. Timestamp and version updated on success.
+func (dr DeviceRepository) Update(ctx context.Context, device *model.Device, match model.VersionMatch) (*model.DeviceChange, error) {
+	set := bson.M{
+		"name":         device.Name,
+		"brand":        device.Brand,
+		"serialNumber": device.SerialNumber,
+		"externalIds":  device.ExternalIDs,
+		"attributes":   device.Attributes,
+	}
+	return dr.change(ctx, device.ID, match, []changeGuard{brandChangeGuard(device.Brand)}, "updatedAt", set, errors.UpdateError,
+		func(after *model.Device, now *time.Time) {
+			after.Name = device.Name
+			after.Brand = device.Brand
+			after.SerialNumber = device.SerialNumber
+			after.ExternalIDs = device.ExternalIDs
+			after.Attributes = device.Attributes
+			after.UpdatedAt = now
+		})
+}
+
+// UpdateName updates a device name by its id when its version matches
+func (dr DeviceRepository) UpdateName(ctx context.Context, id primitive.ObjectID, name string, match model.VersionMatch) (*model.DeviceChange, error) {
+	return dr.change(ctx, id, match, nil, "updatedAt", bson.M{"name": name}, errors.UpdateError,
+		func(after *model.Device, now *time.Time) {
+			after.Name = name
+			after.UpdatedAt = now
+		})
+}
+
+// UpdateBrand updates a device brand by its id when its version matches and it is not in use
+func (dr DeviceRepository) UpdateBrand(ctx context.Context, id primitive.ObjectID, brand model.Brand, match model.VersionMatch) (*model.DeviceChange, error) {
+	if !brand.IsValid() {
+		return nil, errors.InvalidParameterError("brand", "invalid value")
+	}
+	return dr.change(ctx, id, match, []changeGuard{brandChangeGuard(brand)}, "updatedAt", bson.M{"brand": brand}, errors.UpdateError,
+		func(after *model.Device, now *time.Time) {
+			after.Brand = brand
+			after.UpdatedAt = now
+		})
+}
+
+// Patch changes the name, brand, identifiers, attributes and state of a device with apply, a device in use cannot change brand.
+// The change is saved only if the device wasn't changed since it was read, otherwise it is applied again
+// to the new version of the device, unless match requires the version read.
+func (dr DeviceRepository) Patch(ctx context.Context, id primitive.ObjectID, match model.VersionMatch, apply func(device *model.Device) error) (*model.DeviceChange, error) {
+	for attempt := 0; attempt < patchAttempts; attempt++ {
+		before, err := dr.ByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !match.Matches(before.Version) {
+			return nil, errors.VersionMismatchError(DeviceCollectionName, id.Hex())
+		}
+
+		device := *before
+		err = apply(&device)
+		if err != nil {
+			return nil, err
+		}
+		if before.CurrentState() == model.StateInUse && device.Brand != before.Brand {
+			return nil, errors.InvalidStateError(DeviceCollectionName, id.Hex(), string(model.StateInUse))
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		result, err := dr.Collection.UpdateOne(ctx, versionFilter(id, model.VersionMatch{before.Version}),
+			bson.M{
+				"$set": bson.M{
+					"updatedAt":    &now,
+					"name":         device.Name,
+					"brand":        device.Brand,
+					"serialNumber": device.SerialNumber,
+					"externalIds":  device.ExternalIDs,
+					"attributes":   device.Attributes,
+					"state":        device.CurrentState(),
+				},
+				"$inc": bson.M{"version": 1},
+			})
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, dr.duplicateError(ctx, &device, err.Error())
+			}
+			return nil, errors.UpdateError(DeviceCollectionName, err.Error())
+		}
+		if result.MatchedCount == 1 {
+			device.State = device.CurrentState()
+			device.UpdatedAt = &now
+			device.Version++
+			return &model.DeviceChange{Before: before, After: &device}, nil
+		}
+	}
+	return nil, errors.ConcurrentChangeError(DeviceCollectionName, id.Hex())
+}
+
+// Delete soft deletes a device when its version matches, it is not in use and it has no children, it can be restored
+// until it is purged. The children are counted again once the device is deleted, a child attached to the device
+// meanwhile undoing the delete.
+func (dr DeviceRepository) Delete(ctx context.Context, id primitive.ObjectID, match model.VersionMatch) (*model.DeviceChange, error) {
+	countChildren := func() (int64, error) {
+		count, err := dr.Collection.CountDocuments(ctx, bson.M{"parentId": id, "deletedAt": deletedCondition(false)})
+		if err != nil {
+			return 0, errors.DeleteError(DeviceCollectionName, err.Error())
+		}
+		return count, nil
+	}
+	children, err := countChildren()
 	if err != nil {
-		return nil, errors.UpdateError(DeviceCollectionName, err.Error())
+		return nil, err
 	}
-	if result.MatchedCount == 0 {
-		return nil, errors.CouldNotFindObjectError(DeviceCollectionName, device.ID.Hex(), mongo.ErrNoDocuments)
+	if children > 0 {
+		return nil, errors.DeviceHasChildrenError(id.Hex(), children)
 	}
 
-	return device, nil
+	change, err := dr.change(ctx, id, match, []changeGuard{notInUseGuard}, "deletedAt", bson.M{}, errors.DeleteError,
+		func(after *model.Device, now *time.Time) {
+			after.DeletedAt = now
+		})
+	if err != nil {
+		return nil, err
+	}
+	children, err = countChildren()
+	if err == nil && children == 0 {
+		return change, nil
+	}
+	if err == nil {
+		err = errors.DeviceHasChildrenError(id.Hex(), children)
+	}
+	if undoErr := dr.undoDelete(ctx, change); undoErr != nil {
+		return nil, undoErr
+	}
+	return nil, err
 }
 
-// UpdateName updates a device name by its id
-func (dr DeviceRepository) UpdateName(ctx context.Context, id primitive.ObjectID, name string) error {
-	now := time.Now().UTC().Truncate(time.Second)
-	result, err := dr.Collection.UpdateByID(ctx, id,
+// undoDelete puts a device soft deleted back as it was before the delete, unless it was restored meanwhile
+func (dr DeviceRepository) undoDelete(ctx context.Context, change *model.DeviceChange) error {
+	_, err := dr.Collection.UpdateOne(ctx, bson.M{"_id": change.After.ID, "version": change.After.Version, "deletedAt": change.After.DeletedAt},
 		bson.M{
-			"$set": bson.M{
-				"updatedAt": &now,
-				"name":      name,
-			}})
+			"$set":   bson.M{"version": change.Before.Version},
+			"$unset": bson.M{"deletedAt": ""},
+		})
 	if err != nil {
 		return errors.UpdateError(DeviceCollectionName, err.Error())
 	}
-	if result.MatchedCount == 0 {
-		return errors.CouldNotFindObjectError(DeviceCollectionName, id.Hex(), mongo.ErrNoDocuments)
-	}
 	return nil
 }
 
-// UpdateBrand updates a device brand by its id
-func (dr DeviceRepository) UpdateBrand(ctx context.Context, id primitive.ObjectID, brand model.Brand) error {
-	now := time.Now().UTC().Truncate(time.Second)
-	if !brand.IsValid() {
-		return errors.InvalidParameterError("brand", "invalid value")
+// Checkout checks a device out with a lease starting now when its version matches,
+// unless it is already checked out or retired
+func (dr DeviceRepository) Checkout(ctx context.Context, id primitive.ObjectID, lease model.Lease, match model.VersionMatch) (*model.DeviceChange, error) {
+	lease.CheckedOutAt = time.Now().UTC().Truncate(time.Second)
+	lease.ExpiredAt = nil
+	return dr.change(ctx, id, match, []changeGuard{notRetiredGuard, notCheckedOutGuard}, "updatedAt", bson.M{"lease": &lease}, errors.UpdateError,
+		func(after *model.Device, now *time.Time) {
+			after.Lease = &lease
+			after.UpdatedAt = now
+		})
+}
+
+// Checkin ends the lease of a checked out device when its version matches
+func (dr DeviceRepository) Checkin(ctx context.Context, id primitive.ObjectID, match model.VersionMatch) (*model.DeviceChange, error) {
+	return dr.change(ctx, id, match, []changeGuard{checkedOutGuard}, "updatedAt", bson.M{"lease": nil}, errors.UpdateError,
+		func(after *model.Device, now *time.Time) {
+			after.Lease = nil
+			after.UpdatedAt = now
+		})
+}
+
+// ExpireLeases marks the leases past their due date at a time as expired at that time, returning the changes
+// of their devices, the changes made so far on error. A device changed meanwhile is skipped, its lease being
+// marked on the next call if it is still overdue.
+func (dr DeviceRepository) ExpireLeases(ctx context.Context, at time.Time) ([]*model.DeviceChange, error) {
+	cur, err := dr.Collection.Find(ctx, bson.M{
+		"deletedAt":       deletedCondition(false),
+		"lease.dueAt":     bson.M{"$lt": at},
+		"lease.expiredAt": nil,
+	}, options.Find().SetSort(bson.D{{Key: "lease.dueAt", Value: 1}}))
+	if err != nil {
+		return nil, errors.ListError(DeviceCollectionName, err, "overdue")
+	}
+	var overdue []model.Device
+	err = cur.All(ctx, &overdue)
+	if err != nil {
+		return nil, errors.ListError(DeviceCollectionName, err, "overdue")
 	}
-	result, err := dr.Collection.UpdateByID(ctx, id,
+
+	changes := make([]*model.DeviceChange, 0, len(overdue))
+	for i := range overdue {
+		before := &overdue[i]
+		result, err := dr.Collection.UpdateOne(ctx, versionFilter(before.ID, model.VersionMatch{before.Version}), bson.M{
+			"$set": bson.M{"updatedAt": &at, "lease.expiredAt": &at},
+			"$inc": bson.M{"version": 1},
+		})
+		if err != nil {
+			return changes, errors.UpdateError(DeviceCollectionName, err.Error())
+		}
+		if result.MatchedCount == 0 {
+			continue
+		}
+
+		after := *before
+		lease := *before.Lease
+		lease.ExpiredAt = &at
+		after.Lease = &lease
+		after.UpdatedAt = &at
+		after.Version++
+		changes = append(changes, &model.DeviceChange{Before: before, After: &after})
+	}
+	return changes, nil
+}
+
+// SetLabel sets the value of a label of a device when its version matches,
+// a device having at most model.MaxLabels labels
+func (dr DeviceRepository) SetLabel(ctx context.Context, id primitive.ObjectID, key, value string, match model.VersionMatch) (*model.DeviceChange, error) {
+	if !model.IsValidLabelKey(key) {
+		return nil, errors.InvalidParameterError("key", "invalid value")
+	}
+	return dr.change(ctx, id, match, []changeGuard{labelsLimitGuard(key)}, "updatedAt", bson.M{"labels." + key: value}, errors.UpdateError,
+		func(after *model.Device, now *time.Time) {
+			labels := make(map[string]string, len(after.Labels)+1)
+			for k, v := range after.Labels {
+				labels[k] = v
+			}
+			labels[key] = value
+			after.Labels = labels
+			after.UpdatedAt = now
+		})
+}
+
+// RemoveLabel removes a label of a device when its version matches
+func (dr DeviceRepository) RemoveLabel(ctx context.Context, id primitive.ObjectID, key string, match model.VersionMatch) (*model.DeviceChange, error) {
+	if !model.IsValidLabelKey(key) {
+		return nil, errors.InvalidParameterError("key", "invalid value")
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	guard := labelGuard(key)
+	before := new(model.Device)
+	err := dr.Collection.FindOneAndUpdate(ctx, andFilter(versionFilter(id, match), guard.filter),
 		bson.M{
-			"$set": bson.M{
-				"updatedAt": &now,
-				"brand":     brand,
-			}})
+			"$set":   bson.M{"updatedAt": &now},
+			"$unset": bson.M{"labels." + key: ""},
+			"$inc":   bson.M{"version": 1},
+		}).Decode(before)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, dr.notMatchedError(ctx, id, false, match, []changeGuard{guard})
+		}
+		return nil, errors.UpdateError(DeviceCollectionName, err.Error())
+	}
+
+	after := *before
+	after.Labels = make(map[string]string, len(before.Labels))
+	for k, v := range before.Labels {
+		if k != key {
+			after.Labels[k] = v
+		}
+	}
+	if len(after.Labels) == 0 {
+		after.Labels = nil
+	}
+	after.UpdatedAt = &now
+	after.Version++
+	return &model.DeviceChange{Before: before, After: &after}, nil
+}
+
+// SetParent attaches a device to a parent when its version matches, a nil parent detaching it from its parent.
+// The parent is not checked, the caller making sure that it exists and that it is not a descendant of the device.
+func (dr DeviceRepository) SetParent(ctx context.Context, id primitive.ObjectID, parentID *primitive.ObjectID, match model.VersionMatch) (*model.DeviceChange, error) {
+	if parentID != nil {
+		return dr.change(ctx, id, match, nil, "updatedAt", bson.M{"parentId": parentID}, errors.UpdateError,
+			func(after *model.Device, now *time.Time) {
+				after.ParentID = parentID
+				after.UpdatedAt = now
+			})
+	}
+	return dr.unset(ctx, id, match, "parentId", func(after *model.Device) {
+		after.ParentID = nil
+	})
+}
+
+// UpdateLocation replaces the location of a device when its version matches, a nil location removing it
+func (dr DeviceRepository) UpdateLocation(ctx context.Context, id primitive.ObjectID, location *model.Location, match model.VersionMatch) (*model.DeviceChange, error) {
+	if location != nil {
+		return dr.change(ctx, id, match, nil, "updatedAt", bson.M{"location": location}, errors.UpdateError,
+			func(after *model.Device, now *time.Time) {
+				after.Location = location
+				after.UpdatedAt = now
+			})
+	}
+	return dr.unset(ctx, id, match, "location", func(after *model.Device) {
+		after.Location = nil
+	})
+}
+
+// SetHeartbeatToken replaces the hash of the token a device sends its heartbeats with when its version matches
+func (dr DeviceRepository) SetHeartbeatToken(ctx context.Context, id primitive.ObjectID, tokenHash string, match model.VersionMatch) (*model.DeviceChange, error) {
+	return dr.change(ctx, id, match, nil, "updatedAt", bson.M{"heartbeatTokenHash": tokenHash}, errors.UpdateError,
+		func(after *model.Device, now *time.Time) {
+			after.HeartbeatTokenHash = tokenHash
+			after.UpdatedAt = now
+		})
+}
+
+// Heartbeat records that a device that is not soft deleted was seen at a time, when its heartbeat token has the hash.
+// Only lastSeenAt is written, never moving back in time, without changing the version of the device.
+// A device that cannot be found is reported as the token not being valid, so that heartbeats don't tell
+// which devices exist.
+func (dr DeviceRepository) Heartbeat(ctx context.Context, id primitive.ObjectID, tokenHash string, at time.Time) error {
+	result, err := dr.Collection.UpdateOne(ctx,
+		bson.M{"_id": id, "deletedAt": deletedCondition(false), "heartbeatTokenHash": tokenHash},
+		bson.M{"$max": bson.M{"lastSeenAt": at}})
 	if err != nil {
 		return errors.UpdateError(DeviceCollectionName, err.Error())
 	}
 	if result.MatchedCount == 0 {
-		return errors.CouldNotFindObjectError(DeviceCollectionName, id.Hex(), mongo.ErrNoDocuments)
+		return errors.UnauthorizedError(DeviceCollectionName, id.Hex())
 	}
 	return nil
 }
 
-// Delete deletes device from database
-func (dr DeviceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	result, err := dr.Collection.DeleteOne(ctx, bson.M{"_id": id})
+// unset removes a field of a device that is not soft deleted when its version matches,
+// clear removing it from the device after the change
+func (dr DeviceRepository) unset(ctx context.Context, id primitive.ObjectID, match model.VersionMatch, field string, clear func(after *model.Device)) (*model.DeviceChange, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	before := new(model.Device)
+	err := dr.Collection.FindOneAndUpdate(ctx, versionFilter(id, match),
+		bson.M{
+			"$set":   bson.M{"updatedAt": &now},
+			"$unset": bson.M{field: ""},
+			"$inc":   bson.M{"version": 1},
+		}).Decode(before)
 	if err != nil {
-		return errors.DeleteError(DeviceCollectionName, err.Error())
-	}
-	if result.DeletedCount == 0 {
-		return errors.CouldNotFindObjectError(DeviceCollectionName, id.Hex(), mongo.ErrNoDocuments)
+		if err == mongo.ErrNoDocuments {
+			return nil, dr.notMatchedError(ctx, id, false, match, nil)
+		}
+		return nil, errors.UpdateError(DeviceCollectionName, err.Error())
 	}
-	return nil
+
+	after := *before
+	clear(&after)
+	after.UpdatedAt = &now
+	after.Version++
+	return &model.DeviceChange{Before: before, After: &after}, nil
 }
 
-// ListByBrand gets device by brand
-func (dr DeviceRepository) ListByBrand(ctx context.Context, brand model.Brand) ([]model.Device, error) {
-	if !brand.IsValid() {
-		return nil, errors.InvalidParameterError("brand", "invalid value")
+// Ancestors gets the ancestors of a device that is not soft deleted, from its parent up to the root of its tree
+func (dr DeviceRepository) Ancestors(ctx context.Context, id primitive.ObjectID) ([]model.Device, error) {
+	return dr.lookupTree(ctx, id, bson.M{
+		"from":                    DeviceCollectionName,
+		"startWith":               "$parentId",
+		"connectFromField":        "parentId",
+		"connectToField":          "_id",
+		"as":                      "devices",
+		"depthField":              "level",
+		"restrictSearchWithMatch": bson.M{"deletedAt": deletedCondition(false)},
+	})
+}
+
+// Descendants gets the descendants of a device that is not soft deleted down to depth levels below it,
+// all of them when depth is 0. They are sorted by level, its children first, then by creation.
+func (dr DeviceRepository) Descendants(ctx context.Context, id primitive.ObjectID, depth int) ([]model.Device, error) {
+	graphLookup := bson.M{
+		"from":                    DeviceCollectionName,
+		"startWith":               "$_id",
+		"connectFromField":        "_id",
+		"connectToField":          "parentId",
+		"as":                      "devices",
+		"depthField":              "level",
+		"restrictSearchWithMatch": bson.M{"deletedAt": deletedCondition(false)},
+	}
+	if depth > 0 {
+		graphLookup["maxDepth"] = depth - 1
 	}
-	cur, err := dr.Collection.Find(ctx, bson.M{"brand": brand})
+	return dr.lookupTree(ctx, id, graphLookup)
+}
+
+// CountChildren counts the children of a device, soft deleted children not being counted
+func (dr DeviceRepository) CountChildren(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	count, err := dr.Collection.CountDocuments(ctx, bson.M{"parentId": id, "deletedAt": deletedCondition(false)})
 	if err != nil {
-		return nil, errors.ListError(DeviceCollectionName, err, "brand", string(brand))
+		return 0, errors.ListError(DeviceCollectionName, err, "parentId", id.Hex())
 	}
+	return count, nil
+}
 
+// lookupTree gets the devices related to a device that is not soft deleted with a $graphLookup stage
+// that finds them as devices with their level as depthField, sorted by level then by creation
+func (dr DeviceRepository) lookupTree(ctx context.Context, id primitive.ObjectID, graphLookup bson.M) ([]model.Device, error) {
+	cur, err := dr.Collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": id, "deletedAt": deletedCondition(false)}}},
+		{{Key: "$graphLookup", Value: graphLookup}},
+		{{Key: "$unwind", Value: "$devices"}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$devices"}}},
+		{{Key: "$sort", Value: bson.D{{Key: "level", Value: 1}, {Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, errors.ListError(DeviceCollectionName, err, "device", id.Hex())
+	}
 	devices := make([]model.Device, 0)
 	err = cur.All(ctx, &devices)
 	if err != nil {
-		return nil, errors.ListError(DeviceCollectionName, err, "brand", string(brand))
+		return nil, errors.ListError(DeviceCollectionName, err, "device", id.Hex())
+	}
+	if len(devices) == 0 {
+		// tells a device without relatives from a device that doesn't exist
+		if _, err := dr.ByID(ctx, id); err != nil {
+			return nil, err
+		}
 	}
-
 	return devices, nil
 }
+
+// changeGuard is a condition a device must satisfy to be changed, with the error telling why it doesn't
+type changeGuard struct {
+	filter bson.M
+	err    func(id primitive.ObjectID) error
+}
+
+var (
+	// notInUseGuard selects the devices not in use
+	notInUseGuard = changeGuard{
+		filter: bson.M{"state": bson.M{"$ne": model.StateInUse}},
+		err:    stateError(string(model.StateInUse)),
+	}
+	// notRetiredGuard selects the devices not retired
+	notRetiredGuard = changeGuard{
+		filter: bson.M{"state": bson.M{"$ne": model.StateRetired}},
+		err:    stateError(string(model.StateRetired)),
+	}
+	// notCheckedOutGuard selects the devices without lease
+	notCheckedOutGuard = changeGuard{
+		filter: bson.M{"lease": nil},
+		err:    stateError("checked out"),
+	}
+	// checkedOutGuard selects the devices with a lease
+	checkedOutGuard = changeGuard{
+		filter: bson.M{"lease": bson.M{"$ne": nil}},
+		err:    stateError("not checked out"),
+	}
+)
+
+// labelGuard selects the devices having a label
+func labelGuard(key string) changeGuard {
+	return changeGuard{
+		filter: bson.M{"labels." + key: bson.M{"$exists": true}},
+		err: func(id primitive.ObjectID) error {
+			return errors.CouldNotFindObject("label "+key+" of the "+DeviceCollectionName, id.Hex())
+		},
+	}
+}
+
+// labelsLimitGuard selects the devices that can have a label: the devices already having it
+// or having less than model.MaxLabels labels
+func labelsLimitGuard(key string) changeGuard {
+	labelCount := bson.M{"$size": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$labels", bson.M{}}}}}
+	return changeGuard{
+		filter: bson.M{"$or": bson.A{
+			labelGuard(key).filter,
+			bson.M{"$expr": bson.M{"$lt": bson.A{labelCount, model.MaxLabels}}},
+		}},
+		err: func(id primitive.ObjectID) error {
+			return errors.InvalidParameterError("key", "the device already has "+strconv.Itoa(model.MaxLabels)+" labels")
+		},
+	}
+}
+
+// stateError returns the error of the devices that cannot be changed in a state
+func stateError(state string) func(id primitive.ObjectID) error {
+	return func(id primitive.ObjectID) error {
+		return errors.InvalidStateError(DeviceCollectionName, id.Hex(), state)
+	}
+}
+
+// change sets fields of a device that is not soft deleted when its version matches and it satisfies the guards,
+// incrementing its version and setting timeField to the time of the change.
+// The device is read as it was before the change, apply changes a copy of it the same way to get it after the change.
+func (dr DeviceRepository) change(ctx context.Context, id primitive.ObjectID, match model.VersionMatch, guards []changeGuard, timeField string, set bson.M,
+	changeError func(objectType, reason string) error, apply func(after *model.Device, now *time.Time)) (*model.DeviceChange, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	set[timeField] = &now
+
+	conditions := []interface{}{versionFilter(id, match)}
+	for _, guard := range guards {
+		conditions = append(conditions, guard.filter)
+	}
+	before := new(model.Device)
+	err := dr.Collection.FindOneAndUpdate(ctx, andFilter(conditions...), bson.M{
+		"$set": set,
+		"$inc": bson.M{"version": 1},
+	}).Decode(before)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, dr.notMatchedError(ctx, id, false, match, guards)
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, dr.changeDuplicateError(ctx, id, err.Error(), apply)
+		}
+		return nil, changeError(DeviceCollectionName, err.Error())
+	}
+
+	after := *before
+	apply(&after, &now)
+	after.Version++
+	return &model.DeviceChange{Before: before, After: &after}, nil
+}
+
+// brandChangeGuard selects the devices that can have a brand: the devices not in use or already of that brand
+func brandChangeGuard(brand model.Brand) changeGuard {
+	return changeGuard{
+		filter: bson.M{"$or": bson.A{notInUseGuard.filter, bson.M{"brand": brand}}},
+		err:    notInUseGuard.err,
+	}
+}
+
+// changeDuplicateError tells which identifier of the device changed another device already has
+func (dr DeviceRepository) changeDuplicateError(ctx context.Context, id primitive.ObjectID, message string, apply func(after *model.Device, now *time.Time)) error {
+	device, err := dr.ByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	apply(device, device.UpdatedAt)
+	return dr.duplicateError(ctx, device, message)
+}
+
+// Restore undoes the soft delete of a device when its version matches
+func (dr DeviceRepository) Restore(ctx context.Context, id primitive.ObjectID, match model.VersionMatch) (*model.DeviceChange, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	filter := versionFilter(id, match)
+	filter["deletedAt"] = deletedCondition(true)
+	filter["purgedAt"] = bson.M{"$exists": false}
+
+	before := new(model.Device)
+	err := dr.Collection.FindOneAndUpdate(ctx, filter,
+		bson.M{
+			"$set":   bson.M{"updatedAt": &now},
+			"$unset": bson.M{"deletedAt": ""},
+			"$inc":   bson.M{"version": 1},
+		}).Decode(before)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, dr.notMatchedError(ctx, id, true, match, nil)
+		}
+		return nil, errors.UpdateError(DeviceCollectionName, err.Error())
+	}
+
+	after := *before
+	after.UpdatedAt = &now
+	after.DeletedAt = nil
+	after.Version++
+	return &model.DeviceChange{Before: before, After: &after}, nil
+}
+
+// Purge hard deletes the devices soft deleted before a time, with their revisions, the idempotency keys of
+// their creation and their twins, returning how many were deleted.
+// The devices are first marked as purged, so that they cannot be restored anymore while their dependent documents
+// are deleted, and so that a purge stopped midway is completed by the next one.
+func (dr DeviceRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err := dr.Collection.UpdateMany(ctx,
+		bson.M{"deletedAt": bson.M{"$lt": deletedBefore}, "purgedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"purgedAt": now}})
+	if err != nil {
+		return 0, errors.UpdateError(DeviceCollectionName, err.Error())
+	}
+
+	var count int64
+	for {
+		ids, err := dr.purgedIDs(ctx)
+		if err != nil || len(ids) == 0 {
+			return count, err
+		}
+		dependents := bson.M{"deviceId": bson.M{"$in": ids}}
+		if _, err := dr.History.DeleteMany(ctx, dependents); err != nil {
+			return count, errors.DeleteError(DeviceHistoryCollectionName, err.Error())
+		}
+		if _, err := dr.Idempotency.DeleteMany(ctx, dependents); err != nil {
+			return count, errors.DeleteError(IdempotencyCollectionName, err.Error())
+		}
+		// the twin of a device has the id of the device
+		if _, err := dr.Twins.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return count, errors.DeleteError(DeviceTwinCollectionName, err.Error())
+		}
+		result, err := dr.Collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return count, errors.DeleteError(DeviceCollectionName, err.Error())
+		}
+		count += result.DeletedCount
+	}
+}
+
+// purgedIDs returns the ids of a batch of the devices marked as purged
+func (dr DeviceRepository) purgedIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	cursor, err := dr.Collection.Find(ctx, bson.M{"purgedAt": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(purgeBatchSize))
+	if err != nil {
+		return nil, errors.ReadError(DeviceCollectionName, err.Error())
+	}
+	var devices []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, errors.ReadError(DeviceCollectionName, err.Error())
+	}
+	ids := make([]primitive.ObjectID, 0, len(devices))
+	for _, device := range devices {
+		ids = append(ids, device.ID)
+	}
+	return ids, nil
+}
+
+// versionFilter selects a device that is not soft deleted by its id when its version matches
+func versionFilter(id primitive.ObjectID, match model.VersionMatch) bson.M {
+	filter := bson.M{"_id": id, "deletedAt": deletedCondition(false)}
+	if match.Any() {
+		return filter
+	}
+	versions := bson.A{}
+	for _, version := range match {
+		versions = append(versions, version)
+		if version == 0 {
+			// devices created before versioning have no version field
+			versions = append(versions, nil)
+		}
+	}
+	filter["version"] = bson.M{"$in": versions}
+	return filter
+}
+
+// deletedCondition selects the soft deleted devices or the other ones
+func deletedCondition(deleted bool) bson.M {
+	return bson.M{"$exists": deleted}
+}
+
+// notMatchedError tells why a device, soft deleted or not, was not changed: it doesn't exist, it doesn't have
+// a matching version, it doesn't satisfy one of the guards, or else it was changed in between
+func (dr DeviceRepository) notMatchedError(ctx context.Context, id primitive.ObjectID, deleted bool, match model.VersionMatch, guards []changeGuard) error {
+	device := new(model.Device)
+	filter := bson.M{"_id": id, "deletedAt": deletedCondition(deleted)}
+	if deleted {
+		// the devices being purged are not found anymore
+		filter["purgedAt"] = bson.M{"$exists": false}
+	}
+	err := dr.Collection.FindOne(ctx, filter).Decode(device)
+	if err == mongo.ErrNoDocuments {
+		if deleted {
+			return errors.CouldNotFindObject("deleted "+DeviceCollectionName, id.Hex())
+		}
+		return errors.CouldNotFindObjectError(DeviceCollectionName, id.Hex(), mongo.ErrNoDocuments)
+	}
+	if err != nil {
+		return errors.ReadError(DeviceCollectionName, err.Error())
+	}
+	if !match.Matches(device.Version) {
+		return errors.VersionMismatchError(DeviceCollectionName, id.Hex())
+	}
+	for _, guard := range guards {
+		count, err := dr.Collection.CountDocuments(ctx, andFilter(bson.M{"_id": id}, guard.filter))
+		if err != nil {
+			return errors.ReadError(DeviceCollectionName, err.Error())
+		}
+		if count == 0 {
+			return guard.err(id)
+		}
+	}
+	return errors.ConcurrentChangeError(DeviceCollectionName, id.Hex())
+}
+
+// CountByBrand counts the devices of a brand.
+// Soft deleted devices are counted as well: they can still be restored with their brand.
+func (dr DeviceRepository) CountByBrand(ctx context.Context, brand model.Brand) (int64, error) {
+	count, err := dr.Collection.CountDocuments(ctx, bson.M{"brand": brand})
+	if err != nil {
+		return 0, errors.ListError(DeviceCollectionName, err, "brand", string(brand))
+	}
+	return count, nil
+}
@@ -0,0 +1,230 @@
+package mongo
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// deviceFilter translates a device filter into a mongo filter.
+// User values are only used as operands, never as operators, and are quoted when used in regular expressions.
+func deviceFilter(filter model.DeviceFilter) (bson.M, error) {
+	conditions := bson.A{}
+
+	if len(filter.Brands) > 0 {
+		for _, brand := range filter.Brands {
+			if !brand.IsValid() {
+				return nil, errors.InvalidParameterError("brand", "invalid value")
+			}
+		}
+		if len(filter.Brands) == 1 {
+			conditions = append(conditions, bson.M{"brand": filter.Brands[0]})
+		} else {
+			conditions = append(conditions, bson.M{"brand": bson.M{"$in": filter.Brands}})
+		}
+	}
+
+	if len(filter.States) > 0 {
+		states := bson.A{}
+		for _, state := range filter.States {
+			if !state.IsValid() {
+				return nil, errors.InvalidParameterError("state", "invalid value")
+			}
+			states = append(states, state)
+			if state == model.DefaultDeviceState {
+				// devices created before states have no state field
+				states = append(states, nil)
+			}
+		}
+		conditions = append(conditions, bson.M{"state": bson.M{"$in": states}})
+	}
+
+	if filter.Name != nil {
+		condition, err := nameCondition(filter.Name)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, bson.M{"name": condition})
+	}
+
+	if !filter.CreatedAt.IsZero() {
+		conditions = append(conditions, bson.M{"createdAt": timeRangeCondition(filter.CreatedAt)})
+	}
+	if !filter.UpdatedAt.IsZero() {
+		conditions = append(conditions, bson.M{"updatedAt": timeRangeCondition(filter.UpdatedAt)})
+	}
+
+	if filter.UpdatedSince != nil {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"updatedAt": bson.M{"$gte": *filter.UpdatedSince}},
+			bson.M{"createdAt": bson.M{"$gte": *filter.UpdatedSince}},
+		}})
+	}
+
+	if filter.Assignee != "" {
+		conditions = append(conditions, bson.M{"lease.assignee": filter.Assignee})
+	}
+	if filter.Overdue {
+		// the leases found overdue by the sweep, as the overdue flag of the leases read
+		conditions = append(conditions, bson.M{"lease.expiredAt": bson.M{"$ne": nil}})
+	}
+
+	for _, requirement := range filter.Labels {
+		condition, err := labelCondition(requirement)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	for _, attribute := range filter.Attributes {
+		condition, err := attributeCondition(attribute)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if len(filter.Connectivity) > 0 {
+		now := time.Now().UTC()
+		anyOf := bson.A{}
+		for _, connectivity := range filter.Connectivity {
+			if !connectivity.IsValid() {
+				return nil, errors.InvalidParameterError("connectivity", "invalid value")
+			}
+			anyOf = append(anyOf, bson.M{"lastSeenAt": timeRangeCondition(connectivity.LastSeenRange(now))})
+			if connectivity == model.ConnectivityOffline {
+				// the devices that never sent a heartbeat
+				anyOf = append(anyOf, bson.M{"lastSeenAt": nil})
+			}
+		}
+		conditions = append(conditions, bson.M{"$or": anyOf})
+	}
+
+	// $geoWithin rather than $near, which sorts by distance and cannot be counted, so that the searches keep their sort
+	if filter.Near != nil {
+		if err := filter.Near.Validate(); err != nil {
+			return nil, errors.InvalidParameterError("near", err.Error())
+		}
+		conditions = append(conditions, bson.M{"location.point": bson.M{"$geoWithin": bson.M{
+			"$centerSphere": bson.A{bson.A{filter.Near.Lng, filter.Near.Lat}, filter.Near.Radius / model.EarthRadius},
+		}}})
+	}
+	if len(filter.Within) > 0 {
+		if err := filter.Within.Validate(); err != nil {
+			return nil, errors.InvalidParameterError("within", err.Error())
+		}
+		conditions = append(conditions, bson.M{"location.point": bson.M{"$geoWithin": bson.M{
+			"$geometry": bson.M{"type": "Polygon", "coordinates": bson.A{filter.Within.Ring()}},
+		}}})
+	}
+
+	return andFilter(conditions...), nil
+}
+
+// andFilter combines mongo conditions, skipping empty ones
+func andFilter(conditions ...interface{}) bson.M {
+	nonEmpty := bson.A{}
+	for _, condition := range conditions {
+		if m, ok := condition.(bson.M); ok && len(m) == 0 {
+			continue
+		}
+		nonEmpty = append(nonEmpty, condition)
+	}
+	switch len(nonEmpty) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return nonEmpty[0].(bson.M)
+	default:
+		return bson.M{"$and": nonEmpty}
+	}
+}
+
+// labelCondition translates a label requirement, the key being checked as it is part of a field name
+func labelCondition(requirement model.LabelRequirement) (bson.M, error) {
+	if !model.IsValidLabelKey(requirement.Key) {
+		return nil, errors.InvalidParameterError("labels", "invalid label key ["+requirement.Key+"]")
+	}
+	field := "labels." + requirement.Key
+	switch requirement.Operator {
+	case model.LabelEquals:
+		if len(requirement.Values) == 1 {
+			return bson.M{field: requirement.Values[0]}, nil
+		}
+	case model.LabelNotEquals:
+		if len(requirement.Values) == 1 {
+			return bson.M{field: bson.M{"$ne": requirement.Values[0]}}, nil
+		}
+	case model.LabelIn:
+		return bson.M{field: bson.M{"$in": requirement.Values}}, nil
+	case model.LabelNotIn:
+		return bson.M{field: bson.M{"$nin": requirement.Values}}, nil
+	case model.LabelExists:
+		return bson.M{field: bson.M{"$exists": true}}, nil
+	case model.LabelNotExists:
+		return bson.M{field: bson.M{"$exists": false}}, nil
+	}
+	return nil, errors.InvalidParameterError("labels", "invalid requirement on label ["+requirement.Key+"]")
+}
+
+// attributeCondition translates a condition on an attribute, the path being checked as it is a field name.
+// Values reading as numbers or booleans are compared as such, equality also matching them as strings.
+func attributeCondition(condition model.AttributeCondition) (bson.M, error) {
+	if !model.IsValidAttributePath(condition.Path) {
+		return nil, errors.InvalidParameterError("attributes", "invalid attribute path ["+condition.Path+"]")
+	}
+	field := "attributes." + condition.Path
+	typed := condition.TypedValue()
+	switch condition.Operator {
+	case model.AttributeEquals:
+		if typed == nil {
+			return bson.M{field: condition.Value}, nil
+		}
+		return bson.M{field: bson.M{"$in": bson.A{condition.Value, typed}}}, nil
+	case model.AttributeNotEquals:
+		if typed == nil {
+			return bson.M{field: bson.M{"$ne": condition.Value}}, nil
+		}
+		return bson.M{field: bson.M{"$nin": bson.A{condition.Value, typed}}}, nil
+	case model.AttributeGreater, model.AttributeGreaterOrEqual, model.AttributeLess, model.AttributeLessOrEqual:
+		if typed == nil {
+			typed = condition.Value
+		}
+		return bson.M{field: bson.M{"$" + string(condition.Operator): typed}}, nil
+	}
+	return nil, errors.InvalidParameterError("attributes", "invalid condition on attribute ["+condition.Path+"]")
+}
+
+func nameCondition(name *model.NameFilter) (interface{}, error) {
+	switch name.Match {
+	case model.NameMatchExact:
+		return name.Value, nil
+	case model.NameMatchPrefix:
+		return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(name.Value)}, nil
+	case model.NameMatchContains:
+		return primitive.Regex{Pattern: regexp.QuoteMeta(name.Value), Options: "i"}, nil
+	}
+	return nil, errors.InvalidParameterError("name["+string(name.Match)+"]", "unsupported operator ["+string(name.Match)+"]")
+}
+
+func timeRangeCondition(tr model.TimeRange) bson.M {
+	condition := bson.M{}
+	if tr.Gt != nil {
+		condition["$gt"] = *tr.Gt
+	}
+	if tr.Gte != nil {
+		condition["$gte"] = *tr.Gte
+	}
+	if tr.Lt != nil {
+		condition["$lt"] = *tr.Lt
+	}
+	if tr.Lte != nil {
+		condition["$lte"] = *tr.Lte
+	}
+	return condition
+}
@@ -0,0 +1,179 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_deviceFilter(t *testing.T) {
+	from := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	t.Run("empty", func(t *testing.T) {
+		filter, err := deviceFilter(model.DeviceFilter{})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{}, filter)
+	})
+
+	t.Run("one brand", func(t *testing.T) {
+		filter, err := deviceFilter(model.DeviceFilter{Brands: []model.Brand{"brand1"}})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"brand": model.Brand("brand1")}, filter)
+	})
+
+	t.Run("invalid brand", func(t *testing.T) {
+		_, err := deviceFilter(model.DeviceFilter{Brands: []model.Brand{"brand1", "new brand"}})
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'brand' is invalid 'invalid value'")
+	})
+
+	t.Run("name operators are quoted", func(t *testing.T) {
+		filter, err := deviceFilter(model.DeviceFilter{Name: &model.NameFilter{Match: model.NameMatchPrefix, Value: "a.b*"}})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"name": primitive.Regex{Pattern: `^a\.b\*`}}, filter)
+
+		filter, err = deviceFilter(model.DeviceFilter{Name: &model.NameFilter{Match: model.NameMatchContains, Value: "(x)"}})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"name": primitive.Regex{Pattern: `\(x\)`, Options: "i"}}, filter)
+
+		filter, err = deviceFilter(model.DeviceFilter{Name: &model.NameFilter{Match: model.NameMatchExact, Value: "$gt"}})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"name": "$gt"}, filter)
+	})
+
+	t.Run("unsupported name operator", func(t *testing.T) {
+		_, err := deviceFilter(model.DeviceFilter{Name: &model.NameFilter{Match: "regex", Value: "x"}})
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'name[regex]' is invalid 'unsupported operator [regex]'")
+	})
+
+	t.Run("leases", func(t *testing.T) {
+		filter, err := deviceFilter(model.DeviceFilter{Assignee: "ada"})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"lease.assignee": "ada"}, filter)
+
+		filter, err = deviceFilter(model.DeviceFilter{Overdue: true})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"lease.expiredAt": bson.M{"$ne": nil}}, filter)
+	})
+
+	t.Run("label selector", func(t *testing.T) {
+		selector, err := model.ParseLabelSelector("env=prod,team!=qa,has(rack),!has(spare),zone in (a,b),os notin (win)")
+		require.NoError(t, err)
+		filter, err := deviceFilter(model.DeviceFilter{Labels: selector})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"$and": bson.A{
+			bson.M{"labels.env": "prod"},
+			bson.M{"labels.team": bson.M{"$ne": "qa"}},
+			bson.M{"labels.rack": bson.M{"$exists": true}},
+			bson.M{"labels.spare": bson.M{"$exists": false}},
+			bson.M{"labels.zone": bson.M{"$in": []string{"a", "b"}}},
+			bson.M{"labels.os": bson.M{"$nin": []string{"win"}}},
+		}}, filter)
+	})
+
+	t.Run("invalid label key", func(t *testing.T) {
+		_, err := deviceFilter(model.DeviceFilter{Labels: model.LabelSelector{{Key: "$where", Operator: model.LabelExists}}})
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'labels' is invalid 'invalid label key [$where]'")
+	})
+
+	t.Run("attribute conditions", func(t *testing.T) {
+		filter, err := deviceFilter(model.DeviceFilter{Attributes: []model.AttributeCondition{
+			{Path: "ram", Operator: model.AttributeGreaterOrEqual, Value: "8"},
+			{Path: "os", Operator: model.AttributeEquals, Value: "android"},
+			{Path: "screen.size", Operator: model.AttributeEquals, Value: "6.1"},
+			{Path: "nfc", Operator: model.AttributeNotEquals, Value: "true"},
+			{Path: "firmware", Operator: model.AttributeLess, Value: "v2"},
+		}})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"$and": bson.A{
+			bson.M{"attributes.ram": bson.M{"$gte": 8.0}},
+			bson.M{"attributes.os": "android"},
+			bson.M{"attributes.screen.size": bson.M{"$in": bson.A{"6.1", 6.1}}},
+			bson.M{"attributes.nfc": bson.M{"$nin": bson.A{"true", true}}},
+			bson.M{"attributes.firmware": bson.M{"$lt": "v2"}},
+		}}, filter)
+	})
+
+	t.Run("invalid attribute path", func(t *testing.T) {
+		_, err := deviceFilter(model.DeviceFilter{Attributes: []model.AttributeCondition{{Path: "$where", Operator: model.AttributeEquals}}})
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'attributes' is invalid 'invalid attribute path [$where]'")
+	})
+
+	t.Run("connectivity", func(t *testing.T) {
+		before := time.Now().UTC()
+		filter, err := deviceFilter(model.DeviceFilter{Connectivity: []model.Connectivity{model.ConnectivityOnline, model.ConnectivityOffline}})
+		require.NoError(t, err)
+		anyOf := filter["$or"].(bson.A)
+		require.Len(t, anyOf, 3)
+		online := anyOf[0].(bson.M)["lastSeenAt"].(bson.M)["$gt"].(time.Time)
+		require.False(t, online.Before(before.Add(-model.DefaultConnectivityThresholds.StaleAfter)))
+		offline := anyOf[1].(bson.M)["lastSeenAt"].(bson.M)["$lte"].(time.Time)
+		require.Equal(t, model.DefaultConnectivityThresholds.OfflineAfter-model.DefaultConnectivityThresholds.StaleAfter, online.Sub(offline))
+		require.Equal(t, bson.M{"lastSeenAt": nil}, anyOf[2])
+
+		_, err = deviceFilter(model.DeviceFilter{Connectivity: []model.Connectivity{"away"}})
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'connectivity' is invalid 'invalid value'")
+	})
+
+	t.Run("geospatial", func(t *testing.T) {
+		filter, err := deviceFilter(model.DeviceFilter{Near: &model.GeoNear{GeoPosition: model.GeoPosition{Lat: 48.8584, Lng: 2.2945}, Radius: model.EarthRadius / 1000}})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"location.point": bson.M{"$geoWithin": bson.M{"$centerSphere": bson.A{bson.A{2.2945, 48.8584}, 0.001}}}}, filter)
+
+		filter, err = deviceFilter(model.DeviceFilter{Within: model.GeoPolygon{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}, {Lat: 1, Lng: 1}}})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"location.point": bson.M{"$geoWithin": bson.M{"$geometry": bson.M{
+			"type":        "Polygon",
+			"coordinates": bson.A{[][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		}}}}, filter)
+	})
+
+	t.Run("invalid geospatial", func(t *testing.T) {
+		_, err := deviceFilter(model.DeviceFilter{Near: &model.GeoNear{GeoPosition: model.GeoPosition{Lat: 1, Lng: 2}}})
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'near' is invalid 'radius 0 is not greater than 0 and at most 20000000 meters'")
+		_, err = deviceFilter(model.DeviceFilter{Within: model.GeoPolygon{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}}})
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'within' is invalid 'a polygon must have at least 3 distinct vertices'")
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		filter, err := deviceFilter(model.DeviceFilter{
+			Brands:       []model.Brand{"brand1", "brand2"},
+			CreatedAt:    model.TimeRange{Gte: &from, Lt: &to},
+			UpdatedSince: &from,
+		})
+		require.NoError(t, err)
+		require.Equal(t, bson.M{"$and": bson.A{
+			bson.M{"brand": bson.M{"$in": []model.Brand{"brand1", "brand2"}}},
+			bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}},
+			bson.M{"$or": bson.A{
+				bson.M{"updatedAt": bson.M{"$gte": from}},
+				bson.M{"createdAt": bson.M{"$gte": from}},
+			}},
+		}}, filter)
+	})
+}
+
+func Test_versionFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+	notDeleted := bson.M{"$exists": false}
+
+	t.Run("any version", func(t *testing.T) {
+		require.Equal(t, bson.M{"_id": id, "deletedAt": notDeleted}, versionFilter(id, nil))
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		require.Equal(t, bson.M{"_id": id, "deletedAt": notDeleted, "version": bson.M{"$in": bson.A{int64(2), int64(3)}}}, versionFilter(id, model.VersionMatch{2, 3}))
+	})
+
+	t.Run("version 0 matches devices without version", func(t *testing.T) {
+		require.Equal(t, bson.M{"_id": id, "deletedAt": notDeleted, "version": bson.M{"$in": bson.A{int64(0), nil}}}, versionFilter(id, model.VersionMatch{0}))
+	})
+
+	t.Run("no version", func(t *testing.T) {
+		require.Equal(t, bson.M{"_id": id, "deletedAt": notDeleted, "version": bson.M{"$in": bson.A{}}}, versionFilter(id, model.VersionMatch{}))
+	})
+}
@@ -0,0 +1,144 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeviceHistoryCollectionName is the base name for the device history collection
+const DeviceHistoryCollectionName = "device_history"
+
+// deviceRevisionName is the name of a device revision in errors
+const deviceRevisionName = "device revision"
+
+// DeviceHistoryDB Device history database, revisions are only appended
+type DeviceHistoryDB interface {
+	Append(ctx context.Context, revision *model.DeviceRevision) error
+	ByRevision(ctx context.Context, deviceID primitive.ObjectID, revision int64) (*model.DeviceRevision, error)
+	List(ctx context.Context, search model.RevisionSearch) (*model.RevisionPage, error)
+	LastAt(ctx context.Context, deviceID primitive.ObjectID, at time.Time) (*model.DeviceRevision, error)
+	FirstAfter(ctx context.Context, deviceID primitive.ObjectID, at time.Time) (*model.DeviceRevision, error)
+}
+
+// DeviceHistoryRepository repository
+type DeviceHistoryRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewDeviceHistoryDB creates new device history collection
+func NewDeviceHistoryDB(ctx context.Context, db *mongo.Database) (*DeviceHistoryRepository, error) {
+	Collection := db.Collection(DeviceHistoryCollectionName, nil)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "deviceId", Value: 1}, {Key: "revision", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "deviceId", Value: 1}, {Key: "at", Value: 1}, {Key: "revision", Value: 1}},
+			Options: options.Index(),
+		},
+	}
+
+	_, err := Collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeviceHistoryRepository{
+		Collection: Collection,
+	}, nil
+}
+
+// Append saves a new revision of a device
+func (hr DeviceHistoryRepository) Append(ctx context.Context, revision *model.DeviceRevision) error {
+	res, err := hr.Collection.InsertOne(ctx, revision)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.AlreadyExistsError(deviceRevisionName, revisionKey(revision.DeviceID, revision.Revision))
+		}
+		return errors.CreateError(DeviceHistoryCollectionName, err.Error())
+	}
+	revision.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// ByRevision gets a revision of a device
+func (hr DeviceHistoryRepository) ByRevision(ctx context.Context, deviceID primitive.ObjectID, revision int64) (*model.DeviceRevision, error) {
+	rev := new(model.DeviceRevision)
+	err := hr.Collection.FindOne(ctx, bson.M{"deviceId": deviceID, "revision": revision}).Decode(rev)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.CouldNotFindObject(deviceRevisionName, revisionKey(deviceID, revision))
+		}
+		return nil, errors.ReadError(DeviceHistoryCollectionName, err.Error())
+	}
+	return rev, nil
+}
+
+// List lists a page of the revisions of a device, the newest first.
+// One revision more than the limit is read to know if there is a next page.
+func (hr DeviceHistoryRepository) List(ctx context.Context, search model.RevisionSearch) (*model.RevisionPage, error) {
+	filter := bson.M{"deviceId": search.DeviceID}
+	if search.Before > 0 {
+		filter["revision"] = bson.M{"$lt": search.Before}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "revision", Value: -1}})
+	if search.Limit > 0 {
+		opts = opts.SetLimit(search.Limit + 1)
+	}
+
+	cur, err := hr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.ListError(DeviceHistoryCollectionName, err, "deviceId", search.DeviceID.Hex())
+	}
+
+	page := &model.RevisionPage{Revisions: make([]model.DeviceRevision, 0)}
+	err = cur.All(ctx, &page.Revisions)
+	if err != nil {
+		return nil, errors.ListError(DeviceHistoryCollectionName, err, "deviceId", search.DeviceID.Hex())
+	}
+
+	if search.Limit > 0 && int64(len(page.Revisions)) > search.Limit {
+		page.Revisions = page.Revisions[:search.Limit]
+		page.NextCursor = model.NewRevisionCursor(page.Revisions[search.Limit-1].Revision)
+	}
+	return page, nil
+}
+
+// LastAt gets the last revision of a device made at or before a time, nil when there is none
+func (hr DeviceHistoryRepository) LastAt(ctx context.Context, deviceID primitive.ObjectID, at time.Time) (*model.DeviceRevision, error) {
+	return hr.findFirst(ctx, bson.M{"deviceId": deviceID, "at": bson.M{"$lte": at}}, -1)
+}
+
+// FirstAfter gets the first revision of a device made after a time, nil when there is none
+func (hr DeviceHistoryRepository) FirstAfter(ctx context.Context, deviceID primitive.ObjectID, at time.Time) (*model.DeviceRevision, error) {
+	return hr.findFirst(ctx, bson.M{"deviceId": deviceID, "at": bson.M{"$gt": at}}, 1)
+}
+
+// findFirst gets the first revision matching filter in the revision order, nil when there is none
+func (hr DeviceHistoryRepository) findFirst(ctx context.Context, filter bson.M, order int) (*model.DeviceRevision, error) {
+	rev := new(model.DeviceRevision)
+	opts := options.FindOne().SetSort(bson.D{{Key: "revision", Value: order}})
+	err := hr.Collection.FindOne(ctx, filter, opts).Decode(rev)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, errors.ReadError(DeviceHistoryCollectionName, err.Error())
+	}
+	return rev, nil
+}
+
+// revisionKey identifies a revision in errors
+func revisionKey(deviceID primitive.ObjectID, revision int64) string {
+	return fmt.Sprintf("%s/%d", deviceID.Hex(), revision)
+}
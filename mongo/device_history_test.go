@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_DeviceHistory(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := CreateDeviceHistoryTestRepo(ctx, t)
+	defer drop()
+
+	deviceID := primitive.NewObjectID()
+	createdAt := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	names := []string{"io", "europa", "ganymede", "callisto"}
+	var before *model.Device
+	for i, name := range names {
+		after := &model.Device{ID: deviceID, Name: name, Brand: "brand1", CreatedAt: createdAt, Version: int64(i + 1)}
+		operation := model.OperationUpdateName
+		if i == 0 {
+			operation = model.OperationCreate
+		} else {
+			updatedAt := createdAt.AddDate(0, 0, i)
+			after.UpdatedAt = &updatedAt
+		}
+		revision := model.NewDeviceRevision(operation, "alice", model.DeviceChange{Before: before, After: after})
+		require.NoError(t, repo.Append(ctx, revision))
+		require.False(t, revision.ID.IsZero())
+		before = after
+	}
+
+	t.Run("revisions are immutable", func(t *testing.T) {
+		err := repo.Append(ctx, &model.DeviceRevision{DeviceID: deviceID, Revision: 2})
+		require.EqualError(t, err, "result: false; code: 1500010; message: the device revision "+deviceID.Hex()+"/2 already exists")
+	})
+
+	t.Run("by revision", func(t *testing.T) {
+		rev, err := repo.ByRevision(ctx, deviceID, 2)
+		require.NoError(t, err)
+		require.Equal(t, model.OperationUpdateName, rev.Operation)
+		require.Equal(t, "alice", rev.Actor)
+		require.Equal(t, createdAt.AddDate(0, 0, 1), rev.At)
+		require.Equal(t, "io", rev.Before.Name)
+		require.Equal(t, "europa", rev.After.Name)
+
+		_, err = repo.ByRevision(ctx, deviceID, 5)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the device revision with id "+deviceID.Hex()+"/5 could not be found")
+	})
+
+	t.Run("list pages", func(t *testing.T) {
+		page, err := repo.List(ctx, model.RevisionSearch{DeviceID: deviceID, Limit: 3})
+		require.NoError(t, err)
+		require.Len(t, page.Revisions, 3)
+		require.Equal(t, int64(4), page.Revisions[0].Revision)
+		require.Equal(t, "2", page.NextCursor)
+
+		page, err = repo.List(ctx, model.RevisionSearch{DeviceID: deviceID, Limit: 3, Before: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Revisions, 1)
+		require.Equal(t, int64(1), page.Revisions[0].Revision)
+		require.Nil(t, page.Revisions[0].Before)
+		require.Empty(t, page.NextCursor)
+	})
+
+	t.Run("revisions around a time", func(t *testing.T) {
+		at := createdAt.AddDate(0, 0, 2).Add(time.Hour)
+		rev, err := repo.LastAt(ctx, deviceID, at)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), rev.Revision)
+
+		rev, err = repo.FirstAfter(ctx, deviceID, at)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), rev.Revision)
+
+		rev, err = repo.LastAt(ctx, deviceID, createdAt.Add(-time.Second))
+		require.NoError(t, err)
+		require.Nil(t, rev)
+
+		rev, err = repo.FirstAfter(ctx, deviceID, createdAt.AddDate(1, 0, 0))
+		require.NoError(t, err)
+		require.Nil(t, rev)
+	})
+}
@@ -2,9 +2,11 @@ package mongo
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/device-ms/errors"
 	"github.com/device-ms/model"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
@@ -39,6 +41,604 @@ func Test_Device_Create_ByID(t *testing.T) {
 	})
 }
 
+func Test_DeviceCreateMany(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	items := []*model.BulkItem{
+		{Device: &model.Device{Name: "io", Brand: "brand1"}},
+		{Device: &model.Device{Name: "europa", Brand: "brand2"}},
+	}
+	repo.CreateMany(ctx, items, true)
+	for _, item := range items {
+		require.Equal(t, model.BulkCreated, item.Status)
+		require.NoError(t, item.Err)
+		device, err := repo.ByID(ctx, item.Device.ID)
+		require.NoError(t, err)
+		require.Equal(t, item.Device.Name, device.Name)
+		require.Equal(t, int64(1), device.Version)
+	}
+
+	require.NoError(t, repo.Remove(ctx, []primitive.ObjectID{items[0].Device.ID}))
+	_, err := repo.ByID(ctx, items[0].Device.ID)
+	require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+items[0].Device.ID.Hex()+" could not be found")
+}
+
+func Test_DeviceIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	device := model.Device{
+		Name:         "ganymede",
+		Brand:        "brand1",
+		SerialNumber: "SN-1",
+		ExternalIDs:  model.NewExternalIDs(map[string]string{"erp": "E-1", "assetTag": "A-1"}),
+	}
+	require.NoError(t, repo.Create(ctx, &device))
+
+	t.Run("by external id", func(t *testing.T) {
+		dv, err := repo.ByExternalID(ctx, "erp", "E-1")
+		require.NoError(t, err)
+		require.Equal(t, device.ID, dv.ID)
+		require.Equal(t, "SN-1", dv.SerialNumber)
+		require.Equal(t, device.ExternalIDs, dv.ExternalIDs)
+
+		_, err = repo.ByExternalID(ctx, "erp", "A-1")
+		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id erp/A-1 could not be found")
+	})
+
+	t.Run("serial number unique per brand", func(t *testing.T) {
+		err := repo.Create(ctx, &model.Device{Name: "callisto", Brand: "brand1", SerialNumber: "SN-1"})
+		require.EqualError(t, err, "result: false; code: 1500010; message: the device serial number SN-1 of brand brand1 already exists")
+
+		require.NoError(t, repo.Create(ctx, &model.Device{Name: "callisto", Brand: "brand2", SerialNumber: "SN-1"}))
+		require.NoError(t, repo.Create(ctx, &model.Device{Name: "without serial 1", Brand: "brand1"}))
+		require.NoError(t, repo.Create(ctx, &model.Device{Name: "without serial 2", Brand: "brand1"}))
+	})
+
+	t.Run("external id unique", func(t *testing.T) {
+		other := model.Device{Name: "callisto", Brand: "brand3", ExternalIDs: model.NewExternalIDs(map[string]string{"erp": "E-2"})}
+		require.NoError(t, repo.Create(ctx, &other))
+
+		items := []*model.BulkItem{{Device: &model.Device{Name: "io", Brand: "brand1", ExternalIDs: model.NewExternalIDs(map[string]string{"crm": "C-1", "erp": "E-1"})}}}
+		repo.CreateMany(ctx, items, false)
+		require.EqualError(t, items[0].Err, "result: false; code: 1500010; message: the device external id erp/E-1 already exists")
+
+		_, err := repo.Update(ctx, &model.Device{ID: other.ID, Name: "callisto", Brand: "brand3", ExternalIDs: device.ExternalIDs}, nil)
+		require.EqualError(t, err, "result: false; code: 1500010; message: the device external id assetTag/A-1 already exists")
+
+		_, err = repo.Patch(ctx, other.ID, nil, func(dv *model.Device) error {
+			dv.Brand = "brand1"
+			dv.SerialNumber = "SN-1"
+			return nil
+		})
+		require.EqualError(t, err, "result: false; code: 1500010; message: the device serial number SN-1 of brand brand1 already exists")
+	})
+
+	t.Run("identifiers are updated", func(t *testing.T) {
+		change, err := repo.Update(ctx, &model.Device{ID: device.ID, Name: "ganymede", Brand: "brand1"}, nil)
+		require.NoError(t, err)
+		require.Empty(t, change.After.SerialNumber)
+		require.Nil(t, change.After.ExternalIDs)
+
+		dv, err := repo.ByID(ctx, device.ID)
+		require.NoError(t, err)
+		require.Empty(t, dv.SerialNumber)
+		require.Nil(t, dv.ExternalIDs)
+		require.NoError(t, repo.Create(ctx, &model.Device{Name: "callisto", Brand: "brand1", SerialNumber: "SN-1"}))
+	})
+}
+
+func Test_DeviceState(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	inStock := model.Device{Name: "io", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &inStock))
+	require.Equal(t, model.StateInStock, inStock.State)
+	inUse := model.Device{Name: "europa", Brand: "brand1", State: model.StateInUse}
+	require.NoError(t, repo.Create(ctx, &inUse))
+	// a device created before states
+	legacy, err := repo.Collection.InsertOne(ctx, bson.M{"name": "callisto", "brand": "brand1", "createdAt": time.Now(), "version": 1})
+	require.NoError(t, err)
+	legacyID := legacy.InsertedID.(primitive.ObjectID)
+
+	t.Run("filter by state", func(t *testing.T) {
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{States: []model.DeviceState{model.StateInStock}}})
+		require.NoError(t, err)
+		require.Len(t, page.Devices, 2)
+		require.Equal(t, inStock.ID, page.Devices[0].ID)
+		require.Equal(t, legacyID, page.Devices[1].ID)
+		require.Equal(t, model.StateInStock, page.Devices[1].CurrentState())
+
+		page, err = repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{States: []model.DeviceState{model.StateInUse, model.StateRetired}}})
+		require.NoError(t, err)
+		require.Len(t, page.Devices, 1)
+		require.Equal(t, inUse.ID, page.Devices[0].ID)
+	})
+
+	t.Run("a device in use cannot change brand nor be deleted", func(t *testing.T) {
+		inUseError := "result: false; code: 1500019; message: the device with id " + inUse.ID.Hex() + " is inUse"
+		_, err := repo.UpdateBrand(ctx, inUse.ID, "brand2", nil)
+		require.EqualError(t, err, inUseError)
+		_, err = repo.Update(ctx, &model.Device{ID: inUse.ID, Name: "europa", Brand: "brand2"}, model.VersionMatch{1})
+		require.EqualError(t, err, inUseError)
+		_, err = repo.Patch(ctx, inUse.ID, nil, func(dv *model.Device) error {
+			dv.Brand = "brand2"
+			return nil
+		})
+		require.EqualError(t, err, inUseError)
+		_, err = repo.Delete(ctx, inUse.ID, nil)
+		require.EqualError(t, err, inUseError)
+
+		_, err = repo.UpdateName(ctx, inUse.ID, "ganymede", nil)
+		require.NoError(t, err)
+		_, err = repo.Update(ctx, &model.Device{ID: inUse.ID, Name: "europa", Brand: "brand1"}, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("patch the state", func(t *testing.T) {
+		change, err := repo.Patch(ctx, inUse.ID, nil, func(dv *model.Device) error {
+			dv.State = model.StateInRepair
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.StateInUse, change.Before.State)
+		require.Equal(t, model.StateInRepair, change.After.State)
+
+		change, err = repo.UpdateBrand(ctx, inUse.ID, "brand2", nil)
+		require.NoError(t, err)
+		require.Equal(t, model.StateInRepair, change.After.State)
+		_, err = repo.Delete(ctx, inUse.ID, nil)
+		require.NoError(t, err)
+	})
+}
+
+func Test_DeviceLease(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	device := model.Device{Name: "io", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &device))
+	retired := model.Device{Name: "europa", Brand: "brand1", State: model.StateRetired}
+	require.NoError(t, repo.Create(ctx, &retired))
+	dueAt := time.Now().UTC().Truncate(time.Second).Add(time.Hour)
+
+	t.Run("checkout", func(t *testing.T) {
+		change, err := repo.Checkout(ctx, device.ID, model.Lease{Assignee: "ada", DueAt: dueAt}, model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Nil(t, change.Before.Lease)
+		require.Equal(t, "ada", change.After.Lease.Assignee)
+		require.Equal(t, dueAt, change.After.Lease.DueAt)
+		require.False(t, change.After.Lease.CheckedOutAt.IsZero())
+		require.Equal(t, int64(2), change.After.Version)
+
+		saved, err := repo.ByID(ctx, device.ID)
+		require.NoError(t, err)
+		require.Equal(t, change.After.Lease, saved.Lease)
+	})
+
+	t.Run("fail checkout twice or retired", func(t *testing.T) {
+		_, err := repo.Checkout(ctx, device.ID, model.Lease{Assignee: "grace", DueAt: dueAt}, nil)
+		require.EqualError(t, err, "result: false; code: 1500019; message: the device with id "+device.ID.Hex()+" is checked out")
+		_, err = repo.Checkout(ctx, retired.ID, model.Lease{Assignee: "grace", DueAt: dueAt}, nil)
+		require.EqualError(t, err, "result: false; code: 1500019; message: the device with id "+retired.ID.Hex()+" is retired")
+		_, err = repo.Checkin(ctx, retired.ID, nil)
+		require.EqualError(t, err, "result: false; code: 1500019; message: the device with id "+retired.ID.Hex()+" is not checked out")
+	})
+
+	t.Run("filter by assignee and overdue", func(t *testing.T) {
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Assignee: "ada"}})
+		require.NoError(t, err)
+		require.Len(t, page.Devices, 1)
+		require.Equal(t, device.ID, page.Devices[0].ID)
+
+		page, err = repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Overdue: true}})
+		require.NoError(t, err)
+		require.Len(t, page.Devices, 0)
+	})
+
+	t.Run("expire the overdue leases", func(t *testing.T) {
+		changes, err := repo.ExpireLeases(ctx, dueAt)
+		require.NoError(t, err)
+		require.Len(t, changes, 0)
+
+		at := dueAt.Add(time.Minute)
+		changes, err = repo.ExpireLeases(ctx, at)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Nil(t, changes[0].Before.Lease.ExpiredAt)
+		require.Equal(t, at, *changes[0].After.Lease.ExpiredAt)
+		require.Equal(t, int64(3), changes[0].After.Version)
+
+		changes, err = repo.ExpireLeases(ctx, at.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, changes, 0)
+
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Overdue: true}})
+		require.NoError(t, err)
+		require.Len(t, page.Devices, 1)
+		require.Equal(t, device.ID, page.Devices[0].ID)
+	})
+
+	t.Run("checkin", func(t *testing.T) {
+		change, err := repo.Checkin(ctx, device.ID, nil)
+		require.NoError(t, err)
+		require.Equal(t, "ada", change.Before.Lease.Assignee)
+		require.Nil(t, change.After.Lease)
+
+		saved, err := repo.ByID(ctx, device.ID)
+		require.NoError(t, err)
+		require.Nil(t, saved.Lease)
+		_, err = repo.Checkout(ctx, device.ID, model.Lease{Assignee: "grace", DueAt: dueAt}, nil)
+		require.NoError(t, err)
+	})
+}
+
+func Test_DeviceLabels(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	prod := model.Device{Name: "io", Brand: "brand1", Labels: map[string]string{"env": "prod", "rack": "r1"}}
+	require.NoError(t, repo.Create(ctx, &prod))
+	qa := model.Device{Name: "europa", Brand: "brand1", Labels: map[string]string{"env": "qa", "team": "qa"}}
+	require.NoError(t, repo.Create(ctx, &qa))
+	unlabelled := model.Device{Name: "callisto", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &unlabelled))
+
+	list := func(selector string) []primitive.ObjectID {
+		labels, err := model.ParseLabelSelector(selector)
+		require.NoError(t, err)
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Labels: labels}})
+		require.NoError(t, err)
+		var ids []primitive.ObjectID
+		for _, dv := range page.Devices {
+			ids = append(ids, dv.ID)
+		}
+		return ids
+	}
+
+	t.Run("select by labels", func(t *testing.T) {
+		require.Equal(t, []primitive.ObjectID{prod.ID}, list("env=prod"))
+		require.Equal(t, []primitive.ObjectID{prod.ID, unlabelled.ID}, list("team!=qa"))
+		require.Equal(t, []primitive.ObjectID{prod.ID}, list("env in (prod,staging),has(rack)"))
+		require.Equal(t, []primitive.ObjectID{qa.ID, unlabelled.ID}, list("!has(rack)"))
+		require.Equal(t, []primitive.ObjectID{unlabelled.ID}, list("env notin (prod,qa)"))
+	})
+
+	t.Run("set a label", func(t *testing.T) {
+		change, err := repo.SetLabel(ctx, unlabelled.ID, "env", "dev", model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Nil(t, change.Before.Labels)
+		require.Equal(t, map[string]string{"env": "dev"}, change.After.Labels)
+		require.Equal(t, int64(2), change.After.Version)
+
+		change, err = repo.SetLabel(ctx, prod.ID, "env", "staging", nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"env": "prod", "rack": "r1"}, change.Before.Labels)
+		require.Equal(t, map[string]string{"env": "staging", "rack": "r1"}, change.After.Labels)
+
+		saved, err := repo.ByID(ctx, prod.ID)
+		require.NoError(t, err)
+		require.Equal(t, change.After.Labels, saved.Labels)
+	})
+
+	t.Run("fail too many labels", func(t *testing.T) {
+		labels := make(map[string]string, model.MaxLabels)
+		for i := 0; i < model.MaxLabels; i++ {
+			labels[fmt.Sprintf("key%d", i)] = "v"
+		}
+		full := model.Device{Name: "ganymede", Brand: "brand1", Labels: labels}
+		require.NoError(t, repo.Create(ctx, &full))
+
+		_, err := repo.SetLabel(ctx, full.ID, "one-more", "v", nil)
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'key' is invalid 'the device already has 64 labels'")
+		_, err = repo.SetLabel(ctx, full.ID, "key0", "w", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("remove a label", func(t *testing.T) {
+		change, err := repo.RemoveLabel(ctx, unlabelled.ID, "env", nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"env": "dev"}, change.Before.Labels)
+		require.Nil(t, change.After.Labels)
+
+		_, err = repo.RemoveLabel(ctx, unlabelled.ID, "env", nil)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the label env of the device with id "+unlabelled.ID.Hex()+" could not be found")
+		_, err = repo.RemoveLabel(ctx, prod.ID, "rack", model.VersionMatch{1})
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+prod.ID.Hex()+" does not have the expected version")
+	})
+}
+
+func Test_DeviceAttributes(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	phone := model.Device{Name: "io", Brand: "brand1", Attributes: model.Attributes{
+		"ram":    8.0,
+		"os":     "android",
+		"screen": map[string]interface{}{"size": 6.1},
+	}}
+	require.NoError(t, repo.Create(ctx, &phone))
+	tablet := model.Device{Name: "europa", Brand: "brand1", Attributes: model.Attributes{"ram": 16.0, "os": "ios", "firmware": "16"}}
+	require.NoError(t, repo.Create(ctx, &tablet))
+	plain := model.Device{Name: "callisto", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &plain))
+
+	list := func(conditions ...model.AttributeCondition) []primitive.ObjectID {
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Attributes: conditions}})
+		require.NoError(t, err)
+		var ids []primitive.ObjectID
+		for _, dv := range page.Devices {
+			ids = append(ids, dv.ID)
+		}
+		return ids
+	}
+
+	t.Run("read attributes", func(t *testing.T) {
+		saved, err := repo.ByID(ctx, phone.ID)
+		require.NoError(t, err)
+		require.Equal(t, phone.Attributes, saved.Attributes)
+		saved, err = repo.ByID(ctx, plain.ID)
+		require.NoError(t, err)
+		require.Nil(t, saved.Attributes)
+	})
+
+	t.Run("select by attributes", func(t *testing.T) {
+		require.Equal(t, []primitive.ObjectID{tablet.ID}, list(model.AttributeCondition{Path: "ram", Operator: model.AttributeGreater, Value: "8"}))
+		require.Equal(t, []primitive.ObjectID{phone.ID}, list(model.AttributeCondition{Path: "screen.size", Operator: model.AttributeEquals, Value: "6.1"}))
+		require.Equal(t, []primitive.ObjectID{tablet.ID}, list(model.AttributeCondition{Path: "firmware", Operator: model.AttributeEquals, Value: "16"}))
+		require.Equal(t, []primitive.ObjectID{tablet.ID, plain.ID}, list(model.AttributeCondition{Path: "os", Operator: model.AttributeNotEquals, Value: "android"}))
+		require.Equal(t, []primitive.ObjectID{phone.ID}, list(
+			model.AttributeCondition{Path: "ram", Operator: model.AttributeLessOrEqual, Value: "8"},
+			model.AttributeCondition{Path: "os", Operator: model.AttributeEquals, Value: "android"},
+		))
+	})
+
+	t.Run("update attributes", func(t *testing.T) {
+		change, err := repo.Update(ctx, &model.Device{ID: phone.ID, Name: "io", Brand: "brand1", Attributes: model.Attributes{"ram": 12.0}}, nil)
+		require.NoError(t, err)
+		require.Equal(t, phone.Attributes, change.Before.Attributes)
+		require.Equal(t, model.Attributes{"ram": 12.0}, change.After.Attributes)
+
+		_, err = repo.Update(ctx, &model.Device{ID: phone.ID, Name: "io", Brand: "brand1"}, nil)
+		require.NoError(t, err)
+		saved, err := repo.ByID(ctx, phone.ID)
+		require.NoError(t, err)
+		require.Nil(t, saved.Attributes)
+	})
+}
+
+func Test_DeviceHierarchy(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	rack := model.Device{Name: "rack", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &rack))
+	chassis := model.Device{Name: "chassis", Brand: "brand1", ParentID: &rack.ID}
+	require.NoError(t, repo.Create(ctx, &chassis))
+	module := model.Device{Name: "module", Brand: "brand1", ParentID: &chassis.ID}
+	require.NoError(t, repo.Create(ctx, &module))
+	loose := model.Device{Name: "loose", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &loose))
+
+	names := func(devices []model.Device) []string {
+		names := make([]string, 0, len(devices))
+		for _, device := range devices {
+			names = append(names, device.Name)
+		}
+		return names
+	}
+	atDepth := func(depth int) []string {
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Depth: &depth}})
+		require.NoError(t, err)
+		return names(page.Devices)
+	}
+
+	t.Run("ancestors", func(t *testing.T) {
+		ancestors, err := repo.Ancestors(ctx, module.ID)
+		require.NoError(t, err)
+		require.Equal(t, []string{"chassis", "rack"}, names(ancestors))
+
+		ancestors, err = repo.Ancestors(ctx, rack.ID)
+		require.NoError(t, err)
+		require.Empty(t, ancestors)
+
+		id := primitive.NewObjectID()
+		_, err = repo.Ancestors(ctx, id)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+id.Hex()+" could not be found")
+	})
+
+	t.Run("descendants", func(t *testing.T) {
+		children, err := repo.Descendants(ctx, rack.ID, 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"chassis"}, names(children))
+
+		descendants, err := repo.Descendants(ctx, rack.ID, 0)
+		require.NoError(t, err)
+		require.Equal(t, []string{"chassis", "module"}, names(descendants))
+		require.Equal(t, &chassis.ID, descendants[1].ParentID)
+
+		count, err := repo.CountChildren(ctx, rack.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+		count, err = repo.CountChildren(ctx, module.ID)
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
+	t.Run("filter by depth", func(t *testing.T) {
+		require.Equal(t, []string{"rack", "loose"}, atDepth(0))
+		require.Equal(t, []string{"chassis"}, atDepth(1))
+		require.Equal(t, []string{"module"}, atDepth(2))
+		require.Empty(t, atDepth(3))
+
+		depth := -1
+		_, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Depth: &depth}})
+		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'depth' is invalid 'must be at least 0'")
+	})
+
+	t.Run("set and remove the parent", func(t *testing.T) {
+		change, err := repo.SetParent(ctx, module.ID, &loose.ID, model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Equal(t, &chassis.ID, change.Before.ParentID)
+		require.Equal(t, &loose.ID, change.After.ParentID)
+		require.Equal(t, int64(2), change.After.Version)
+		require.Equal(t, []string{"chassis", "module"}, atDepth(1))
+
+		change, err = repo.SetParent(ctx, module.ID, nil, model.VersionMatch{2})
+		require.NoError(t, err)
+		require.Nil(t, change.After.ParentID)
+		saved, err := repo.ByID(ctx, module.ID)
+		require.NoError(t, err)
+		require.Nil(t, saved.ParentID)
+		require.Equal(t, int64(3), saved.Version)
+
+		_, err = repo.SetParent(ctx, module.ID, nil, model.VersionMatch{2})
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+module.ID.Hex()+" does not have the expected version")
+	})
+
+	t.Run("fail delete with children", func(t *testing.T) {
+		_, err := repo.Delete(ctx, rack.ID, nil)
+		require.EqualError(t, err, "result: false; code: 1500023; message: the device with id "+rack.ID.Hex()+" has 1 child device(s)")
+		saved, err := repo.ByID(ctx, rack.ID)
+		require.NoError(t, err)
+		require.Equal(t, rack.Version, saved.Version)
+	})
+
+	t.Run("soft deleted devices are not part of the tree", func(t *testing.T) {
+		_, err := repo.Delete(ctx, chassis.ID, nil)
+		require.NoError(t, err)
+		children, err := repo.Descendants(ctx, rack.ID, 0)
+		require.NoError(t, err)
+		require.Empty(t, children)
+		count, err := repo.CountChildren(ctx, rack.ID)
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+}
+
+func Test_DeviceLocation(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	eiffel := model.Device{Name: "eiffel", Brand: "brand1", Location: &model.Location{
+		Site: "paris", Point: model.NewGeoPoint(model.GeoPosition{Lat: 48.8584, Lng: 2.2945}),
+	}}
+	require.NoError(t, repo.Create(ctx, &eiffel))
+	louvre := model.Device{Name: "louvre", Brand: "brand1", Location: &model.Location{
+		Site: "paris", Building: "pyramid", Point: model.NewGeoPoint(model.GeoPosition{Lat: 48.8606, Lng: 2.3376}),
+	}}
+	require.NoError(t, repo.Create(ctx, &louvre))
+	nowhere := model.Device{Name: "nowhere", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &nowhere))
+
+	search := func(filter model.DeviceFilter) []string {
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: filter, WithTotal: true})
+		require.NoError(t, err)
+		names := make([]string, 0, len(page.Devices))
+		for _, device := range page.Devices {
+			names = append(names, device.Name)
+		}
+		require.Equal(t, int64(len(names)), *page.Total)
+		return names
+	}
+
+	t.Run("near", func(t *testing.T) {
+		position := model.GeoPosition{Lat: 48.8583, Lng: 2.2944}
+		require.Equal(t, []string{"eiffel"}, search(model.DeviceFilter{Near: &model.GeoNear{GeoPosition: position, Radius: 1000}}))
+		require.Equal(t, []string{"eiffel", "louvre"}, search(model.DeviceFilter{Near: &model.GeoNear{GeoPosition: position, Radius: 5000}}))
+	})
+
+	t.Run("within", func(t *testing.T) {
+		polygon := model.GeoPolygon{{Lat: 48.85, Lng: 2.33}, {Lat: 48.87, Lng: 2.33}, {Lat: 48.87, Lng: 2.35}, {Lat: 48.85, Lng: 2.35}}
+		require.Equal(t, []string{"louvre"}, search(model.DeviceFilter{Within: polygon}))
+	})
+
+	t.Run("update and remove the location", func(t *testing.T) {
+		location := &model.Location{Site: "lyon", Room: "b12"}
+		change, err := repo.UpdateLocation(ctx, eiffel.ID, location, model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Equal(t, "paris", change.Before.Location.Site)
+		require.Equal(t, location, change.After.Location)
+		require.Equal(t, int64(2), change.After.Version)
+		saved, err := repo.ByID(ctx, eiffel.ID)
+		require.NoError(t, err)
+		require.Equal(t, location, saved.Location)
+
+		change, err = repo.UpdateLocation(ctx, eiffel.ID, nil, nil)
+		require.NoError(t, err)
+		require.Nil(t, change.After.Location)
+		saved, err = repo.ByID(ctx, eiffel.ID)
+		require.NoError(t, err)
+		require.Nil(t, saved.Location)
+		require.Equal(t, int64(3), saved.Version)
+
+		_, err = repo.UpdateLocation(ctx, eiffel.ID, location, model.VersionMatch{2})
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+eiffel.ID.Hex()+" does not have the expected version")
+	})
+}
+
+func Test_DeviceHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	device := model.Device{Name: "io", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &device))
+	silent := model.Device{Name: "europa", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &silent))
+	hash := model.HashHeartbeatToken("secret")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("fail without token", func(t *testing.T) {
+		err := repo.Heartbeat(ctx, device.ID, hash, now)
+		require.EqualError(t, err, "result: false; code: 1500024; message: the credentials are not valid for the device with id "+device.ID.Hex())
+	})
+
+	t.Run("set the token", func(t *testing.T) {
+		change, err := repo.SetHeartbeatToken(ctx, device.ID, hash, model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Equal(t, hash, change.After.HeartbeatTokenHash)
+		require.Equal(t, int64(2), change.After.Version)
+	})
+
+	t.Run("heartbeats only move forward", func(t *testing.T) {
+		require.NoError(t, repo.Heartbeat(ctx, device.ID, hash, now))
+		require.NoError(t, repo.Heartbeat(ctx, device.ID, hash, now.Add(-time.Hour)))
+		saved, err := repo.ByID(ctx, device.ID)
+		require.NoError(t, err)
+		require.Equal(t, now, *saved.LastSeenAt)
+		require.Equal(t, int64(2), saved.Version)
+
+		err = repo.Heartbeat(ctx, device.ID, model.HashHeartbeatToken("guess"), now)
+		require.EqualError(t, err, "result: false; code: 1500024; message: the credentials are not valid for the device with id "+device.ID.Hex())
+	})
+
+	t.Run("search by connectivity", func(t *testing.T) {
+		search := func(connectivity model.Connectivity) []primitive.ObjectID {
+			page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Connectivity: []model.Connectivity{connectivity}}})
+			require.NoError(t, err)
+			ids := make([]primitive.ObjectID, 0, len(page.Devices))
+			for _, device := range page.Devices {
+				ids = append(ids, device.ID)
+			}
+			return ids
+		}
+		require.Equal(t, []primitive.ObjectID{device.ID}, search(model.ConnectivityOnline))
+		require.Empty(t, search(model.ConnectivityStale))
+		require.Equal(t, []primitive.ObjectID{silent.ID}, search(model.ConnectivityOffline))
+	})
+}
+
 func Test_DeviceList(t *testing.T) {
 	ctx := context.Background()
 	repo, drop := NewTestDeviceRepo(t)
@@ -73,9 +673,82 @@ func Test_DeviceList(t *testing.T) {
 	}
 
 	t.Run("list all", func(t *testing.T) {
-		devices, err := repo.List(ctx)
+		page, err := repo.List(ctx, model.DeviceSearch{})
+		require.NoError(t, err)
+		require.Len(t, page.Devices, 5)
+		require.Empty(t, page.NextCursor)
+		require.Nil(t, page.Total)
+	})
+
+	t.Run("stream matching devices in order", func(t *testing.T) {
+		var streamed []string
+		search := model.DeviceSearch{DeviceFilter: model.DeviceFilter{Brands: []model.Brand{"brand1", "brand2"}}, Sort: model.SortNameAsc}
+		err := repo.Stream(ctx, search, func(dv *model.Device) error {
+			streamed = append(streamed, dv.Name)
+			return nil
+		})
 		require.NoError(t, err)
-		require.Len(t, devices, 5)
+		require.Equal(t, []string{"marte", "mercurio", "plutao", "venus"}, streamed)
+	})
+
+	t.Run("stream stops at the first error", func(t *testing.T) {
+		errStop := fmt.Errorf("stop")
+		streamed := 0
+		err := repo.Stream(ctx, model.DeviceSearch{}, func(dv *model.Device) error {
+			streamed++
+			return errStop
+		})
+		require.Equal(t, errStop, err)
+		require.Equal(t, 1, streamed)
+	})
+}
+
+func Test_DeviceListPages(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	names := []string{"mercurio", "marte", "saturno", "venus", "plutao"}
+	for i, name := range names {
+		// two devices share each creation time so the _id tie-breaker is exercised
+		_, err := repo.Collection.InsertOne(ctx, model.Device{
+			Name:      name,
+			Brand:     "brand1",
+			CreatedAt: createdAt.Add(time.Duration(i/2) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	listAll := func(sort model.DeviceSort) []string {
+		var listed []string
+		search := model.DeviceSearch{Limit: 2, Sort: sort, WithTotal: true}
+		for {
+			page, err := repo.List(ctx, search)
+			require.NoError(t, err)
+			require.Equal(t, int64(5), *page.Total)
+			for _, dv := range page.Devices {
+				listed = append(listed, dv.Name)
+			}
+			if page.NextCursor == "" {
+				return listed
+			}
+			search.Cursor, err = model.DecodeDeviceCursor(page.NextCursor, sort)
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("by creation time", func(t *testing.T) {
+		require.Equal(t, names, listAll(model.SortCreatedAtAsc))
+	})
+	t.Run("by creation time descending", func(t *testing.T) {
+		require.Equal(t, []string{"plutao", "venus", "saturno", "marte", "mercurio"}, listAll(model.SortCreatedAtDesc))
+	})
+	t.Run("by name", func(t *testing.T) {
+		require.Equal(t, []string{"marte", "mercurio", "plutao", "saturno", "venus"}, listAll(model.SortNameAsc))
+	})
+	t.Run("by name descending", func(t *testing.T) {
+		require.Equal(t, []string{"venus", "saturno", "plutao", "mercurio", "marte"}, listAll(model.SortNameDesc))
 	})
 }
 
@@ -99,21 +772,23 @@ func Test_DeviceUpdate(t *testing.T) {
 			CreatedAt: createdAt,
 			UpdatedAt: &createdAt,
 		}
-		updatedDevice, err := repo.Update(ctx, &device)
+		change, err := repo.Update(ctx, &device, nil)
 		require.NoError(t, err)
 
-		dv, err := repo.ByID(ctx, updatedDevice.ID)
+		dv, err := repo.ByID(ctx, change.After.ID)
 		require.NoError(t, err)
 		require.NotEqual(t, createdAt, device.UpdatedAt) // updatedAt is redefined by Update
 		require.Equal(t, dv.ID, device.ID)
 		require.NotEqual(t, dv.CreatedAt, device.CreatedAt) // createdAt is not updated
 		require.Equal(t, dv.Name, device.Name)
 		require.Equal(t, dv.Brand, device.Brand)
-		require.NotEqual(t, dv.UpdatedAt, updatedDevice.UpdatedAt) // updatedAt is redefined by Update
+		require.Equal(t, dv, change.After)
+		require.Equal(t, "jupiter", change.Before.Name)
+		require.Nil(t, change.Before.UpdatedAt)
 	})
 	t.Run("update failed", func(t *testing.T) {
 		device.ID = primitive.NewObjectID()
-		_, err := repo.Update(ctx, &device)
+		_, err := repo.Update(ctx, &device, nil)
 		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+device.ID.Hex()+" could not be found: mongo: no documents in result")
 	})
 }
@@ -130,7 +805,7 @@ func Test_DeviceUpdateName(t *testing.T) {
 	require.NoError(t, repo.Create(ctx, &device))
 
 	t.Run("success update", func(t *testing.T) {
-		err := repo.UpdateName(ctx, device.ID, "mercurio")
+		_, err := repo.UpdateName(ctx, device.ID, "mercurio", nil)
 		require.NoError(t, err)
 
 		dv, err := repo.ByID(ctx, device.ID)
@@ -140,7 +815,7 @@ func Test_DeviceUpdateName(t *testing.T) {
 	})
 	t.Run("update failed", func(t *testing.T) {
 		device.ID = primitive.NewObjectID()
-		err := repo.UpdateName(ctx, device.ID, "saturno")
+		_, err := repo.UpdateName(ctx, device.ID, "saturno", nil)
 		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+device.ID.Hex()+" could not be found: mongo: no documents in result")
 	})
 }
@@ -157,7 +832,7 @@ func Test_DeviceUpdateBrand(t *testing.T) {
 	require.NoError(t, repo.Create(ctx, &device))
 
 	t.Run("success update", func(t *testing.T) {
-		err := repo.UpdateBrand(ctx, device.ID, "brand3")
+		_, err := repo.UpdateBrand(ctx, device.ID, "brand3", nil)
 		require.NoError(t, err)
 
 		dv, err := repo.ByID(ctx, device.ID)
@@ -166,12 +841,12 @@ func Test_DeviceUpdateBrand(t *testing.T) {
 		require.Equal(t, model.Brand("brand3"), dv.Brand)
 	})
 	t.Run("invalid brand", func(t *testing.T) {
-		err := repo.UpdateBrand(ctx, device.ID, "new brand")
+		_, err := repo.UpdateBrand(ctx, device.ID, "new brand", nil)
 		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'brand' is invalid 'invalid value'")
 	})
 	t.Run("update failed", func(t *testing.T) {
 		device.ID = primitive.NewObjectID()
-		err := repo.UpdateBrand(ctx, device.ID, "brand1")
+		_, err := repo.UpdateBrand(ctx, device.ID, "brand1", nil)
 		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+device.ID.Hex()+" could not be found: mongo: no documents in result")
 	})
 }
@@ -189,11 +864,11 @@ func Test_DeviceDelete(t *testing.T) {
 
 	t.Run("failed and success delete", func(t *testing.T) {
 		id := primitive.NewObjectID()
-		err := repo.Delete(ctx, id)
+		_, err := repo.Delete(ctx, id, nil)
 		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+id.Hex()+" could not be found: mongo: no documents in result")
 
 		id = device.ID
-		err = repo.Delete(ctx, id)
+		_, err = repo.Delete(ctx, id, nil)
 		require.NoError(t, err)
 
 		_, err = repo.ByID(ctx, id)
@@ -201,6 +876,235 @@ func Test_DeviceDelete(t *testing.T) {
 	})
 }
 
+func Test_DeviceTrash(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	deleted := model.Device{Name: "pluto", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &deleted))
+	kept := model.Device{Name: "mercury", Brand: "brand1"}
+	require.NoError(t, repo.Create(ctx, &kept))
+	_, err := repo.Delete(ctx, deleted.ID, nil)
+	require.NoError(t, err)
+
+	names := func(deleted bool) []string {
+		page, err := repo.List(ctx, model.DeviceSearch{Deleted: deleted})
+		require.NoError(t, err)
+		var names []string
+		for _, device := range page.Devices {
+			names = append(names, device.Name)
+		}
+		return names
+	}
+
+	t.Run("soft deleted devices are only listed in the trash", func(t *testing.T) {
+		require.Equal(t, []string{"mercury"}, names(false))
+		require.Equal(t, []string{"pluto"}, names(true))
+
+		page, err := repo.List(ctx, model.DeviceSearch{Deleted: true})
+		require.NoError(t, err)
+		require.NotNil(t, page.Devices[0].DeletedAt)
+		require.Equal(t, int64(2), page.Devices[0].Version)
+
+		count, err := repo.CountByBrand(ctx, "brand1")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+	})
+
+	t.Run("soft deleted devices cannot be changed", func(t *testing.T) {
+		_, err := repo.UpdateName(ctx, deleted.ID, "charon", nil)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+deleted.ID.Hex()+" could not be found: mongo: no documents in result")
+
+		_, err = repo.Delete(ctx, deleted.ID, nil)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+deleted.ID.Hex()+" could not be found: mongo: no documents in result")
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		_, err := repo.Restore(ctx, kept.ID, nil)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the deleted device with id "+kept.ID.Hex()+" could not be found")
+
+		_, err = repo.Restore(ctx, deleted.ID, model.VersionMatch{1})
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+deleted.ID.Hex()+" does not have the expected version")
+
+		change, err := repo.Restore(ctx, deleted.ID, model.VersionMatch{2})
+		require.NoError(t, err)
+		require.NotNil(t, change.Before.DeletedAt)
+		device := change.After
+		require.Equal(t, "pluto", device.Name)
+		require.Nil(t, device.DeletedAt)
+		require.NotNil(t, device.UpdatedAt)
+		require.Equal(t, int64(3), device.Version)
+
+		require.Equal(t, []string{"pluto", "mercury"}, names(false))
+		require.Empty(t, names(true))
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		_, err := repo.Delete(ctx, deleted.ID, nil)
+		require.NoError(t, err)
+		twins := DeviceTwinRepository{Collection: repo.Twins}
+		for _, device := range []model.Device{deleted, kept} {
+			_, err = repo.History.InsertOne(ctx, &model.DeviceRevision{DeviceID: device.ID, Revision: 1})
+			require.NoError(t, err)
+			_, err = repo.Idempotency.InsertOne(ctx, &model.IdempotentRequest{Key: device.Name, DeviceID: &device.ID})
+			require.NoError(t, err)
+			_, err = twins.SetDesired(ctx, device.ID, model.TwinProperties{"fan": "auto"}, nil)
+			require.NoError(t, err)
+		}
+		dependents := func(id primitive.ObjectID) int64 {
+			revisions, err := repo.History.CountDocuments(ctx, bson.M{"deviceId": id})
+			require.NoError(t, err)
+			keys, err := repo.Idempotency.CountDocuments(ctx, bson.M{"deviceId": id})
+			require.NoError(t, err)
+			twin, err := repo.Twins.CountDocuments(ctx, bson.M{"_id": id})
+			require.NoError(t, err)
+			return revisions + keys + twin
+		}
+
+		count, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(0), count)
+		require.Equal(t, int64(3), dependents(deleted.ID))
+
+		count, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		require.Empty(t, names(true))
+		require.Equal(t, []string{"mercury"}, names(false))
+		require.Equal(t, int64(0), dependents(deleted.ID))
+		require.Equal(t, int64(3), dependents(kept.ID))
+
+		twin, err := twins.ByDeviceID(ctx, deleted.ID)
+		require.NoError(t, err)
+		require.Empty(t, twin.Desired.Properties)
+
+		_, err = repo.Restore(ctx, deleted.ID, nil)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the deleted device with id "+deleted.ID.Hex()+" could not be found")
+	})
+}
+
+func Test_DeviceVersion(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	device := model.Device{
+		Name:  "jupiter",
+		Brand: "brand2",
+	}
+	require.NoError(t, repo.Create(ctx, &device))
+	require.Equal(t, int64(1), device.Version)
+
+	t.Run("changes increment the version", func(t *testing.T) {
+		_, err := repo.UpdateName(ctx, device.ID, "mercurio", model.VersionMatch{1})
+		require.NoError(t, err)
+		_, err = repo.UpdateBrand(ctx, device.ID, "brand3", nil)
+		require.NoError(t, err)
+		_, err = repo.Update(ctx, &model.Device{ID: device.ID, Name: "venus", Brand: "brand1"}, model.VersionMatch{2, 3})
+		require.NoError(t, err)
+
+		dv, err := repo.ByID(ctx, device.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), dv.Version)
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		_, err := repo.UpdateName(ctx, device.ID, "saturno", model.VersionMatch{1})
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+device.ID.Hex()+" does not have the expected version")
+		_, err = repo.UpdateBrand(ctx, device.ID, "brand1", model.VersionMatch{})
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+device.ID.Hex()+" does not have the expected version")
+		_, err = repo.Update(ctx, &model.Device{ID: device.ID, Name: "venus", Brand: "brand1"}, model.VersionMatch{3})
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+device.ID.Hex()+" does not have the expected version")
+		_, err = repo.Delete(ctx, device.ID, model.VersionMatch{3})
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+device.ID.Hex()+" does not have the expected version")
+
+		dv, err := repo.ByID(ctx, device.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), dv.Version)
+		require.Equal(t, "venus", dv.Name)
+	})
+
+	t.Run("not found with version", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		_, err := repo.UpdateName(ctx, id, "saturno", model.VersionMatch{1})
+		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+id.Hex()+" could not be found: mongo: no documents in result")
+	})
+
+	t.Run("delete with version", func(t *testing.T) {
+		_, err := repo.Delete(ctx, device.ID, model.VersionMatch{4})
+		require.NoError(t, err)
+	})
+
+	t.Run("device without version", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		_, err := repo.Collection.InsertOne(ctx, bson.M{"_id": id, "name": "pluto", "brand": "brand1", "createdAt": time.Now()})
+		require.NoError(t, err)
+
+		_, err = repo.UpdateName(ctx, id, "plutao", model.VersionMatch{0})
+		require.NoError(t, err)
+
+		dv, err := repo.ByID(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), dv.Version)
+	})
+}
+
+func Test_DevicePatch(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	device := model.Device{
+		Name:  "jupiter",
+		Brand: "brand2",
+	}
+	require.NoError(t, repo.Create(ctx, &device))
+
+	t.Run("ok", func(t *testing.T) {
+		change, err := repo.Patch(ctx, device.ID, model.VersionMatch{1}, func(dv *model.Device) error {
+			dv.Name = "europa"
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "jupiter", change.Before.Name)
+		require.Equal(t, int64(1), change.Before.Version)
+		require.Equal(t, "europa", change.After.Name)
+		require.Equal(t, int64(2), change.After.Version)
+
+		dv, err := repo.ByID(ctx, device.ID)
+		require.NoError(t, err)
+		require.Equal(t, change.After, dv)
+	})
+
+	t.Run("apply failed", func(t *testing.T) {
+		_, err := repo.Patch(ctx, device.ID, nil, func(dv *model.Device) error {
+			return errors.RequiredParameterError("brand", "body")
+		})
+		require.EqualError(t, err, "result: false; code: 1500001; message: parameter 'brand' in body is required")
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		_, err := repo.Patch(ctx, device.ID, model.VersionMatch{1}, func(dv *model.Device) error { return nil })
+		require.EqualError(t, err, "result: false; code: 1500014; message: the device with id "+device.ID.Hex()+" does not have the expected version")
+	})
+
+	t.Run("concurrent changes", func(t *testing.T) {
+		_, err := repo.Patch(ctx, device.ID, nil, func(dv *model.Device) error {
+			_, err := repo.UpdateName(ctx, dv.ID, dv.Name+"!", nil)
+			return err
+		})
+		require.EqualError(t, err, "result: false; code: 1500017; message: the device with id "+device.ID.Hex()+" is being changed concurrently")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		_, err := repo.Patch(ctx, id, nil, func(dv *model.Device) error { return nil })
+		require.EqualError(t, err, "result: false; code: 1500005; message: the device with id "+id.Hex()+" could not be found")
+	})
+}
+
 func Test_DeviceSearchByBrand(t *testing.T) {
 	ctx := context.Background()
 	repo, drop := NewTestDeviceRepo(t)
@@ -235,21 +1139,91 @@ func Test_DeviceSearchByBrand(t *testing.T) {
 	}
 
 	t.Run("invalid brand", func(t *testing.T) {
-		_, err := repo.ListByBrand(ctx, "new brand")
+		_, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Brands: []model.Brand{"new brand"}}})
 		require.EqualError(t, err, "result: false; code: 1500002; message: parameter 'brand' is invalid 'invalid value'")
 	})
 	t.Run("empty", func(t *testing.T) {
-		devices, err := repo.ListByBrand(ctx, "brand3")
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Brands: []model.Brand{"brand3"}}})
 		require.NoError(t, err)
-		require.Len(t, devices, 0)
+		require.Len(t, page.Devices, 0)
 	})
 	t.Run("success", func(t *testing.T) {
-		devices, err := repo.ListByBrand(ctx, "brand2")
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: model.DeviceFilter{Brands: []model.Brand{"brand2"}}, WithTotal: true})
 		require.NoError(t, err)
-		require.Len(t, devices, 3)
+		require.Len(t, page.Devices, 3)
+		require.Equal(t, int64(3), *page.Total)
 	})
 }
 
+func Test_DeviceSearchFilters(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	day := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := day.AddDate(0, 0, 3)
+	devices := []model.Device{
+		{Name: "Mercurio", Brand: "brand1", CreatedAt: day},
+		{Name: "marte", Brand: "brand2", CreatedAt: day.AddDate(0, 0, 1), UpdatedAt: &updatedAt},
+		{Name: "saturno", Brand: "brand2", CreatedAt: day.AddDate(0, 0, 2)},
+		{Name: "venus", Brand: "brand3", CreatedAt: day.AddDate(0, 0, 4)},
+	}
+	for i := range devices {
+		_, err := repo.Collection.InsertOne(ctx, devices[i])
+		require.NoError(t, err)
+	}
+
+	names := func(filter model.DeviceFilter) []string {
+		page, err := repo.List(ctx, model.DeviceSearch{DeviceFilter: filter})
+		require.NoError(t, err)
+		var names []string
+		for _, dv := range page.Devices {
+			names = append(names, dv.Name)
+		}
+		return names
+	}
+
+	t.Run("brands", func(t *testing.T) {
+		require.Equal(t, []string{"Mercurio", "venus"}, names(model.DeviceFilter{Brands: []model.Brand{"brand1", "brand3"}}))
+	})
+	t.Run("name", func(t *testing.T) {
+		require.Equal(t, []string{"marte"}, names(model.DeviceFilter{Name: &model.NameFilter{Match: model.NameMatchExact, Value: "marte"}}))
+		require.Equal(t, []string{"marte"}, names(model.DeviceFilter{Name: &model.NameFilter{Match: model.NameMatchPrefix, Value: "m"}}))
+		require.Equal(t, []string{"Mercurio", "marte"}, names(model.DeviceFilter{Name: &model.NameFilter{Match: model.NameMatchContains, Value: "M"}}))
+		require.Nil(t, names(model.DeviceFilter{Name: &model.NameFilter{Match: model.NameMatchContains, Value: ".*"}}))
+	})
+	t.Run("creation range", func(t *testing.T) {
+		from, to := day.AddDate(0, 0, 1), day.AddDate(0, 0, 4)
+		require.Equal(t, []string{"marte", "saturno"}, names(model.DeviceFilter{CreatedAt: model.TimeRange{Gte: &from, Lt: &to}}))
+	})
+	t.Run("update range", func(t *testing.T) {
+		from := day.AddDate(0, 0, 3)
+		require.Equal(t, []string{"marte"}, names(model.DeviceFilter{UpdatedAt: model.TimeRange{Lte: &from}}))
+	})
+	t.Run("updated since", func(t *testing.T) {
+		since := day.AddDate(0, 0, 3)
+		require.Equal(t, []string{"marte", "venus"}, names(model.DeviceFilter{UpdatedSince: &since}))
+	})
+}
+
+func Test_DeviceCountByBrand(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	for _, brand := range []model.Brand{"brand1", "brand2", "brand2"} {
+		require.NoError(t, repo.Create(ctx, &model.Device{Name: "io", Brand: brand}))
+	}
+
+	count, err := repo.CountByBrand(ctx, "brand2")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	count, err = repo.CountByBrand(ctx, "brand3")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), count)
+}
+
 func NewTestDeviceRepo(t *testing.T) (repo *DeviceRepository, drop func()) {
 	ctx := context.Background()
 	db := createTestDB(ctx, t)
@@ -260,5 +1234,9 @@ func NewTestDeviceRepo(t *testing.T) (repo *DeviceRepository, drop func()) {
 	return repo, func() {
 		_, err := repo.Collection.DeleteMany(ctx, bson.D{})
 		require.NoError(t, err)
+		_, err = repo.Telemetry.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+		_, err = repo.Twins.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
 	}
 }
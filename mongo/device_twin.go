@@ -0,0 +1,101 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeviceTwinCollectionName is the base name for the device twin collection
+const DeviceTwinCollectionName = "device_twin"
+
+// deviceTwinName is the name of a device twin in errors
+const deviceTwinName = "device twin"
+
+// Sides of the device twins, their fields in the twin documents
+const (
+	twinDesired  = "desired"
+	twinReported = "reported"
+)
+
+// DeviceTwinDB Device twin database, a twin per device whose id is the one of its device
+type DeviceTwinDB interface {
+	ByDeviceID(ctx context.Context, deviceID primitive.ObjectID) (*model.DeviceTwin, error)
+	SetDesired(ctx context.Context, deviceID primitive.ObjectID, properties model.TwinProperties, match model.VersionMatch) (*model.DeviceTwin, error)
+	SetReported(ctx context.Context, deviceID primitive.ObjectID, properties model.TwinProperties, match model.VersionMatch) (*model.DeviceTwin, error)
+}
+
+// DeviceTwinRepository repository
+type DeviceTwinRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewDeviceTwinDB creates new device twin collection, the twins being found by their _id only
+func NewDeviceTwinDB(ctx context.Context, db *mongo.Database) (*DeviceTwinRepository, error) {
+	return &DeviceTwinRepository{
+		Collection: db.Collection(DeviceTwinCollectionName, nil),
+	}, nil
+}
+
+// ByDeviceID gets the twin of a device, a twin with empty sides when none was set yet
+func (tr DeviceTwinRepository) ByDeviceID(ctx context.Context, deviceID primitive.ObjectID) (*model.DeviceTwin, error) {
+	twin := new(model.DeviceTwin)
+	err := tr.Collection.FindOne(ctx, bson.M{"_id": deviceID}).Decode(twin)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &model.DeviceTwin{DeviceID: deviceID}, nil
+		}
+		return nil, errors.ReadError(DeviceTwinCollectionName, err.Error())
+	}
+	return twin, nil
+}
+
+// SetDesired replaces the desired properties of the twin of a device when the version of its desired side matches
+func (tr DeviceTwinRepository) SetDesired(ctx context.Context, deviceID primitive.ObjectID, properties model.TwinProperties, match model.VersionMatch) (*model.DeviceTwin, error) {
+	return tr.set(ctx, deviceID, twinDesired, properties, match)
+}
+
+// SetReported replaces the reported properties of the twin of a device when the version of its reported side matches
+func (tr DeviceTwinRepository) SetReported(ctx context.Context, deviceID primitive.ObjectID, properties model.TwinProperties, match model.VersionMatch) (*model.DeviceTwin, error) {
+	return tr.set(ctx, deviceID, twinReported, properties, match)
+}
+
+// set replaces the properties of a side of the twin of a device and increments the version of the side, when it
+// matches. A side never set has the version 0, the twin being created when a match of version 0 changes it.
+func (tr DeviceTwinRepository) set(ctx context.Context, deviceID primitive.ObjectID, side string, properties model.TwinProperties, match model.VersionMatch) (*model.DeviceTwin, error) {
+	filter := bson.M{"_id": deviceID}
+	if !match.Any() {
+		versions := bson.M{side + ".version": bson.M{"$in": match}}
+		if match.Matches(0) {
+			filter["$or"] = bson.A{versions, bson.M{side + ".version": bson.M{"$exists": false}}}
+		} else {
+			filter[side+".version"] = versions[side+".version"]
+		}
+	}
+	if properties == nil {
+		properties = model.TwinProperties{}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	// the upsert fails with a duplicate key when the twin exists with another version
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetUpsert(match.Matches(0))
+	twin := new(model.DeviceTwin)
+	err := tr.Collection.FindOneAndUpdate(ctx, filter,
+		bson.M{
+			"$set": bson.M{side + ".properties": properties, side + ".updatedAt": &now},
+			"$inc": bson.M{side + ".version": 1},
+		}, opts).Decode(twin)
+	if err != nil {
+		if err == mongo.ErrNoDocuments || mongo.IsDuplicateKeyError(err) {
+			return nil, errors.VersionMismatchError(deviceTwinName+" "+side+" side", deviceID.Hex())
+		}
+		return nil, errors.UpdateError(DeviceTwinCollectionName, err.Error())
+	}
+	return twin, nil
+}
@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_DeviceTwin(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := CreateDeviceTwinTestRepo(ctx, t)
+	defer drop()
+
+	deviceID := primitive.NewObjectID()
+
+	t.Run("twin never set", func(t *testing.T) {
+		twin, err := repo.ByDeviceID(ctx, deviceID)
+		require.NoError(t, err)
+		require.Equal(t, &model.DeviceTwin{DeviceID: deviceID}, twin)
+	})
+
+	t.Run("set desired then reported", func(t *testing.T) {
+		desired := model.TwinProperties{"interval": float64(60), "led": map[string]interface{}{"on": true}}
+		twin, err := repo.SetDesired(ctx, deviceID, desired, model.VersionMatch{0})
+		require.NoError(t, err)
+		require.Equal(t, desired, twin.Desired.Properties)
+		require.Equal(t, int64(1), twin.Desired.Version)
+		require.NotNil(t, twin.Desired.UpdatedAt)
+		require.Zero(t, twin.Reported.Version)
+
+		reported := model.TwinProperties{"interval": float64(60), "led": map[string]interface{}{"on": false}}
+		twin, err = repo.SetReported(ctx, deviceID, reported, nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), twin.Reported.Version)
+		require.Equal(t, int64(1), twin.Desired.Version)
+
+		twin, err = repo.ByDeviceID(ctx, deviceID)
+		require.NoError(t, err)
+		require.Equal(t, reported, twin.Reported.Properties)
+		require.Equal(t, model.TwinProperties{"led": map[string]interface{}{"on": true}}, twin.Delta())
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		_, err := repo.SetDesired(ctx, deviceID, model.TwinProperties{}, model.VersionMatch{0})
+		require.Equal(t, errors.VersionMismatchError("device twin desired side", deviceID.Hex()), err)
+
+		otherID := primitive.NewObjectID()
+		_, err = repo.SetDesired(ctx, otherID, model.TwinProperties{}, model.VersionMatch{1})
+		require.Equal(t, errors.VersionMismatchError("device twin desired side", otherID.Hex()), err)
+
+		twin, err := repo.SetDesired(ctx, deviceID, model.TwinProperties{}, model.VersionMatch{1})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), twin.Desired.Version)
+		require.Empty(t, twin.Desired.Properties)
+	})
+}
@@ -0,0 +1,95 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_unreachableStorageErrors(t *testing.T) {
+	ctx := context.Background()
+	deviceRepo, historyRepo, brandRepo, jobRepo, idempotencyRepo, twinRepo := CreateUnreachableTestRepos(ctx, t)
+	id := primitive.NewObjectID()
+
+	t.Run("device", func(t *testing.T) {
+		err := deviceRepo.Create(ctx, &model.Device{Name: "io", Brand: "brand1"})
+		require.EqualError(t, err, "result: false; code: 1500003; message: error creating device reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+
+		_, err = deviceRepo.ByID(ctx, id)
+		require.EqualError(t, err, "result: false; code: 1500011; message: error reading device reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+
+		_, err = deviceRepo.List(ctx, model.DeviceSearch{})
+		require.EqualError(t, err, "result: false; code: 1500004; message: the device queried by ALL returned an error: client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+
+		items := []*model.BulkItem{{Device: &model.Device{Name: "io", Brand: "brand1"}}, {Device: &model.Device{Name: "europa", Brand: "brand1"}}}
+		deviceRepo.CreateMany(ctx, items, false)
+		for _, item := range items {
+			require.Equal(t, model.BulkFailed, item.Status)
+			require.EqualError(t, item.Err, "result: false; code: 1500003; message: error creating device reason client is disconnected")
+		}
+
+		_, err = deviceRepo.Delete(ctx, id, nil)
+		require.EqualError(t, err, "result: false; code: 1500007; message: error deleting device reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+	})
+
+	t.Run("device history", func(t *testing.T) {
+		err := historyRepo.Append(ctx, &model.DeviceRevision{DeviceID: id, Revision: 1})
+		require.EqualError(t, err, "result: false; code: 1500003; message: error creating device_history reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+
+		_, err = historyRepo.LastAt(ctx, id, time.Now())
+		require.EqualError(t, err, "result: false; code: 1500011; message: error reading device_history reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+	})
+
+	t.Run("bulk job", func(t *testing.T) {
+		err := jobRepo.Create(ctx, &model.BulkJob{Action: model.BulkAction{Type: model.BulkActionDelete}})
+		require.EqualError(t, err, "result: false; code: 1500003; message: error creating device_jobs reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+
+		_, err = jobRepo.Claim(ctx, time.Now())
+		require.EqualError(t, err, "result: false; code: 1500006; message: error updating device_jobs reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+	})
+
+	t.Run("idempotency key", func(t *testing.T) {
+		_, err := idempotencyRepo.Reserve(ctx, &model.IdempotentRequest{Key: "key1", CreatedAt: time.Now()})
+		require.EqualError(t, err, "result: false; code: 1500003; message: error creating device_idempotency_keys reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+
+		err = idempotencyRepo.Release(ctx, "key1")
+		require.EqualError(t, err, "result: false; code: 1500007; message: error deleting device_idempotency_keys reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+	})
+
+	t.Run("device twin", func(t *testing.T) {
+		_, err := twinRepo.ByDeviceID(ctx, id)
+		require.EqualError(t, err, "result: false; code: 1500011; message: error reading device_twin reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+
+		_, err = twinRepo.SetDesired(ctx, id, model.TwinProperties{"interval": 60.0}, nil)
+		require.EqualError(t, err, "result: false; code: 1500006; message: error updating device_twin reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+	})
+
+	t.Run("brand", func(t *testing.T) {
+		_, err := brandRepo.ByName(ctx, "brand1")
+		require.EqualError(t, err, "result: false; code: 1500011; message: error reading brand reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+
+		err = brandRepo.Update(ctx, &model.BrandInfo{Name: "brand1"})
+		require.EqualError(t, err, "result: false; code: 1500006; message: error updating brand reason client is disconnected")
+		require.Equal(t, errors.KindStorage, errors.KindOf(err))
+
+		require.False(t, brandRepo.Exists("brand1"))
+	})
+}
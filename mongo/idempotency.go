@@ -0,0 +1,120 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IdempotencyCollectionName is the base name for the idempotency key collection
+const IdempotencyCollectionName = "device_idempotency_keys"
+
+// idempotencyKeyName is the name of an idempotency key in errors
+const idempotencyKeyName = "idempotency key"
+
+// IdempotencyDB Idempotency key database
+type IdempotencyDB interface {
+	Reserve(ctx context.Context, req *model.IdempotentRequest) (*model.IdempotentRequest, error)
+	Complete(ctx context.Context, key string, status int, contentType string, body []byte, deviceID *primitive.ObjectID) error
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyRepository repository
+type IdempotencyRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewIdempotencyDB creates new idempotency key collection, whose keys are removed by MongoDB once expired
+func NewIdempotencyDB(ctx context.Context, db *mongo.Database) (*IdempotencyRepository, error) {
+	Collection := db.Collection(IdempotencyCollectionName, nil)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			// the keys are purged with the devices their requests created
+			Keys:    bson.D{{Key: "deviceId", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+
+	_, err := Collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IdempotencyRepository{
+		Collection: Collection,
+	}, nil
+}
+
+// Reserve saves a request not completed yet for its key, unless the key is taken by another request,
+// which is returned. A key is free again once expired, or once its request is abandoned, not completed
+// before its lock.
+// The expired keys are checked too since MongoDB only removes them every minute.
+func (ir IdempotencyRepository) Reserve(ctx context.Context, req *model.IdempotentRequest) (*model.IdempotentRequest, error) {
+	filter := bson.M{
+		"_id": req.Key,
+		"$or": bson.A{
+			bson.M{"expiresAt": bson.M{"$lte": req.CreatedAt}},
+			bson.M{"completed": false, "lockedUntil": bson.M{"$lte": req.CreatedAt}},
+		},
+	}
+	// the upsert fails with a duplicate key when the key is taken
+	_, err := ir.Collection.ReplaceOne(ctx, filter, req, options.Replace().SetUpsert(true))
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, errors.CreateError(IdempotencyCollectionName, err.Error())
+	}
+
+	taken := new(model.IdempotentRequest)
+	err = ir.Collection.FindOne(ctx, bson.M{"_id": req.Key}).Decode(taken)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			// the key expired meanwhile
+			return nil, errors.CreateError(IdempotencyCollectionName, err.Error())
+		}
+		return nil, errors.ReadError(IdempotencyCollectionName, err.Error())
+	}
+	return taken, nil
+}
+
+// Complete saves the response of the request of a key, and the device the request created if any
+func (ir IdempotencyRepository) Complete(ctx context.Context, key string, status int, contentType string, body []byte, deviceID *primitive.ObjectID) error {
+	set := bson.M{
+		"completed":   true,
+		"status":      status,
+		"contentType": contentType,
+		"body":        body,
+	}
+	if deviceID != nil {
+		set["deviceId"] = deviceID
+	}
+	update := bson.M{"$set": set}
+	res, err := ir.Collection.UpdateOne(ctx, bson.M{"_id": key, "completed": false}, update)
+	if err != nil {
+		return errors.UpdateError(IdempotencyCollectionName, err.Error())
+	}
+	if res.MatchedCount == 0 {
+		return errors.CouldNotFindObject(idempotencyKeyName, key)
+	}
+	return nil
+}
+
+// Release removes a key whose request is not completed, so that the request can be sent again with it
+func (ir IdempotencyRepository) Release(ctx context.Context, key string) error {
+	_, err := ir.Collection.DeleteOne(ctx, bson.M{"_id": key, "completed": false})
+	if err != nil {
+		return errors.DeleteError(IdempotencyCollectionName, err.Error())
+	}
+	return nil
+}
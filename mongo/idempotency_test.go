@@ -0,0 +1,76 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_Idempotency(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := CreateIdempotencyTestRepo(ctx, t)
+	defer drop()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	newRequest := func(key string, at time.Time) *model.IdempotentRequest {
+		return &model.IdempotentRequest{
+			Key:         key,
+			RequestHash: "hash1",
+			CreatedAt:   at,
+			LockedUntil: at.Add(time.Minute),
+			ExpiresAt:   at.Add(24 * time.Hour),
+		}
+	}
+
+	t.Run("reserve, complete and replay", func(t *testing.T) {
+		taken, err := repo.Reserve(ctx, newRequest("key1", now))
+		require.NoError(t, err)
+		require.Nil(t, taken)
+
+		taken, err = repo.Reserve(ctx, newRequest("key1", now))
+		require.NoError(t, err)
+		require.False(t, taken.Completed)
+
+		deviceID := primitive.NewObjectID()
+		require.NoError(t, repo.Complete(ctx, "key1", 201, "application/json", []byte(`{"id":"1"}`), &deviceID))
+		taken, err = repo.Reserve(ctx, newRequest("key1", now))
+		require.NoError(t, err)
+		require.True(t, taken.Completed)
+		require.Equal(t, 201, taken.Status)
+		require.Equal(t, "application/json", taken.ContentType)
+		require.Equal(t, []byte(`{"id":"1"}`), taken.Body)
+		require.Equal(t, &deviceID, taken.DeviceID)
+
+		err = repo.Complete(ctx, "key1", 201, "application/json", nil, nil)
+		require.EqualError(t, err, "result: false; code: 1500005; message: the idempotency key with id key1 could not be found")
+	})
+
+	t.Run("released key is free", func(t *testing.T) {
+		_, err := repo.Reserve(ctx, newRequest("key2", now))
+		require.NoError(t, err)
+		require.NoError(t, repo.Release(ctx, "key2"))
+		taken, err := repo.Reserve(ctx, newRequest("key2", now))
+		require.NoError(t, err)
+		require.Nil(t, taken)
+	})
+
+	t.Run("abandoned or expired key is free", func(t *testing.T) {
+		_, err := repo.Reserve(ctx, newRequest("key3", now))
+		require.NoError(t, err)
+		taken, err := repo.Reserve(ctx, newRequest("key3", now.Add(2*time.Minute)))
+		require.NoError(t, err)
+		require.Nil(t, taken)
+
+		require.NoError(t, repo.Complete(ctx, "key3", 201, "application/json", nil, nil))
+		taken, err = repo.Reserve(ctx, newRequest("key3", now.Add(time.Hour)))
+		require.NoError(t, err)
+		require.True(t, taken.Completed)
+		taken, err = repo.Reserve(ctx, newRequest("key3", now.Add(25*time.Hour)))
+		require.NoError(t, err)
+		require.Nil(t, taken)
+	})
+}
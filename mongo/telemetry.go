@@ -0,0 +1,167 @@
+package mongo
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/device-ms/errors"
+	"github.com/device-ms/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// TelemetryCollectionName is the name of the time-series collection of the telemetry samples
+	TelemetryCollectionName = "device_telemetry"
+	envTelemetryRetention   = "TELEMETRY_RETENTION"
+	// defaultTelemetryRetention keeps the telemetry samples for 30 days
+	defaultTelemetryRetention = 30 * 24 * time.Hour
+	// namespaceExistsCode is the code of the error creating a collection that already exists
+	namespaceExistsCode = 48
+)
+
+// telemetryRetentionFromEnv reads from the environment how long the telemetry samples are kept, 0 keeping them forever
+func telemetryRetentionFromEnv() (time.Duration, error) {
+	value := os.Getenv(envTelemetryRetention)
+	if value == "" {
+		return defaultTelemetryRetention, nil
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s [%s]: %w", envTelemetryRetention, value, err)
+	}
+	if retention < 0 || retention%time.Second != 0 {
+		return 0, fmt.Errorf("invalid %s [%s]: must be a non negative whole number of seconds", envTelemetryRetention, value)
+	}
+	return retention, nil
+}
+
+// newTelemetryCollection creates the time-series collection of the telemetry samples, the samples expiring after
+// the retention. When the collection already exists, its retention is updated.
+func newTelemetryCollection(ctx context.Context, db *mongo.Database) (*mongo.Collection, error) {
+	retention, err := telemetryRetentionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.CreateCollection().SetTimeSeriesOptions(
+		options.TimeSeries().SetTimeField("at").SetMetaField("deviceId").SetGranularity("seconds"))
+	var expireAfter interface{} = "off"
+	if retention > 0 {
+		opts = opts.SetExpireAfterSeconds(int64(retention / time.Second))
+		expireAfter = int64(retention / time.Second)
+	}
+	err = db.CreateCollection(ctx, TelemetryCollectionName, opts)
+	var cmdErr mongo.CommandError
+	if stderrors.As(err, &cmdErr) && cmdErr.HasErrorCode(namespaceExistsCode) {
+		err = db.RunCommand(ctx, bson.D{{Key: "collMod", Value: TelemetryCollectionName}, {Key: "expireAfterSeconds", Value: expireAfter}}).Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	collection := db.Collection(TelemetryCollectionName)
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		// the series of a device are read by time
+		Keys:    bson.D{{Key: "deviceId", Value: 1}, {Key: "at", Value: 1}},
+		Options: options.Index(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// AddTelemetry saves telemetry samples
+func (dr DeviceRepository) AddTelemetry(ctx context.Context, samples []model.TelemetrySample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, len(samples))
+	for i := range samples {
+		documents[i] = samples[i]
+	}
+	_, err := dr.Telemetry.InsertMany(ctx, documents)
+	if err != nil {
+		return errors.CreateError(TelemetryCollectionName, err.Error())
+	}
+	return nil
+}
+
+// TelemetrySeries gets the samples of a device selected by a query, downsampled when the query has a bucket.
+// One point more than MaxTelemetryPoints is read to know if the series is truncated.
+func (dr DeviceRepository) TelemetrySeries(ctx context.Context, query model.TelemetryQuery) (*model.TelemetrySeries, error) {
+	filter := bson.M{"deviceId": query.DeviceID, "at": bson.M{"$gte": query.From, "$lt": query.To}}
+	if len(query.Metrics) > 0 {
+		exists := make(bson.A, len(query.Metrics))
+		for i, name := range query.Metrics {
+			exists[i] = bson.M{"metrics." + name: bson.M{"$exists": true}}
+		}
+		filter["$or"] = exists
+	}
+
+	var cur *mongo.Cursor
+	var err error
+	if query.Bucket == 0 {
+		projection := bson.M{"_id": 0, "at": 1, "metrics": 1}
+		if len(query.Metrics) > 0 {
+			projection = bson.M{"_id": 0, "at": 1}
+			for _, name := range query.Metrics {
+				projection["metrics."+name] = 1
+			}
+		}
+		opts := options.Find().SetSort(bson.D{{Key: "at", Value: 1}}).SetLimit(model.MaxTelemetryPoints + 1).SetProjection(projection)
+		cur, err = dr.Telemetry.Find(ctx, filter, opts)
+	} else {
+		cur, err = dr.Telemetry.Aggregate(ctx, telemetryBucketPipeline(filter, query))
+	}
+	if err != nil {
+		return nil, errors.ListError(TelemetryCollectionName, err, "deviceId", query.DeviceID.Hex())
+	}
+
+	series := &model.TelemetrySeries{Points: make([]model.TelemetryPoint, 0)}
+	err = cur.All(ctx, &series.Points)
+	if err != nil {
+		return nil, errors.ListError(TelemetryCollectionName, err, "deviceId", query.DeviceID.Hex())
+	}
+
+	if len(series.Points) > model.MaxTelemetryPoints {
+		series.Points = series.Points[:model.MaxTelemetryPoints]
+		series.Truncated = true
+	}
+	return series, nil
+}
+
+// telemetryBucketPipeline aggregates every metric of the samples matching filter per bucket, the bucket of a sample
+// starting at its time rounded down to a multiple of the bucket duration since the Unix epoch
+func telemetryBucketPipeline(filter bson.M, query model.TelemetryQuery) mongo.Pipeline {
+	bucket := query.Bucket.Milliseconds()
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$project", Value: bson.M{
+			"at":      bson.M{"$subtract": bson.A{"$at", bson.M{"$mod": bson.A{bson.M{"$toLong": "$at"}, bucket}}}},
+			"metrics": bson.M{"$objectToArray": "$metrics"},
+		}}},
+		{{Key: "$unwind", Value: "$metrics"}},
+	}
+	if len(query.Metrics) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"metrics.k": bson.M{"$in": query.Metrics}}}})
+	}
+	return append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"at": "$at", "metric": "$metrics.k"},
+			"value": bson.M{"$" + string(query.Aggregate): "$metrics.v"},
+		}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":     "$_id.at",
+			"metrics": bson.M{"$push": bson.M{"k": "$_id.metric", "v": "$value"}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{"_id": 0, "at": "$_id", "metrics": bson.M{"$arrayToObject": "$metrics"}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "at", Value: 1}}}},
+		bson.D{{Key: "$limit", Value: model.MaxTelemetryPoints + 1}},
+	)
+}
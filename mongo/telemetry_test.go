@@ -0,0 +1,108 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/device-ms/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_telemetryRetentionFromEnv(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv(envTelemetryRetention, "")
+		retention, err := telemetryRetentionFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, 30*24*time.Hour, retention)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		t.Setenv(envTelemetryRetention, "168h")
+		retention, err := telemetryRetentionFromEnv()
+		require.NoError(t, err)
+		require.Equal(t, 7*24*time.Hour, retention)
+
+		t.Setenv(envTelemetryRetention, "0")
+		retention, err = telemetryRetentionFromEnv()
+		require.NoError(t, err)
+		require.Zero(t, retention)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv(envTelemetryRetention, "a week")
+		_, err := telemetryRetentionFromEnv()
+		require.EqualError(t, err, "invalid TELEMETRY_RETENTION [a week]: time: invalid duration \"a week\"")
+
+		t.Setenv(envTelemetryRetention, "1500ms")
+		_, err = telemetryRetentionFromEnv()
+		require.EqualError(t, err, "invalid TELEMETRY_RETENTION [1500ms]: must be a non negative whole number of seconds")
+	})
+}
+
+func Test_DeviceTelemetry(t *testing.T) {
+	ctx := context.Background()
+	repo, drop := NewTestDeviceRepo(t)
+	defer drop()
+
+	deviceID := primitive.NewObjectID()
+	start := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	samples := []model.TelemetrySample{
+		{DeviceID: deviceID, At: start, Metrics: map[string]float64{"temperature": 20, "battery": 90}},
+		{DeviceID: deviceID, At: start.Add(30 * time.Second), Metrics: map[string]float64{"temperature": 22}},
+		{DeviceID: deviceID, At: start.Add(time.Minute), Metrics: map[string]float64{"temperature": 25, "battery": 88}},
+		{DeviceID: primitive.NewObjectID(), At: start, Metrics: map[string]float64{"temperature": 99}},
+	}
+	require.NoError(t, repo.AddTelemetry(ctx, samples))
+
+	t.Run("raw samples", func(t *testing.T) {
+		series, err := repo.TelemetrySeries(ctx, model.TelemetryQuery{DeviceID: deviceID, From: start, To: start.Add(time.Minute)})
+		require.NoError(t, err)
+		require.Equal(t, []model.TelemetryPoint{
+			{At: start, Metrics: map[string]float64{"temperature": 20, "battery": 90}},
+			{At: start.Add(30 * time.Second), Metrics: map[string]float64{"temperature": 22}},
+		}, series.Points)
+		require.False(t, series.Truncated)
+	})
+
+	t.Run("raw samples of a metric", func(t *testing.T) {
+		series, err := repo.TelemetrySeries(ctx, model.TelemetryQuery{
+			DeviceID: deviceID, From: start, To: start.Add(time.Hour), Metrics: []string{"battery"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []model.TelemetryPoint{
+			{At: start, Metrics: map[string]float64{"battery": 90}},
+			{At: start.Add(time.Minute), Metrics: map[string]float64{"battery": 88}},
+		}, series.Points)
+	})
+
+	t.Run("downsampled", func(t *testing.T) {
+		query := model.TelemetryQuery{DeviceID: deviceID, From: start, To: start.Add(time.Hour), Bucket: time.Minute, Aggregate: model.TelemetryAverage}
+		series, err := repo.TelemetrySeries(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, []model.TelemetryPoint{
+			{At: start, Metrics: map[string]float64{"temperature": 21, "battery": 90}},
+			{At: start.Add(time.Minute), Metrics: map[string]float64{"temperature": 25, "battery": 88}},
+		}, series.Points)
+
+		query.Bucket, query.Aggregate, query.Metrics = time.Hour, model.TelemetryMaximum, []string{"temperature"}
+		series, err = repo.TelemetrySeries(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, []model.TelemetryPoint{{At: start, Metrics: map[string]float64{"temperature": 25}}}, series.Points)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		many := make([]model.TelemetrySample, model.MaxTelemetryPoints+1)
+		for i := range many {
+			many[i] = model.TelemetrySample{DeviceID: deviceID, At: start.Add(-time.Duration(i+1) * time.Second), Metrics: map[string]float64{"battery": 100}}
+		}
+		require.NoError(t, repo.AddTelemetry(ctx, many))
+
+		series, err := repo.TelemetrySeries(ctx, model.TelemetryQuery{DeviceID: deviceID, From: start.Add(-time.Hour), To: start})
+		require.NoError(t, err)
+		require.Len(t, series.Points, model.MaxTelemetryPoints)
+		require.True(t, series.Truncated)
+		require.Equal(t, start.Add(-time.Duration(model.MaxTelemetryPoints+1)*time.Second), series.Points[0].At)
+	})
+}
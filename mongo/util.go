@@ -61,6 +61,51 @@ func CreateDeviceRepo(ctx context.Context) (*DeviceRepository, error) {
 	return NewDeviceDB(ctx, db)
 }
 
+// CreateDeviceHistoryRepo creates a device history repository
+func CreateDeviceHistoryRepo(ctx context.Context) (*DeviceHistoryRepository, error) {
+	db, err := createDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewDeviceHistoryDB(ctx, db)
+}
+
+// CreateBulkJobRepo creates a bulk job repository
+func CreateBulkJobRepo(ctx context.Context) (*BulkJobRepository, error) {
+	db, err := createDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewBulkJobDB(ctx, db)
+}
+
+// CreateIdempotencyRepo creates an idempotency key repository
+func CreateIdempotencyRepo(ctx context.Context) (*IdempotencyRepository, error) {
+	db, err := createDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewIdempotencyDB(ctx, db)
+}
+
+// CreateDeviceTwinRepo creates a device twin repository
+func CreateDeviceTwinRepo(ctx context.Context) (*DeviceTwinRepository, error) {
+	db, err := createDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewDeviceTwinDB(ctx, db)
+}
+
+// CreateBrandRepo creates a brand repository
+func CreateBrandRepo(ctx context.Context) (*BrandRepository, error) {
+	db, err := createDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewBrandDB(ctx, db)
+}
+
 // CreatDeviceTestRepo creates a device test repository
 func CreateDeviceTestRepo(ctx context.Context, t *testing.T) (repo *DeviceRepository, drop func()) {
 	db = createTestDB(ctx, t)
@@ -71,10 +116,108 @@ func CreateDeviceTestRepo(ctx context.Context, t *testing.T) (repo *DeviceReposi
 	drop = func() {
 		_, err := repo.Collection.DeleteMany(ctx, bson.D{})
 		require.NoError(t, err)
+		_, err = repo.Telemetry.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+		_, err = repo.History.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+		_, err = repo.Idempotency.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+		_, err = repo.Twins.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
 	}
 	return
 }
 
+// CreateDeviceHistoryTestRepo creates a device history test repository
+func CreateDeviceHistoryTestRepo(ctx context.Context, t *testing.T) (repo *DeviceHistoryRepository, drop func()) {
+	db = createTestDB(ctx, t)
+
+	repo, err := NewDeviceHistoryDB(ctx, db)
+	require.NoError(t, err)
+
+	drop = func() {
+		_, err := repo.Collection.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+	}
+	return
+}
+
+// CreateBulkJobTestRepo creates a bulk job test repository
+func CreateBulkJobTestRepo(ctx context.Context, t *testing.T) (repo *BulkJobRepository, drop func()) {
+	db = createTestDB(ctx, t)
+
+	repo, err := NewBulkJobDB(ctx, db)
+	require.NoError(t, err)
+
+	drop = func() {
+		_, err := repo.Collection.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+	}
+	return
+}
+
+// CreateIdempotencyTestRepo creates an idempotency key test repository
+func CreateIdempotencyTestRepo(ctx context.Context, t *testing.T) (repo *IdempotencyRepository, drop func()) {
+	db = createTestDB(ctx, t)
+
+	repo, err := NewIdempotencyDB(ctx, db)
+	require.NoError(t, err)
+
+	drop = func() {
+		_, err := repo.Collection.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+	}
+	return
+}
+
+// CreateDeviceTwinTestRepo creates a device twin test repository
+func CreateDeviceTwinTestRepo(ctx context.Context, t *testing.T) (repo *DeviceTwinRepository, drop func()) {
+	db = createTestDB(ctx, t)
+
+	repo, err := NewDeviceTwinDB(ctx, db)
+	require.NoError(t, err)
+
+	drop = func() {
+		_, err := repo.Collection.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+	}
+	return
+}
+
+// CreateBrandTestRepo creates a brand test repository.
+// drop removes all brands and inserts the default ones again.
+func CreateBrandTestRepo(ctx context.Context, t *testing.T) (repo *BrandRepository, drop func()) {
+	db = createTestDB(ctx, t)
+
+	repo, err := NewBrandDB(ctx, db)
+	require.NoError(t, err)
+
+	drop = func() {
+		_, err := repo.Collection.DeleteMany(ctx, bson.D{})
+		require.NoError(t, err)
+		require.NoError(t, repo.insertDefaultBrands(ctx))
+	}
+	return
+}
+
+// CreateUnreachableTestRepos creates device, device history, brand, bulk job, idempotency key and device twin test
+// repositories whose database client is disconnected, so every database operation fails
+func CreateUnreachableTestRepos(ctx context.Context, t *testing.T) (*DeviceRepository, *DeviceHistoryRepository, *BrandRepository, *BulkJobRepository, *IdempotencyRepository, *DeviceTwinRepository) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017/"))
+	require.NoError(t, err)
+	require.NoError(t, client.Disconnect(ctx))
+
+	db := client.Database("device-test")
+	return &DeviceRepository{Collection: db.Collection(DeviceCollectionName), Telemetry: db.Collection(TelemetryCollectionName),
+			History: db.Collection(DeviceHistoryCollectionName), Idempotency: db.Collection(IdempotencyCollectionName),
+			Twins: db.Collection(DeviceTwinCollectionName)},
+		&DeviceHistoryRepository{Collection: db.Collection(DeviceHistoryCollectionName)},
+		&BrandRepository{Collection: db.Collection(BrandCollectionName), cache: new(brandCache)},
+		&BulkJobRepository{Collection: db.Collection(BulkJobCollectionName)},
+		&IdempotencyRepository{Collection: db.Collection(IdempotencyCollectionName)},
+		&DeviceTwinRepository{Collection: db.Collection(DeviceTwinCollectionName)}
+}
+
 func initDB(ctx context.Context, name, mongoURI string) (*mongo.Database, error) {
 	nrMon := nrmongo.NewCommandMonitor(nil)
 	opts := options.Client().ApplyURI(mongoURI).SetAppName(name)
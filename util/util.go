@@ -4,38 +4,158 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"mime"
 	"net/http"
+	"strings"
 
 	"github.com/device-ms/errors"
 )
 
+// ProblemJSONContentType is the media type of RFC 7807 problem details responses
+const ProblemJSONContentType = "application/problem+json"
+
 type resultError struct {
-	Result  bool                   `json:"result"`
-	Code    int64                  `json:"code"`
-	Details string                 `json:"message"`
-	Meta    map[string]interface{} `json:"meta,omitempty"`
+	Result  bool         `json:"result"`
+	Code    int64        `json:"code"`
+	Details string       `json:"message"`
+	Errors  []fieldError `json:"errors,omitempty"`
+}
+
+// problemDetails is the RFC 7807 error response, extended with the error code and the invalid fields
+type problemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail"`
+	Instance string       `json:"instance,omitempty"`
+	Code     int64        `json:"code"`
+	Errors   []fieldError `json:"errors,omitempty"`
+}
+
+type fieldError struct {
+	Field   string `json:"field"`
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+type requestInfoKey struct{}
+
+// requestInfo is what the error responses need to know about the request being served
+type requestInfo struct {
+	path           string
+	problemDetails bool
+}
+
+// WithRequestInfo is a middleware keeping in the request context what JSONErrorWithCtx needs to build
+// the error response: the request path and whether the client accepts problem details
+func WithRequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := requestInfo{
+			path:           r.URL.Path,
+			problemDetails: Accepts(r, ProblemJSONContentType),
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+	})
+}
+
+// Accepts tells if the media type is explicitly one of the media types accepted by the request
+func Accepts(r *http.Request, mediaType string) bool {
+	for _, header := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(header, ",") {
+			accepted, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err == nil && accepted == mediaType && params["q"] != "0" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func buildFieldErrors(custErr errors.CustError) []fieldError {
+	var fieldErrors []fieldError
+	for _, fieldErr := range custErr.FieldErrors() {
+		fieldErrors = append(fieldErrors, fieldError{
+			Field:   fieldErr.Field,
+			Code:    fieldErr.Code,
+			Message: fieldErr.Message,
+		})
+	}
+	return fieldErrors
 }
 
 func buildResultError(err error) resultError {
-	custErr := err.(errors.CustError)
-	return resultError{
+	custErr := errors.Wrap(err)
+	res := resultError{
 		Result:  false,
 		Code:    custErr.Code,
 		Details: custErr.Message,
 	}
+	// a single invalid field is already described by the message
+	if len(custErr.Details) > 0 {
+		res.Errors = buildFieldErrors(custErr)
+	}
+	return res
+}
+
+func buildProblemDetails(err error, status int, instance string) problemDetails {
+	custErr := errors.Wrap(err)
+	return problemDetails{
+		Type:     custErr.TypeURI(),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   custErr.Message,
+		Instance: instance,
+		Code:     custErr.Code,
+		Errors:   buildFieldErrors(custErr),
+	}
+}
+
+var httpStatusesByKind = map[errors.Kind]int{
+	errors.KindValidation:           http.StatusBadRequest,
+	errors.KindNotFound:             http.StatusNotFound,
+	errors.KindConflict:             http.StatusConflict,
+	errors.KindStorage:              http.StatusInternalServerError,
+	errors.KindInternal:             http.StatusInternalServerError,
+	errors.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	errors.KindPreconditionRequired: http.StatusPreconditionRequired,
+	errors.KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	errors.KindUnprocessable:        http.StatusUnprocessableEntity,
+	errors.KindUnauthorized:         http.StatusUnauthorized,
+}
+
+// HTTPStatus returns the HTTP status code reporting an error, based on its kind
+func HTTPStatus(err error) int {
+	if status, ok := httpStatusesByKind[errors.KindOf(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
 }
 
-// JSONErrorWithCtx builds and returns the error response while also adding it as an attribute to the New Relic transaction
-func JSONErrorWithCtx(ctx context.Context, w http.ResponseWriter, err error, httpStatus int) {
+// JSONErrorWithCtx builds and returns the error response, with the HTTP status matching the error kind, while also adding it as an attribute to the New Relic transaction.
+// The response is a problem details document when the request accepts application/problem+json.
+func JSONErrorWithCtx(ctx context.Context, w http.ResponseWriter, err error) {
+	status := HTTPStatus(err)
+	info, _ := ctx.Value(requestInfoKey{}).(requestInfo)
+	if info.problemDetails {
+		res := buildProblemDetails(err, status, info.path)
+		logErrorBody(ctx, res.Detail, res.Errors)
+		writeJSON(w, status, ProblemJSONContentType, res)
+		return
+	}
+
 	res := buildResultError(err)
-	logErrorBody(ctx, res)
-	JSONReturnWithCtx(ctx, w, httpStatus, res)
+	logErrorBody(ctx, res.Details, res.Errors)
+	JSONReturnWithCtx(ctx, w, status, res)
 }
 
 // JSONReturnWithCtx returns server response in JSON format.
 func JSONReturnWithCtx(ctx context.Context, w http.ResponseWriter, statusCode int, jsonObject interface{}) {
+	writeJSON(w, statusCode, "application/json", jsonObject)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, contentType string, jsonObject interface{}) {
 	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
 	w.WriteHeader(statusCode)
 	err := json.NewEncoder(w).Encode(jsonObject)
 	if err != nil {
@@ -45,6 +165,6 @@ func JSONReturnWithCtx(ctx context.Context, w http.ResponseWriter, statusCode in
 }
 
 // logErrorBody adds the error to New Relic log and error details to de APM error distributed transaction
-func logErrorBody(ctx context.Context, res resultError) {
-	log.Println(res.Details, res.Meta)
+func logErrorBody(ctx context.Context, message string, fieldErrors []fieldError) {
+	log.Println(message, fieldErrors)
 }
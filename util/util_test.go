@@ -0,0 +1,128 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/device-ms/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	t.Run("status by error kind", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, HTTPStatus(errors.RequiredParameterError("brand", "body")))
+		require.Equal(t, http.StatusBadRequest, HTTPStatus(errors.DecodeError(fmt.Errorf("EOF"))))
+		require.Equal(t, http.StatusNotFound, HTTPStatus(errors.CouldNotFindObject("device", "1")))
+		require.Equal(t, http.StatusConflict, HTTPStatus(errors.BrandInUseError("brand1", 1)))
+		require.Equal(t, http.StatusPreconditionFailed, HTTPStatus(errors.VersionMismatchError("device", "1")))
+		require.Equal(t, http.StatusPreconditionRequired, HTTPStatus(errors.PreconditionRequiredError("If-Match")))
+		require.Equal(t, http.StatusUnsupportedMediaType, HTTPStatus(errors.UnsupportedMediaTypeError("text/plain", "application/json")))
+		require.Equal(t, http.StatusUnprocessableEntity, HTTPStatus(errors.IdempotencyKeyReusedError("key1")))
+		require.Equal(t, http.StatusConflict, HTTPStatus(errors.RequestInProgressError("key1")))
+		require.Equal(t, http.StatusConflict, HTTPStatus(errors.IllegalTransitionError("device", "1", "inUse", "retired")))
+		require.Equal(t, http.StatusConflict, HTTPStatus(errors.DeviceHasChildrenError("1", 2)))
+		require.Equal(t, http.StatusUnauthorized, HTTPStatus(errors.UnauthorizedError("device", "1")))
+		require.Equal(t, http.StatusInternalServerError, HTTPStatus(errors.UpdateError("device", "timeout")))
+		require.Equal(t, http.StatusInternalServerError, HTTPStatus(fmt.Errorf("errMock")))
+	})
+
+	t.Run("wrapped error", func(t *testing.T) {
+		err := fmt.Errorf("getting device: %w", errors.CouldNotFindObject("device", "1"))
+		require.Equal(t, http.StatusNotFound, HTTPStatus(err))
+	})
+}
+
+func TestJSONErrorWithCtx(t *testing.T) {
+	t.Run("cust error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		JSONErrorWithCtx(context.Background(), w, errors.CouldNotFindObject("device", "1"))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "{\"result\":false,\"code\":1500005,\"message\":\"the device with id 1 could not be found\"}\n", w.Body.String())
+	})
+
+	t.Run("any error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		JSONErrorWithCtx(context.Background(), w, fmt.Errorf("errMock"))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "{\"result\":false,\"code\":1500012,\"message\":\"unexpected error: errMock\"}\n", w.Body.String())
+	})
+}
+
+func TestJSONErrorWithCtxInvalidFields(t *testing.T) {
+	err := errors.Join(
+		errors.RequiredParameterError("brand", "body"),
+		errors.InvalidParameterError("limit", "must be between 1 and 500"),
+	)
+
+	t.Run("result error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		JSONErrorWithCtx(context.Background(), w, err)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, "{\"result\":false,\"code\":1500013,\"message\":\"the request has 2 invalid parameters\",\"errors\":["+
+			"{\"field\":\"brand\",\"code\":1500001,\"message\":\"parameter 'brand' in body is required\"},"+
+			"{\"field\":\"limit\",\"code\":1500002,\"message\":\"parameter 'limit' is invalid 'must be between 1 and 500'\"}]}\n", w.Body.String())
+	})
+
+	t.Run("problem details", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/device?limit=0", nil)
+		r.Header.Set("Accept", "application/problem+json")
+		WithRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			JSONErrorWithCtx(r.Context(), w, err)
+		})).ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, "application/problem+json; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, "{\"type\":\"urn:device-ms:error:invalidRequest\",\"title\":\"Bad Request\",\"status\":400,"+
+			"\"detail\":\"the request has 2 invalid parameters\",\"instance\":\"/device\",\"code\":1500013,\"errors\":["+
+			"{\"field\":\"brand\",\"code\":1500001,\"message\":\"parameter 'brand' in body is required\"},"+
+			"{\"field\":\"limit\",\"code\":1500002,\"message\":\"parameter 'limit' is invalid 'must be between 1 and 500'\"}]}\n", w.Body.String())
+	})
+}
+
+func TestProblemDetails(t *testing.T) {
+	serve := func(accept string, err error) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/device/1", nil)
+		r.Header.Set("Accept", accept)
+		WithRequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			JSONErrorWithCtx(r.Context(), w, err)
+		})).ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		w := serve("application/json, application/problem+json", errors.CouldNotFindObject("device", "1"))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "{\"type\":\"urn:device-ms:error:couldNotFindObject\",\"title\":\"Not Found\",\"status\":404,"+
+			"\"detail\":\"the device with id 1 could not be found\",\"instance\":\"/device/1\",\"code\":1500005}\n", w.Body.String())
+	})
+
+	t.Run("single invalid field", func(t *testing.T) {
+		w := serve("application/problem+json", errors.InvalidParameterError("id", "invalid object id [1]"))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, "{\"type\":\"urn:device-ms:error:invalidParameter\",\"title\":\"Bad Request\",\"status\":400,"+
+			"\"detail\":\"parameter 'id' is invalid 'invalid object id [1]'\",\"instance\":\"/device/1\",\"code\":1500002,"+
+			"\"errors\":[{\"field\":\"id\",\"code\":1500002,\"message\":\"parameter 'id' is invalid 'invalid object id [1]'\"}]}\n", w.Body.String())
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		w := serve("application/problem+json;q=0, application/json", errors.CouldNotFindObject("device", "1"))
+		require.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, "{\"result\":false,\"code\":1500005,\"message\":\"the device with id 1 could not be found\"}\n", w.Body.String())
+	})
+}
+
+func TestAccepts(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/device/import", nil)
+	require.False(t, Accepts(r, "text/csv"))
+
+	r.Header.Set("Accept", "application/json, text/csv")
+	require.True(t, Accepts(r, "text/csv"))
+
+	r.Header.Set("Accept", "text/csv;q=0, */*")
+	require.False(t, Accepts(r, "text/csv"))
+}